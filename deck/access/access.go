@@ -0,0 +1,127 @@
+package access
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	"cardGame/deck/model"
+)
+
+// Visibility says who may see the cards in a location. Everyone may always
+// see how many cards it holds.
+type Visibility string
+
+const (
+	Public Visibility = "public"
+	// Owner cards are seen only by the player the location belongs to.
+	Owner  Visibility = "owner"
+	Hidden Visibility = "hidden"
+)
+
+const handPrefix = "hand:"
+
+// HandPile names the deck pile holding player's hand.
+func HandPile(player string) string {
+	return handPrefix + player
+}
+
+// OwnerOf returns the player a pile belongs to, or "" for a shared pile.
+func OwnerOf(pile string) string {
+	if strings.HasPrefix(pile, handPrefix) {
+		return pile[len(handPrefix):]
+	}
+	return ""
+}
+
+// Policy assigns a visibility to every location of a deck: the stock of
+// undealt cards and each named pile. Piles not listed are owner-only if they
+// are hands and Default otherwise.
+type Policy struct {
+	Stock   Visibility
+	Piles   map[string]Visibility
+	Default Visibility
+}
+
+// DefaultPolicy hides the stock, shows hands to their owners and every
+// other pile to everyone.
+var DefaultPolicy = Policy{Stock: Hidden, Default: Public}
+
+// Pile returns the visibility of the named pile.
+func (p Policy) Pile(name string) Visibility {
+	if v, ok := p.Piles[name]; ok {
+		return v
+	}
+	if OwnerOf(name) != "" {
+		return Owner
+	}
+	return p.Default
+}
+
+// Visible reports whether viewer may see the cards of a location owned by
+// owner.
+func Visible(v Visibility, owner, viewer string) bool {
+	switch v {
+	case Public:
+		return true
+	case Owner:
+		return owner != "" && owner == viewer
+	}
+	return false
+}
+
+// Location is one place in a deck as a viewer sees it. Cards is left out
+// when the viewer may not see them.
+type Location struct {
+	Visibility Visibility   `json:"visibility"`
+	Owner      string       `json:"owner,omitempty"`
+	Size       int          `json:"size"`
+	Cards      []model.Card `json:"cards,omitempty"`
+}
+
+// DeckView is a deck projected for one viewer.
+type DeckView struct {
+	ID        uuid.UUID           `json:"deck_id"`
+	Shuffled  bool                `json:"shuffled"`
+	Remaining int                 `json:"remaining"`
+	Owner     string              `json:"owner,omitempty"`
+	Stock     Location            `json:"stock"`
+	Piles     map[string]Location `json:"piles,omitempty"`
+}
+
+func locate(v Visibility, owner string, cards []model.Card, viewer string) Location {
+	location := Location{Visibility: v, Owner: owner, Size: len(cards)}
+	if Visible(v, owner, viewer) {
+		location.Cards = cards
+	}
+	return location
+}
+
+// Project builds the view of deck that viewer is allowed. An empty viewer
+// is anyone who is not a player.
+func (p Policy) Project(deck model.Deck, viewer string) DeckView {
+	view := DeckView{
+		ID:        deck.ID,
+		Shuffled:  deck.Shuffled,
+		Remaining: deck.Remaining,
+		Owner:     deck.Owner,
+		Stock:     locate(p.Stock, "", deck.Cards, viewer),
+	}
+	if len(deck.Piles) > 0 {
+		view.Piles = make(map[string]Location)
+		for name, cards := range deck.Piles {
+			view.Piles[name] = locate(p.Pile(name), OwnerOf(name), cards, viewer)
+		}
+	}
+	return view
+}
+
+// Reveal builds the view of deck a commentator is allowed: every hand shown
+// as if to its owner, with hidden locations still hidden.
+func (p Policy) Reveal(deck model.Deck) DeckView {
+	view := p.Project(deck, "")
+	for name, cards := range deck.Piles {
+		view.Piles[name] = locate(p.Pile(name), OwnerOf(name), cards, OwnerOf(name))
+	}
+	return view
+}
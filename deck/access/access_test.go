@@ -0,0 +1,66 @@
+package access
+
+import (
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+func TestProject(t *testing.T) {
+	deck := model.NewDeck(false, "AS,2S,3S,4S,5S,6S,7S")
+	dealt, _ := deck.DrawCards(5)
+	deck.AddToPile(HandPile("ann"), dealt[0:2]...)
+	deck.AddToPile(HandPile("bob"), dealt[2:4]...)
+	deck.AddToPile("discard", dealt[4])
+
+	view := DefaultPolicy.Project(deck, "ann")
+	if view.Stock.Size != 2 || view.Stock.Cards != nil || view.Remaining != 2 {
+		t.Errorf("Stock was not hidden: %+v", view.Stock)
+	}
+	if hand := view.Piles["hand:ann"]; hand.Owner != "ann" || len(hand.Cards) != 2 {
+		t.Errorf("Ann cannot see her hand: %+v", hand)
+	}
+	if hand := view.Piles["hand:bob"]; hand.Size != 2 || hand.Cards != nil || hand.Visibility != Owner {
+		t.Errorf("Ann can see Bob's hand: %+v", hand)
+	}
+	if discard := view.Piles["discard"]; len(discard.Cards) != 1 || discard.Visibility != Public {
+		t.Errorf("Discard pile is not public: %+v", discard)
+	}
+
+	view = DefaultPolicy.Project(deck, "")
+	if view.Piles["hand:ann"].Cards != nil || view.Piles["hand:bob"].Cards != nil {
+		t.Errorf("An onlooker can see a hand: %+v", view.Piles)
+	}
+
+	policy := Policy{Stock: Public, Piles: map[string]Visibility{"discard": Hidden}, Default: Public}
+	view = policy.Project(deck, "bob")
+	if len(view.Stock.Cards) != 2 || view.Piles["discard"].Cards != nil || len(view.Piles["hand:bob"].Cards) != 2 {
+		t.Errorf("Policy was not applied: %+v", view)
+	}
+}
+
+func TestReveal(t *testing.T) {
+	deck := model.NewDeck(false, "AS,2S,3S,4S,5S")
+	dealt, _ := deck.DrawCards(3)
+	deck.AddToPile(HandPile("ann"), dealt[0])
+	deck.AddToPile(HandPile("bob"), dealt[1])
+	deck.AddToPile("burn", dealt[2])
+
+	policy := Policy{Stock: Hidden, Piles: map[string]Visibility{"burn": Hidden}, Default: Public}
+	view := policy.Reveal(deck)
+	if len(view.Piles["hand:ann"].Cards) != 1 || len(view.Piles["hand:bob"].Cards) != 1 {
+		t.Errorf("Hands should be revealed: %+v", view.Piles)
+	}
+	if view.Stock.Cards != nil || view.Piles["burn"].Cards != nil || view.Piles["burn"].Size != 1 {
+		t.Errorf("Hidden cards were revealed: %+v", view)
+	}
+}
+
+func TestOwnerOf(t *testing.T) {
+	if owner := OwnerOf(HandPile("ann")); owner != "ann" {
+		t.Errorf("OwnerOf(hand:ann) = %q", owner)
+	}
+	if owner := OwnerOf("discard"); owner != "" {
+		t.Errorf("OwnerOf(discard) = %q", owner)
+	}
+}
@@ -0,0 +1,74 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrAccountNotFound = errors.New("Account not found")
+	ErrAccountExists   = errors.New("Player ID is taken")
+	// ErrBadCredentials is returned for an unknown player and a wrong
+	// password alike, so logins do not reveal which IDs exist.
+	ErrBadCredentials = errors.New("Wrong player ID or password")
+)
+
+// MinPassword is the shortest password accepted.
+const MinPassword = 8
+
+var validID = regexp.MustCompile(`^[a-z0-9_-]{2,32}$`)
+
+// Profile is what a player shows others.
+type Profile struct {
+	DisplayName string `json:"display_name,omitempty"`
+	Avatar      string `json:"avatar,omitempty"`
+	Country     string `json:"country,omitempty"`
+	Bio         string `json:"bio,omitempty"`
+}
+
+// Account is a registered player. Its ID is the player ID used everywhere
+// on the server: as a player in games, in hand pile names and as a deck's
+// owner.
+type Account struct {
+	ID           string    `json:"id"`
+	Profile      Profile   `json:"profile"`
+	Created      time.Time `json:"created"`
+	PasswordHash string    `json:"-"`
+}
+
+// ValidateID checks a new player ID: 2 to 32 lower case letters, digits,
+// underscores or hyphens.
+func ValidateID(id string) error {
+	if !validID.MatchString(id) {
+		return fmt.Errorf("Player IDs are 2 to 32 lower case letters, digits, _ or -")
+	}
+	return nil
+}
+
+func ValidatePassword(password string) error {
+	if utf8.RuneCountInString(password) < MinPassword {
+		return fmt.Errorf("Passwords need at least %v characters", MinPassword)
+	}
+	return nil
+}
+
+func (p Profile) Validate() error {
+	limits := []struct {
+		field, value string
+		max          int
+	}{
+		{"display_name", p.DisplayName, 64},
+		{"avatar", p.Avatar, 512},
+		{"country", p.Country, 2},
+		{"bio", p.Bio, 1000},
+	}
+	for _, l := range limits {
+		if utf8.RuneCountInString(l.value) > l.max {
+			return fmt.Errorf("%v is longer than %v characters", l.field, l.max)
+		}
+	}
+	return nil
+}
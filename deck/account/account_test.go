@@ -0,0 +1,40 @@
+package account
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateID(t *testing.T) {
+	for _, id := range []string{"ann", "b0b", "cat_9", "x-y"} {
+		if err := ValidateID(id); err != nil {
+			t.Errorf("%q rejected: %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "a", "Ann", "ann smith", "hand:ann", strings.Repeat("a", 33)} {
+		if err := ValidateID(id); err == nil {
+			t.Errorf("%q accepted", id)
+		}
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	if ValidatePassword("short") == nil {
+		t.Errorf("Short password accepted")
+	}
+	if err := ValidatePassword("long enough"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	if err := (Profile{DisplayName: "Ann", Country: "NZ"}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if (Profile{Country: "NZL"}).Validate() == nil {
+		t.Errorf("Three letter country accepted")
+	}
+	if (Profile{Bio: strings.Repeat("x", 1001)}).Validate() == nil {
+		t.Errorf("Long bio accepted")
+	}
+}
@@ -0,0 +1,62 @@
+package account
+
+import (
+	"errors"
+	"time"
+)
+
+// Defaults for a Limiter: five failed logins in fifteen minutes lock a
+// player ID out until the fifteen minutes are up.
+const (
+	DefaultMaxFailures = 5
+	DefaultLockout     = 15 * time.Minute
+)
+
+var ErrTooManyAttempts = errors.New("Too many failed logins, try again later")
+
+type failures struct {
+	count int
+	since time.Time
+}
+
+// Limiter counts login attempts per player ID. Every attempt counts as a
+// failure until it succeeds, so attempts made at the same time are limited
+// too. It is not safe for concurrent use.
+type Limiter struct {
+	MaxFailures int
+	Lockout     time.Duration
+	failures    map[string]failures
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{MaxFailures: DefaultMaxFailures, Lockout: DefaultLockout, failures: make(map[string]failures)}
+}
+
+// Attempt records a login attempt for id, or refuses it when id has failed
+// MaxFailures times since the lockout period began.
+func (l *Limiter) Attempt(id string, now time.Time) error {
+	f, ok := l.failures[id]
+	if !ok || !now.Before(f.since.Add(l.Lockout)) {
+		f = failures{since: now}
+	}
+	if f.count >= l.MaxFailures {
+		return ErrTooManyAttempts
+	}
+	f.count++
+	l.failures[id] = f
+	return nil
+}
+
+// Succeed forgets the failures of id after a successful login.
+func (l *Limiter) Succeed(id string) {
+	delete(l.failures, id)
+}
+
+// Sweep forgets the failures whose lockout period is over.
+func (l *Limiter) Sweep(now time.Time) {
+	for id, f := range l.failures {
+		if !now.Before(f.since.Add(l.Lockout)) {
+			delete(l.failures, id)
+		}
+	}
+}
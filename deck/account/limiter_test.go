@@ -0,0 +1,35 @@
+package account
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter()
+	start := time.Unix(0, 0)
+
+	for i := 0; i < DefaultMaxFailures; i++ {
+		if err := limiter.Attempt("ann", start); err != nil {
+			t.Fatalf("Attempt %v refused: %v", i+1, err)
+		}
+	}
+	if err := limiter.Attempt("ann", start.Add(time.Minute)); err != ErrTooManyAttempts {
+		t.Errorf("Attempt allowed after %v failures: %v", DefaultMaxFailures, err)
+	}
+	if err := limiter.Attempt("bob", start); err != nil {
+		t.Errorf("Another player was locked out: %v", err)
+	}
+	if err := limiter.Attempt("ann", start.Add(DefaultLockout)); err != nil {
+		t.Errorf("Attempt refused after the lockout: %v", err)
+	}
+
+	limiter.Succeed("ann")
+	if len(limiter.failures) != 1 {
+		t.Errorf("Succeed kept the failures: %v", limiter.failures)
+	}
+	limiter.Sweep(start.Add(DefaultLockout))
+	if len(limiter.failures) != 0 {
+		t.Errorf("Sweep kept failures from an earlier lockout period: %v", limiter.failures)
+	}
+}
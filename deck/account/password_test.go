@@ -0,0 +1,41 @@
+package account
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// RFC 7914, section 11: PBKDF2-HMAC-SHA256 with P="passwd", S="salt", c=1.
+	key := pbkdf2([]byte("passwd"), []byte("salt"), 1, 64)
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got := hex.EncodeToString(key); got != want {
+		t.Errorf("Unexpected key %v", got)
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	Iterations = 1000
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "pbkdf2-sha256$1000$") {
+		t.Errorf("Unexpected hash format %v", hash)
+	}
+	if !CheckPassword(hash, "correct horse") {
+		t.Errorf("Password does not match its own hash")
+	}
+	if CheckPassword(hash, "correct horsE") {
+		t.Errorf("Wrong password matched")
+	}
+	if again, _ := HashPassword("correct horse"); again == hash {
+		t.Errorf("Hashes should be salted")
+	}
+	for _, bad := range []string{"", "plain", "md5$1$a$b", "pbkdf2-sha256$x$a$b", "pbkdf2-sha256$0$AA$AA"} {
+		if CheckPassword(bad, "") {
+			t.Errorf("Malformed hash %q matched", bad)
+		}
+	}
+}
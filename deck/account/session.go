@@ -0,0 +1,95 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// DefaultSessionTTL is how long a session lasts.
+const DefaultSessionTTL = 24 * time.Hour
+
+var ErrInvalidSession = errors.New("Invalid or expired session")
+
+// Session is a login. Its token is only shown when it is created; the store
+// keeps a hash of it.
+type Session struct {
+	Token   string    `json:"token,omitempty"`
+	Player  string    `json:"player"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+}
+
+// Sessions stores sessions by token hash. It is not safe for concurrent use.
+type Sessions struct {
+	TTL      time.Duration
+	sessions map[string]Session
+}
+
+func NewSessions() *Sessions {
+	return &Sessions{TTL: DefaultSessionTTL, sessions: make(map[string]Session)}
+}
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Start opens a session for player and returns it with its token.
+func (s *Sessions) Start(player string, now time.Time) (Session, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return Session{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	session := Session{Player: player, Created: now, Expires: now.Add(s.TTL)}
+	s.sessions[tokenHash(token)] = session
+	session.Token = token
+	return session, nil
+}
+
+// Check returns the live session for token. Expired sessions are dropped.
+func (s *Sessions) Check(token string, now time.Time) (Session, error) {
+	key := tokenHash(token)
+	session, ok := s.sessions[key]
+	if !ok {
+		return Session{}, ErrInvalidSession
+	}
+	if !now.Before(session.Expires) {
+		delete(s.sessions, key)
+		return Session{}, ErrInvalidSession
+	}
+	return session, nil
+}
+
+// Sweep drops every expired session and returns how many there were.
+func (s *Sessions) Sweep(now time.Time) int {
+	count := 0
+	for key, session := range s.sessions {
+		if !now.Before(session.Expires) {
+			delete(s.sessions, key)
+			count++
+		}
+	}
+	return count
+}
+
+// Revoke ends the session for token.
+func (s *Sessions) Revoke(token string) {
+	delete(s.sessions, tokenHash(token))
+}
+
+// RevokeAll ends every session of player and returns how many there were.
+func (s *Sessions) RevokeAll(player string) int {
+	count := 0
+	for key, session := range s.sessions {
+		if session.Player == player {
+			delete(s.sessions, key)
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,55 @@
+package account
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessions(t *testing.T) {
+	sessions := NewSessions()
+	now := time.Unix(0, 0)
+	ann, err := sessions.Start("ann", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := sessions.Start("ann", now)
+	bob, _ := sessions.Start("bob", now)
+	if ann.Token == "" || ann.Token == second.Token {
+		t.Fatalf("Tokens should be random: %v %v", ann.Token, second.Token)
+	}
+	if _, ok := sessions.sessions[ann.Token]; ok {
+		t.Errorf("Token stored in the clear")
+	}
+
+	session, err := sessions.Check(ann.Token, now.Add(time.Hour))
+	if err != nil || session.Player != "ann" || session.Token != "" {
+		t.Errorf("Unexpected session %+v: %v", session, err)
+	}
+	if _, err := sessions.Check("nope", now); err != ErrInvalidSession {
+		t.Errorf("Unknown token accepted")
+	}
+
+	sessions.Revoke(second.Token)
+	if _, err := sessions.Check(second.Token, now); err != ErrInvalidSession {
+		t.Errorf("Revoked session still valid")
+	}
+	if n := sessions.RevokeAll("ann"); n != 1 {
+		t.Errorf("Revoked %v sessions, want 1", n)
+	}
+	if _, err := sessions.Check(ann.Token, now); err != ErrInvalidSession {
+		t.Errorf("Session survived RevokeAll")
+	}
+
+	if _, err := sessions.Check(bob.Token, now.Add(DefaultSessionTTL)); err != ErrInvalidSession {
+		t.Errorf("Expired session still valid")
+	}
+	if len(sessions.sessions) != 0 {
+		t.Errorf("Expired session kept: %v", sessions.sessions)
+	}
+
+	sessions.Start("cat", now)
+	sessions.Start("dan", now.Add(time.Hour))
+	if n := sessions.Sweep(now.Add(DefaultSessionTTL)); n != 1 || len(sessions.sessions) != 1 {
+		t.Errorf("Sweep dropped %v sessions, kept %v", n, len(sessions.sessions))
+	}
+}
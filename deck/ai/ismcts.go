@@ -0,0 +1,163 @@
+package ai
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"cardGame/deck/game"
+)
+
+const (
+	// Exploration weighs trying rarely chosen moves against repeating good
+	// ones. Rewards run from 0 to 1.
+	Exploration = 0.7
+	// MaxRollout is how many random moves a playout makes before it is
+	// judged on the scores so far.
+	MaxRollout = 300
+)
+
+// node is one move in the search tree, shared by every determinization in
+// which it was legal. reward sums the results of the player who made it.
+type node struct {
+	action   game.Action
+	player   string
+	parent   *node
+	children []*node
+	visits   int
+	avail    int
+	reward   float64
+}
+
+func (n *node) child(player string, action game.Action) *node {
+	for _, c := range n.children {
+		if c.player == player && c.action.Equal(action) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Search runs information set Monte Carlo tree search for player, choosing
+// among actions. Each iteration plays out a fresh determinization from
+// sample; the search stops after iterations of them, at the deadline, or
+// once sample returns nil. It returns the move tried most, and how many
+// iterations were run.
+func Search(sample func(*rand.Rand) game.Game, player string, actions []game.Action, iterations int, deadline time.Time, rng *rand.Rand) (game.Action, int) {
+	root := &node{}
+	done := 0
+	for ; done < iterations && time.Now().Before(deadline); done++ {
+		g := sample(rng)
+		if g == nil {
+			break
+		}
+		iterate(root, g, player, actions, rng)
+	}
+
+	var best *node
+	for _, c := range root.children {
+		if best == nil || c.visits > best.visits {
+			best = c
+		}
+	}
+	if best == nil {
+		return actions[rng.Intn(len(actions))], done
+	}
+	return best.action, done
+}
+
+// iterate selects a path down the tree that is legal in g, adds one new
+// move to it, plays the rest of the game out at random and credits every
+// move on the path with its player's result.
+func iterate(root *node, g game.Game, player string, actions []game.Action, rng *rand.Rand) {
+	n := root
+	mover, legal := player, actions
+	for !g.Terminal() && mover != "" {
+		var untried []game.Action
+		for _, action := range legal {
+			if n.child(mover, action) == nil {
+				untried = append(untried, action)
+			}
+		}
+		if len(untried) > 0 {
+			action := untried[rng.Intn(len(untried))]
+			child := &node{action: action, player: mover, parent: n}
+			n.children = append(n.children, child)
+			n = child
+			g.Apply(mover, action)
+			break
+		}
+
+		var best *node
+		bestScore := math.Inf(-1)
+		for _, action := range legal {
+			c := n.child(mover, action)
+			c.avail++
+			score := c.reward/float64(c.visits) + Exploration*math.Sqrt(math.Log(float64(c.avail))/float64(c.visits))
+			if score > bestScore {
+				best, bestScore = c, score
+			}
+		}
+		n = best
+		if g.Apply(mover, best.action) != nil {
+			break
+		}
+		mover, legal = toAct(g)
+	}
+
+	rollout(g, rng)
+	rewards := results(g)
+	for ; n != root; n = n.parent {
+		n.visits++
+		n.reward += rewards[n.player]
+	}
+}
+
+// toAct returns the first player with a move and their moves.
+func toAct(g game.Game) (string, []game.Action) {
+	for _, p := range g.Players() {
+		if actions := g.LegalActions(p); len(actions) > 0 {
+			return p, actions
+		}
+	}
+	return "", nil
+}
+
+func rollout(g game.Game, rng *rand.Rand) {
+	for moves := 0; moves < MaxRollout && !g.Terminal(); moves++ {
+		player, actions := toAct(g)
+		if player == "" || g.Apply(player, actions[rng.Intn(len(actions))]) != nil {
+			return
+		}
+	}
+}
+
+// results scores a played out game: 1 for the leaders, 0 for the rest, and
+// a half each when the scores are level. A player alone wins with a positive
+// score.
+func results(g game.Game) map[string]float64 {
+	scores := g.Scores()
+	players := g.Players()
+	rewards := make(map[string]float64)
+	if len(players) == 1 {
+		if scores[players[0]] > 0 {
+			rewards[players[0]] = 1
+		}
+		return rewards
+	}
+
+	best, worst := scores[players[0]], scores[players[0]]
+	for _, p := range players {
+		best = max(best, scores[p])
+		worst = min(worst, scores[p])
+	}
+	for _, p := range players {
+		switch {
+		case best == worst:
+			rewards[p] = 0.5
+		case scores[p] == best:
+			rewards[p] = 1
+		}
+	}
+	return rewards
+}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+	"cardGame/deck/shedding"
+)
+
+// lastCard sets up Crazy Eights with one card each: ann holds 5H, bob 9C
+// and 6H is turned up, so ann wins by playing and not by drawing.
+func lastCard(t *testing.T) game.Game {
+	t.Helper()
+	g := shedding.NewGame()
+	config := game.Config{Players: []string{"ann", "bob"}, Options: map[string]string{"hand_size": "1"}}
+	if err := g.Setup(model.NewDeck(false, "5H,9C,6H,2S,3S,4S,7D"), config); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func sampler(g game.Game, player string) func(*rand.Rand) game.Game {
+	return func(rng *rand.Rand) game.Game {
+		return g.(game.Determinizer).Determinize(player, rng)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	g := lastCard(t)
+	actions := g.LegalActions("ann")
+	rng := rand.New(rand.NewSource(1))
+
+	action, done := Search(sampler(g, "ann"), "ann", actions, 200, time.Now().Add(time.Minute), rng)
+	if done != 200 || action.Type != "play" || action.Cards[0] != "5H" {
+		t.Errorf("Search should find the winning play: %+v after %v iterations", action, done)
+	}
+	if g.Terminal() || len(g.LegalActions("ann")) != len(actions) {
+		t.Errorf("Search changed the game")
+	}
+
+	start := time.Now()
+	if _, done := Search(sampler(g, "ann"), "ann", actions, 1<<30, start.Add(50*time.Millisecond), rng); done == 0 || time.Since(start) > time.Second {
+		t.Errorf("Search overran its deadline: %v iterations in %v", done, time.Since(start))
+	}
+
+	none := func(*rand.Rand) game.Game { return nil }
+	if action, done := Search(none, "ann", actions, 200, time.Now().Add(time.Minute), rng); done != 0 || !game.IsLegal(g, "ann", action) {
+		t.Errorf("Search without samples should fall back to a legal move: %+v", action)
+	}
+}
+
+func TestResults(t *testing.T) {
+	g := lastCard(t)
+	if rewards := results(g); rewards["ann"] != 0.5 || rewards["bob"] != 0.5 {
+		t.Errorf("Level scores should share the reward: %v", rewards)
+	}
+	g.Apply("ann", game.Action{Type: "play", Cards: []string{"5H"}})
+	if rewards := results(g); rewards["ann"] != 1 || rewards["bob"] != 0 {
+		t.Errorf("Unexpected rewards %v", rewards)
+	}
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"cardGame/deck/bot"
+	"cardGame/deck/game"
+)
+
+// Difficulty sets how many determinizations the AI searches each move.
+type Difficulty string
+
+const (
+	Easy   Difficulty = "easy"
+	Medium Difficulty = "medium"
+	Hard   Difficulty = "hard"
+)
+
+// Difficulties lists the difficulties from easiest, and Iterations their
+// search budgets.
+var (
+	Difficulties = []Difficulty{Easy, Medium, Hard}
+	Iterations   = map[Difficulty]int{Easy: 50, Medium: 500, Hard: 5000}
+)
+
+// DefaultTimeLimit is how long the AI may think about each move.
+const DefaultTimeLimit = 2 * time.Second
+
+// Player is a built-in opponent for games that implement game.Determinizer.
+// It searches determinizations of the game, so it never sees the real
+// hidden cards, for at most Iterations iterations or TimeLimit, whichever
+// ends first. In other games, and when it has only one move, it moves at
+// random without searching.
+type Player struct {
+	Iterations int
+	TimeLimit  time.Duration
+}
+
+// seeds keeps players that start in the same instant from searching alike.
+var seeds atomic.Int64
+
+// New returns a player at difficulty that takes at most timeLimit a move.
+func New(difficulty Difficulty, timeLimit time.Duration) (*Player, error) {
+	iterations, ok := Iterations[difficulty]
+	if !ok {
+		return nil, fmt.Errorf("Unknown difficulty %q", difficulty)
+	}
+	if timeLimit <= 0 {
+		return nil, fmt.Errorf("Time limit must be positive")
+	}
+	return &Player{Iterations: iterations, TimeLimit: timeLimit}, nil
+}
+
+func (p *Player) Act(turn bot.Turn) (game.Action, error) {
+	if len(turn.Actions) == 0 {
+		return game.Action{}, fmt.Errorf("No legal actions")
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano() + seeds.Add(1)))
+	if turn.Sample == nil || len(turn.Actions) == 1 {
+		return turn.Actions[rng.Intn(len(turn.Actions))], nil
+	}
+	action, _ := Search(turn.Sample, turn.Player, turn.Actions, p.Iterations, time.Now().Add(p.TimeLimit), rng)
+	return action, nil
+}
@@ -0,0 +1,36 @@
+package ai
+
+import (
+	"testing"
+	"time"
+
+	"cardGame/deck/bot"
+	"cardGame/deck/game"
+)
+
+func TestPlayer(t *testing.T) {
+	if _, err := New("impossible", time.Second); err == nil {
+		t.Errorf("New accepted an unknown difficulty")
+	}
+	if _, err := New(Easy, 0); err == nil {
+		t.Errorf("New accepted no time to think")
+	}
+	player, err := New(Medium, time.Minute)
+	if err != nil || player.Iterations != Iterations[Medium] {
+		t.Fatalf("New failed: %v %+v", err, player)
+	}
+
+	g := lastCard(t)
+	turn := bot.Turn{Player: "ann", Actions: g.LegalActions("ann"), Sample: sampler(g, "ann")}
+	if action, err := player.Act(turn); err != nil || action.Type != "play" {
+		t.Errorf("Medium should find the winning play: %+v %v", action, err)
+	}
+
+	turn.Sample = nil
+	if action, err := player.Act(turn); err != nil || !game.IsLegal(g, "ann", action) {
+		t.Errorf("Without samples the player should still move legally: %+v %v", action, err)
+	}
+	if _, err := player.Act(bot.Turn{Player: "ann"}); err == nil {
+		t.Errorf("Acted without legal actions")
+	}
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/account"
+	"cardGame/deck/service"
+)
+
+type RegisterRequest struct {
+	ID       string          `json:"id"`
+	Password string          `json:"password"`
+	Profile  account.Profile `json:"profile"`
+}
+
+type LoginRequest struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+}
+
+type PasswordRequest struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// AccountHandler serves registration, login and profiles, and its
+// Authenticate middleware ties requests to accounts.
+type AccountHandler struct {
+	AccountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		AccountService: accountService,
+	}
+}
+
+type identityKey struct{}
+
+// identity is who a request was authenticated as, and a way to tell which
+// other player IDs are taken by accounts.
+type identity struct {
+	player     string
+	registered func(string) bool
+}
+
+// Authenticate resolves the caller of every request. A request with an
+// Authorization: Bearer token acts as the session's player, who becomes the
+// X-Player for the handlers; naming anyone else is refused. A request with
+// no token may still name a guest player, but not a registered one.
+func (h *AccountHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := identity{registered: h.AccountService.Registered}
+		claimed := claimedPlayer(r)
+		if header := r.Header.Get("Authorization"); header != "" {
+			token := strings.TrimPrefix(header, "Bearer ")
+			if token == header {
+				http.Error(w, "Authorization must be a Bearer token", http.StatusUnauthorized)
+				return
+			}
+			session, err := h.AccountService.Authenticate(token)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			if claimed != "" && claimed != session.Player {
+				http.Error(w, fmt.Sprintf("Logged in as %v, not %v", session.Player, claimed), http.StatusForbidden)
+				return
+			}
+			id.player = session.Player
+		} else if claimed != "" && id.registered(claimed) {
+			http.Error(w, fmt.Sprintf("%v is a registered player; log in to act as them", claimed), http.StatusUnauthorized)
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), identityKey{}, id))
+		if id.player != "" {
+			r.Header = r.Header.Clone()
+			r.Header.Set("X-Player", id.player)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticated returns the player the request has a session for, or "".
+func authenticated(r *http.Request) string {
+	id, _ := r.Context().Value(identityKey{}).(identity)
+	return id.player
+}
+
+// mayActAs checks that the request is made for player: through their
+// session, or with an API key whose holder vouches for them. It writes the
+// error when it is not.
+func mayActAs(w http.ResponseWriter, r *http.Request, player string) bool {
+	switch p := viewer(r); {
+	case p == "":
+		http.Error(w, "Login required", http.StatusUnauthorized)
+	case p != player:
+		http.Error(w, fmt.Sprintf("Acting as %v, not %v", p, player), http.StatusForbidden)
+	default:
+		return true
+	}
+	return false
+}
+
+// self checks that the request is authenticated as the account in the path.
+func self(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	switch authenticated(r) {
+	case id:
+		return id, true
+	case "":
+		http.Error(w, "Login required", http.StatusUnauthorized)
+	default:
+		http.Error(w, "You may only change your own account", http.StatusForbidden)
+	}
+	return "", false
+}
+
+func (h *AccountHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var request RegisterRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	a, err := h.AccountService.Register(request.ID, request.Password, request.Profile)
+	if err == account.ErrAccountExists {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeAccount(w, http.StatusCreated, a, err)
+}
+
+func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	a, err := h.AccountService.Account(mux.Vars(r)["id"])
+	writeAccount(w, http.StatusOK, a, err)
+}
+
+func (h *AccountHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	id, ok := self(w, r)
+	if !ok {
+		return
+	}
+	var profile account.Profile
+	if !decode(w, r, &profile) {
+		return
+	}
+	a, err := h.AccountService.UpdateProfile(id, profile)
+	writeAccount(w, http.StatusOK, a, err)
+}
+
+// ChangePassword sets a new password and logs the player out everywhere.
+func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	id, ok := self(w, r)
+	if !ok {
+		return
+	}
+	var request PasswordRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	switch err := h.AccountService.ChangePassword(id, request.Old, request.New); {
+	case err == account.ErrBadCredentials:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Login returns a new session. Its token is shown only this once.
+func (h *AccountHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var request LoginRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	session, err := h.AccountService.Login(request.ID, request.Password)
+	switch {
+	case err == account.ErrTooManyAttempts:
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(session)
+}
+
+// Logout ends the request's session, or with all=true every session of its
+// player.
+func (h *AccountHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	player := authenticated(r)
+	if player == "" {
+		http.Error(w, "Login required", http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Query().Get("all") == "true" {
+		h.AccountService.LogoutAll(player)
+	} else {
+		h.AccountService.Logout(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAccount(w http.ResponseWriter, status int, a account.Account, err error) {
+	switch {
+	case err == account.ErrAccountNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(a)
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/account"
+	"cardGame/deck/dao"
+	"cardGame/deck/service"
+)
+
+func newAccountRouter() *mux.Router {
+	account.Iterations = 1000
+	handler := NewAccountHandler(service.NewAccountService())
+	storage := dao.NewDeckStorage()
+	deckHandler := NewDeckHandler(service.NewDeckService(storage), storage)
+	ledgerHandler := NewLedgerHandler(service.NewLedgerService())
+	apiKeyService := service.NewAPIKeyService()
+	apiKeyService.Import("admin", testAdminKey)
+
+	router := mux.NewRouter()
+	router.Use(NewAPIKeyHandler(apiKeyService, false).Authenticate, handler.Authenticate)
+	router.HandleFunc("/deck", deckHandler.CreateDeck).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/draw", deckHandler.DrawCards).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/deal", deckHandler.Deal).Methods("POST")
+	router.HandleFunc("/deck/{deckID}/claim", deckHandler.Claim).Methods("POST")
+	router.HandleFunc("/ledger/players/{player}/buyin", ledgerHandler.BuyIn).Methods("POST")
+	router.HandleFunc("/ledger/transfers", ledgerHandler.Transfer).Methods("POST")
+	router.HandleFunc("/accounts", handler.Register).Methods("POST")
+	router.HandleFunc("/accounts/{id}", handler.GetAccount).Methods("GET")
+	router.HandleFunc("/accounts/{id}/profile", handler.UpdateProfile).Methods("PUT")
+	router.HandleFunc("/accounts/{id}/password", handler.ChangePassword).Methods("POST")
+	router.HandleFunc("/sessions", handler.Login).Methods("POST")
+	router.HandleFunc("/sessions", handler.Logout).Methods("DELETE")
+	return router
+}
+
+func serveWithToken(router *mux.Router, token, player, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if player != "" {
+		req.Header.Set("X-Player", player)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// register creates an account and returns a session token for it.
+func register(t *testing.T, router *mux.Router, id string) string {
+	t.Helper()
+	if rr := serve(router, "POST", "/accounts", `{"id": "`+id+`", "password": "`+id+` password"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("Register returned %v: %v", rr.Code, rr.Body.String())
+	}
+	rr := serve(router, "POST", "/sessions", `{"id": "`+id+`", "password": "`+id+` password"}`)
+	var session account.Session
+	if err := json.NewDecoder(rr.Body).Decode(&session); err != nil || session.Token == "" {
+		t.Fatalf("Login returned %v: %v", rr.Code, rr.Body.String())
+	}
+	return session.Token
+}
+
+func TestAccountHandler_Register(t *testing.T) {
+	router := newAccountRouter()
+	register(t, router, "ann")
+
+	if rr := serve(router, "POST", "/accounts", `{"id": "ann", "password": "another password"}`); rr.Code != http.StatusConflict {
+		t.Errorf("Register with a taken ID returned %v", rr.Code)
+	}
+	if rr := serve(router, "POST", "/accounts", `{"id": "bob", "password": "short"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Register with a short password returned %v", rr.Code)
+	}
+	if rr := serve(router, "POST", "/sessions", `{"id": "ann", "password": "wrong password"}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Login with a wrong password returned %v", rr.Code)
+	}
+	rr := serve(router, "GET", "/accounts/ann", "")
+	if rr.Code != http.StatusOK || strings.Contains(rr.Body.String(), "pbkdf2") {
+		t.Errorf("GetAccount returned %v: %v", rr.Code, rr.Body.String())
+	}
+	if rr := serve(router, "GET", "/accounts/bob", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("GetAccount for an unknown player returned %v", rr.Code)
+	}
+}
+
+func TestAccountHandler_Authenticate(t *testing.T) {
+	router := newAccountRouter()
+	ann := register(t, router, "ann")
+
+	if rr := serveWithToken(router, "", "ann", "GET", "/deck", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Claiming a registered player without a session returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, "", "", "GET", "/deck?player=ann", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Claiming a registered player by query returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, ann, "bob", "GET", "/deck", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Claiming another player with a session returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, "nonsense", "", "GET", "/deck", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("An unknown token returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, "", "guest", "GET", "/deck", ""); rr.Code != http.StatusOK {
+		t.Errorf("A guest player returned %v", rr.Code)
+	}
+
+	serveWithAPIKey(router, testAdminKey, "POST", "/ledger/players/ann/buyin", `{"amount": 100}`)
+	transfer := `{"from": "ann", "to": "guest", "amount": 10}`
+	if rr := serveWithToken(router, "", "", "POST", "/ledger/transfers", transfer); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Transfer from a registered player without a session returned %v", rr.Code)
+	}
+	bob := register(t, router, "bob")
+	if rr := serveWithToken(router, bob, "", "POST", "/ledger/transfers", transfer); rr.Code != http.StatusForbidden {
+		t.Errorf("Transfer from another player returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, ann, "", "POST", "/ledger/transfers", transfer); rr.Code != http.StatusOK {
+		t.Errorf("Transfer by its owner returned %v: %v", rr.Code, rr.Body.String())
+	}
+
+	if rr := serveWithToken(router, ann, "", "DELETE", "/sessions", ""); rr.Code != http.StatusNoContent {
+		t.Errorf("Logout returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, ann, "", "GET", "/deck", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("A logged out token returned %v", rr.Code)
+	}
+
+	for i := 0; i < account.DefaultMaxFailures; i++ {
+		serve(router, "POST", "/sessions", `{"id": "bob", "password": "guess"}`)
+	}
+	if rr := serve(router, "POST", "/sessions", `{"id": "bob", "password": "bob password"}`); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Login after too many failures returned %v", rr.Code)
+	}
+}
+
+func TestAccountHandler_Profile(t *testing.T) {
+	router := newAccountRouter()
+	ann := register(t, router, "ann")
+	bob := register(t, router, "bob")
+
+	profile := `{"display_name": "Ann", "country": "NZ"}`
+	if rr := serveWithToken(router, "", "", "PUT", "/accounts/ann/profile", profile); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Anonymous profile update returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, bob, "", "PUT", "/accounts/ann/profile", profile); rr.Code != http.StatusForbidden {
+		t.Errorf("Updating another player's profile returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, ann, "", "PUT", "/accounts/ann/profile", profile); rr.Code != http.StatusOK {
+		t.Errorf("Profile update returned %v: %v", rr.Code, rr.Body.String())
+	}
+	var a account.Account
+	json.NewDecoder(serve(router, "GET", "/accounts/ann", "").Body).Decode(&a)
+	if a.Profile.DisplayName != "Ann" || a.Profile.Country != "NZ" {
+		t.Errorf("Profile not updated: %+v", a)
+	}
+
+	change := `{"old": "ann password", "new": "better password"}`
+	if rr := serveWithToken(router, ann, "", "POST", "/accounts/ann/password", change); rr.Code != http.StatusNoContent {
+		t.Fatalf("ChangePassword returned %v: %v", rr.Code, rr.Body.String())
+	}
+	if rr := serveWithToken(router, ann, "", "GET", "/accounts/ann", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Session survived a password change: %v", rr.Code)
+	}
+}
+
+func TestAccountHandler_DeckOwnership(t *testing.T) {
+	router := newAccountRouter()
+	ann := register(t, router, "ann")
+	bob := register(t, router, "bob")
+
+	var created CreateDeckResponse
+	json.NewDecoder(serveWithToken(router, ann, "", "GET", "/deck", "").Body).Decode(&created)
+	if created.Owner != "ann" {
+		t.Fatalf("Deck created with a session should be owned: %+v", created)
+	}
+	draw := "/deck/" + created.DeckID.String() + "/draw?count=1"
+	if rr := serve(router, "GET", draw, ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Anonymous draw from an owned deck returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, bob, "", "GET", draw, ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Draw from another player's deck returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, ann, "", "GET", draw, ""); rr.Code != http.StatusOK {
+		t.Errorf("Owner's draw returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, bob, "", "GET", "/deck?deckId="+created.DeckID.String(), ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Reading another player's deck returned %v", rr.Code)
+	}
+	deal := "/deck/" + created.DeckID.String() + "/deal?count=2&players=ann,bob"
+	if rr := serveWithToken(router, bob, "", "POST", deal, ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Deal from another player's deck returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, ann, "", "POST", deal, ""); rr.Code != http.StatusOK {
+		t.Errorf("Owner's deal returned %v", rr.Code)
+	}
+
+	var anonymous CreateDeckResponse
+	json.NewDecoder(serve(router, "GET", "/deck", "").Body).Decode(&anonymous)
+	claim := "/deck/" + anonymous.DeckID.String() + "/claim"
+	if anonymous.Owner != "" {
+		t.Fatalf("Anonymous deck has an owner: %+v", anonymous)
+	}
+	if rr := serve(router, "POST", claim, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Anonymous claim returned %v", rr.Code)
+	}
+	if rr := serveWithToken(router, bob, "", "POST", claim, ""); rr.Code != http.StatusOK {
+		t.Errorf("Claim returned %v: %v", rr.Code, rr.Body.String())
+	}
+	if rr := serveWithToken(router, ann, "", "POST", claim, ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Claiming a claimed deck returned %v", rr.Code)
+	}
+	if rr := serve(router, "GET", "/deck/"+anonymous.DeckID.String()+"/draw?count=1", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Anonymous draw from a claimed deck returned %v", rr.Code)
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/model"
+	"cardGame/deck/service"
+)
+
+type CreateKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type CreateKeyResponse struct {
+	Key apikey.Key `json:"key"`
+	// Secret is the key to send in X-API-Key. It is not shown again.
+	Secret string `json:"secret"`
+}
+
+// APIKeyHandler manages API keys, and its Authenticate middleware checks
+// the key each request presents in the X-API-Key header. Unless Required,
+// requests may come without a key and have every scope.
+type APIKeyHandler struct {
+	APIKeyService *service.APIKeyService
+	Required      bool
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService, required bool) *APIKeyHandler {
+	return &APIKeyHandler{
+		APIKeyService: apiKeyService,
+		Required:      required,
+	}
+}
+
+type apiKeyKey struct{}
+
+// Authenticate rejects unknown and revoked keys, and requests without a key
+// when keys are required. The health check is always open, and so is a
+// deck or game to a request carrying a capability token for it.
+func (h *APIKeyHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := r.Header.Get("X-API-Key")
+		if raw == "" {
+			if h.Required && r.URL.Path != "/health" && !capabilityScoped(r) {
+				http.Error(w, "API key required", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, err := h.APIKeyService.Check(raw)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyKey{}, key)))
+	})
+}
+
+// apiKey returns the key the request was made with.
+func apiKey(r *http.Request) (apikey.Key, bool) {
+	key, ok := r.Context().Value(apiKeyKey{}).(apikey.Key)
+	return key, ok
+}
+
+// keyID returns the ID of the request's key, or "".
+func keyID(r *http.Request) string {
+	key, _ := apiKey(r)
+	return key.ID
+}
+
+// requireScope checks that the request's key carries scope. Requests
+// without a key only get this far when keys are optional.
+func requireScope(w http.ResponseWriter, r *http.Request, scope apikey.Scope) bool {
+	key, ok := apiKey(r)
+	if ok && !key.Has(scope) {
+		http.Error(w, fmt.Sprintf("API key lacks the %v scope", scope), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// requireAdmin checks that the request was made with an admin key.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := apiKey(r); !ok {
+		http.Error(w, "Admin API key required", http.StatusUnauthorized)
+		return false
+	}
+	return requireScope(w, r, apikey.Admin)
+}
+
+// keyAllows reports whether the request's key may use deck. Admin keys may
+// use every deck.
+func keyAllows(r *http.Request, deck model.Deck) bool {
+	key, ok := apiKey(r)
+	if ok && key.Has(apikey.Admin) {
+		return true
+	}
+	return deck.KeyAllows(key.ID)
+}
+
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	var request CreateKeyRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	key, secret, err := h.APIKeyService.Issue(request.Name, request.Scopes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreateKeyResponse{Key: key, Secret: secret})
+}
+
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": h.APIKeyService.List()})
+}
+
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	key, err := h.APIKeyService.Revoke(mux.Vars(r)["keyID"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(key)
+}
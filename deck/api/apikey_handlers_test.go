@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/dao"
+	"cardGame/deck/service"
+)
+
+var testAdminKey = strings.Repeat("a", 40)
+
+// testKeys holds testAdminKey and testGatewayKey, the key of a game server
+// with the create and draw scopes that vouches for the players its requests
+// name.
+var testKeys, testGatewayKey = newTestKeys()
+
+func newTestKeys() (*service.APIKeyService, string) {
+	keys := service.NewAPIKeyService()
+	keys.Import("admin", testAdminKey)
+	_, raw, _ := keys.Issue("gateway", []string{"create", "draw"})
+	return keys, raw
+}
+
+func newAPIKeyRouter(required bool) *mux.Router {
+	apiKeyService := service.NewAPIKeyService()
+	apiKeyService.Import("admin", testAdminKey)
+	handler := NewAPIKeyHandler(apiKeyService, required)
+	storage := dao.NewDeckStorage()
+	deckHandler := NewDeckHandler(service.NewDeckService(storage), storage)
+
+	router := mux.NewRouter()
+	router.Use(handler.Authenticate)
+	router.HandleFunc("/health", deckHandler.HealthCheck).Methods("GET")
+	router.HandleFunc("/deck", deckHandler.CreateDeck).Methods("GET")
+	router.HandleFunc("/deck/{deckID}", deckHandler.DeleteDeck).Methods("DELETE")
+	router.HandleFunc("/deck/{deckID}/draw", deckHandler.DrawCards).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/grants", deckHandler.Grant).Methods("POST")
+	router.HandleFunc("/deck/{deckID}/grants/{keyID}", deckHandler.Ungrant).Methods("DELETE")
+	router.HandleFunc("/keys", handler.CreateKey).Methods("POST")
+	router.HandleFunc("/keys", handler.ListKeys).Methods("GET")
+	router.HandleFunc("/keys/{keyID}", handler.RevokeKey).Methods("DELETE")
+	return router
+}
+
+func serveWithAPIKey(router *mux.Router, key, method, path, body string) *httptest.ResponseRecorder {
+	return serveWithAPIKeyAs(router, key, "", method, path, body)
+}
+
+// serveWithAPIKeyAs sends a request with key that acts for player.
+func serveWithAPIKeyAs(router *mux.Router, key, player, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	if player != "" {
+		req.Header.Set("X-Player", player)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// issueKey has the admin key issue a key and returns it.
+func issueKey(t *testing.T, router *mux.Router, name, scopes string) CreateKeyResponse {
+	t.Helper()
+	rr := serveWithAPIKey(router, testAdminKey, "POST", "/keys", `{"name": "`+name+`", "scopes": [`+scopes+`]}`)
+	var created CreateKeyResponse
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil || rr.Code != http.StatusCreated {
+		t.Fatalf("CreateKey returned %v: %v", rr.Code, err)
+	}
+	return created
+}
+
+func TestAPIKeyHandler_Keys(t *testing.T) {
+	router := newAPIKeyRouter(true)
+
+	if rr := serveWithAPIKey(router, "", "GET", "/deck", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Request without a key returned %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, "", "GET", "/health", ""); rr.Code != http.StatusOK {
+		t.Errorf("Health check without a key returned %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, "wrong", "GET", "/deck", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Request with an unknown key returned %v", rr.Code)
+	}
+
+	teamA := issueKey(t, router, "team-a", `"create", "draw"`)
+	if rr := serveWithAPIKey(router, teamA.Secret, "POST", "/keys", `{"name": "x", "scopes": ["admin"]}`); rr.Code != http.StatusForbidden {
+		t.Errorf("Non-admin key issued a key: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, testAdminKey, "POST", "/keys", `{"name": "x", "scopes": ["root"]}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Key with an unknown scope returned %v", rr.Code)
+	}
+	rr := serveWithAPIKey(router, testAdminKey, "GET", "/keys", "")
+	if rr.Code != http.StatusOK || strings.Contains(rr.Body.String(), teamA.Secret) || !strings.Contains(rr.Body.String(), teamA.Key.ID) {
+		t.Errorf("ListKeys returned %v: %v", rr.Code, rr.Body.String())
+	}
+
+	if rr := serveWithAPIKey(router, testAdminKey, "DELETE", "/keys/"+teamA.Key.ID, ""); rr.Code != http.StatusOK {
+		t.Errorf("RevokeKey returned %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamA.Secret, "GET", "/deck", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Revoked key returned %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, testAdminKey, "DELETE", "/keys/nope", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Revoking an unknown key returned %v", rr.Code)
+	}
+}
+
+func TestAPIKeyHandler_Decks(t *testing.T) {
+	router := newAPIKeyRouter(true)
+	teamA := issueKey(t, router, "team-a", `"create", "draw"`)
+	teamB := issueKey(t, router, "team-b", `"create", "draw"`)
+	reader := issueKey(t, router, "reader", `"draw"`)
+
+	if rr := serveWithAPIKey(router, reader.Secret, "GET", "/deck", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Key without the create scope created a deck: %v", rr.Code)
+	}
+	var created CreateDeckResponse
+	json.NewDecoder(serveWithAPIKey(router, teamA.Secret, "GET", "/deck", "").Body).Decode(&created)
+	if created.Key != teamA.Key.ID {
+		t.Fatalf("Deck should record the key that created it: %+v", created)
+	}
+	deck := "/deck/" + created.DeckID.String()
+
+	if rr := serveWithAPIKey(router, teamB.Secret, "GET", deck+"/draw?count=1", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Another team drew from the deck: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamB.Secret, "GET", "/deck?deckId="+created.DeckID.String(), ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Another team read the deck: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamA.Secret, "GET", deck+"/draw?count=1", ""); rr.Code != http.StatusOK {
+		t.Errorf("Creating key could not draw: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, testAdminKey, "GET", deck+"/draw?count=1", ""); rr.Code != http.StatusOK {
+		t.Errorf("Admin key could not draw: %v", rr.Code)
+	}
+
+	grant := `{"key_id": "` + teamB.Key.ID + `"}`
+	if rr := serveWithAPIKey(router, teamB.Secret, "POST", deck+"/grants", grant); rr.Code != http.StatusForbidden {
+		t.Errorf("Another team granted itself the deck: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamA.Secret, "POST", deck+"/grants", grant); rr.Code != http.StatusOK {
+		t.Fatalf("Grant returned %v: %v", rr.Code, rr.Body.String())
+	}
+	if rr := serveWithAPIKey(router, teamB.Secret, "GET", deck+"/draw?count=1", ""); rr.Code != http.StatusOK {
+		t.Errorf("Granted key could not draw: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamB.Secret, "POST", deck+"/grants", `{"key_id": "`+reader.Key.ID+`"}`); rr.Code != http.StatusForbidden {
+		t.Errorf("Granted key shared the deck on: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamA.Secret, "DELETE", deck+"/grants/"+teamB.Key.ID, ""); rr.Code != http.StatusOK {
+		t.Errorf("Ungrant returned %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamB.Secret, "DELETE", deck, ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Ungranted key deleted the deck: %v", rr.Code)
+	}
+
+	if rr := serveWithAPIKey(router, teamA.Secret, "DELETE", deck, ""); rr.Code != http.StatusNoContent {
+		t.Errorf("DeleteDeck returned %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamA.Secret, "GET", deck+"/draw?count=1", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Deleted deck returned %v", rr.Code)
+	}
+}
+
+func TestAPIKeyHandler_Optional(t *testing.T) {
+	router := newAPIKeyRouter(false)
+
+	var open CreateDeckResponse
+	json.NewDecoder(serveWithAPIKey(router, "", "GET", "/deck", "").Body).Decode(&open)
+	if open.Key != "" {
+		t.Fatalf("Deck created without a key has one: %+v", open)
+	}
+	teamA := issueKey(t, router, "team-a", `"create", "draw"`)
+	if rr := serveWithAPIKey(router, teamA.Secret, "GET", "/deck/"+open.DeckID.String()+"/draw?count=1", ""); rr.Code != http.StatusOK {
+		t.Errorf("Key could not draw from an open deck: %v", rr.Code)
+	}
+
+	var keyed CreateDeckResponse
+	json.NewDecoder(serveWithAPIKey(router, teamA.Secret, "GET", "/deck", "").Body).Decode(&keyed)
+	if rr := serveWithAPIKey(router, "", "GET", "/deck/"+keyed.DeckID.String()+"/draw?count=1", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Keyless request drew from a keyed deck: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, "", "GET", "/keys", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Keyless request listed keys: %v", rr.Code)
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/service"
+)
+
+type BotRequest struct {
+	Name     string `json:"name"`
+	Callback string `json:"callback"`
+}
+
+// BotHandler registers bots and runs arenas between them. Only admin keys
+// may register callbacks, since the server POSTs to whatever URL is given.
+type BotHandler struct {
+	BotService   *service.BotService
+	ArenaService *service.ArenaService
+}
+
+func NewBotHandler(botService *service.BotService, arenaService *service.ArenaService) *BotHandler {
+	return &BotHandler{
+		BotService:   botService,
+		ArenaService: arenaService,
+	}
+}
+
+func (h *BotHandler) RegisterBot(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	var request BotRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	info, err := h.BotService.Register(request.Name, request.Callback)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(info)
+}
+
+func (h *BotHandler) ListBots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.BotService.List())
+}
+
+func (h *BotHandler) RemoveBot(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if err := h.BotService.Remove(mux.Vars(r)["name"]); err != nil {
+		status := http.StatusBadRequest
+		if err == service.ErrBotNotFound {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StartArena starts an arena run and returns it at once; poll GetArena for
+// the results.
+func (h *BotHandler) StartArena(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, apikey.Create) {
+		return
+	}
+	var config service.ArenaConfig
+	if !decode(w, r, &config) {
+		return
+	}
+	run, err := h.ArenaService.Start(config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(run)
+}
+
+func (h *BotHandler) GetArena(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["runID"])
+	if err != nil {
+		http.Error(w, "Invalid run ID", http.StatusBadRequest)
+		return
+	}
+	run, err := h.ArenaService.Run(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/bot"
+	"cardGame/deck/game"
+	"cardGame/deck/service"
+)
+
+func newBotRouter() (*mux.Router, *service.APIKeyService) {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	bots := service.NewBotService()
+	bots.RegisterPlayer("first", bot.First)
+	apiKeyService := service.NewAPIKeyService()
+	apiKeyService.Import("admin", testAdminKey)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService, true)
+	handler := NewBotHandler(bots, service.NewArenaService(bots, registry))
+
+	router := mux.NewRouter()
+	router.Use(apiKeyHandler.Authenticate)
+	router.HandleFunc("/bots", handler.RegisterBot).Methods("POST")
+	router.HandleFunc("/bots", handler.ListBots).Methods("GET")
+	router.HandleFunc("/bots/{name}", handler.RemoveBot).Methods("DELETE")
+	router.HandleFunc("/arena", handler.StartArena).Methods("POST")
+	router.HandleFunc("/arena/{runID}", handler.GetArena).Methods("GET")
+	return router, apiKeyService
+}
+
+func TestBotHandler(t *testing.T) {
+	router, keys := newBotRouter()
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var turn bot.Turn
+		json.NewDecoder(r.Body).Decode(&turn)
+		json.NewEncoder(w).Encode(map[string]interface{}{"action": turn.Actions[0]})
+	}))
+	defer callback.Close()
+
+	body := `{"name": "remote", "callback": "` + callback.URL + `"}`
+	if rr := serve(router, "POST", "/bots", body); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Registered a bot without an admin key: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, testAdminKey, "POST", "/bots", body); rr.Code != http.StatusCreated {
+		t.Fatalf("RegisterBot returned %v: %v", rr.Code, rr.Body.String())
+	}
+	var list []service.BotInfo
+	json.NewDecoder(serveWithAPIKey(router, testAdminKey, "GET", "/bots", "").Body).Decode(&list)
+	if len(list) != 2 || list[1].Callback != callback.URL {
+		t.Errorf("Unexpected bots %+v", list)
+	}
+
+	_, viewer, _ := keys.Issue("viewer", []string{"draw"})
+	arena := `{"game_type": "highcard", "bots": ["first", "remote"], "games": 20, "parallel": 4}`
+	if rr := serveWithAPIKey(router, viewer, "POST", "/arena", arena); rr.Code != http.StatusForbidden {
+		t.Errorf("Started an arena without the create scope: %v", rr.Code)
+	}
+	rr := serveWithAPIKey(router, testAdminKey, "POST", "/arena", arena)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("StartArena returned %v: %v", rr.Code, rr.Body.String())
+	}
+	var run service.ArenaRun
+	json.NewDecoder(rr.Body).Decode(&run)
+
+	for !run.Done {
+		var polled service.ArenaRun
+		json.NewDecoder(serveWithAPIKey(router, testAdminKey, "GET", "/arena/"+run.ID.String(), "").Body).Decode(&polled)
+		run = polled
+	}
+	if run.Played != 20 || run.Failed != 0 || run.Entrants[1].Games != 20 || run.Entrants[1].Errors != 0 {
+		t.Errorf("Unexpected run %+v", run)
+	}
+
+	if rr := serveWithAPIKey(router, testAdminKey, "POST", "/arena", `{"game_type": "highcard", "bots": ["first", "nobody"], "games": 1}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Started an arena with an unknown bot: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, testAdminKey, "DELETE", "/bots/remote", ""); rr.Code != http.StatusNoContent {
+		t.Errorf("RemoveBot returned %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, testAdminKey, "DELETE", "/bots/remote", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Removing a missing bot returned %v", rr.Code)
+	}
+}
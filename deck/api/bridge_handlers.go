@@ -0,0 +1,303 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/bridge"
+	"cardGame/deck/service"
+)
+
+const defaultDealAttempts = 100000
+
+type StartTableRequest struct {
+	DeckID   uuid.UUID              `json:"deck_id"`
+	Board    int                    `json:"board"`
+	Players  map[bridge.Seat]string `json:"players"`
+	RubberID *uuid.UUID             `json:"rubber_id,omitempty"`
+}
+
+type CallRequest struct {
+	Call bridge.Call `json:"call"`
+}
+
+type ResultRequest struct {
+	Tricks int `json:"tricks"`
+}
+
+type RubberResponse struct {
+	ID            uuid.UUID            `json:"id"`
+	Rubber        bridge.Rubber        `json:"rubber"`
+	Vulnerability bridge.Vulnerability `json:"vulnerability"`
+	Total         [2]int               `json:"total"`
+}
+
+type BridgeHandler struct {
+	BridgeService *service.BridgeService
+}
+
+func NewBridgeHandler(bridgeService *service.BridgeService) *BridgeHandler {
+	return &BridgeHandler{
+		BridgeService: bridgeService,
+	}
+}
+
+func (h *BridgeHandler) DealBoards(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, apikey.Create) {
+		return
+	}
+	query := r.URL.Query()
+
+	count, ok := intParam(w, query.Get("count"), "count", 1)
+	if !ok {
+		return
+	}
+	maxAttempts, ok := intParam(w, query.Get("max_attempts"), "max_attempts", defaultDealAttempts)
+	if !ok {
+		return
+	}
+	board, ok := intParam(w, query.Get("board"), "board", 1)
+	if !ok {
+		return
+	}
+	if count > service.MaxDealCount || maxAttempts > service.MaxDealAttempts {
+		http.Error(w, fmt.Sprintf("At most %v deals and %v attempts per request", service.MaxDealCount, service.MaxDealAttempts), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.BridgeService.DealBoards(authenticated(r), keyID(r), query.Get("constraint"), count, maxAttempts, board)
+	if err != nil && len(result.Deals) == 0 {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if query.Get("format") == "pbn" {
+		w.Header().Set("Content-Type", "application/x-pbn")
+		w.Write([]byte(bridge.ExportPBN(query.Get("event"), result.Deals)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// AnalyzeDeal returns the double-dummy trick table and par for a stored deck,
+// dealt as the board given in the query (board 1 by default). A deal still
+// being played at a table gets 409.
+func (h *BridgeHandler) AnalyzeDeal(w http.ResponseWriter, r *http.Request) {
+	deckID, err := uuid.Parse(mux.Vars(r)["deckID"])
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	board, ok := intParam(w, r.URL.Query().Get("board"), "board", 1)
+	if !ok {
+		return
+	}
+	if deck, found := h.BridgeService.Deck(deckID); found && !mayUse(w, r, deck) {
+		return
+	}
+
+	analysis, err := h.BridgeService.AnalyzeDeck(deckID, board)
+	switch {
+	case errors.Is(err, service.ErrDealInPlay):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), deckErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analysis)
+}
+
+func (h *BridgeHandler) StartTable(w http.ResponseWriter, r *http.Request) {
+	var request StartTableRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Board == 0 {
+		request.Board = 1
+	}
+	if request.Board < 0 {
+		http.Error(w, "Invalid board", http.StatusBadRequest)
+		return
+	}
+	if deck, found := h.BridgeService.Deck(request.DeckID); found && !mayUse(w, r, deck) {
+		return
+	}
+
+	table, err := h.BridgeService.StartTable(request.DeckID, request.Board, request.Players, request.RubberID)
+	writeTable(w, table, err)
+}
+
+func (h *BridgeHandler) GetTable(w http.ResponseWriter, r *http.Request) {
+	tableID, err := uuid.Parse(mux.Vars(r)["tableID"])
+	if err != nil {
+		http.Error(w, "Invalid table ID", http.StatusBadRequest)
+		return
+	}
+
+	table, err := h.BridgeService.GetTable(tableID)
+	writeTable(w, table, err)
+}
+
+// MakeCall makes the next call for the requesting player, who must sit in
+// the seat whose turn it is.
+func (h *BridgeHandler) MakeCall(w http.ResponseWriter, r *http.Request) {
+	tableID, err := uuid.Parse(mux.Vars(r)["tableID"])
+	if err != nil {
+		http.Error(w, "Invalid table ID", http.StatusBadRequest)
+		return
+	}
+	var request CallRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	player, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+
+	table, err := h.BridgeService.MakeCall(tableID, player, request.Call)
+	writeTable(w, table, err)
+}
+
+// GetHand shows the hand of a seat to the player sitting in it.
+func (h *BridgeHandler) GetHand(w http.ResponseWriter, r *http.Request) {
+	tableID, err := uuid.Parse(mux.Vars(r)["tableID"])
+	if err != nil {
+		http.Error(w, "Invalid table ID", http.StatusBadRequest)
+		return
+	}
+	seat, err := bridge.ParseSeat(mux.Vars(r)["seat"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	player, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+
+	hand, err := h.BridgeService.Hand(tableID, seat, player)
+	switch {
+	case errors.Is(err, service.ErrNotYourSeat):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hand)
+}
+
+// RecordResult records the tricks declarer took, for the requesting player,
+// who must sit on the declaring side, or with an admin key.
+func (h *BridgeHandler) RecordResult(w http.ResponseWriter, r *http.Request) {
+	tableID, err := uuid.Parse(mux.Vars(r)["tableID"])
+	if err != nil {
+		http.Error(w, "Invalid table ID", http.StatusBadRequest)
+		return
+	}
+	var request ResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	var player string
+	if key, ok := apiKey(r); !ok || !key.Has(apikey.Admin) {
+		if player, ok = requirePlayer(w, r); !ok {
+			return
+		}
+	}
+
+	table, err := h.BridgeService.RecordResult(tableID, player, request.Tricks)
+	writeTable(w, table, err)
+}
+
+// BoardResults lists every table's result on a board, scored against each
+// other in matchpoints and cross-IMPs.
+func (h *BridgeHandler) BoardResults(w http.ResponseWriter, r *http.Request) {
+	deckID, err := uuid.Parse(mux.Vars(r)["deckID"])
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	board, ok := intParam(w, r.URL.Query().Get("board"), "board", 1)
+	if !ok {
+		return
+	}
+	if deck, found := h.BridgeService.Deck(deckID); found && !mayUse(w, r, deck) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"results": h.BridgeService.BoardResults(deckID, board)})
+}
+
+func (h *BridgeHandler) NewRubber(w http.ResponseWriter, r *http.Request) {
+	id, rubber := h.BridgeService.NewRubber()
+	writeRubber(w, id, rubber)
+}
+
+func (h *BridgeHandler) GetRubber(w http.ResponseWriter, r *http.Request) {
+	rubberID, err := uuid.Parse(mux.Vars(r)["rubberID"])
+	if err != nil {
+		http.Error(w, "Invalid rubber ID", http.StatusBadRequest)
+		return
+	}
+
+	rubber, err := h.BridgeService.GetRubber(rubberID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeRubber(w, rubberID, rubber)
+}
+
+func writeTable(w http.ResponseWriter, table service.BridgeTable, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotYourSeat):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), deckErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(table)
+}
+
+func writeRubber(w http.ResponseWriter, id uuid.UUID, rubber bridge.Rubber) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RubberResponse{
+		ID:            id,
+		Rubber:        rubber,
+		Vulnerability: rubber.Vulnerability(),
+		Total:         rubber.Total(),
+	})
+}
+
+func intParam(w http.ResponseWriter, value, name string, fallback int) (int, bool) {
+	if value == "" {
+		return fallback, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		http.Error(w, "Invalid "+name+" parameter", http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
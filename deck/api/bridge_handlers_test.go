@@ -0,0 +1,280 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/bridge"
+	"cardGame/deck/bridge/dds"
+	"cardGame/deck/dao"
+	"cardGame/deck/model"
+	"cardGame/deck/service"
+)
+
+func TestBridgeHandler_DealBoards(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	handler := NewBridgeHandler(service.NewBridgeService(storage))
+	constraint := url.QueryEscape("S spades >= 5 and S has AS")
+
+	t.Run("Owned Deals", func(t *testing.T) {
+		router := mux.NewRouter()
+		router.Use(NewAPIKeyHandler(testKeys, false).Authenticate)
+		router.HandleFunc("/bridge/deals", handler.DealBoards).Methods("GET")
+
+		_, reader, _ := testKeys.Issue("reader", []string{"draw"})
+		if rr := serveWithAPIKey(router, reader, "GET", "/bridge/deals", ""); rr.Code != http.StatusForbidden {
+			t.Errorf("DealBoards handler dealt for a key without the create scope: %v", rr.Code)
+		}
+
+		rr := serveWithAPIKey(router, testGatewayKey, "GET", "/bridge/deals", "")
+		var response bridge.DealResult
+		json.NewDecoder(rr.Body).Decode(&response)
+		if deck, _ := storage.GetDeck(response.Deals[0].DeckID); deck.Key == "" {
+			t.Errorf("DealBoards handler stored a deck without the caller's key: %+v", deck)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/bridge/deals?count=2&constraint="+constraint, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.DealBoards(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("DealBoards handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		var response bridge.DealResult
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Deals) != 2 || response.Deals[0].Hands[bridge.South].Length(bridge.Spades) < 5 {
+			t.Errorf("DealBoards handler returned unexpected deals: %v", response.Deals)
+		}
+	})
+
+	t.Run("PBN", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/bridge/deals?format=pbn&event=Club&constraint="+constraint, nil)
+
+		rr := httptest.NewRecorder()
+		handler.DealBoards(rr, req)
+
+		if !strings.Contains(rr.Body.String(), `[Event "Club"]`) || !strings.Contains(rr.Body.String(), `[Deal "N:`) {
+			t.Errorf("DealBoards handler returned unexpected PBN: %v", rr.Body.String())
+		}
+	})
+
+	t.Run("Invalid Constraint", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/bridge/deals?constraint=N+hcp", nil)
+
+		rr := httptest.NewRecorder()
+		handler.DealBoards(rr, req)
+
+		if status := rr.Code; status != http.StatusUnprocessableEntity {
+			t.Errorf("DealBoards handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("Too Many", func(t *testing.T) {
+		for _, query := range []string{"count=101", "max_attempts=1000001"} {
+			req, _ := http.NewRequest("GET", "/bridge/deals?"+query, nil)
+
+			rr := httptest.NewRecorder()
+			handler.DealBoards(rr, req)
+
+			if status := rr.Code; status != http.StatusBadRequest {
+				t.Errorf("DealBoards handler returned wrong status code for %v: got %v want %v", query, status, http.StatusBadRequest)
+			}
+		}
+	})
+}
+
+func TestBridgeHandler_AnalyzeDeal(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	handler := NewBridgeHandler(service.NewBridgeService(storage))
+
+	deal, _ := bridge.ParsePBNDeal("N:AKQJT98765432... .AKQJT98765432.. ..AKQJT98765432. ...AKQJT98765432")
+	deal.DeckID = uuid.New()
+	storage.SaveDeck(deal.Deck())
+
+	req, _ := http.NewRequest("GET", "/bridge/deals/"+deal.DeckID.String()+"/analysis", nil)
+	req = mux.SetURLVars(req, map[string]string{"deckID": deal.DeckID.String()})
+
+	rr := httptest.NewRecorder()
+	handler.AnalyzeDeal(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("AnalyzeDeal handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response dds.Analysis
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if got := response.Table.TricksFor(bridge.Spades, bridge.North); got != 13 {
+		t.Errorf("AnalyzeDeal handler returned %v spade tricks for North, want 13", got)
+	}
+	if response.Par.Contract.Level != 7 {
+		t.Errorf("AnalyzeDeal handler returned unexpected par: %v", response.Par)
+	}
+
+	req, _ = http.NewRequest("GET", "/bridge/deals/nope/analysis", nil)
+	req = mux.SetURLVars(req, map[string]string{"deckID": "nope"})
+	rr = httptest.NewRecorder()
+	handler.AnalyzeDeal(rr, req)
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("AnalyzeDeal handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+
+	seats := map[bridge.Seat]string{bridge.North: "ann", bridge.East: "bob", bridge.South: "cat", bridge.West: "dan"}
+	if _, err := handler.BridgeService.StartTable(deal.DeckID, 1, seats, nil); err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/bridge/deals/x/analysis", nil), map[string]string{"deckID": deal.DeckID.String()})
+	rr = httptest.NewRecorder()
+	handler.AnalyzeDeal(rr, req)
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("AnalyzeDeal handler analysed a deal in play: got %v want %v", status, http.StatusConflict)
+	}
+
+	private := model.NewDeck(true, "")
+	private.Owner = "ann"
+	storage.SaveDeck(private)
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/bridge/deals/x/analysis", nil), map[string]string{"deckID": private.ID.String()})
+	rr = httptest.NewRecorder()
+	handler.AnalyzeDeal(rr, req)
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("AnalyzeDeal handler analysed another player's deck: got %v want %v", status, http.StatusForbidden)
+	}
+}
+
+func TestBridgeHandler_Tables(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	handler := NewBridgeHandler(service.NewBridgeService(storage))
+	deck := model.NewDeck(true, "")
+	storage.SaveDeck(deck)
+
+	router := mux.NewRouter()
+	router.Use(NewAPIKeyHandler(testKeys, false).Authenticate)
+	router.HandleFunc("/bridge/tables", handler.StartTable).Methods("POST")
+	router.HandleFunc("/bridge/tables/{tableID}", handler.GetTable).Methods("GET")
+	router.HandleFunc("/bridge/tables/{tableID}/calls", handler.MakeCall).Methods("POST")
+	router.HandleFunc("/bridge/tables/{tableID}/hands/{seat}", handler.GetHand).Methods("GET")
+	router.HandleFunc("/bridge/tables/{tableID}/result", handler.RecordResult).Methods("POST")
+	router.HandleFunc("/bridge/deals/{deckID}/results", handler.BoardResults).Methods("GET")
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	private := model.NewDeck(true, "")
+	private.Owner = "ann"
+	storage.SaveDeck(private)
+	players := `"players": {"N": "ann", "E": "bob", "S": "cat", "W": "dan"}`
+	if rr := send("POST", "/bridge/tables", `{"deck_id": "`+private.ID.String()+`", `+players+`}`); rr.Code != http.StatusForbidden {
+		t.Errorf("StartTable handler used another player's deck: got %v want %v", rr.Code, http.StatusForbidden)
+	}
+
+	rr := send("POST", "/bridge/tables", `{"deck_id": "`+deck.ID.String()+`", "board": 3, `+players+`}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("StartTable handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var table service.BridgeTable
+	json.NewDecoder(rr.Body).Decode(&table)
+	if table.Auction.Dealer != bridge.South || table.Auction.Vulnerability != bridge.VulnerableEW {
+		t.Errorf("StartTable handler returned unexpected auction: %+v", table.Auction)
+	}
+
+	path := "/bridge/tables/" + table.ID.String()
+	if rr := send("POST", path+"/calls?player=cat", `{"call": "1S"}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("MakeCall handler accepted an unauthenticated call: %v", rr.Code)
+	}
+	if rr := serveAs(router, "ann", "POST", path+"/calls", `{"call": "1S"}`); rr.Code != http.StatusForbidden {
+		t.Errorf("MakeCall handler accepted a call out of turn: %v", rr.Code)
+	}
+	callers := []string{"cat", "dan", "ann", "bob", "cat", "dan"}
+	for i, call := range []string{"1S", "P", "4S", "P", "P", "P"} {
+		if rr := serveAs(router, callers[i], "POST", path+"/calls", `{"call": "`+call+`"}`); rr.Code != http.StatusOK {
+			t.Fatalf("MakeCall handler rejected %v: %v", call, rr.Body.String())
+		}
+	}
+	if rr := serveAs(router, "ann", "POST", path+"/calls", `{"call": "P"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("MakeCall handler accepted a call after the auction ended")
+	}
+
+	var hand service.BridgeHand
+	rr = serveAs(router, "cat", "GET", path+"/hands/S", "")
+	json.NewDecoder(rr.Body).Decode(&hand)
+	if rr.Code != http.StatusOK || hand.Seat != bridge.South || len(hand.Hand) != 13 {
+		t.Errorf("GetHand handler returned %v: %+v", rr.Code, hand)
+	}
+	if rr := serveAs(router, "cat", "GET", path+"/hands/N", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("GetHand handler showed another seat's hand: %v", rr.Code)
+	}
+
+	if rr := send("POST", path+"/result", `{"tricks": 11}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("RecordResult handler accepted an unauthenticated result: %v", rr.Code)
+	}
+	if rr := serveAs(router, "dan", "POST", path+"/result", `{"tricks": 11}`); rr.Code != http.StatusForbidden {
+		t.Errorf("RecordResult handler accepted a defender's result: %v", rr.Code)
+	}
+	rr = serveAs(router, "ann", "POST", path+"/result", `{"tricks": 11}`)
+	json.NewDecoder(rr.Body).Decode(&table)
+	if table.Contract.String() != "4S-S" || table.Result == nil || table.Result.Score != 450 {
+		t.Errorf("RecordResult handler returned unexpected table: %+v", table)
+	}
+
+	rr = send("GET", "/bridge/deals/"+deck.ID.String()+"/results?board=3", "")
+	var results struct {
+		Results []bridge.BoardResult `json:"results"`
+	}
+	json.NewDecoder(rr.Body).Decode(&results)
+	if len(results.Results) != 1 || results.Results[0].TableID != table.ID {
+		t.Errorf("BoardResults handler returned unexpected results: %v", rr.Body.String())
+	}
+	if rr := send("GET", "/bridge/deals/"+private.ID.String()+"/results", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("BoardResults handler showed another player's deck: got %v want %v", rr.Code, http.StatusForbidden)
+	}
+
+	if rr := send("GET", "/bridge/tables/"+uuid.New().String(), ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("GetTable handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestBridgeHandler_Rubbers(t *testing.T) {
+	handler := NewBridgeHandler(service.NewBridgeService(dao.NewDeckStorage()))
+
+	rr := httptest.NewRecorder()
+	handler.NewRubber(rr, httptest.NewRequest("POST", "/bridge/rubbers", nil))
+	var created RubberResponse
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/bridge/rubbers/"+created.ID.String(), nil), map[string]string{"rubberID": created.ID.String()})
+	rr = httptest.NewRecorder()
+	handler.GetRubber(rr, req)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"vulnerability":"None"`) {
+		t.Errorf("GetRubber handler returned unexpected response: %v %v", rr.Code, rr.Body.String())
+	}
+
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/bridge/rubbers/x", nil), map[string]string{"rubberID": uuid.New().String()})
+	rr = httptest.NewRecorder()
+	handler.GetRubber(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("GetRubber handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
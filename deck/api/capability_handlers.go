@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/capability"
+	"cardGame/deck/service"
+)
+
+type IssueCapabilityRequest struct {
+	DeckID     *uuid.UUID       `json:"deck_id,omitempty"`
+	GameType   string           `json:"game_type,omitempty"`
+	GameID     *uuid.UUID       `json:"game_id,omitempty"`
+	Right      capability.Right `json:"right"`
+	Limit      int              `json:"limit,omitempty"`
+	Seat       int              `json:"seat,omitempty"`
+	TTLSeconds int              `json:"ttl_seconds,omitempty"`
+}
+
+type IssueCapabilityResponse struct {
+	Token  string            `json:"token"`
+	Claims capability.Claims `json:"claims"`
+}
+
+type RevokeCapabilityRequest struct {
+	Token string `json:"token"`
+}
+
+// CapabilityHandler issues capability tokens, which grant limited rights on
+// one deck or one game seat to whoever holds them, without an account or an
+// API key. Its Authenticate middleware reads the token a request carries in
+// the X-Capability header or the cap query parameter, so a token can be
+// handed out as a link.
+type CapabilityHandler struct {
+	CapabilityService *service.CapabilityService
+	GameService       *service.GameService
+}
+
+func NewCapabilityHandler(capabilityService *service.CapabilityService, gameService *service.GameService) *CapabilityHandler {
+	return &CapabilityHandler{
+		CapabilityService: capabilityService,
+		GameService:       gameService,
+	}
+}
+
+type grantKey struct{}
+
+// grant is the verified token a request carries.
+type grant struct {
+	claims   capability.Claims
+	spending *service.CapabilityService
+}
+
+func (g grant) spend(count int) error {
+	return g.spending.Spend(g.claims, count)
+}
+
+// Authenticate rejects requests with a bad, expired or revoked token.
+func (h *CapabilityHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-Capability")
+		if token == "" {
+			token = r.URL.Query().Get("cap")
+		}
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		claims, err := h.CapabilityService.Verify(token)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		g := grant{claims: claims, spending: h.CapabilityService}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), grantKey{}, g)))
+	})
+}
+
+// deckGrant returns the request's token if it gives right on deckID.
+func deckGrant(r *http.Request, deckID uuid.UUID, right capability.Right) (grant, bool) {
+	g, ok := r.Context().Value(grantKey{}).(grant)
+	return g, ok && g.claims.ForDeck(deckID, right)
+}
+
+// gameGrant returns the claims of the request's token if it is for the game.
+func gameGrant(r *http.Request, gameType string, gameID uuid.UUID) (capability.Claims, bool) {
+	g, ok := r.Context().Value(grantKey{}).(grant)
+	return g.claims, ok && g.claims.ForGame(gameType, gameID)
+}
+
+// capabilityScoped reports whether the request carries a token for the deck
+// or game it is made on. Such requests need no API key.
+func capabilityScoped(r *http.Request) bool {
+	g, ok := r.Context().Value(grantKey{}).(grant)
+	if !ok {
+		return false
+	}
+	vars := mux.Vars(r)
+	deckParam := vars["deckID"]
+	if deckParam == "" {
+		deckParam = r.URL.Query().Get("deckId")
+	}
+	if deckID, err := uuid.Parse(deckParam); err == nil && g.claims.Deck != nil {
+		return *g.claims.Deck == deckID
+	}
+	if gameID, err := uuid.Parse(vars["gameID"]); err == nil {
+		return g.claims.ForGame(vars["type"], gameID)
+	}
+	return false
+}
+
+// seatPlayer returns the player in the seat claims grant.
+func seatPlayer(games *service.GameService, claims capability.Claims) (string, error) {
+	state, err := games.State(claims.GameType, *claims.Game, "")
+	if err != nil {
+		return "", err
+	}
+	if claims.Seat >= len(state.Players) {
+		return "", fmt.Errorf("The game has no seat %v", claims.Seat)
+	}
+	return state.Players[claims.Seat], nil
+}
+
+// authorize checks that the caller may hand out claims: they must be able
+// to use the deck, or to act as the player in the seat. Admin keys may hand
+// out any seat, and only they may hand out commentate tokens, which show
+// every hand.
+func (h *CapabilityHandler) authorize(w http.ResponseWriter, r *http.Request, claims capability.Claims) bool {
+	if claims.Right == capability.Commentate && !requireAdmin(w, r) {
+		return false
+	}
+	if claims.Deck != nil {
+		deck, found := h.GameService.Deck(*claims.Deck)
+		if !found {
+			http.Error(w, "Deck not found", http.StatusNotFound)
+			return false
+		}
+		if claims.Right == capability.Draw && !requireScope(w, r, apikey.Draw) {
+			return false
+		}
+		return mayUse(w, r, deck)
+	}
+
+	if _, err := h.GameService.State(claims.GameType, *claims.Game, ""); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return false
+	}
+	if claims.Right != capability.Seat {
+		return true
+	}
+	player, err := seatPlayer(h.GameService, claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if key, ok := apiKey(r); ok && key.Has(apikey.Admin) {
+		return true
+	}
+	return mayActAs(w, r, player)
+}
+
+func (h *CapabilityHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	var request IssueCapabilityRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	claims := capability.Claims{
+		Deck:     request.DeckID,
+		GameType: request.GameType,
+		Game:     request.GameID,
+		Right:    request.Right,
+		Limit:    request.Limit,
+		Seat:     request.Seat,
+	}
+	if err := claims.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.authorize(w, r, claims) {
+		return
+	}
+
+	token, claims, err := h.CapabilityService.Issue(claims, time.Duration(request.TTLSeconds)*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(IssueCapabilityResponse{Token: token, Claims: claims})
+}
+
+// Revoke stops a token working. Whoever could issue it may revoke it.
+func (h *CapabilityHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	var request RevokeCapabilityRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	claims, err := h.CapabilityService.Inspect(request.Token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.authorize(w, r, claims) {
+		return
+	}
+	h.CapabilityService.Revoke(claims)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/capability"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/service"
+)
+
+func newCapabilityRouter() (*mux.Router, *service.APIKeyService) {
+	storage := dao.NewDeckStorage()
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	gameService := service.NewGameService(storage, registry)
+	apiKeyService := service.NewAPIKeyService()
+	apiKeyService.Import("admin", testAdminKey)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService, true)
+	handler := NewCapabilityHandler(service.NewCapabilityService(capability.NewKey()), gameService)
+	deckHandler := NewDeckHandler(service.NewDeckService(storage), storage)
+	gameHandler := NewGameHandler(gameService)
+
+	router := mux.NewRouter()
+	router.Use(handler.Authenticate, apiKeyHandler.Authenticate)
+	router.HandleFunc("/deck", deckHandler.CreateDeck).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/draw", deckHandler.DrawCards).Methods("GET")
+	router.HandleFunc("/games/{type}", gameHandler.CreateGame).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}", gameHandler.GetGame).Methods("GET")
+	router.HandleFunc("/games/{type}/{gameID}", gameHandler.PostAction).Methods("POST")
+	router.HandleFunc("/capabilities", handler.Issue).Methods("POST")
+	router.HandleFunc("/capabilities/revocations", handler.Revoke).Methods("POST")
+	return router, apiKeyService
+}
+
+func serveWithCapability(router *mux.Router, token, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-Capability", token)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func issueCapability(t *testing.T, router *mux.Router, key, body string) string {
+	t.Helper()
+	rr := serveWithAPIKey(router, key, "POST", "/capabilities", body)
+	var issued IssueCapabilityResponse
+	if err := json.NewDecoder(rr.Body).Decode(&issued); err != nil || rr.Code != http.StatusCreated {
+		t.Fatalf("Issue returned %v: %v", rr.Code, err)
+	}
+	return issued.Token
+}
+
+func TestCapabilityHandler_Deck(t *testing.T) {
+	router, keys := newCapabilityRouter()
+	_, teamA, _ := keys.Issue("team-a", []string{"create", "draw"})
+	_, teamB, _ := keys.Issue("team-b", []string{"create", "draw"})
+
+	var created, other CreateDeckResponse
+	json.NewDecoder(serveWithAPIKey(router, teamA, "GET", "/deck", "").Body).Decode(&created)
+	json.NewDecoder(serveWithAPIKey(router, teamA, "GET", "/deck", "").Body).Decode(&other)
+	deck := "/deck/" + created.DeckID.String()
+	request := `{"deck_id": "` + created.DeckID.String() + `", "right": "draw", "limit": 3}`
+
+	if rr := serveWithAPIKey(router, teamB, "POST", "/capabilities", request); rr.Code != http.StatusForbidden {
+		t.Errorf("Another team issued a token for the deck: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamA, "POST", "/capabilities", `{"deck_id": "`+created.DeckID.String()+`", "right": "draw"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Draw token without a limit returned %v", rr.Code)
+	}
+	token := issueCapability(t, router, teamA, request)
+
+	if rr := serveWithCapability(router, token, "GET", "/deck?deckId="+created.DeckID.String(), ""); rr.Code != http.StatusOK {
+		t.Errorf("Token could not read its deck: %v", rr.Code)
+	}
+	if rr := serveWithCapability(router, token, "GET", deck+"/draw?count=2", ""); rr.Code != http.StatusOK {
+		t.Errorf("Token could not draw: %v", rr.Code)
+	}
+	if rr := serveWithCapability(router, token, "GET", deck+"/draw?count=2", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Token drew past its limit: %v", rr.Code)
+	}
+	if rr := serve(router, "GET", deck+"/draw?count=1&cap="+token, ""); rr.Code != http.StatusOK {
+		t.Errorf("Token in a link could not draw: %v", rr.Code)
+	}
+	if rr := serveWithCapability(router, token, "GET", "/deck/"+other.DeckID.String()+"/draw?count=1", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Token drew from another deck: %v", rr.Code)
+	}
+	if rr := serveWithCapability(router, token, "GET", "/deck", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Token created a deck: %v", rr.Code)
+	}
+	if rr := serveWithCapability(router, token[:len(token)-2]+"xx", "GET", deck+"/draw?count=1", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Tampered token returned %v", rr.Code)
+	}
+
+	read := issueCapability(t, router, teamA, `{"deck_id": "`+created.DeckID.String()+`", "right": "read"}`)
+	if rr := serveWithCapability(router, read, "GET", deck+"/draw?count=1", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Read-only token drew: %v", rr.Code)
+	}
+	revoke := `{"token": "` + read + `"}`
+	if rr := serveWithAPIKey(router, teamB, "POST", "/capabilities/revocations", revoke); rr.Code != http.StatusForbidden {
+		t.Errorf("Another team revoked the token: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamA, "POST", "/capabilities/revocations", revoke); rr.Code != http.StatusNoContent {
+		t.Errorf("Revoke returned %v", rr.Code)
+	}
+	if rr := serveWithCapability(router, read, "GET", "/deck?deckId="+created.DeckID.String(), ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Revoked token returned %v", rr.Code)
+	}
+}
+
+func TestCapabilityHandler_Seat(t *testing.T) {
+	router, _ := newCapabilityRouter()
+	rr := serveWithAPIKey(router, testAdminKey, "POST", "/games/highcard", `{"players": ["ann", "bob"]}`)
+	var state service.GameState
+	json.NewDecoder(rr.Body).Decode(&state)
+	path := "/games/highcard/" + state.ID.String()
+	seat := func(n string) string {
+		return `{"game_type": "highcard", "game_id": "` + state.ID.String() + `", "right": "seat", "seat": ` + n + `}`
+	}
+
+	if rr := serveWithAPIKey(router, testAdminKey, "POST", "/capabilities", seat("2")); rr.Code != http.StatusBadRequest {
+		t.Errorf("Token for a missing seat returned %v", rr.Code)
+	}
+	ann := issueCapability(t, router, testAdminKey, seat("0"))
+	watch := issueCapability(t, router, testAdminKey, `{"game_type": "highcard", "game_id": "`+state.ID.String()+`", "right": "read"}`)
+
+	json.NewDecoder(serveWithCapability(router, ann, "GET", path+"?player=bob", "").Body).Decode(&state)
+	if len(state.Actions) != 1 {
+		t.Errorf("Seat token should see the seat's actions: %+v", state.Actions)
+	}
+	json.NewDecoder(serveWithCapability(router, watch, "GET", path+"?player=ann", "").Body).Decode(&state)
+	if len(state.Actions) != 0 {
+		t.Errorf("Read token should see the spectators' view: %+v", state.Actions)
+	}
+
+	draw := `{"action": {"type": "draw"}}`
+	if rr := serveWithCapability(router, watch, "POST", path, draw); rr.Code != http.StatusForbidden {
+		t.Errorf("Read token played: %v", rr.Code)
+	}
+	if rr := serveWithCapability(router, ann, "POST", path, `{"player": "bob", "action": {"type": "draw"}}`); rr.Code != http.StatusForbidden {
+		t.Errorf("Seat token played for another seat: %v", rr.Code)
+	}
+	if rr := serveWithCapability(router, ann, "POST", path, draw); rr.Code != http.StatusOK {
+		t.Errorf("Seat token could not play: %v %v", rr.Code, rr.Body.String())
+	}
+	if rr := serve(router, "POST", path, `{"player": "bob", "action": {"type": "draw"}}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Request without a key or token played: %v", rr.Code)
+	}
+}
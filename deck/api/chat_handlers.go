@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/chat"
+	"cardGame/deck/lobby"
+	"cardGame/deck/service"
+)
+
+type ChatRequest struct {
+	Text string `json:"text"`
+}
+
+type ModerationRequest struct {
+	Player  string  `json:"player"`
+	Seconds float64 `json:"seconds,omitempty"`
+}
+
+// ChatHandler serves the chat of each lobby room, under /rooms/{roomID}/chat,
+// and of each table, under /games/{type}/{gameID}/chat. Players chat as
+// the player they may act as, or as a seat token's player.
+type ChatHandler struct {
+	ChatService *service.ChatService
+	GameService *service.GameService
+}
+
+func NewChatHandler(chatService *service.ChatService, gameService *service.GameService) *ChatHandler {
+	return &ChatHandler{
+		ChatService: chatService,
+		GameService: gameService,
+	}
+}
+
+func chatChannel(w http.ResponseWriter, r *http.Request) (service.ChatChannel, bool) {
+	vars := mux.Vars(r)
+	if vars["gameID"] != "" {
+		id, ok := gameID(w, r)
+		return service.ChatChannel{GameType: vars["type"], ID: id}, ok
+	}
+	id, ok := roomID(w, r)
+	return service.ChatChannel{ID: id}, ok
+}
+
+// chatter returns the player the request chats as.
+func (h *ChatHandler) chatter(w http.ResponseWriter, r *http.Request, channel service.ChatChannel) (string, bool) {
+	if claims, ok := gameGrant(r, channel.GameType, channel.ID); ok {
+		request := ActionRequest{}
+		if !actAsSeat(w, h.GameService, claims, &request) {
+			return "", false
+		}
+		return request.Player, true
+	}
+	player, ok := requirePlayer(w, r)
+	return player, ok && mayActAs(w, r, player)
+}
+
+func writeChatError(w http.ResponseWriter, err error) {
+	switch err {
+	case service.ErrGameNotFound, lobby.ErrRoomNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case service.ErrNotInChannel, chat.ErrMuted, chat.ErrBanned:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case chat.ErrRateLimited:
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case service.ErrChatClosed:
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+func (h *ChatHandler) Post(w http.ResponseWriter, r *http.Request) {
+	channel, ok := chatChannel(w, r)
+	if !ok {
+		return
+	}
+	player, ok := h.chatter(w, r, channel)
+	if !ok {
+		return
+	}
+	var request ChatRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	message, err := h.ChatService.Post(channel, player, request.Text)
+	if err != nil {
+		writeChatError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+// Messages returns the messages after since, or with before the history
+// page before it. Without either it returns the latest page.
+func (h *ChatHandler) Messages(w http.ResponseWriter, r *http.Request) {
+	channel, ok := chatChannel(w, r)
+	if !ok {
+		return
+	}
+	player, ok := h.chatter(w, r, channel)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	before, ok := intParam(w, query.Get("before"), "before", 0)
+	if !ok {
+		return
+	}
+	limit, ok := intParam(w, query.Get("limit"), "limit", service.ChatPageSize)
+	if !ok {
+		return
+	}
+
+	var page service.ChatPage
+	var err error
+	if param := query.Get("since"); param != "" {
+		since, convErr := strconv.Atoi(param)
+		if convErr != nil || since < 0 {
+			http.Error(w, "Invalid since parameter", http.StatusBadRequest)
+			return
+		}
+		page, err = h.ChatService.Since(channel, player, since, limit)
+	} else {
+		page, err = h.ChatService.History(channel, player, before, limit)
+	}
+	if err != nil {
+		writeChatError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// mayModerate checks that the caller moderates channel: admin keys moderate
+// every channel, and a room's host moderates its chat.
+func (h *ChatHandler) mayModerate(w http.ResponseWriter, r *http.Request, channel service.ChatChannel) bool {
+	if key, ok := apiKey(r); ok && key.Has(apikey.Admin) {
+		return true
+	}
+	player := viewer(r)
+	if !mayActAs(w, r, player) {
+		return false
+	}
+	if !h.ChatService.Moderator(channel, player) {
+		http.Error(w, "Only the room's host or an admin key may moderate its chat", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// moderate runs a mute, ban or their reversal on the player in the request
+// body, or in the path when undoing one.
+func (h *ChatHandler) moderate(w http.ResponseWriter, r *http.Request, fn func(service.ChatChannel, ModerationRequest) error) {
+	channel, ok := chatChannel(w, r)
+	if !ok || !h.mayModerate(w, r, channel) {
+		return
+	}
+	request := ModerationRequest{Player: mux.Vars(r)["player"]}
+	if request.Player == "" && !decode(w, r, &request) {
+		return
+	}
+	if request.Player == "" {
+		http.Error(w, "player is required", http.StatusBadRequest)
+		return
+	}
+	if err := fn(channel, request); err != nil {
+		writeChatError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ChatHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, func(channel service.ChatChannel, request ModerationRequest) error {
+		return h.ChatService.Mute(channel, request.Player, time.Duration(request.Seconds*float64(time.Second)))
+	})
+}
+
+func (h *ChatHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, func(channel service.ChatChannel, request ModerationRequest) error {
+		return h.ChatService.Unmute(channel, request.Player)
+	})
+}
+
+func (h *ChatHandler) Ban(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, func(channel service.ChatChannel, request ModerationRequest) error {
+		return h.ChatService.Ban(channel, request.Player)
+	})
+}
+
+func (h *ChatHandler) Unban(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, func(channel service.ChatChannel, request ModerationRequest) error {
+		return h.ChatService.Unban(channel, request.Player)
+	})
+}
+
+// tableChat returns the table's messages after since for player, for
+// delivery alongside the game's events. A finished game has none.
+func tableChat(chats *service.ChatService, gameType string, gameID uuid.UUID, player string, since int) (*service.ChatPage, error) {
+	page, err := chats.Since(service.ChatChannel{GameType: gameType, ID: gameID}, player, since, 0)
+	switch {
+	case err == service.ErrChatClosed:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return &page, nil
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/capability"
+	"cardGame/deck/chat"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/lobby"
+	"cardGame/deck/service"
+)
+
+func newChatRouter() (*mux.Router, *service.LobbyService, *service.GameService) {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	gameService := service.NewGameService(dao.NewDeckStorage(), registry)
+	lobbyService := service.NewLobbyService(gameService)
+	chatService := service.NewChatService(lobbyService, gameService)
+	chatService.SetLimiter(chat.NewLimiter(3, time.Minute))
+	chatService.SetFilter(chat.NewWordList([]string{"darn"}))
+	capabilityHandler := NewCapabilityHandler(service.NewCapabilityService(capability.NewKey()), gameService)
+	handler := NewChatHandler(chatService, gameService)
+
+	router := mux.NewRouter()
+	router.Use(capabilityHandler.Authenticate, NewAPIKeyHandler(testKeys, false).Authenticate)
+	for _, prefix := range []string{"/rooms/{roomID}", "/games/{type}/{gameID}"} {
+		router.HandleFunc(prefix+"/chat", handler.Post).Methods("POST")
+		router.HandleFunc(prefix+"/chat", handler.Messages).Methods("GET")
+		router.HandleFunc(prefix+"/chat/mutes", handler.Mute).Methods("POST")
+		router.HandleFunc(prefix+"/chat/mutes/{player}", handler.Unmute).Methods("DELETE")
+		router.HandleFunc(prefix+"/chat/bans", handler.Ban).Methods("POST")
+		router.HandleFunc(prefix+"/chat/bans/{player}", handler.Unban).Methods("DELETE")
+	}
+	router.HandleFunc("/capabilities", capabilityHandler.Issue).Methods("POST")
+	return router, lobbyService, gameService
+}
+
+func TestChatHandler_Room(t *testing.T) {
+	router, lobbyService, _ := newChatRouter()
+	room, _ := lobbyService.CreateRoom("ann", "highcard", 3, lobby.Public, nil)
+	lobbyService.Join(room.ID, "bob", "")
+	path := "/rooms/" + room.ID.String() + "/chat"
+
+	if rr := serveAs(router, "cat", "POST", path, `{"text": "hi"}`); rr.Code != http.StatusForbidden {
+		t.Errorf("Player outside the room posted: %v", rr.Code)
+	}
+	if rr := serve(router, "POST", path+"?player=bob", `{"text": "hi"}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Post by an unauthenticated player returned %v", rr.Code)
+	}
+	rr := serveAs(router, "bob", "POST", path, `{"text": "darn"}`)
+	var message chat.Message
+	json.NewDecoder(rr.Body).Decode(&message)
+	if rr.Code != http.StatusCreated || message.Text != "****" || message.Seq != 1 {
+		t.Errorf("Post returned %v: %+v", rr.Code, message)
+	}
+	serveAs(router, "bob", "POST", path, `{"text": "two"}`)
+	serveAs(router, "bob", "POST", path, `{"text": "three"}`)
+	if rr := serveAs(router, "bob", "POST", path, `{"text": "four"}`); rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Rate limit returned %v", rr.Code)
+	}
+
+	var page service.ChatPage
+	json.NewDecoder(serveAs(router, "ann", "GET", path+"?limit=2", "").Body).Decode(&page)
+	if len(page.Messages) != 2 || page.Messages[0].Text != "two" || page.Before != 2 {
+		t.Errorf("Latest page = %+v", page)
+	}
+	var older service.ChatPage
+	json.NewDecoder(serveAs(router, "ann", "GET", path+"?before=2", "").Body).Decode(&older)
+	if len(older.Messages) != 1 || older.Before != 0 {
+		t.Errorf("Older page = %+v", older)
+	}
+	json.NewDecoder(serveAs(router, "ann", "GET", path+"?since=2", "").Body).Decode(&page)
+	if len(page.Messages) != 1 || page.Messages[0].Text != "three" || !page.Complete {
+		t.Errorf("Since page = %+v", page)
+	}
+	if rr := serveAs(router, "ann", "GET", path+"?since=-1", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Bad since returned %v", rr.Code)
+	}
+
+	if rr := serveAs(router, "bob", "POST", path+"/mutes", `{"player": "ann", "seconds": 60}`); rr.Code != http.StatusForbidden {
+		t.Errorf("A guest muted the host: %v", rr.Code)
+	}
+	if rr := serveAs(router, "ann", "POST", path+"/bans", `{"player": "bob"}`); rr.Code != http.StatusNoContent {
+		t.Errorf("Ban returned %v", rr.Code)
+	}
+	if rr := serveAs(router, "bob", "GET", path, ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Banned player read: %v", rr.Code)
+	}
+	if rr := serveAs(router, "ann", "DELETE", path+"/bans/bob", ""); rr.Code != http.StatusNoContent {
+		t.Errorf("Unban returned %v", rr.Code)
+	}
+	if rr := serveAs(router, "ann", "POST", path+"/mutes", `{"player": "bob"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Mute without a duration returned %v", rr.Code)
+	}
+	if rr := serveAs(router, "ann", "GET", "/rooms/"+room.ID.String()[:8]+"/chat", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Bad room ID returned %v", rr.Code)
+	}
+}
+
+func TestChatHandler_Table(t *testing.T) {
+	router, _, games := newChatRouter()
+	state, _ := games.CreateGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}})
+	path := "/games/highcard/" + state.ID.String() + "/chat"
+
+	var issued IssueCapabilityResponse
+	json.NewDecoder(serveAs(router, "bob", "POST", "/capabilities", `{"game_type": "highcard", "game_id": "`+state.ID.String()+`", "right": "seat", "seat": 1}`).Body).Decode(&issued)
+	rr := serveWithCapability(router, issued.Token, "POST", path, `{"text": "gl"}`)
+	var message chat.Message
+	json.NewDecoder(rr.Body).Decode(&message)
+	if rr.Code != http.StatusCreated || message.Player != "bob" {
+		t.Errorf("Seat token posted %v: %+v", rr.Code, message)
+	}
+	if rr := serve(router, "POST", path+"?player=ann", `{"text": "gl"}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Anonymous caller posted as ann: %v", rr.Code)
+	}
+	if rr := serveAs(router, "ann", "POST", path+"/mutes", `{"player": "bob", "seconds": 60}`); rr.Code != http.StatusForbidden {
+		t.Errorf("A player moderated the table: %v", rr.Code)
+	}
+	if rr := serveAs(router, "ann", "GET", "/games/war/"+state.ID.String()+"/chat", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Missing table returned %v", rr.Code)
+	}
+}
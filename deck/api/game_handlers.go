@@ -0,0 +1,235 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/capability"
+	"cardGame/deck/game"
+	"cardGame/deck/service"
+	"cardGame/deck/turn"
+)
+
+// CreateGameRequest may seat bots, such as the built-in AI, for some of the
+// players: Bots maps each of those players to a bot name.
+type CreateGameRequest struct {
+	Players []string          `json:"players"`
+	Options map[string]string `json:"options,omitempty"`
+	DeckID  *uuid.UUID        `json:"deck_id,omitempty"`
+	Clock   *ClockRequest     `json:"clock,omitempty"`
+	Bots    map[string]string `json:"bots,omitempty"`
+}
+
+// ClockRequest is a time control in seconds.
+type ClockRequest struct {
+	MoveSeconds      float64 `json:"move_seconds"`
+	BankSeconds      float64 `json:"bank_seconds"`
+	IncrementSeconds float64 `json:"increment_seconds"`
+	Default          string  `json:"default"`
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func (c *ClockRequest) control() turn.Control {
+	if c == nil {
+		return turn.Control{}
+	}
+	return turn.Control{
+		Move:      seconds(c.MoveSeconds),
+		Bank:      seconds(c.BankSeconds),
+		Increment: seconds(c.IncrementSeconds),
+		Default:   c.Default,
+	}
+}
+
+type ActionRequest struct {
+	Player string      `json:"player"`
+	Action game.Action `json:"action"`
+}
+
+// GameHandler serves every game in the registry under /games/{type}, so a
+// new game needs no routes of its own.
+type GameHandler struct {
+	GameService *service.GameService
+}
+
+func NewGameHandler(gameService *service.GameService) *GameHandler {
+	return &GameHandler{
+		GameService: gameService,
+	}
+}
+
+func (h *GameHandler) ListTypes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"types": h.GameService.Types()})
+}
+
+func (h *GameHandler) CreateGame(w http.ResponseWriter, r *http.Request) {
+	var request CreateGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !requireScope(w, r, apikey.Create) {
+		return
+	}
+	if request.DeckID != nil {
+		if deck, found := h.GameService.Deck(*request.DeckID); found && !mayUse(w, r, deck) {
+			return
+		}
+	}
+
+	players := make(map[string]bool)
+	for _, player := range request.Players {
+		players[player] = true
+	}
+	for player, name := range request.Bots {
+		if !players[player] {
+			http.Error(w, fmt.Sprintf("Bot seat %v is not a player", player), http.StatusBadRequest)
+			return
+		}
+		if _, err := h.GameService.Bot(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	gameType := mux.Vars(r)["type"]
+	config := game.Config{Players: request.Players, Options: request.Options}
+	state, err := h.GameService.CreateTimedGame(gameType, request.DeckID, config, request.Clock.control())
+	if err == nil {
+		for player, name := range request.Bots {
+			if err = h.GameService.Seat(gameType, state.ID, player, name); err != nil {
+				break
+			}
+		}
+	}
+	writeGameState(w, state, err)
+}
+
+// RunTool runs a tool the game type offers, such as the cribbage hand
+// scorer, on the JSON request body.
+func (h *GameHandler) RunTool(w http.ResponseWriter, r *http.Request) {
+	var request json.RawMessage
+	if !decode(w, r, &request) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	result, err := h.GameService.Tool(vars["type"], vars["tool"], request)
+	switch {
+	case errors.Is(err, game.ErrUnknownTool):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetGame returns the game as seen by the requesting player, or the public
+// view without one.
+func (h *GameHandler) GetGame(w http.ResponseWriter, r *http.Request) {
+	gameID, err := uuid.Parse(mux.Vars(r)["gameID"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	player, ok := gameViewer(w, r, h.GameService, gameID)
+	if !ok {
+		return
+	}
+	state, err := h.GameService.State(mux.Vars(r)["type"], gameID, player)
+	writeGameState(w, state, err)
+}
+
+// gameViewer is the player a request sees the game as. A token for the game
+// shows the seat it grants, or the spectators' view.
+func gameViewer(w http.ResponseWriter, r *http.Request, games *service.GameService, gameID uuid.UUID) (string, bool) {
+	claims, ok := gameGrant(r, mux.Vars(r)["type"], gameID)
+	if !ok {
+		return viewer(r), true
+	}
+	if claims.Right != capability.Seat {
+		return "", true
+	}
+	player, err := seatPlayer(games, claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+	return player, true
+}
+
+func (h *GameHandler) PostAction(w http.ResponseWriter, r *http.Request) {
+	gameID, err := uuid.Parse(mux.Vars(r)["gameID"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+	var request ActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if claims, ok := gameGrant(r, mux.Vars(r)["type"], gameID); ok {
+		if !actAsSeat(w, h.GameService, claims, &request) {
+			return
+		}
+	} else {
+		if request.Player == "" {
+			request.Player = viewer(r)
+		}
+		if !mayActAs(w, r, request.Player) {
+			return
+		}
+	}
+
+	state, err := h.GameService.Apply(mux.Vars(r)["type"], gameID, request.Player, request.Action)
+	writeGameState(w, state, err)
+}
+
+// actAsSeat plays the action as the player in the seat a token grants.
+func actAsSeat(w http.ResponseWriter, games *service.GameService, claims capability.Claims, request *ActionRequest) bool {
+	if claims.Right != capability.Seat {
+		http.Error(w, "Capability token is read-only", http.StatusForbidden)
+		return false
+	}
+	player, err := seatPlayer(games, claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if request.Player != "" && request.Player != player {
+		http.Error(w, fmt.Sprintf("Capability token is for %v, not %v", player, request.Player), http.StatusForbidden)
+		return false
+	}
+	request.Player = player
+	return true
+}
+
+func writeGameState(w http.ResponseWriter, state service.GameState, err error) {
+	switch {
+	case err == service.ErrGameNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), deckErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/bot"
+	"cardGame/deck/cribbage"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/service"
+)
+
+func newGameRouter() *mux.Router {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	registry.Register("cribbage", cribbage.NewGame)
+	games := service.NewGameService(dao.NewDeckStorage(), registry)
+	bots := service.NewBotService()
+	bots.RegisterPlayer("first", bot.First)
+	games.SetBots(bots)
+	handler := NewGameHandler(games)
+
+	router := mux.NewRouter()
+	router.Use(NewAPIKeyHandler(testKeys, false).Authenticate)
+	router.HandleFunc("/games", handler.ListTypes).Methods("GET")
+	router.HandleFunc("/games/{type}", handler.CreateGame).Methods("POST")
+	router.HandleFunc("/games/{type}/tools/{tool}", handler.RunTool).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}", handler.GetGame).Methods("GET")
+	router.HandleFunc("/games/{type}/{gameID}", handler.PostAction).Methods("POST")
+	return router
+}
+
+func serve(router *mux.Router, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestGameHandler(t *testing.T) {
+	router := newGameRouter()
+
+	rr := serve(router, "GET", "/games", "")
+	if !strings.Contains(rr.Body.String(), `"highcard"`) {
+		t.Errorf("ListTypes handler returned unexpected body: %v", rr.Body.String())
+	}
+
+	rr = serve(router, "POST", "/games/highcard", `{"players": ["ann", "bob"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("CreateGame handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var state service.GameState
+	json.NewDecoder(rr.Body).Decode(&state)
+	path := "/games/highcard/" + state.ID.String()
+
+	rr = serveAs(router, "ann", "GET", path, "")
+	json.NewDecoder(rr.Body).Decode(&state)
+	if len(state.Actions) != 1 || state.Actions[0].Type != "draw" {
+		t.Errorf("GetGame handler returned unexpected actions: %v", state.Actions)
+	}
+
+	if rr := serveAs(router, "bob", "POST", path, `{"action": {"type": "draw"}}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("PostAction handler accepted a move out of turn")
+	}
+	if rr := serve(router, "POST", path, `{"player": "ann", "action": {"type": "draw"}}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("PostAction handler let an anonymous caller act as ann: %v", rr.Code)
+	}
+	if rr := serveAs(router, "bob", "POST", path, `{"player": "ann", "action": {"type": "draw"}}`); rr.Code != http.StatusForbidden {
+		t.Errorf("PostAction handler let bob act as ann: %v", rr.Code)
+	}
+	rr = serveAs(router, "ann", "POST", path, `{"action": {"type": "draw"}}`)
+	json.NewDecoder(rr.Body).Decode(&state)
+	if rr.Code != http.StatusOK || state.Version != 1 || !strings.Contains(string(state.View), `"ann"`) {
+		t.Errorf("PostAction handler returned unexpected state: %v %+v", rr.Code, state)
+	}
+	rr = serveAs(router, "bob", "POST", path, `{"player": "bob", "action": {"type": "draw"}}`)
+	json.NewDecoder(rr.Body).Decode(&state)
+	if !state.Terminal || len(state.Scores) != 2 {
+		t.Errorf("PostAction handler returned unexpected state: %+v", state)
+	}
+
+	if rr := serve(router, "GET", "/games/highcard/"+uuid.New().String(), ""); rr.Code != http.StatusNotFound {
+		t.Errorf("GetGame handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+	if rr := serve(router, "POST", "/games/poker", `{"players": ["ann", "bob"]}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("CreateGame handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGameHandler_Clock(t *testing.T) {
+	router := newGameRouter()
+
+	rr := serve(router, "POST", "/games/highcard", `{"players": ["ann", "bob"], "clock": {"move_seconds": 30, "default": "pass"}}`)
+	var state service.GameState
+	json.NewDecoder(rr.Body).Decode(&state)
+	if rr.Code != http.StatusOK || state.Turn.Deadlines["ann"].IsZero() {
+		t.Errorf("CreateGame handler returned unexpected state: %v %+v", rr.Code, state)
+	}
+
+	rr = serve(router, "POST", "/games/highcard", `{"players": ["ann", "bob"], "clock": {"increment_seconds": 5}}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("CreateGame handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGameHandler_Bots(t *testing.T) {
+	router := newGameRouter()
+
+	if rr := serve(router, "POST", "/games/highcard", `{"players": ["ann", "bob"], "bots": {"cat": "first"}}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Seated a bot for someone not playing: %v", rr.Code)
+	}
+	if rr := serve(router, "POST", "/games/highcard", `{"players": ["ann", "bob"], "bots": {"bob": "nobody"}}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Seated an unknown bot: %v", rr.Code)
+	}
+
+	rr := serve(router, "POST", "/games/highcard", `{"players": ["ann", "bob"], "bots": {"bob": "first"}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("CreateGame returned %v: %v", rr.Code, rr.Body.String())
+	}
+	var created service.GameState
+	json.NewDecoder(rr.Body).Decode(&created)
+	path := "/games/highcard/" + created.ID.String()
+	if rr := serveAs(router, "ann", "POST", path, `{"action": {"type": "draw"}}`); rr.Code != http.StatusOK {
+		t.Fatalf("PostAction returned %v: %v", rr.Code, rr.Body.String())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var state service.GameState
+		json.NewDecoder(serve(router, "GET", path, "").Body).Decode(&state)
+		if state.Terminal {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("The bot never moved for bob: %+v", state)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGameHandler_RunTool(t *testing.T) {
+	router := newGameRouter()
+
+	rr := serve(router, "POST", "/games/cribbage/tools/score", `{"hand": ["4H", "4S", "5D", "6C"], "starter": "6H"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("RunTool handler returned wrong status code: got %v want %v: %v", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var breakdown cribbage.Breakdown
+	json.NewDecoder(rr.Body).Decode(&breakdown)
+	if breakdown.Total != 24 || breakdown.Runs != 12 {
+		t.Errorf("RunTool handler returned unexpected breakdown: %+v", breakdown)
+	}
+
+	if rr := serve(router, "POST", "/games/cribbage/tools/score", `{"hand": ["4H"], "starter": "6H"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("RunTool handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+	for _, path := range []string{"/games/cribbage/tools/compare", "/games/highcard/tools/score", "/games/poker/tools/score"} {
+		if rr := serve(router, "POST", path, `{}`); rr.Code != http.StatusNotFound {
+			t.Errorf("RunTool handler returned wrong status code for %v: got %v want %v", path, rr.Code, http.StatusNotFound)
+		}
+	}
+}
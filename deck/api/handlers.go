@@ -4,11 +4,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
+	"cardGame/deck/access"
+	"cardGame/deck/apikey"
+	"cardGame/deck/capability"
 	"cardGame/deck/dao"
+	"cardGame/deck/model"
 	"cardGame/deck/service"
 )
 
@@ -21,8 +26,16 @@ type CreateDeckResponse struct {
 	DeckID    uuid.UUID `json:"deck_id"`
 	Shuffled  bool      `json:"shuffled"`
 	Remaining int       `json:"remaining"`
+	Owner     string    `json:"owner,omitempty"`
+	Key       string    `json:"key,omitempty"`
 }
 
+type GrantRequest struct {
+	KeyID string `json:"key_id"`
+}
+
+const errDeckKey = "Deck belongs to another API key"
+
 func (h *DeckHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
@@ -39,38 +52,73 @@ func (h *DeckHandler) CreateDeck(w http.ResponseWriter, r *http.Request) {
 	deckIDParam := r.URL.Query().Get("deckId")
 
 	if deckIDParam != "" {
-		h.handleExistingDeck(w, deckIDParam)
+		h.handleExistingDeck(w, r, deckIDParam)
 		return
 	}
 
 	h.handleNewDeck(w, r)
 }
 
-func (h *DeckHandler) handleExistingDeck(w http.ResponseWriter, deckIDParam string) {
+// claimedPlayer is the player a request names, in the X-Player header or
+// the player query parameter. Nothing vouches for it on its own.
+func claimedPlayer(r *http.Request) string {
+	if player := r.Header.Get("X-Player"); player != "" {
+		return player
+	}
+	return r.URL.Query().Get("player")
+}
+
+// viewer is the player a request is made for: its session's player, or the
+// player named by a request with an API key, whose holder vouches for it.
+// Anyone else sees only what is public.
+func viewer(r *http.Request) string {
+	if player := authenticated(r); player != "" {
+		return player
+	}
+	if _, ok := apiKey(r); ok {
+		return claimedPlayer(r)
+	}
+	return ""
+}
+
+// handleExistingDeck returns the deck as the viewer may see it. The order of
+// the undealt cards is never shown.
+func (h *DeckHandler) handleExistingDeck(w http.ResponseWriter, r *http.Request, deckIDParam string) {
 	deckID, err := uuid.Parse(deckIDParam)
 	if err != nil {
 		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
 		return
 	}
 
-	existingDeck, found := h.DeckService.GetDeck(deckID)
+	deck, found := h.DeckStorage.GetDeck(deckID)
 	if !found {
 		http.Error(w, "Deck not found", http.StatusNotFound)
 		return
 	}
+	if _, granted := deckGrant(r, deck.ID, capability.Read); !granted && !mayUse(w, r, deck) {
+		return
+	}
+	view := access.DefaultPolicy.Project(deck, viewer(r))
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(existingDeck)
+	json.NewEncoder(w).Encode(view)
 }
 
+// handleNewDeck creates a deck, owned by the caller if they are logged in
+// and by their API key if they have one.
 func (h *DeckHandler) handleNewDeck(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, apikey.Create) {
+		return
+	}
 	cards := r.URL.Query().Get("cards")
 	shuffled, _ := strconv.ParseBool(r.URL.Query().Get("shuffled"))
-	newDeck := h.DeckService.CreateDeck(shuffled, cards)
+	newDeck := h.DeckService.CreateDeckFor(authenticated(r), keyID(r), shuffled, cards)
 	response := CreateDeckResponse{
 		DeckID:    newDeck.ID,
 		Shuffled:  newDeck.Shuffled,
 		Remaining: newDeck.Remaining,
+		Owner:     newDeck.Owner,
+		Key:       newDeck.Key,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -78,6 +126,9 @@ func (h *DeckHandler) handleNewDeck(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *DeckHandler) DrawCards(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, apikey.Draw) {
+		return
+	}
 	vars := mux.Vars(r)
 	deckID, err := uuid.Parse(vars["deckID"])
 	if err != nil {
@@ -96,13 +147,191 @@ func (h *DeckHandler) DrawCards(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Deck not found", http.StatusNotFound)
 		return
 	}
+	// A draw token stands in for the deck's owners, up to its limit.
+	g, granted := deckGrant(r, deck.ID, capability.Draw)
+	switch {
+	case granted && count < 1:
+		http.Error(w, "Invalid count parameter", http.StatusBadRequest)
+		return
+	case granted:
+		if err := g.spend(count); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	case !mayUse(w, r, deck):
+		return
+	}
 
 	drawnCards, err := h.DeckService.DrawCards(deck, count)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		if granted {
+			g.spend(-count)
+		}
+		http.Error(w, err.Error(), deckErrorStatus(err))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{"cards": drawnCards})
 }
+
+// Deal deals count cards to each of the comma-separated players into their
+// hand piles and returns the deck as the viewer sees it.
+func (h *DeckHandler) Deal(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, apikey.Draw) {
+		return
+	}
+	deckID, err := uuid.Parse(mux.Vars(r)["deckID"])
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return
+	}
+
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil {
+		http.Error(w, "Invalid count parameter", http.StatusBadRequest)
+		return
+	}
+	var players []string
+	if param := r.URL.Query().Get("players"); param != "" {
+		players = strings.Split(param, ",")
+	}
+
+	stored, found := h.DeckStorage.GetDeck(deckID)
+	if !found {
+		http.Error(w, "Deck not found", http.StatusNotFound)
+		return
+	}
+	if !mayUse(w, r, stored) {
+		return
+	}
+	deck, err := h.DeckService.Deal(deckID, players, count)
+	if err != nil {
+		http.Error(w, err.Error(), deckErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(access.DefaultPolicy.Project(deck, viewer(r)))
+}
+
+// Claim makes the logged in caller the owner of a deck nobody owns, so only
+// they may draw from and deal it from then on.
+func (h *DeckHandler) Claim(w http.ResponseWriter, r *http.Request) {
+	deckID, err := uuid.Parse(mux.Vars(r)["deckID"])
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	player := authenticated(r)
+	if player == "" {
+		http.Error(w, "Login required", http.StatusUnauthorized)
+		return
+	}
+	if stored, found := h.DeckStorage.GetDeck(deckID); found && !keyAllows(r, stored) {
+		http.Error(w, errDeckKey, http.StatusForbidden)
+		return
+	}
+
+	deck, err := h.DeckService.Claim(deckID, player)
+	switch {
+	case err == service.ErrNotDeckOwner:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Deck not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(access.DefaultPolicy.Project(deck, player))
+}
+
+// deckErrorStatus is 409 for a deck dealt to a hosted game or bridge table
+// and 400 for any other error with the request.
+func deckErrorStatus(err error) int {
+	if err == service.ErrDeckInGame {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// mayUse checks that the caller's player and API key may both use the deck,
+// writing the error when they may not.
+func mayUse(w http.ResponseWriter, r *http.Request, deck model.Deck) bool {
+	switch {
+	case !keyAllows(r, deck):
+		http.Error(w, errDeckKey, http.StatusForbidden)
+	case !deck.UsableBy(authenticated(r)):
+		http.Error(w, service.ErrNotDeckOwner.Error(), http.StatusForbidden)
+	default:
+		return true
+	}
+	return false
+}
+
+// stored finds the deck in the path and checks the caller may use it.
+func (h *DeckHandler) stored(w http.ResponseWriter, r *http.Request) (model.Deck, bool) {
+	deckID, err := uuid.Parse(mux.Vars(r)["deckID"])
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return model.Deck{}, false
+	}
+	deck, found := h.DeckStorage.GetDeck(deckID)
+	if !found {
+		http.Error(w, "Deck not found", http.StatusNotFound)
+		return model.Deck{}, false
+	}
+	return deck, mayUse(w, r, deck)
+}
+
+func (h *DeckHandler) DeleteDeck(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, apikey.Create) {
+		return
+	}
+	deck, ok := h.stored(w, r)
+	if !ok {
+		return
+	}
+	if err := h.DeckService.Delete(deck.ID); err != nil {
+		http.Error(w, "Deck not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Grant shares a deck with another API key. Only the key that created the
+// deck, or an admin key, may share it.
+func (h *DeckHandler) Grant(w http.ResponseWriter, r *http.Request) {
+	var request GrantRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	h.grant(w, r, request.KeyID, false)
+}
+
+func (h *DeckHandler) Ungrant(w http.ResponseWriter, r *http.Request) {
+	h.grant(w, r, mux.Vars(r)["keyID"], true)
+}
+
+func (h *DeckHandler) grant(w http.ResponseWriter, r *http.Request, grantee string, revoke bool) {
+	deck, ok := h.stored(w, r)
+	if !ok {
+		return
+	}
+	key, hasKey := apiKey(r)
+	if !hasKey || (key.ID != deck.Key && !key.Has(apikey.Admin)) {
+		http.Error(w, "Only the key that created the deck may share it", http.StatusForbidden)
+		return
+	}
+	if grantee == "" {
+		http.Error(w, "key_id is required", http.StatusBadRequest)
+		return
+	}
+	deck, err := h.DeckService.Grant(deck.ID, grantee, revoke)
+	if err != nil {
+		http.Error(w, "Deck not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"deck_id": deck.ID, "key": deck.Key, "grants": deck.Grants})
+}
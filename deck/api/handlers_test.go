@@ -6,6 +6,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/access"
 	"cardGame/deck/dao"
 	"cardGame/deck/model"
 	"cardGame/deck/service"
@@ -92,5 +96,55 @@ func TestDeckHandler_CreateDeck(t *testing.T) {
 		if response.Remaining != existingDeck.Remaining {
 			t.Errorf("CreateDeck handler returned wrong remaining cards: got %v want %v", response.Remaining, existingDeck.Remaining)
 		}
+		if len(response.Cards) != 0 {
+			t.Errorf("CreateDeck handler revealed the undealt cards")
+		}
 	})
 }
+
+func TestDeckHandler_Deal(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := service.NewDeckService(storage)
+	handler := NewDeckHandler(service, storage)
+	deck := service.CreateDeck(true, "")
+
+	router := mux.NewRouter()
+	router.Use(NewAPIKeyHandler(testKeys, false).Authenticate)
+	router.HandleFunc("/deck", handler.CreateDeck).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/deal", handler.Deal).Methods("POST")
+
+	rr := serveAs(router, "ann", "POST", "/deck/"+deck.ID.String()+"/deal?players=ann,bob&count=5", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Deal handler returned wrong status code: got %v want %v: %v", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var view access.DeckView
+	json.NewDecoder(rr.Body).Decode(&view)
+	if view.Remaining != 42 || view.Stock.Size != 42 || len(view.Stock.Cards) != 0 {
+		t.Errorf("Deal handler revealed the stock: %+v", view.Stock)
+	}
+	if hand := view.Piles["hand:ann"]; len(hand.Cards) != 5 {
+		t.Errorf("Ann cannot see her hand: %+v", hand)
+	}
+	if hand := view.Piles["hand:bob"]; hand.Size != 5 || len(hand.Cards) != 0 {
+		t.Errorf("Ann can see Bob's hand: %+v", hand)
+	}
+
+	view = access.DeckView{}
+	json.NewDecoder(serveAs(router, "bob", "GET", "/deck?deckId="+deck.ID.String(), "").Body).Decode(&view)
+	if len(view.Piles["hand:bob"].Cards) != 5 || len(view.Piles["hand:ann"].Cards) != 0 {
+		t.Errorf("Unexpected view for Bob: %+v", view.Piles)
+	}
+	view = access.DeckView{}
+	json.NewDecoder(serve(router, "GET", "/deck?deckId="+deck.ID.String()+"&player=bob", "").Body).Decode(&view)
+	if len(view.Piles["hand:bob"].Cards) != 0 {
+		t.Errorf("An unauthenticated player parameter showed Bob's hand: %+v", view.Piles)
+	}
+
+	if rr := serve(router, "POST", "/deck/"+deck.ID.String()+"/deal?players=ann,bob&count=30", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Deal handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+	if rr := serve(router, "POST", "/deck/"+uuid.New().String()+"/deal?players=ann&count=1", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Deal handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
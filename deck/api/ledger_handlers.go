@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/ledger"
+	"cardGame/deck/service"
+)
+
+type AmountRequest struct {
+	Amount int64 `json:"amount"`
+}
+
+type TransferRequest struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount int64  `json:"amount"`
+	Memo   string `json:"memo,omitempty"`
+}
+
+// LedgerHandler serves the chip ledger. Posting requests may carry an
+// Idempotency-Key header; a retried request with the same key returns the
+// original entry instead of posting again. Chips go into and out of pots only
+// through the games that hold them, by game.Bank, never over HTTP.
+type LedgerHandler struct {
+	LedgerService *service.LedgerService
+}
+
+func NewLedgerHandler(ledgerService *service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		LedgerService: ledgerService,
+	}
+}
+
+func idempotencyKey(r *http.Request) string {
+	return r.Header.Get("Idempotency-Key")
+}
+
+func decode(w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// BuyIn sells a player chips from the cashier. Only admins may mint chips.
+func (h *LedgerHandler) BuyIn(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	var request AmountRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	entry, err := h.LedgerService.BuyIn(idempotencyKey(r), mux.Vars(r)["player"], request.Amount)
+	writeEntry(w, entry, err)
+}
+
+// mayMoveChips checks that the request may move player's chips: it must be
+// logged in as player or carry an admin API key.
+func mayMoveChips(w http.ResponseWriter, r *http.Request, player string) bool {
+	if key, ok := apiKey(r); ok && key.Has(apikey.Admin) {
+		return true
+	}
+	switch p := authenticated(r); {
+	case p == "":
+		http.Error(w, "Login required", http.StatusUnauthorized)
+	case p != player:
+		http.Error(w, fmt.Sprintf("Logged in as %v, not %v", p, player), http.StatusForbidden)
+	default:
+		return true
+	}
+	return false
+}
+
+func (h *LedgerHandler) CashOut(w http.ResponseWriter, r *http.Request) {
+	var request AmountRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if !mayMoveChips(w, r, mux.Vars(r)["player"]) {
+		return
+	}
+	entry, err := h.LedgerService.CashOut(idempotencyKey(r), mux.Vars(r)["player"], request.Amount)
+	writeEntry(w, entry, err)
+}
+
+func (h *LedgerHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	var request TransferRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if !mayMoveChips(w, r, request.From) {
+		return
+	}
+	entry, err := h.LedgerService.Transfer(idempotencyKey(r), request.From, request.To, request.Amount, request.Memo)
+	writeEntry(w, entry, err)
+}
+
+func writeEntry(w http.ResponseWriter, entry ledger.Entry, err error) {
+	switch {
+	case errors.Is(err, ledger.ErrKeyReused):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// GetPlayer returns a player's balance and transaction history.
+func (h *LedgerHandler) GetPlayer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.LedgerService.Player(mux.Vars(r)["player"]))
+}
+
+func (h *LedgerHandler) GetPot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.LedgerService.Pot(mux.Vars(r)["potID"]))
+}
+
+func (h *LedgerHandler) Audit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.LedgerService.Audit())
+}
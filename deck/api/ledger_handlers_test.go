@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/service"
+)
+
+func newLedgerRouter() (*mux.Router, *service.LedgerService) {
+	ledgerService := service.NewLedgerService()
+	handler := NewLedgerHandler(ledgerService)
+	apiKeyService := service.NewAPIKeyService()
+	apiKeyService.Import("admin", testAdminKey)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService, false)
+
+	router := mux.NewRouter()
+	router.Use(apiKeyHandler.Authenticate)
+	router.HandleFunc("/ledger/players/{player}", handler.GetPlayer).Methods("GET")
+	router.HandleFunc("/ledger/players/{player}/buyin", handler.BuyIn).Methods("POST")
+	router.HandleFunc("/ledger/players/{player}/cashout", handler.CashOut).Methods("POST")
+	router.HandleFunc("/ledger/transfers", handler.Transfer).Methods("POST")
+	router.HandleFunc("/ledger/pots/{potID}", handler.GetPot).Methods("GET")
+	router.HandleFunc("/ledger/audit", handler.Audit).Methods("GET")
+	return router, ledgerService
+}
+
+func serveWithKey(router *mux.Router, key, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+	req.Header.Set("X-API-Key", testAdminKey)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestLedgerHandler(t *testing.T) {
+	router, ledgerService := newLedgerRouter()
+
+	for i := 0; i < 2; i++ {
+		if rr := serveWithKey(router, "ann-buy", "/ledger/players/ann/buyin", `{"amount": 300}`); rr.Code != http.StatusOK {
+			t.Fatalf("BuyIn handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+	}
+	if rr := serveWithKey(router, "ann-buy", "/ledger/players/ann/buyin", `{"amount": 400}`); rr.Code != http.StatusConflict {
+		t.Errorf("BuyIn with a reused key returned %v", rr.Code)
+	}
+	if rr := serve(router, "POST", "/ledger/players/bob/buyin", `{"amount": 100}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("BuyIn without an admin key returned %v", rr.Code)
+	}
+	serveWithAPIKey(router, testAdminKey, "POST", "/ledger/players/bob/buyin", `{"amount": 100}`)
+	if rr := serve(router, "POST", "/ledger/transfers", `{"from": "ann", "to": "bob", "amount": 50}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Transfer without a login or an admin key returned %v", rr.Code)
+	}
+	if rr := serve(router, "POST", "/ledger/players/ann/cashout", `{"amount": 50}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("CashOut without a login or an admin key returned %v", rr.Code)
+	}
+	serveWithAPIKey(router, testAdminKey, "POST", "/ledger/transfers", `{"from": "ann", "to": "bob", "amount": 50, "memo": "side bet"}`)
+	if _, err := ledgerService.Bet("", "table-1", "bob", 150); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ledgerService.Bet("", "table-1", "bob", 1); err == nil {
+		t.Errorf("Bet overdrew bob")
+	}
+	if _, err := ledgerService.SettlePot("", "table-1", map[string]int64{"ann": 145}, 5); err != nil {
+		t.Fatalf("SettlePot failed: %v", err)
+	}
+
+	var statement service.Statement
+	rr := serve(router, "GET", "/ledger/players/ann", "")
+	json.NewDecoder(rr.Body).Decode(&statement)
+	if statement.Balance != 395 || len(statement.History) != 3 {
+		t.Errorf("Unexpected statement: %+v", statement)
+	}
+
+	var audit service.Audit
+	rr = serve(router, "GET", "/ledger/audit", "")
+	json.NewDecoder(rr.Body).Decode(&audit)
+	if !audit.Valid || audit.InPlay != 400 || audit.Rake != 5 {
+		t.Errorf("Unexpected audit: %+v", audit)
+	}
+}
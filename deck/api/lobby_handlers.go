@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/lobby"
+	"cardGame/deck/service"
+)
+
+type CreateRoomRequest struct {
+	GameType   string            `json:"game_type"`
+	Capacity   int               `json:"capacity"`
+	Visibility lobby.Visibility  `json:"visibility"`
+	Options    map[string]string `json:"options,omitempty"`
+}
+
+type JoinRoomRequest struct {
+	InviteCode string `json:"invite_code"`
+}
+
+type ReadyRequest struct {
+	Ready bool `json:"ready"`
+}
+
+type MatchmakingRequest struct {
+	GameType string `json:"game_type"`
+	Players  int    `json:"players"`
+	Skill    *int   `json:"skill,omitempty"`
+}
+
+// LobbyHandler serves rooms and the matchmaking queue. Every request acts
+// for the player named by the X-Player header or player query parameter.
+type LobbyHandler struct {
+	LobbyService *service.LobbyService
+}
+
+func NewLobbyHandler(lobbyService *service.LobbyService) *LobbyHandler {
+	return &LobbyHandler{
+		LobbyService: lobbyService,
+	}
+}
+
+// requirePlayer returns the requesting player, answering 401 if there is none.
+func requirePlayer(w http.ResponseWriter, r *http.Request) (string, bool) {
+	p := viewer(r)
+	if p == "" {
+		http.Error(w, "Login required", http.StatusUnauthorized)
+	}
+	return p, p != ""
+}
+
+func roomID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["roomID"])
+	if err != nil {
+		http.Error(w, "Invalid room ID", http.StatusBadRequest)
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func (h *LobbyHandler) ListRooms(w http.ResponseWriter, r *http.Request) {
+	rooms := h.LobbyService.ListRooms(r.URL.Query().Get("game_type"))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"rooms": rooms})
+}
+
+func (h *LobbyHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	host, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	var request CreateRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	room, err := h.LobbyService.CreateRoom(host, request.GameType, request.Capacity, request.Visibility, request.Options)
+	writeRoom(w, room, err)
+}
+
+// GetRoom shows the invite code only to the room's members.
+func (h *LobbyHandler) GetRoom(w http.ResponseWriter, r *http.Request) {
+	id, ok := roomID(w, r)
+	if !ok {
+		return
+	}
+	room, err := h.LobbyService.Room(id, viewer(r))
+	writeRoom(w, room, err)
+}
+
+func (h *LobbyHandler) JoinRoom(w http.ResponseWriter, r *http.Request) {
+	id, ok := roomID(w, r)
+	if !ok {
+		return
+	}
+	p, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	var request JoinRoomRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	room, err := h.LobbyService.Join(id, p, request.InviteCode)
+	writeRoom(w, room, err)
+}
+
+func (h *LobbyHandler) LeaveRoom(w http.ResponseWriter, r *http.Request) {
+	id, ok := roomID(w, r)
+	if !ok {
+		return
+	}
+	p, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	room, err := h.LobbyService.Leave(id, p)
+	writeRoom(w, room, err)
+}
+
+// Ready marks the player ready, or not ready with {"ready": false}. The
+// request body is optional.
+func (h *LobbyHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	id, ok := roomID(w, r)
+	if !ok {
+		return
+	}
+	p, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	request := ReadyRequest{Ready: true}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	room, err := h.LobbyService.SetReady(id, p, request.Ready)
+	writeRoom(w, room, err)
+}
+
+func (h *LobbyHandler) StartRoom(w http.ResponseWriter, r *http.Request) {
+	id, ok := roomID(w, r)
+	if !ok {
+		return
+	}
+	p, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	room, err := h.LobbyService.Start(id, p)
+	writeRoom(w, room, err)
+}
+
+func writeRoom(w http.ResponseWriter, room lobby.Room, err error) {
+	switch err {
+	case nil:
+	case lobby.ErrRoomNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case lobby.ErrBadInviteCode, lobby.ErrNotHost:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case lobby.ErrAlreadyStarted:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(room)
+}
+
+func (h *LobbyHandler) Enqueue(w http.ResponseWriter, r *http.Request) {
+	p, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	var request MatchmakingRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Players == 0 {
+		request.Players = 2
+	}
+
+	match, err := h.LobbyService.Enqueue(p, request.GameType, request.Players, request.Skill)
+	writeMatch(w, match, err, http.StatusBadRequest)
+}
+
+// GetMatch returns the game the player was matched into, or their ticket
+// while they wait.
+func (h *LobbyHandler) GetMatch(w http.ResponseWriter, r *http.Request) {
+	p, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	match, err := h.LobbyService.Match(p)
+	writeMatch(w, match, err, http.StatusNotFound)
+}
+
+func (h *LobbyHandler) Dequeue(w http.ResponseWriter, r *http.Request) {
+	p, ok := requirePlayer(w, r)
+	if !ok {
+		return
+	}
+	if err := h.LobbyService.Dequeue(p); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeMatch(w http.ResponseWriter, match service.Match, err error, status int) {
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(match)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/lobby"
+	"cardGame/deck/service"
+)
+
+func newLobbyRouter() *mux.Router {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	handler := NewLobbyHandler(service.NewLobbyService(service.NewGameService(dao.NewDeckStorage(), registry)))
+
+	router := mux.NewRouter()
+	router.Use(NewAPIKeyHandler(testKeys, false).Authenticate)
+	router.HandleFunc("/rooms", handler.ListRooms).Methods("GET")
+	router.HandleFunc("/rooms", handler.CreateRoom).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}", handler.GetRoom).Methods("GET")
+	router.HandleFunc("/rooms/{roomID}/join", handler.JoinRoom).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/leave", handler.LeaveRoom).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/ready", handler.Ready).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/start", handler.StartRoom).Methods("POST")
+	router.HandleFunc("/matchmaking", handler.Enqueue).Methods("POST")
+	router.HandleFunc("/matchmaking", handler.GetMatch).Methods("GET")
+	router.HandleFunc("/matchmaking", handler.Dequeue).Methods("DELETE")
+	return router
+}
+
+func serveAs(router *mux.Router, player, method, path, body string) *httptest.ResponseRecorder {
+	return serveWithAPIKeyAs(router, testGatewayKey, player, method, path, body)
+}
+
+func TestLobbyHandler_Rooms(t *testing.T) {
+	router := newLobbyRouter()
+
+	if rr := serve(router, "POST", "/rooms?player=ann", `{"game_type": "highcard", "capacity": 2}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("CreateRoom by an unauthenticated player returned %v", rr.Code)
+	}
+	rr := serveAs(router, "ann", "POST", "/rooms", `{"game_type": "highcard", "capacity": 2, "visibility": "invite"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("CreateRoom handler returned wrong status code: got %v want %v: %v", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var room lobby.Room
+	json.NewDecoder(rr.Body).Decode(&room)
+	path := "/rooms/" + room.ID.String()
+
+	if rr := serve(router, "GET", "/rooms", ""); strings.Contains(rr.Body.String(), room.ID.String()) {
+		t.Errorf("ListRooms listed an invite room: %v", rr.Body.String())
+	}
+	if rr := serveAs(router, "bob", "GET", path, ""); strings.Contains(rr.Body.String(), room.InviteCode) {
+		t.Errorf("GetRoom showed the invite code to a non-member")
+	}
+	if rr := serveAs(router, "bob", "POST", path+"/join", `{"invite_code": "nope"}`); rr.Code != http.StatusForbidden {
+		t.Errorf("JoinRoom with a wrong code returned %v", rr.Code)
+	}
+	if rr := serveAs(router, "bob", "POST", path+"/join", `{"invite_code": "`+room.InviteCode+`"}`); rr.Code != http.StatusOK {
+		t.Fatalf("JoinRoom returned %v: %v", rr.Code, rr.Body.String())
+	}
+
+	serveAs(router, "ann", "POST", path+"/ready", "")
+	rr = serveAs(router, "bob", "POST", path+"/ready", `{"ready": true}`)
+	json.NewDecoder(rr.Body).Decode(&room)
+	if room.Status != lobby.Playing || room.GameID == nil {
+		t.Fatalf("Room did not start: %+v", room)
+	}
+	if rr := serveAs(router, "bob", "POST", path+"/leave", ""); rr.Code != http.StatusConflict {
+		t.Errorf("LeaveRoom after the start returned %v", rr.Code)
+	}
+	if rr := serve(router, "GET", "/rooms/nope", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("GetRoom with a bad ID returned %v", rr.Code)
+	}
+}
+
+func TestLobbyHandler_Matchmaking(t *testing.T) {
+	router := newLobbyRouter()
+
+	if rr := serveAs(router, "ann", "POST", "/matchmaking", `{"game_type": "highcard"}`); rr.Code != http.StatusOK {
+		t.Fatalf("Enqueue returned %v: %v", rr.Code, rr.Body.String())
+	}
+	serveAs(router, "bob", "POST", "/matchmaking", `{"game_type": "highcard"}`)
+
+	var match service.Match
+	rr := serveAs(router, "ann", "GET", "/matchmaking", "")
+	json.NewDecoder(rr.Body).Decode(&match)
+	if match.GameID == nil || len(match.Players) != 2 {
+		t.Errorf("Ann should have been matched: %+v", match)
+	}
+
+	if rr := serveAs(router, "cat", "DELETE", "/matchmaking", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Dequeue for a player not queued returned %v", rr.Code)
+	}
+}
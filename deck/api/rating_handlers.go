@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/rating"
+	"cardGame/deck/service"
+)
+
+type RatingResultRequest struct {
+	Places map[string]int `json:"places"`
+	GameID string         `json:"game_id,omitempty"`
+}
+
+// RatingHandler serves ratings and leaderboards under /ratings/{type}.
+type RatingHandler struct {
+	RatingService *service.RatingService
+}
+
+func NewRatingHandler(ratingService *service.RatingService) *RatingHandler {
+	return &RatingHandler{
+		RatingService: ratingService,
+	}
+}
+
+// Leaderboard takes the query parameters system (elo or glicko), window
+// (day, week, month, year or all), offset and limit.
+func (h *RatingHandler) Leaderboard(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	offset, limit := 0, 50
+	var err error
+	if value := query.Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > 500 {
+			http.Error(w, "Limit must be 1 to 500", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.RatingService.Leaderboard(mux.Vars(r)["type"], query.Get("system"), query.Get("window"), offset, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *RatingHandler) GetPlayer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rated, err := h.RatingService.Player(vars["type"], vars["player"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rated)
+}
+
+// RecordResult rates a game played away from the server. Hosted games are
+// rated when they finish, so only admin keys may record results.
+func (h *RatingHandler) RecordResult(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	var request RatingResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ratings, err := h.RatingService.Record(mux.Vars(r)["type"], request.Places, request.GameID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]rating.Rating{"ratings": ratings})
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/rating"
+	"cardGame/deck/service"
+)
+
+func newRatingRouter() *mux.Router {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	handler := NewRatingHandler(service.NewRatingService(service.NewGameService(dao.NewDeckStorage(), registry)))
+
+	router := mux.NewRouter()
+	router.Use(NewAPIKeyHandler(testKeys, false).Authenticate)
+	router.HandleFunc("/ratings/{type}", handler.Leaderboard).Methods("GET")
+	router.HandleFunc("/ratings/{type}/players/{player}", handler.GetPlayer).Methods("GET")
+	router.HandleFunc("/ratings/{type}/results", handler.RecordResult).Methods("POST")
+	return router
+}
+
+func TestRatingHandler(t *testing.T) {
+	router := newRatingRouter()
+
+	result := `{"places": {"ann": 1, "bob": 2, "cat": 3}}`
+	if rr := serve(router, "POST", "/ratings/highcard/results", result); rr.Code != http.StatusUnauthorized {
+		t.Errorf("RecordResult accepted a result without a key: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, testGatewayKey, "POST", "/ratings/highcard/results", result); rr.Code != http.StatusForbidden {
+		t.Errorf("RecordResult accepted a result from a key that is not admin: %v", rr.Code)
+	}
+	rr := serveWithAPIKey(router, testAdminKey, "POST", "/ratings/highcard/results", result)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("RecordResult handler returned wrong status code: got %v want %v: %v", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if rr := serveWithAPIKey(router, testAdminKey, "POST", "/ratings/poker/results", `{"places": {"ann": 1, "bob": 2}}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("RecordResult accepted an unknown game type: %v", rr.Code)
+	}
+	if rr := serve(router, "GET", "/ratings/poker", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Leaderboard accepted an unknown game type: %v", rr.Code)
+	}
+	if rr := serve(router, "GET", "/ratings/poker/players/ann", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("GetPlayer accepted an unknown game type: %v", rr.Code)
+	}
+
+	var page rating.Page
+	rr = serve(router, "GET", "/ratings/highcard?system=elo&limit=2&offset=1", "")
+	json.NewDecoder(rr.Body).Decode(&page)
+	if page.Total != 3 || len(page.Standings) != 2 || page.Standings[0].Rating.Player != "bob" || page.Standings[0].Rank != 2 {
+		t.Errorf("Unexpected leaderboard page: %+v", page)
+	}
+	if rr := serve(router, "GET", "/ratings/highcard?limit=0", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Leaderboard accepted a limit of 0: %v", rr.Code)
+	}
+
+	if rr := serve(router, "GET", "/ratings/highcard/players/ann", ""); rr.Code != http.StatusOK {
+		t.Errorf("GetPlayer returned %v", rr.Code)
+	}
+	if rr := serve(router, "GET", "/ratings/highcard/players/zed", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("GetPlayer for an unrated player returned %v", rr.Code)
+	}
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/service"
+)
+
+type AttachResponse struct {
+	Attachment service.Attachment `json:"attachment"`
+	State      service.GameState  `json:"state"`
+}
+
+type ResumeRequest struct {
+	Token string `json:"token"`
+	// Ack is the last event the client saw. Without it the events after
+	// the last acknowledged one are sent.
+	Ack *int `json:"ack,omitempty"`
+	// ChatSince, if set, asks for the table's chat after that message too.
+	ChatSince *int `json:"chat_since,omitempty"`
+}
+
+type ResumeResponse struct {
+	Attachment service.Attachment `json:"attachment"`
+	service.Replay
+	Chat *service.ChatPage `json:"chat,omitempty"`
+}
+
+// EventsResponse is a replay with, if asked for, the table's chat.
+type EventsResponse struct {
+	service.Replay
+	Chat *service.ChatPage `json:"chat,omitempty"`
+}
+
+// ResumeHandler lets clients that lost their connection take their seat
+// back and catch up on what they missed, table chat included.
+type ResumeHandler struct {
+	ResumeService *service.ResumeService
+	GameService   *service.GameService
+	ChatService   *service.ChatService
+}
+
+func NewResumeHandler(resumeService *service.ResumeService, gameService *service.GameService, chatService *service.ChatService) *ResumeHandler {
+	return &ResumeHandler{
+		ResumeService: resumeService,
+		GameService:   gameService,
+		ChatService:   chatService,
+	}
+}
+
+func gameID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["gameID"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// Attach gives the caller a resume token for their seat. The seat comes
+// from a seat token, or from the player the caller may act as.
+func (h *ResumeHandler) Attach(w http.ResponseWriter, r *http.Request) {
+	id, ok := gameID(w, r)
+	if !ok {
+		return
+	}
+	var request ActionRequest
+	request.Player = viewer(r)
+	if claims, granted := gameGrant(r, mux.Vars(r)["type"], id); granted {
+		request.Player = ""
+		if !actAsSeat(w, h.GameService, claims, &request) {
+			return
+		}
+	} else if !mayActAs(w, r, request.Player) {
+		return
+	}
+	if request.Player == "" {
+		http.Error(w, "Player required", http.StatusBadRequest)
+		return
+	}
+
+	attachment, state, err := h.ResumeService.Attach(mux.Vars(r)["type"], id, request.Player)
+	if err != nil {
+		writeGameState(w, state, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(AttachResponse{Attachment: attachment, State: state})
+}
+
+// Resume re-attaches the token's player and returns the events they missed.
+// When the replay is not complete the buffer has dropped some of them, and
+// the client should start again from the state.
+func (h *ResumeHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	id, ok := gameID(w, r)
+	if !ok {
+		return
+	}
+	var request ResumeRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	attachment, replayed, err := h.ResumeService.Resume(mux.Vars(r)["type"], id, request.Token, request.Ack)
+	switch {
+	case err == service.ErrInvalidResume:
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		writeGameState(w, service.GameState{}, err)
+		return
+	}
+	response := ResumeResponse{Attachment: attachment, Replay: replayed}
+	if request.ChatSince != nil {
+		if response.Chat, err = tableChat(h.ChatService, attachment.GameType, id, attachment.Player, *request.ChatSince); err != nil {
+			writeChatError(w, err)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Events returns the events after since, as the viewer sees them, and with
+// chat_since the table's chat after that message.
+func (h *ResumeHandler) Events(w http.ResponseWriter, r *http.Request) {
+	id, ok := gameID(w, r)
+	if !ok {
+		return
+	}
+	since, err := strconv.Atoi(r.URL.Query().Get("since"))
+	if err != nil || since < 0 {
+		http.Error(w, "Invalid since parameter", http.StatusBadRequest)
+		return
+	}
+	player, ok := gameViewer(w, r, h.GameService, id)
+	if !ok {
+		return
+	}
+	replayed, err := h.GameService.Events(mux.Vars(r)["type"], id, player, since)
+	if err != nil {
+		writeGameState(w, service.GameState{}, err)
+		return
+	}
+	response := EventsResponse{Replay: replayed}
+	if param := r.URL.Query().Get("chat_since"); param != "" {
+		chatSince, err := strconv.Atoi(param)
+		if err != nil || chatSince < 0 {
+			http.Error(w, "Invalid chat_since parameter", http.StatusBadRequest)
+			return
+		}
+		if _, granted := gameGrant(r, mux.Vars(r)["type"], id); !granted && !mayActAs(w, r, player) {
+			return
+		}
+		if response.Chat, err = tableChat(h.ChatService, mux.Vars(r)["type"], id, player, chatSince); err != nil {
+			writeChatError(w, err)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/capability"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/service"
+)
+
+func newResumeRouter() *mux.Router {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	gameService := service.NewGameService(dao.NewDeckStorage(), registry)
+	capabilityHandler := NewCapabilityHandler(service.NewCapabilityService(capability.NewKey()), gameService)
+	gameHandler := NewGameHandler(gameService)
+	chatService := service.NewChatService(service.NewLobbyService(gameService), gameService)
+	handler := NewResumeHandler(service.NewResumeService(gameService), gameService, chatService)
+	chatHandler := NewChatHandler(chatService, gameService)
+
+	router := mux.NewRouter()
+	router.Use(capabilityHandler.Authenticate, NewAPIKeyHandler(testKeys, false).Authenticate)
+	router.HandleFunc("/games/{type}", gameHandler.CreateGame).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}", gameHandler.PostAction).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/attach", handler.Attach).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/resume", handler.Resume).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/events", handler.Events).Methods("GET")
+	router.HandleFunc("/games/{type}/{gameID}/chat", chatHandler.Post).Methods("POST")
+	router.HandleFunc("/capabilities", capabilityHandler.Issue).Methods("POST")
+	return router
+}
+
+func TestResumeHandler(t *testing.T) {
+	router := newResumeRouter()
+	var state service.GameState
+	json.NewDecoder(serve(router, "POST", "/games/highcard", `{"players": ["ann", "bob"]}`).Body).Decode(&state)
+	path := "/games/highcard/" + state.ID.String()
+
+	if rr := serveAs(router, "cat", "POST", path+"/attach", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Player without a seat attached: %v", rr.Code)
+	}
+	rr := serveAs(router, "bob", "POST", path+"/attach", "")
+	var attached AttachResponse
+	if err := json.NewDecoder(rr.Body).Decode(&attached); err != nil || rr.Code != http.StatusCreated || attached.Attachment.Token == "" {
+		t.Fatalf("Attach returned %v: %v", rr.Code, err)
+	}
+	if attached.Attachment.Player != "bob" || len(attached.State.Players) != 2 {
+		t.Errorf("Attach should show bob's seat: %+v", attached)
+	}
+
+	serveAs(router, "ann", "POST", path, `{"action": {"type": "draw"}}`)
+	rr = serve(router, "POST", path+"/resume", `{"token": "`+attached.Attachment.Token+`"}`)
+	var resumed ResumeResponse
+	json.NewDecoder(rr.Body).Decode(&resumed)
+	if rr.Code != http.StatusOK || len(resumed.Events) != 1 || resumed.Events[0].Player != "ann" || !resumed.Complete {
+		t.Errorf("Resume returned %v: %+v", rr.Code, resumed)
+	}
+	if resumed.Attachment.Token != "" || resumed.State.Version != 1 {
+		t.Errorf("Unexpected resume %+v", resumed)
+	}
+	if rr := serve(router, "POST", path+"/resume", `{"token": "`+attached.Attachment.Token+`", "ack": 5}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Resume from a future event returned %v", rr.Code)
+	}
+	if rr := serve(router, "POST", path+"/resume", `{"token": "nope"}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("Resume with a bad token returned %v", rr.Code)
+	}
+
+	var replayed service.Replay
+	json.NewDecoder(serveAs(router, "bob", "GET", path+"/events?since=0", "").Body).Decode(&replayed)
+	if len(replayed.Events) != 1 || replayed.State.Version != 1 {
+		t.Errorf("Events returned %+v", replayed)
+	}
+	serveAs(router, "ann", "POST", path+"/chat", `{"text": "gl"}`)
+	var withChat EventsResponse
+	json.NewDecoder(serveAs(router, "bob", "GET", path+"/events?since=1&chat_since=0", "").Body).Decode(&withChat)
+	if withChat.Chat == nil || len(withChat.Chat.Messages) != 1 || withChat.Chat.Messages[0].Text != "gl" {
+		t.Errorf("Events should carry the table's chat: %+v", withChat.Chat)
+	}
+	if rr := serveAs(router, "cat", "GET", path+"/events?since=0&chat_since=0", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Chat sent to a player away from the table: %v", rr.Code)
+	}
+	rr = serve(router, "POST", path+"/resume", `{"token": "`+attached.Attachment.Token+`", "chat_since": 0}`)
+	json.NewDecoder(rr.Body).Decode(&resumed)
+	if resumed.Chat == nil || len(resumed.Chat.Messages) != 1 {
+		t.Errorf("Resume should carry the table's chat: %v %+v", rr.Code, resumed.Chat)
+	}
+	if rr := serve(router, "GET", path+"/events?since=x", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Events with a bad since returned %v", rr.Code)
+	}
+
+	seat := `{"game_type": "highcard", "game_id": "` + state.ID.String() + `", "right": "seat", "seat": 0}`
+	var issued IssueCapabilityResponse
+	json.NewDecoder(serveAs(router, "ann", "POST", "/capabilities", seat).Body).Decode(&issued)
+	rr = serveWithCapability(router, issued.Token, "POST", path+"/attach?player=bob", "")
+	json.NewDecoder(rr.Body).Decode(&attached)
+	if rr.Code != http.StatusCreated || attached.Attachment.Player != "ann" || attached.Attachment.Ack != 1 {
+		t.Errorf("Seat token attached %v: %+v", rr.Code, attached.Attachment)
+	}
+}
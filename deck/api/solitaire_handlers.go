@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/service"
+	"cardGame/deck/solitaire"
+)
+
+const (
+	defaultSolverNodes    = 200000
+	defaultSolverTimeout  = 2 * time.Second
+	defaultWinnableTrials = 25
+	maxSolverNodes        = 2000000
+	maxSolverTimeout      = 10 * time.Second
+)
+
+type SolitaireHandler struct {
+	SolitaireService *service.SolitaireService
+}
+
+type SolveRequest struct {
+	Layout *solitaire.Layout `json:"layout,omitempty"`
+	Seed   *int64            `json:"seed,omitempty"`
+	DeckID *uuid.UUID        `json:"deck_id,omitempty"`
+}
+
+func NewSolitaireHandler(solitaireService *service.SolitaireService) *SolitaireHandler {
+	return &SolitaireHandler{
+		SolitaireService: solitaireService,
+	}
+}
+
+func (h *SolitaireHandler) Deal(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, apikey.Create) {
+		return
+	}
+	variant := solitaire.Variant(mux.Vars(r)["variant"])
+	budget, err := solverBudget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var deal service.SolitaireDeal
+	if winnable, _ := strconv.ParseBool(r.URL.Query().Get("winnable")); winnable {
+		deal, err = h.SolitaireService.WinnableDeal(authenticated(r), keyID(r), variant, budget, defaultWinnableTrials)
+	} else {
+		seed := time.Now().UnixNano()
+		if seedParam := r.URL.Query().Get("seed"); seedParam != "" {
+			if seed, err = strconv.ParseInt(seedParam, 10, 64); err != nil {
+				http.Error(w, "Invalid seed parameter", http.StatusBadRequest)
+				return
+			}
+		}
+		deal, err = h.SolitaireService.Deal(authenticated(r), keyID(r), variant, seed)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deal)
+}
+
+func (h *SolitaireHandler) Solve(w http.ResponseWriter, r *http.Request) {
+	variant := solitaire.Variant(mux.Vars(r)["variant"])
+	budget, err := solverBudget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request SolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var result solitaire.Result
+	switch {
+	case request.Layout != nil:
+		request.Layout.Variant = variant
+		result, err = h.SolitaireService.Solve(*request.Layout, budget)
+	case request.DeckID != nil:
+		if deck, found := h.SolitaireService.Deck(*request.DeckID); found && !mayUse(w, r, deck) {
+			return
+		}
+		result, err = h.SolitaireService.SolveDeck(*request.DeckID, variant, budget)
+	case request.Seed != nil:
+		result, err = h.SolitaireService.SolveSeed(variant, *request.Seed, budget)
+	default:
+		http.Error(w, "One of layout, seed or deck_id is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// solverBudget reads max_nodes and timeout_ms, holding both to the server's
+// limits.
+func solverBudget(r *http.Request) (solitaire.Budget, error) {
+	budget := solitaire.Budget{MaxNodes: defaultSolverNodes, Timeout: defaultSolverTimeout}
+
+	if nodes := r.URL.Query().Get("max_nodes"); nodes != "" {
+		n, err := strconv.Atoi(nodes)
+		if err != nil || n <= 0 {
+			return budget, fmt.Errorf("Invalid max_nodes parameter")
+		}
+		budget.MaxNodes = min(n, maxSolverNodes)
+	}
+	if timeout := r.URL.Query().Get("timeout_ms"); timeout != "" {
+		ms, err := strconv.Atoi(timeout)
+		if err != nil || ms <= 0 {
+			return budget, fmt.Errorf("Invalid timeout_ms parameter")
+		}
+		budget.Timeout = time.Duration(min(ms, int(maxSolverTimeout/time.Millisecond))) * time.Millisecond
+	}
+
+	return budget, nil
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/dao"
+	"cardGame/deck/model"
+	"cardGame/deck/service"
+	"cardGame/deck/solitaire"
+)
+
+func TestSolitaireHandler_Deal(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	handler := NewSolitaireHandler(service.NewSolitaireService(storage))
+
+	t.Run("Seeded Deal", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/solitaire/klondike/deal?seed=5", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"variant": "klondike"})
+
+		rr := httptest.NewRecorder()
+		handler.Deal(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("Deal handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		var response service.SolitaireDeal
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Seed != 5 || len(response.Layout.Tableau) != 7 {
+			t.Errorf("Deal handler returned unexpected deal: seed %v with %v piles", response.Seed, len(response.Layout.Tableau))
+		}
+	})
+
+	t.Run("Owned Deal", func(t *testing.T) {
+		router := mux.NewRouter()
+		router.Use(NewAPIKeyHandler(testKeys, false).Authenticate)
+		router.HandleFunc("/solitaire/{variant}/deal", handler.Deal).Methods("GET")
+
+		_, reader, _ := testKeys.Issue("reader", []string{"draw"})
+		if rr := serveWithAPIKey(router, reader, "GET", "/solitaire/klondike/deal?seed=5", ""); rr.Code != http.StatusForbidden {
+			t.Errorf("Deal handler dealt for a key without the create scope: %v", rr.Code)
+		}
+
+		rr := serveWithAPIKey(router, testGatewayKey, "GET", "/solitaire/klondike/deal?seed=5", "")
+		var response service.SolitaireDeal
+		json.NewDecoder(rr.Body).Decode(&response)
+		if deck, _ := storage.GetDeck(response.DeckID); deck.Key == "" {
+			t.Errorf("Deal handler stored a deck without the caller's key: %+v", deck)
+		}
+	})
+
+	t.Run("Unknown Variant", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/solitaire/spider/deal", nil)
+		req = mux.SetURLVars(req, map[string]string{"variant": "spider"})
+
+		rr := httptest.NewRecorder()
+		handler.Deal(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("Deal handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestSolitaireHandler_Solve(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	handler := NewSolitaireHandler(service.NewSolitaireService(storage))
+
+	req, err := http.NewRequest("POST", "/solitaire/freecell/solve", bytes.NewBufferString(`{"seed": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"variant": "freecell"})
+
+	rr := httptest.NewRecorder()
+	handler.Solve(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Solve handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response solitaire.Result
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != solitaire.StatusSolved || len(response.Moves) == 0 {
+		t.Errorf("Solve handler returned unexpected result: %v", response.Status)
+	}
+
+	private := model.NewDeck(true, "")
+	private.Owner = "ann"
+	storage.SaveDeck(private)
+	req = mux.SetURLVars(httptest.NewRequest("POST", "/solitaire/freecell/solve", bytes.NewBufferString(`{"deck_id": "`+private.ID.String()+`"}`)), map[string]string{"variant": "freecell"})
+	rr = httptest.NewRecorder()
+	handler.Solve(rr, req)
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("Solve handler solved another player's deck: got %v want %v", status, http.StatusForbidden)
+	}
+}
+
+func TestSolverBudget(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/solitaire/klondike/solve?max_nodes=999999999&timeout_ms=9223372036854775807", nil)
+
+	budget, err := solverBudget(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budget.MaxNodes != maxSolverNodes || budget.Timeout != maxSolverTimeout {
+		t.Errorf("solverBudget did not clamp the budget: got %v nodes and %v", budget.MaxNodes, budget.Timeout)
+	}
+}
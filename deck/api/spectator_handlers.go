@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/capability"
+	"cardGame/deck/service"
+	"cardGame/deck/spectate"
+)
+
+type DelayRequest struct {
+	DelaySeconds float64 `json:"delay_seconds"`
+}
+
+// SpectatorHandler serves delayed feeds of games and decks to spectators.
+// Anyone may watch a game, and whoever may read a deck may watch it.
+// Commentators, who also see every hand, need an admin key or a commentate
+// token for the game or deck.
+type SpectatorHandler struct {
+	SpectatorService *service.SpectatorService
+	DeckService      *service.DeckService
+}
+
+func NewSpectatorHandler(spectatorService *service.SpectatorService, deckService *service.DeckService) *SpectatorHandler {
+	return &SpectatorHandler{
+		SpectatorService: spectatorService,
+		DeckService:      deckService,
+	}
+}
+
+// target finds the game or deck in the path and checks the caller may
+// watch it.
+func (h *SpectatorHandler) target(w http.ResponseWriter, r *http.Request) (service.Target, bool) {
+	vars := mux.Vars(r)
+	if vars["gameID"] != "" {
+		id, ok := gameID(w, r)
+		return service.Target{GameType: vars["type"], ID: id}, ok
+	}
+
+	deckID, err := uuid.Parse(vars["deckID"])
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return service.Target{}, false
+	}
+	deck, found := h.DeckService.GetDeck(deckID)
+	if !found {
+		http.Error(w, "Deck not found", http.StatusNotFound)
+		return service.Target{}, false
+	}
+	if _, granted := deckGrant(r, deck.ID, capability.Read); !granted && !mayUse(w, r, deck) {
+		return service.Target{}, false
+	}
+	return service.Target{ID: deckID}, true
+}
+
+// mayWatchAs checks that the caller may watch target as role.
+func mayWatchAs(w http.ResponseWriter, r *http.Request, target service.Target, role spectate.Role) bool {
+	if role != spectate.Commentator {
+		return true
+	}
+	if key, ok := apiKey(r); ok && key.Has(apikey.Admin) {
+		return true
+	}
+	if target.GameType != "" {
+		if claims, ok := gameGrant(r, target.GameType, target.ID); ok && claims.Right == capability.Commentate {
+			return true
+		}
+	} else if _, ok := deckGrant(r, target.ID, capability.Commentate); ok {
+		return true
+	}
+	http.Error(w, "Commentators need an admin key or a commentate token", http.StatusForbidden)
+	return false
+}
+
+func subscriptionID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["subscriptionID"])
+	if err != nil {
+		http.Error(w, "Invalid subscription ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// Subscribe starts watching the game or deck in the role query parameter,
+// spectator unless it says commentator.
+func (h *SpectatorHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	role, err := spectate.ParseRole(r.URL.Query().Get("role"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !mayWatchAs(w, r, target, role) {
+		return
+	}
+	subscription, err := h.SpectatorService.Subscribe(target, role)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+// Feed returns the frames after since that are past the broadcast delay.
+// A commentator must show their right to each time.
+func (h *SpectatorHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	id, ok := subscriptionID(w, r)
+	if !ok {
+		return
+	}
+	since := 0
+	if param := r.URL.Query().Get("since"); param != "" {
+		var err error
+		if since, err = strconv.Atoi(param); err != nil || since < 0 {
+			http.Error(w, "Invalid since parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	page, err := h.SpectatorService.Feed(target, id, since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !mayWatchAs(w, r, target, page.Subscription.Role) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *SpectatorHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	id, ok := subscriptionID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.SpectatorService.Unsubscribe(target, id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetDelay sets the broadcast delay of the game or deck. Only admin keys
+// may, since a short delay lets a spectator help a player.
+func (h *SpectatorHandler) SetDelay(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	target, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	var request DelayRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	delay := time.Duration(request.DelaySeconds * float64(time.Second))
+	if err := h.SpectatorService.SetDelay(target, delay); err != nil {
+		status := http.StatusBadRequest
+		if err == service.ErrGameNotFound {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DelayRequest{DelaySeconds: delay.Seconds()})
+}
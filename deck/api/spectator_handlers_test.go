@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/capability"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/service"
+)
+
+func newSpectatorRouter() (*mux.Router, *service.APIKeyService) {
+	storage := dao.NewDeckStorage()
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	gameService := service.NewGameService(storage, registry)
+	deckService := service.NewDeckService(storage)
+	apiKeyService := service.NewAPIKeyService()
+	apiKeyService.Import("admin", testAdminKey)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService, true)
+	capabilityHandler := NewCapabilityHandler(service.NewCapabilityService(capability.NewKey()), gameService)
+	spectators := service.NewSpectatorService(gameService, deckService)
+	spectators.Delay = 0
+	handler := NewSpectatorHandler(spectators, deckService)
+	deckHandler := NewDeckHandler(deckService, storage)
+	gameHandler := NewGameHandler(gameService)
+
+	router := mux.NewRouter()
+	router.Use(capabilityHandler.Authenticate, apiKeyHandler.Authenticate)
+	router.HandleFunc("/deck", deckHandler.CreateDeck).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/draw", deckHandler.DrawCards).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/spectators", handler.Subscribe).Methods("POST")
+	router.HandleFunc("/deck/{deckID}/spectators/{subscriptionID}", handler.Feed).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/spectators/{subscriptionID}", handler.Unsubscribe).Methods("DELETE")
+	router.HandleFunc("/games/{type}", gameHandler.CreateGame).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}", gameHandler.PostAction).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/spectators", handler.Subscribe).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/spectators/{subscriptionID}", handler.Feed).Methods("GET")
+	router.HandleFunc("/games/{type}/{gameID}/delay", handler.SetDelay).Methods("PUT")
+	router.HandleFunc("/capabilities", capabilityHandler.Issue).Methods("POST")
+	return router, apiKeyService
+}
+
+func subscribe(t *testing.T, rr *http.Response, code int) service.Subscription {
+	t.Helper()
+	var subscription service.Subscription
+	json.NewDecoder(rr.Body).Decode(&subscription)
+	if rr.StatusCode != code {
+		t.Fatalf("Subscribe returned %v, want %v", rr.StatusCode, code)
+	}
+	return subscription
+}
+
+func TestSpectatorHandler_Game(t *testing.T) {
+	router, keys := newSpectatorRouter()
+	_, team, _ := keys.Issue("team", []string{"create", "draw"})
+	var state service.GameState
+	json.NewDecoder(serveWithAPIKey(router, team, "POST", "/games/highcard", `{"players": ["ann", "bob"]}`).Body).Decode(&state)
+	path := "/games/highcard/" + state.ID.String()
+	serveWithAPIKeyAs(router, team, "ann", "POST", path, `{"action": {"type": "draw"}}`)
+
+	watcher := subscribe(t, serveWithAPIKey(router, team, "POST", path+"/spectators", "").Result(), http.StatusCreated)
+	if rr := serveWithAPIKey(router, team, "POST", path+"/spectators?role=commentator", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Commentator without the right subscribed: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, team, "POST", path+"/spectators?role=player", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Unknown role returned %v", rr.Code)
+	}
+
+	var page service.FeedPage
+	json.NewDecoder(serveWithAPIKey(router, team, "GET", path+"/spectators/"+watcher.ID.String()+"?since=0", "").Body).Decode(&page)
+	if len(page.Frames) != 1 || page.Frames[0].Revealed != nil || page.Subscription.Role != "spectator" {
+		t.Errorf("Unexpected spectator feed %+v", page)
+	}
+
+	request := `{"game_type": "highcard", "game_id": "` + state.ID.String() + `", "right": "commentate"}`
+	if rr := serveWithAPIKey(router, team, "POST", "/capabilities", request); rr.Code != http.StatusForbidden {
+		t.Errorf("Non-admin key issued a commentate token: %v", rr.Code)
+	}
+	token := issueCapability(t, router, testAdminKey, request)
+	commentator := subscribe(t, serveWithCapability(router, token, "POST", path+"/spectators?role=commentator", "").Result(), http.StatusCreated)
+	serveWithAPIKeyAs(router, team, "bob", "POST", path, `{"action": {"type": "draw"}}`)
+
+	feed := path + "/spectators/" + commentator.ID.String()
+	json.NewDecoder(serveWithCapability(router, token, "GET", feed+"?since=1", "").Body).Decode(&page)
+	if len(page.Frames) != 1 || page.Frames[0].Revealed == nil || page.Frames[0].Event.Player != "bob" {
+		t.Errorf("Unexpected commentator feed %+v", page)
+	}
+	if rr := serveWithAPIKey(router, team, "GET", feed, ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Commentator feed read without the right: %v", rr.Code)
+	}
+
+	if rr := serveWithAPIKey(router, team, "PUT", path+"/delay", `{"delay_seconds": 0}`); rr.Code != http.StatusForbidden {
+		t.Errorf("Non-admin key set the delay: %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, testAdminKey, "PUT", path+"/delay", `{"delay_seconds": 7200}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Delay past the maximum returned %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, testAdminKey, "PUT", path+"/delay", `{"delay_seconds": 60}`); rr.Code != http.StatusOK {
+		t.Errorf("SetDelay returned %v", rr.Code)
+	}
+	json.NewDecoder(serveWithAPIKey(router, team, "GET", path+"/spectators/"+watcher.ID.String(), "").Body).Decode(&page)
+	if len(page.Frames) != 0 || page.DelaySeconds != 60 {
+		t.Errorf("Frames inside the delay were shown: %+v", page)
+	}
+}
+
+func TestSpectatorHandler_Deck(t *testing.T) {
+	router, keys := newSpectatorRouter()
+	_, teamA, _ := keys.Issue("team-a", []string{"create", "draw"})
+	_, teamB, _ := keys.Issue("team-b", []string{"create", "draw"})
+	var created CreateDeckResponse
+	json.NewDecoder(serveWithAPIKey(router, teamA, "GET", "/deck", "").Body).Decode(&created)
+	deck := "/deck/" + created.DeckID.String()
+
+	if rr := serveWithAPIKey(router, teamB, "POST", deck+"/spectators", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("Another team watched the deck: %v", rr.Code)
+	}
+	watcher := subscribe(t, serveWithAPIKey(router, teamA, "POST", deck+"/spectators", "").Result(), http.StatusCreated)
+	serveWithAPIKey(router, teamA, "GET", deck+"/draw?count=2", "")
+
+	feed := deck + "/spectators/" + watcher.ID.String()
+	var page service.FeedPage
+	json.NewDecoder(serveWithAPIKey(router, teamA, "GET", feed+"?since=1", "").Body).Decode(&page)
+	if len(page.Frames) != 1 || page.Frames[0].Seq != 2 {
+		t.Errorf("Unexpected deck feed %+v", page)
+	}
+	if rr := serveWithAPIKey(router, teamA, "DELETE", feed, ""); rr.Code != http.StatusNoContent {
+		t.Errorf("Unsubscribe returned %v", rr.Code)
+	}
+	if rr := serveWithAPIKey(router, teamA, "GET", feed, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Ended subscription returned %v", rr.Code)
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/apikey"
+	"cardGame/deck/service"
+	"cardGame/deck/tournament"
+)
+
+type MatchResultRequest struct {
+	Winner string `json:"winner"`
+	Draw   bool   `json:"draw"`
+}
+
+type TableResultRequest struct {
+	Chips map[string]int `json:"chips"`
+}
+
+type TournamentHandler struct {
+	TournamentService *service.TournamentService
+}
+
+func NewTournamentHandler(tournamentService *service.TournamentService) *TournamentHandler {
+	return &TournamentHandler{
+		TournamentService: tournamentService,
+	}
+}
+
+// CreateTournament makes the requesting player the organizer. An admin key
+// may create a tournament without one.
+func (h *TournamentHandler) CreateTournament(w http.ResponseWriter, r *http.Request) {
+	var config service.TournamentConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	config.Organizer = viewer(r)
+	if key, ok := apiKey(r); config.Organizer == "" && (!ok || !key.Has(apikey.Admin)) {
+		http.Error(w, "Login required", http.StatusUnauthorized)
+		return
+	}
+
+	state, err := h.TournamentService.Create(config)
+	writeTournament(w, state, err)
+}
+
+func tournamentID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["tournamentID"])
+	if err != nil {
+		http.Error(w, "Invalid tournament ID", http.StatusBadRequest)
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+// reporter is the player reporting a result, or "" with an admin key.
+func reporter(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if key, ok := apiKey(r); ok && key.Has(apikey.Admin) {
+		return "", true
+	}
+	return requirePlayer(w, r)
+}
+
+func (h *TournamentHandler) GetTournament(w http.ResponseWriter, r *http.Request) {
+	id, ok := tournamentID(w, r)
+	if !ok {
+		return
+	}
+	state, err := h.TournamentService.State(id)
+	writeTournament(w, state, err)
+}
+
+func (h *TournamentHandler) ReportMatch(w http.ResponseWriter, r *http.Request) {
+	id, ok := tournamentID(w, r)
+	if !ok {
+		return
+	}
+	match, err := strconv.Atoi(mux.Vars(r)["matchID"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+	var request MatchResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Winner == "" && !request.Draw || request.Winner != "" && request.Draw {
+		http.Error(w, "Give either a winner or a draw", http.StatusBadRequest)
+		return
+	}
+	player, ok := reporter(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := h.TournamentService.Report(id, player, match, request.Winner)
+	writeTournament(w, state, err)
+}
+
+func (h *TournamentHandler) ReportTable(w http.ResponseWriter, r *http.Request) {
+	id, ok := tournamentID(w, r)
+	if !ok {
+		return
+	}
+	table, err := strconv.Atoi(mux.Vars(r)["table"])
+	if err != nil {
+		http.Error(w, "Invalid table number", http.StatusBadRequest)
+		return
+	}
+	var request TableResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	player, ok := reporter(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := h.TournamentService.ReportTable(id, player, table, request.Chips)
+	writeTournament(w, state, err)
+}
+
+func writeTournament(w http.ResponseWriter, state service.TournamentState, err error) {
+	switch err {
+	case nil:
+	case service.ErrTournamentNotFound, tournament.ErrMatchNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case service.ErrNotOrganizer:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case service.ErrMatchHosted:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
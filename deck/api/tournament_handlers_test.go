@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/service"
+)
+
+func newTournamentRouter() *mux.Router {
+	storage := dao.NewDeckStorage()
+	games := service.NewGameService(storage, game.NewRegistry())
+	handler := NewTournamentHandler(service.NewTournamentService(games, service.NewDeckService(storage)))
+
+	router := mux.NewRouter()
+	router.Use(NewAPIKeyHandler(testKeys, false).Authenticate)
+	router.HandleFunc("/tournaments", handler.CreateTournament).Methods("POST")
+	router.HandleFunc("/tournaments/{tournamentID}", handler.GetTournament).Methods("GET")
+	router.HandleFunc("/tournaments/{tournamentID}/matches/{matchID}/result", handler.ReportMatch).Methods("POST")
+	router.HandleFunc("/tournaments/{tournamentID}/tables/{table}/result", handler.ReportTable).Methods("POST")
+	return router
+}
+
+func TestTournamentHandler_Bracket(t *testing.T) {
+	router := newTournamentRouter()
+
+	if rr := serve(router, "POST", "/tournaments", `{"format": "double_elimination", "players": ["ann", "bob"]}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("CreateTournament handler accepted an anonymous organizer: %v", rr.Code)
+	}
+	rr := serveAs(router, "dan", "POST", "/tournaments", `{"format": "double_elimination", "players": ["ann", "bob"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("CreateTournament handler returned wrong status code: got %v want %v: %v", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var state service.TournamentState
+	json.NewDecoder(rr.Body).Decode(&state)
+	path := "/tournaments/" + state.ID.String()
+
+	if rr := serve(router, "POST", path+"/matches/1/result", `{"winner": "ann"}`); rr.Code != http.StatusUnauthorized {
+		t.Errorf("ReportMatch accepted an anonymous result: %v", rr.Code)
+	}
+	if rr := serveAs(router, "ann", "POST", path+"/matches/1/result", `{"winner": "ann"}`); rr.Code != http.StatusForbidden {
+		t.Errorf("ReportMatch accepted a player's own result: %v", rr.Code)
+	}
+	if rr := serveAs(router, "dan", "POST", path+"/matches/1/result", `{}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("ReportMatch without a winner returned %v", rr.Code)
+	}
+	if rr := serveAs(router, "dan", "POST", path+"/matches/1/result", `{"draw": true}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("ReportMatch accepted a draw in a bracket: %v", rr.Code)
+	}
+	if rr := serveAs(router, "dan", "POST", path+"/matches/99/result", `{"winner": "ann"}`); rr.Code != http.StatusNotFound {
+		t.Errorf("ReportMatch on a missing match returned %v", rr.Code)
+	}
+	for _, match := range []string{"1", "2"} {
+		if rr := serveWithAPIKey(router, testAdminKey, "POST", path+"/matches/"+match+"/result", `{"winner": "ann"}`); rr.Code != http.StatusOK {
+			t.Fatalf("ReportMatch returned %v: %v", rr.Code, rr.Body.String())
+		}
+	}
+
+	rr = serve(router, "GET", path, "")
+	json.NewDecoder(rr.Body).Decode(&state)
+	if !state.Complete || state.Standings[0].Player != "ann" {
+		t.Errorf("Ann should have won: %+v", state)
+	}
+}
+
+func TestTournamentHandler_Poker(t *testing.T) {
+	router := newTournamentRouter()
+
+	rr := serveAs(router, "dan", "POST", "/tournaments", `{"format": "poker", "players": ["ann", "bob", "cat"], "stack": 500}`)
+	var state service.TournamentState
+	json.NewDecoder(rr.Body).Decode(&state)
+	if len(state.Tables) != 1 || state.Chips["ann"] != 500 {
+		t.Fatalf("Unexpected poker tournament: %v", rr.Body.String())
+	}
+
+	path := "/tournaments/" + state.ID.String() + "/tables/1/result"
+	if rr := serveAs(router, "bob", "POST", path, `{"chips": {"ann": 0, "bob": 1000}}`); rr.Code != http.StatusForbidden {
+		t.Errorf("ReportTable accepted a player's own stacks: %v", rr.Code)
+	}
+	if rr := serveAs(router, "dan", "POST", path, `{"chips": {"ann": 0, "bob": 900}}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("ReportTable accepted stacks that lost chips: %v", rr.Code)
+	}
+	if rr := serveAs(router, "dan", "POST", path, `{"chips": {"ann": 0, "bob": 1000}}`); !strings.Contains(rr.Body.String(), `"place":3`) {
+		t.Errorf("ReportTable did not place ann: %v", rr.Body.String())
+	}
+	if rr := serveAs(router, "dan", "POST", path, `{"chips": {"zed": 0}}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("ReportTable accepted an unknown player: %v", rr.Code)
+	}
+	if rr := serve(router, "GET", "/tournaments/"+state.ID.String()[:8], ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("GetTournament with a bad ID returned %v", rr.Code)
+	}
+}
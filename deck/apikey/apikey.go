@@ -0,0 +1,174 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Scope is a right a key carries.
+type Scope string
+
+const (
+	// Create lets a key create decks and games.
+	Create Scope = "create"
+	// Draw lets a key draw from and deal its decks.
+	Draw Scope = "draw"
+	// Admin grants every scope, access to every deck and managing keys.
+	Admin Scope = "admin"
+)
+
+var Scopes = []Scope{Create, Draw, Admin}
+
+// Prefix starts every key the server issues.
+const Prefix = "cgk_"
+
+// MinLength is the shortest key accepted from configuration.
+const MinLength = 32
+
+var (
+	ErrInvalidKey  = errors.New("Invalid or revoked API key")
+	ErrKeyNotFound = errors.New("API key not found")
+)
+
+// Key is an issued API key. The key itself is never stored; ID names it.
+type Key struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name"`
+	Scopes  []Scope    `json:"scopes"`
+	Created time.Time  `json:"created"`
+	Revoked *time.Time `json:"revoked,omitempty"`
+}
+
+// Has reports whether the key carries scope. Admin keys carry them all.
+func (k Key) Has(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == Admin {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScopes checks scope names.
+func ParseScopes(names []string) ([]Scope, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("A key needs at least one scope")
+	}
+	var scopes []Scope
+	for _, name := range names {
+		found := false
+		for _, scope := range Scopes {
+			if Scope(name) == scope {
+				scopes = append(scopes, scope)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown scope %q", name)
+		}
+	}
+	return scopes, nil
+}
+
+func random(n int) string {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// hash is how keys are stored. Keys are long and random, so unlike
+// passwords they need no salt or stretching.
+func hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// Keys stores keys by the hash of the key. It is not safe for concurrent
+// use.
+type Keys struct {
+	keys map[string]*Key
+	byID map[string]*Key
+}
+
+func NewKeys() *Keys {
+	return &Keys{keys: make(map[string]*Key), byID: make(map[string]*Key)}
+}
+
+// Issue creates a key and returns it with the key itself, which is shown
+// only this once.
+func (k *Keys) Issue(name string, scopes []Scope, now time.Time) (Key, string) {
+	raw := Prefix + random(32)
+	return k.add(name, raw, scopes, now), raw
+}
+
+// Import adds a key chosen elsewhere, such as an admin key from the
+// environment.
+func (k *Keys) Import(name, raw string, scopes []Scope, now time.Time) (Key, error) {
+	if len(raw) < MinLength {
+		return Key{}, fmt.Errorf("API keys need at least %v characters", MinLength)
+	}
+	if _, ok := k.keys[hash(raw)]; ok {
+		return Key{}, fmt.Errorf("API key already added")
+	}
+	return k.add(name, raw, scopes, now), nil
+}
+
+func (k *Keys) add(name, raw string, scopes []Scope, now time.Time) Key {
+	key := &Key{ID: random(6), Name: name, Scopes: scopes, Created: now}
+	k.keys[hash(raw)] = key
+	k.byID[key.ID] = key
+	return *key
+}
+
+// Check returns the live key for raw.
+func (k *Keys) Check(raw string) (Key, error) {
+	key, ok := k.keys[hash(raw)]
+	if !ok || key.Revoked != nil {
+		return Key{}, ErrInvalidKey
+	}
+	return *key, nil
+}
+
+// Get returns a key by ID, revoked or not.
+func (k *Keys) Get(id string) (Key, error) {
+	key, ok := k.byID[id]
+	if !ok {
+		return Key{}, ErrKeyNotFound
+	}
+	return *key, nil
+}
+
+// Revoke stops a key working. Revoked keys stay listed.
+func (k *Keys) Revoke(id string, now time.Time) (Key, error) {
+	key, ok := k.byID[id]
+	if !ok {
+		return Key{}, ErrKeyNotFound
+	}
+	if key.Revoked == nil {
+		key.Revoked = &now
+	}
+	return *key, nil
+}
+
+// List returns every key, oldest first.
+func (k *Keys) List() []Key {
+	keys := make([]Key, 0, len(k.byID))
+	for _, key := range k.byID {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].Created.Equal(keys[j].Created) {
+			return keys[i].Created.Before(keys[j].Created)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
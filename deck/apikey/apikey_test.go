@@ -0,0 +1,78 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"create", "draw"})
+	if err != nil || len(scopes) != 2 {
+		t.Errorf("ParseScopes failed: %v %v", scopes, err)
+	}
+	if _, err := ParseScopes([]string{"create", "delete"}); err == nil {
+		t.Errorf("Unknown scope accepted")
+	}
+	if _, err := ParseScopes(nil); err == nil {
+		t.Errorf("No scopes accepted")
+	}
+}
+
+func TestKeyHas(t *testing.T) {
+	key := Key{Scopes: []Scope{Draw}}
+	if !key.Has(Draw) || key.Has(Create) || key.Has(Admin) {
+		t.Errorf("Unexpected scopes for %v", key.Scopes)
+	}
+	admin := Key{Scopes: []Scope{Admin}}
+	if !admin.Has(Create) || !admin.Has(Draw) {
+		t.Errorf("Admin keys should carry every scope")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := NewKeys()
+	now := time.Unix(0, 0)
+	key, raw := keys.Issue("staging", []Scope{Create, Draw}, now)
+	if !strings.HasPrefix(raw, Prefix) || key.ID == "" {
+		t.Fatalf("Unexpected key %+v %v", key, raw)
+	}
+	for stored := range keys.keys {
+		if strings.Contains(raw, stored) || stored == raw {
+			t.Errorf("Key stored in the clear")
+		}
+	}
+	if checked, err := keys.Check(raw); err != nil || checked.ID != key.ID {
+		t.Errorf("Check failed: %+v %v", checked, err)
+	}
+	if _, err := keys.Check(raw + "x"); err != ErrInvalidKey {
+		t.Errorf("Wrong key accepted")
+	}
+
+	if _, err := keys.Import("admin", "too short", []Scope{Admin}, now); err == nil {
+		t.Errorf("Short key imported")
+	}
+	admin, err := keys.Import("admin", strings.Repeat("k", MinLength), []Scope{Admin}, now.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Import("again", strings.Repeat("k", MinLength), []Scope{Admin}, now); err == nil {
+		t.Errorf("Same key imported twice")
+	}
+	if list := keys.List(); len(list) != 2 || list[0].ID != key.ID || list[1].ID != admin.ID {
+		t.Errorf("Unexpected list %+v", list)
+	}
+
+	if _, err := keys.Revoke(key.ID, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Check(raw); err != ErrInvalidKey {
+		t.Errorf("Revoked key accepted")
+	}
+	if revoked, _ := keys.Get(key.ID); revoked.Revoked == nil {
+		t.Errorf("Revocation not recorded")
+	}
+	if _, err := keys.Revoke("nope", now); err != ErrKeyNotFound {
+		t.Errorf("Revoked an unknown key")
+	}
+}
@@ -0,0 +1,74 @@
+package baccarat
+
+import (
+	"fmt"
+)
+
+type BetType string
+
+const (
+	BetPlayer     BetType = "player"
+	BetBanker     BetType = "banker"
+	BetTie        BetType = "tie"
+	BetPlayerPair BetType = "player_pair"
+	BetBankerPair BetType = "banker_pair"
+)
+
+func ParseBetType(name string) (BetType, error) {
+	switch bet := BetType(name); bet {
+	case BetPlayer, BetBanker, BetTie, BetPlayerPair, BetBankerPair:
+		return bet, nil
+	}
+	return "", fmt.Errorf("Invalid bet %q", name)
+}
+
+type Bet struct {
+	Type   BetType `json:"type"`
+	Amount int     `json:"amount"`
+}
+
+// Rules hold the table minimum and the payouts. Banker wins pay even money
+// less CommissionPercent, rounded down.
+type Rules struct {
+	MinBet            int
+	CommissionPercent int
+	TiePays           int
+	PairPays          int
+}
+
+var DefaultRules = Rules{MinBet: 10, CommissionPercent: 5, TiePays: 8, PairPays: 11}
+
+// Stakes are the bet sizes offered as legal actions, in multiples of the
+// table minimum. Any amount from the minimum up may still be bet.
+var Stakes = []int{1, 5, 10, 25}
+
+// Settle returns the player's net result for a bet: the winnings, zero for a
+// push or minus the stake for a loss. Player and banker bets push on a tie.
+func (r Rules) Settle(bet Bet, round Round) int {
+	switch bet.Type {
+	case BetPlayer, BetBanker:
+		if round.Outcome == Tie {
+			return 0
+		}
+		if string(round.Outcome) != string(bet.Type) {
+			return -bet.Amount
+		}
+		if bet.Type == BetBanker {
+			return bet.Amount * (100 - r.CommissionPercent) / 100
+		}
+		return bet.Amount
+	case BetTie:
+		if round.Outcome == Tie {
+			return bet.Amount * r.TiePays
+		}
+	case BetPlayerPair:
+		if round.PlayerPair {
+			return bet.Amount * r.PairPays
+		}
+	case BetBankerPair:
+		if round.BankerPair {
+			return bet.Amount * r.PairPays
+		}
+	}
+	return -bet.Amount
+}
@@ -0,0 +1,34 @@
+package baccarat
+
+import "testing"
+
+func TestSettle(t *testing.T) {
+	banker := Round{Outcome: BankerWins, PlayerPair: true}
+	tie := Round{Outcome: Tie}
+
+	tests := []struct {
+		bet   Bet
+		round Round
+		want  int
+	}{
+		{Bet{BetBanker, 100}, banker, 95},
+		{Bet{BetBanker, 10}, banker, 9},
+		{Bet{BetPlayer, 100}, banker, -100},
+		{Bet{BetPlayer, 100}, Round{Outcome: PlayerWins}, 100},
+		{Bet{BetPlayer, 100}, tie, 0},
+		{Bet{BetBanker, 100}, tie, 0},
+		{Bet{BetTie, 10}, tie, 80},
+		{Bet{BetTie, 10}, banker, -10},
+		{Bet{BetPlayerPair, 10}, banker, 110},
+		{Bet{BetBankerPair, 10}, banker, -10},
+	}
+	for _, test := range tests {
+		if got := DefaultRules.Settle(test.bet, test.round); got != test.want {
+			t.Errorf("Settle(%v, %v): got %v want %v", test.bet, test.round.Outcome, got, test.want)
+		}
+	}
+
+	if _, err := ParseBetType("dragon"); err == nil {
+		t.Errorf("ParseBetType accepted an unknown bet")
+	}
+}
@@ -0,0 +1,216 @@
+package baccarat
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+var betTypes = []BetType{BetPlayer, BetBanker, BetTie, BetPlayerPair, BetBankerPair}
+
+// Game hosts a punto banco table for the game registry. Players place bets
+// and any of them calls the coup once bets are down; the game ends with the
+// round in which the cut card comes out. Hosted tables are played for chips
+// the house banks.
+type Game struct {
+	rules   Rules
+	players []string
+	shoe    *Shoe
+	road    *Road
+	bets    map[string][]Bet
+	net     map[string]int
+	last    *Round
+	bank    game.Bank
+	pot     string
+}
+
+type View struct {
+	Bets      map[string][]Bet `json:"bets"`
+	Last      *Round           `json:"last,omitempty"`
+	Road      *Road            `json:"road"`
+	Net       map[string]int   `json:"net"`
+	Remaining int              `json:"remaining"`
+	Burned    []model.Card     `json:"burned"`
+}
+
+func NewGame() game.Game {
+	return &Game{rules: DefaultRules}
+}
+
+// NewDeck builds the shoe, DefaultDecks decks unless the "decks" option says
+// otherwise.
+func (g *Game) NewDeck(config game.Config) model.Deck {
+	decks, err := strconv.Atoi(config.Options["decks"])
+	if err != nil || decks < 1 {
+		decks = DefaultDecks
+	}
+	return NewShoeDeck(decks, rand.New(rand.NewSource(time.Now().UnixNano())))
+}
+
+// SetBank plays the table for chips: each bet is staked in pot as it is
+// placed, and the pot is settled after every coup, with the house covering
+// the winnings and keeping the losing stakes.
+func (g *Game) SetBank(bank game.Bank, pot string) {
+	g.bank, g.pot = bank, pot
+}
+
+func (g *Game) Setup(deck model.Deck, config game.Config) error {
+	if len(config.Players) == 0 {
+		return fmt.Errorf("Baccarat needs at least 1 player")
+	}
+	shoe, err := NewShoe(deck)
+	if err != nil {
+		return err
+	}
+	if min, err := strconv.Atoi(config.Options["min_bet"]); err == nil && min > 0 {
+		g.rules.MinBet = min
+	}
+
+	g.players = config.Players
+	g.shoe = shoe
+	g.road = NewRoad()
+	g.bets = make(map[string][]Bet)
+	g.net = make(map[string]int)
+	for _, player := range g.players {
+		g.net[player] = 0
+	}
+	return nil
+}
+
+func (g *Game) Players() []string {
+	return g.players
+}
+
+// LegalActions lists a bet of each of the Stakes on each bet type.
+func (g *Game) LegalActions(player string) []game.Action {
+	if _, seated := g.net[player]; !seated || g.Terminal() {
+		return nil
+	}
+
+	actions := make([]game.Action, 0, len(betTypes)*len(Stakes)+2)
+	for _, bet := range betTypes {
+		for _, stake := range Stakes {
+			actions = append(actions, game.Action{Type: "bet", Value: string(bet), Amount: stake * g.rules.MinBet})
+		}
+	}
+	if len(g.bets[player]) > 0 && g.bank == nil {
+		actions = append(actions, game.Action{Type: "clear"})
+	}
+	if len(g.bets) > 0 {
+		actions = append(actions, game.Action{Type: "deal"})
+	}
+	return actions
+}
+
+func (g *Game) Apply(player string, action game.Action) error {
+	if _, seated := g.net[player]; !seated {
+		return fmt.Errorf("%v is not at the table", player)
+	}
+	if g.Terminal() {
+		return fmt.Errorf("The shoe is finished")
+	}
+
+	switch action.Type {
+	case "bet":
+		bet, err := ParseBetType(action.Value)
+		if err != nil {
+			return err
+		}
+		if action.Amount < g.rules.MinBet {
+			return fmt.Errorf("The table minimum is %v", g.rules.MinBet)
+		}
+		if g.bank != nil {
+			if err := g.bank.Reserve("", g.pot, player, int64(action.Amount)); err != nil {
+				return err
+			}
+		}
+		g.bets[player] = append(g.bets[player], Bet{Type: bet, Amount: action.Amount})
+	case "clear":
+		if g.bank != nil {
+			return fmt.Errorf("Bets are staked once placed and cannot be cleared")
+		}
+		delete(g.bets, player)
+	case "deal":
+		if len(g.bets) == 0 {
+			return fmt.Errorf("No bets on the table")
+		}
+		return g.deal()
+	default:
+		return fmt.Errorf("Illegal action %q for %v", action.Type, player)
+	}
+	return nil
+}
+
+// deal plays a coup from a copy of the shoe, which replaces the shoe only
+// once the bank has settled, so a failed settlement leaves the game as it
+// was.
+func (g *Game) deal() error {
+	shoe := *g.shoe
+	round, err := shoe.Deal()
+	if err != nil {
+		return err
+	}
+	results := make(map[string]int, len(g.bets))
+	payouts := make(map[string]int64, len(g.bets))
+	var staked, paid int64
+	for player, bets := range g.bets {
+		for _, bet := range bets {
+			result := g.rules.Settle(bet, round)
+			results[player] += result
+			payouts[player] += int64(bet.Amount + result)
+			staked += int64(bet.Amount)
+			paid += int64(bet.Amount + result)
+		}
+	}
+	if g.bank != nil {
+		if err := g.bank.SettleHouse("", g.pot, payouts, staked-paid); err != nil {
+			return err
+		}
+	}
+	*g.shoe = shoe
+	for player, result := range results {
+		g.net[player] += result
+	}
+	g.road.Add(round)
+	g.last = &round
+	g.bets = make(map[string][]Bet)
+	return nil
+}
+
+func (g *Game) Terminal() bool {
+	return g.shoe.Finished()
+}
+
+// Scores are each player's net winnings over the shoe.
+func (g *Game) Scores() map[string]int {
+	scores := make(map[string]int, len(g.net))
+	for player, net := range g.net {
+		scores[player] = net
+	}
+	return scores
+}
+
+// View is the same for everyone: every card in baccarat is dealt face up. It
+// copies the bets, results and road rather than share them with the game.
+func (g *Game) View(player string) interface{} {
+	bets := make(map[string][]Bet, len(g.bets))
+	for p, b := range g.bets {
+		bets[p] = append([]Bet{}, b...)
+	}
+	net := make(map[string]int, len(g.net))
+	for p, n := range g.net {
+		net[p] = n
+	}
+	return View{
+		Bets:      bets,
+		Last:      g.last,
+		Road:      g.road.Copy(),
+		Net:       net,
+		Remaining: g.shoe.Remaining(),
+		Burned:    g.shoe.Burned,
+	}
+}
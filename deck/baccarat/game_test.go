@@ -0,0 +1,149 @@
+package baccarat
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"cardGame/deck/game"
+)
+
+func TestGame(t *testing.T) {
+	g := NewGame()
+	deck := g.(game.DeckProvider).NewDeck(game.Config{Options: map[string]string{"decks": "1"}})
+	if len(deck.Cards) != 52 {
+		t.Fatalf("NewDeck ignored the decks option: %v cards", len(deck.Cards))
+	}
+	if err := g.Setup(NewShoeDeck(DefaultDecks, rand.New(rand.NewSource(4))), game.Config{Players: []string{"ann", "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Apply("ann", game.Action{Type: "deal"}); err == nil {
+		t.Errorf("Apply dealt with no bets on the table")
+	}
+	if err := g.Apply("ann", game.Action{Type: "bet", Value: "banker", Amount: -5}); err == nil {
+		t.Errorf("Apply accepted a negative bet")
+	}
+	if err := g.Apply("cid", game.Action{Type: "bet", Value: "banker", Amount: 5}); err == nil {
+		t.Errorf("Apply accepted a bet from someone not at the table")
+	}
+
+	total := 0
+	for !g.Terminal() {
+		if err := g.Apply("ann", game.Action{Type: "bet", Value: "banker", Amount: 100}); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Apply("bob", game.Action{Type: "bet", Value: "player", Amount: 100}); err != nil {
+			t.Fatal(err)
+		}
+		if !game.IsLegal(g, "bob", game.Action{Type: "deal"}) {
+			t.Fatalf("Deal should be legal with bets down")
+		}
+		if err := g.Apply("bob", game.Action{Type: "deal"}); err != nil {
+			t.Fatal(err)
+		}
+
+		last := g.View("").(View).Last
+		switch last.Outcome {
+		case BankerWins:
+			total += 95 - 100
+		case PlayerWins:
+			total += 100 - 100
+		}
+	}
+
+	scores := g.Scores()
+	if scores["ann"]+scores["bob"] != total {
+		t.Errorf("Scores %v don't add up to %v", scores, total)
+	}
+	if len(g.LegalActions("ann")) != 0 {
+		t.Errorf("Actions offered after the shoe finished")
+	}
+}
+
+type move struct {
+	player string
+	action game.Action
+}
+
+func TestGame_LegalActionsApply(t *testing.T) {
+	table := func(moves []move) game.Game {
+		g := NewGame()
+		if err := g.Setup(NewShoeDeck(1, rand.New(rand.NewSource(7))), game.Config{Players: []string{"ann", "bob"}, Options: map[string]string{"min_bet": "25"}}); err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range moves {
+			if err := g.Apply(m.player, m.action); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return g
+	}
+	if err := table(nil).Apply("ann", game.Action{Type: "bet", Value: "tie", Amount: 24}); err == nil {
+		t.Errorf("Apply accepted a bet under the table minimum")
+	}
+
+	bet := game.Action{Type: "bet", Value: "player", Amount: 25}
+	histories := [][]move{
+		nil,
+		{{"bob", bet}},
+		{{"bob", bet}, {"ann", bet}},
+		{{"bob", bet}, {"ann", bet}, {"ann", game.Action{Type: "deal"}}},
+	}
+	for _, history := range histories {
+		for _, player := range []string{"ann", "bob"} {
+			for _, action := range table(history).LegalActions(player) {
+				if err := table(history).Apply(player, action); err != nil {
+					t.Errorf("After %v, Apply rejected %v's legal action %+v: %v", history, player, action, err)
+				}
+			}
+		}
+	}
+}
+
+// failingBank stakes every bet and fails to settle until it is told to.
+type failingBank struct {
+	fail bool
+}
+
+func (b *failingBank) Reserve(key, pot, player string, amount int64) error { return nil }
+
+func (b *failingBank) Settle(key, pot string, payouts map[string]int64, rake int64) error {
+	return nil
+}
+
+func (b *failingBank) SettleHouse(key, pot string, payouts map[string]int64, house int64) error {
+	if b.fail {
+		return errors.New("Settlement failed")
+	}
+	return nil
+}
+
+func TestGame_FailedSettlement(t *testing.T) {
+	g := NewGame()
+	bank := &failingBank{fail: true}
+	g.(game.Banked).SetBank(bank, "table")
+	if err := g.Setup(NewShoeDeck(1, rand.New(rand.NewSource(7))), game.Config{Players: []string{"ann"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply("ann", game.Action{Type: "bet", Value: "banker", Amount: 10}); err != nil {
+		t.Fatal(err)
+	}
+	before := g.View("").(View)
+
+	if err := g.Apply("ann", game.Action{Type: "deal"}); err == nil {
+		t.Fatalf("Dealt a coup the bank could not settle")
+	}
+	after := g.View("").(View)
+	if after.Remaining != before.Remaining || after.Last != nil || len(after.Bets["ann"]) != 1 {
+		t.Errorf("A failed settlement changed the table: %+v", after)
+	}
+
+	bank.fail = false
+	if err := g.Apply("ann", game.Action{Type: "deal"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(before.Bets["ann"]) != 1 || len(before.Road.BeadPlate) != 0 {
+		t.Errorf("An earlier view changed with the game: %+v", before)
+	}
+}
@@ -0,0 +1,87 @@
+package baccarat
+
+// RoadRows is the height of the bead plate and big road grids.
+const RoadRows = 6
+
+// Cell is one mark on a scoreboard grid.
+type Cell struct {
+	Column     int     `json:"column"`
+	Row        int     `json:"row"`
+	Outcome    Outcome `json:"outcome"`
+	Ties       int     `json:"ties,omitempty"`
+	PlayerPair bool    `json:"player_pair,omitempty"`
+	BankerPair bool    `json:"banker_pair,omitempty"`
+}
+
+// Road keeps the scoreboards of one shoe. The bead plate marks every coup,
+// filling columns top to bottom. The big road marks only player and banker
+// wins, a column per streak; ties are counted on the previous mark and a
+// streak longer than the grid runs to the right along its last free row.
+type Road struct {
+	BeadPlate []Cell `json:"bead_plate"`
+	BigRoad   []Cell `json:"big_road"`
+
+	streakStart int
+	leadingTies int
+	occupied    map[[2]int]bool
+}
+
+func NewRoad() *Road {
+	return &Road{BeadPlate: []Cell{}, BigRoad: []Cell{}, occupied: make(map[[2]int]bool)}
+}
+
+// Copy returns the scoreboards as they stand, sharing nothing with r.
+func (r *Road) Copy() *Road {
+	occupied := make(map[[2]int]bool, len(r.occupied))
+	for cell := range r.occupied {
+		occupied[cell] = true
+	}
+	return &Road{
+		BeadPlate:   append([]Cell{}, r.BeadPlate...),
+		BigRoad:     append([]Cell{}, r.BigRoad...),
+		streakStart: r.streakStart,
+		leadingTies: r.leadingTies,
+		occupied:    occupied,
+	}
+}
+
+func (r *Road) Add(round Round) {
+	n := len(r.BeadPlate)
+	r.BeadPlate = append(r.BeadPlate, Cell{
+		Column:     n / RoadRows,
+		Row:        n % RoadRows,
+		Outcome:    round.Outcome,
+		PlayerPair: round.PlayerPair,
+		BankerPair: round.BankerPair,
+	})
+
+	if round.Outcome == Tie {
+		if len(r.BigRoad) == 0 {
+			r.leadingTies++
+		} else {
+			r.BigRoad[len(r.BigRoad)-1].Ties++
+		}
+		return
+	}
+
+	cell := Cell{Outcome: round.Outcome, PlayerPair: round.PlayerPair, BankerPair: round.BankerPair}
+	switch {
+	case len(r.BigRoad) == 0:
+		cell.Ties = r.leadingTies
+	case r.BigRoad[len(r.BigRoad)-1].Outcome != round.Outcome:
+		r.streakStart++
+		for r.occupied[[2]int{r.streakStart, 0}] {
+			r.streakStart++
+		}
+		cell.Column = r.streakStart
+	default:
+		last := r.BigRoad[len(r.BigRoad)-1]
+		cell.Column, cell.Row = last.Column, last.Row+1
+		if last.Column > r.streakStart || cell.Row >= RoadRows || r.occupied[[2]int{cell.Column, cell.Row}] {
+			cell.Column, cell.Row = last.Column+1, last.Row
+		}
+	}
+
+	r.occupied[[2]int{cell.Column, cell.Row}] = true
+	r.BigRoad = append(r.BigRoad, cell)
+}
@@ -0,0 +1,42 @@
+package baccarat
+
+import "testing"
+
+func TestRoad(t *testing.T) {
+	road := NewRoad()
+	outcomes := []Outcome{Tie, BankerWins, BankerWins, Tie, PlayerWins}
+	for i := 0; i < 7; i++ {
+		outcomes = append(outcomes, BankerWins)
+	}
+	outcomes = append(outcomes, PlayerWins, PlayerWins)
+	for _, outcome := range outcomes {
+		road.Add(Round{Outcome: outcome})
+	}
+
+	if len(road.BeadPlate) != len(outcomes) || road.BeadPlate[7].Column != 1 || road.BeadPlate[7].Row != 1 {
+		t.Errorf("Unexpected bead plate: %+v", road.BeadPlate)
+	}
+
+	want := []Cell{
+		{Column: 0, Row: 0, Outcome: BankerWins, Ties: 1},
+		{Column: 0, Row: 1, Outcome: BankerWins, Ties: 1},
+		{Column: 1, Row: 0, Outcome: PlayerWins},
+		{Column: 2, Row: 0, Outcome: BankerWins},
+		{Column: 2, Row: 1, Outcome: BankerWins},
+		{Column: 2, Row: 2, Outcome: BankerWins},
+		{Column: 2, Row: 3, Outcome: BankerWins},
+		{Column: 2, Row: 4, Outcome: BankerWins},
+		{Column: 2, Row: 5, Outcome: BankerWins},
+		{Column: 3, Row: 5, Outcome: BankerWins},
+		{Column: 3, Row: 0, Outcome: PlayerWins},
+		{Column: 3, Row: 1, Outcome: PlayerWins},
+	}
+	if len(road.BigRoad) != len(want) {
+		t.Fatalf("Unexpected big road: %+v", road.BigRoad)
+	}
+	for i := range want {
+		if road.BigRoad[i] != want[i] {
+			t.Errorf("Big road cell %v: got %+v want %+v", i, road.BigRoad[i], want[i])
+		}
+	}
+}
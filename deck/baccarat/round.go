@@ -0,0 +1,109 @@
+package baccarat
+
+import (
+	"fmt"
+
+	"cardGame/deck/model"
+)
+
+type Outcome string
+
+const (
+	PlayerWins Outcome = "player"
+	BankerWins Outcome = "banker"
+	Tie        Outcome = "tie"
+)
+
+// Value is a card's baccarat value: aces count one, tens and pictures zero.
+func Value(c model.Card) int {
+	rank := c.Rank()
+	switch {
+	case rank == 14:
+		return 1
+	case rank >= 10:
+		return 0
+	}
+	return rank
+}
+
+// Total is the last digit of the sum of the card values.
+func Total(hand []model.Card) int {
+	total := 0
+	for _, c := range hand {
+		total += Value(c)
+	}
+	return total % 10
+}
+
+type Round struct {
+	Player      []model.Card `json:"player"`
+	Banker      []model.Card `json:"banker"`
+	PlayerTotal int          `json:"player_total"`
+	BankerTotal int          `json:"banker_total"`
+	Outcome     Outcome      `json:"outcome"`
+	Natural     bool         `json:"natural"`
+	PlayerPair  bool         `json:"player_pair"`
+	BankerPair  bool         `json:"banker_pair"`
+}
+
+// Deal plays one coup. Cards go player, banker, player, banker, then the third
+// cards follow the tableau.
+func (s *Shoe) Deal() (Round, error) {
+	if s.deck.Remaining < 6 {
+		return Round{}, fmt.Errorf("Not enough cards left in the shoe")
+	}
+
+	var round Round
+	for i := 0; i < 2; i++ {
+		round.Player = append(round.Player, s.draw())
+		round.Banker = append(round.Banker, s.draw())
+	}
+	player, banker := Total(round.Player), Total(round.Banker)
+	round.Natural = player >= 8 || banker >= 8
+
+	if !round.Natural {
+		third := -1
+		if player <= 5 {
+			c := s.draw()
+			round.Player = append(round.Player, c)
+			third = Value(c)
+		}
+		if BankerDraws(banker, third) {
+			round.Banker = append(round.Banker, s.draw())
+		}
+	}
+
+	round.PlayerTotal, round.BankerTotal = Total(round.Player), Total(round.Banker)
+	switch {
+	case round.PlayerTotal > round.BankerTotal:
+		round.Outcome = PlayerWins
+	case round.BankerTotal > round.PlayerTotal:
+		round.Outcome = BankerWins
+	default:
+		round.Outcome = Tie
+	}
+	round.PlayerPair = round.Player[0].Value == round.Player[1].Value
+	round.BankerPair = round.Banker[0].Value == round.Banker[1].Value
+	return round, nil
+}
+
+// BankerDraws applies the banker's side of the tableau. playerThird is the
+// value of the player's third card, or -1 when the player stood.
+func BankerDraws(banker, playerThird int) bool {
+	if playerThird < 0 {
+		return banker <= 5
+	}
+	switch banker {
+	case 0, 1, 2:
+		return true
+	case 3:
+		return playerThird != 8
+	case 4:
+		return playerThird >= 2 && playerThird <= 7
+	case 5:
+		return playerThird >= 4 && playerThird <= 7
+	case 6:
+		return playerThird == 6 || playerThird == 7
+	}
+	return false
+}
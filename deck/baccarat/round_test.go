@@ -0,0 +1,81 @@
+package baccarat
+
+import (
+	"strings"
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+// testShoe burns AS and 2C, then deals codes, with kings behind them.
+func testShoe(t *testing.T, codes ...string) *Shoe {
+	t.Helper()
+	cards := append([]string{"AS", "2C"}, codes...)
+	for i := 0; i < 40; i++ {
+		cards = append(cards, "KD")
+	}
+	shoe, err := NewShoe(model.NewDeck(false, strings.Join(cards, ",")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return shoe
+}
+
+func TestDeal(t *testing.T) {
+	tests := []struct {
+		name    string
+		codes   []string
+		outcome Outcome
+		player  int
+		banker  int
+		cards   [2]int
+	}{
+		{"Natural", []string{"4H", "KD", "5H", "KC"}, PlayerWins, 9, 0, [2]int{2, 2}},
+		{"Banker stands on 3 against an 8", []string{"2C", "1S", "3C", "3S", "8D"}, Tie, 3, 3, [2]int{3, 2}},
+		{"Player stands on 6", []string{"3C", "2H", "3D", "3H", "4S"}, BankerWins, 6, 9, [2]int{2, 3}},
+	}
+
+	for _, test := range tests {
+		shoe := testShoe(t, test.codes...)
+		round, err := shoe.Deal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if round.Outcome != test.outcome || round.PlayerTotal != test.player || round.BankerTotal != test.banker ||
+			len(round.Player) != test.cards[0] || len(round.Banker) != test.cards[1] {
+			t.Errorf("%v: unexpected round %+v", test.name, round)
+		}
+	}
+
+	round, _ := testShoe(t, "4H", "KD", "5H", "KC").Deal()
+	if !round.Natural || !round.BankerPair || round.PlayerPair {
+		t.Errorf("Unexpected natural or pairs: %+v", round)
+	}
+	round, _ = testShoe(t, "3C", "2H", "3D", "3H", "4S").Deal()
+	if !round.PlayerPair || round.Natural {
+		t.Errorf("Unexpected natural or pairs: %+v", round)
+	}
+}
+
+func TestBankerDraws(t *testing.T) {
+	tests := []struct {
+		banker, third int
+		want          bool
+	}{
+		{5, -1, true}, {6, -1, false}, {2, 9, true}, {3, 8, false}, {3, 9, true},
+		{4, 1, false}, {4, 2, true}, {5, 3, false}, {5, 4, true}, {6, 5, false},
+		{6, 6, true}, {7, 0, false},
+	}
+	for _, test := range tests {
+		if got := BankerDraws(test.banker, test.third); got != test.want {
+			t.Errorf("BankerDraws(%v, %v): got %v want %v", test.banker, test.third, got, test.want)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	hand := model.NewDeck(false, "AS,9H,1D,KC").Cards
+	if Value(hand[0]) != 1 || Value(hand[1]) != 9 || Value(hand[2]) != 0 || Value(hand[3]) != 0 || Total(hand) != 0 {
+		t.Errorf("Unexpected values for %v", hand)
+	}
+}
@@ -0,0 +1,71 @@
+package baccarat
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/google/uuid"
+
+	"cardGame/deck/model"
+)
+
+const (
+	DefaultDecks = 8
+	// CutCardReserve is how many cards stay behind the cut card. The round in
+	// which the cut card comes out is the last one of the shoe.
+	CutCardReserve = 16
+)
+
+// NewShoeDeck shuffles decks standard decks together into one model.Deck.
+func NewShoeDeck(decks int, rng *rand.Rand) model.Deck {
+	var cards []model.Card
+	for i := 0; i < decks; i++ {
+		cards = append(cards, model.NewDeck(false, "").Cards...)
+	}
+	rng.Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+
+	id, _ := uuid.NewUUID()
+	return model.Deck{ID: id, Shuffled: true, Remaining: len(cards), Cards: cards}
+}
+
+// Shoe deals punto banco rounds from a deck.
+type Shoe struct {
+	deck   model.Deck
+	cut    int
+	Burned []model.Card
+}
+
+// NewShoe burns the first card and as many more as its value, counting tens
+// and pictures as ten, then places the cut card CutCardReserve cards from the
+// end.
+func NewShoe(deck model.Deck) (*Shoe, error) {
+	if len(deck.Cards) < CutCardReserve+20 {
+		return nil, fmt.Errorf("A shoe needs at least %v cards", CutCardReserve+20)
+	}
+
+	shoe := &Shoe{deck: deck, cut: CutCardReserve}
+	first, _ := shoe.deck.DrawCards(1)
+	burn := Value(first[0])
+	if burn == 0 {
+		burn = 10
+	}
+	more, _ := shoe.deck.DrawCards(burn)
+	shoe.Burned = append(first, more...)
+	return shoe, nil
+}
+
+func (s *Shoe) Remaining() int {
+	return s.deck.Remaining
+}
+
+// Finished reports whether the cut card has come out.
+func (s *Shoe) Finished() bool {
+	return s.deck.Remaining <= s.cut
+}
+
+func (s *Shoe) draw() model.Card {
+	cards, _ := s.deck.DrawCards(1)
+	return cards[0]
+}
@@ -0,0 +1,42 @@
+package baccarat
+
+import (
+	"math/rand"
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+func TestNewShoe(t *testing.T) {
+	deck := NewShoeDeck(DefaultDecks, rand.New(rand.NewSource(1)))
+	if len(deck.Cards) != 416 || deck.Remaining != 416 {
+		t.Fatalf("Unexpected shoe size %v", len(deck.Cards))
+	}
+
+	shoe, err := NewShoe(deck)
+	if err != nil {
+		t.Fatal(err)
+	}
+	burn := Value(shoe.Burned[0])
+	if burn == 0 {
+		burn = 10
+	}
+	if len(shoe.Burned) != burn+1 || shoe.Remaining() != 416-burn-1 {
+		t.Errorf("Burned %v cards after a %v", len(shoe.Burned), shoe.Burned[0].Code)
+	}
+
+	coups := 0
+	for !shoe.Finished() {
+		if _, err := shoe.Deal(); err != nil {
+			t.Fatal(err)
+		}
+		coups++
+	}
+	if coups < 60 || shoe.Remaining() > CutCardReserve {
+		t.Errorf("Shoe finished after %v coups with %v cards left", coups, shoe.Remaining())
+	}
+
+	if _, err := NewShoe(model.NewDeck(false, "AS,KH")); err == nil {
+		t.Errorf("NewShoe accepted a tiny deck")
+	}
+}
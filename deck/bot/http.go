@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"cardGame/deck/game"
+)
+
+// DefaultTimeout is how long an HTTP bot has to answer each turn.
+const DefaultTimeout = 5 * time.Second
+
+// HTTPPlayer is a bot behind an HTTP callback. Each turn is POSTed to URL
+// as JSON, and the bot answers with {"action": {...}}.
+type HTTPPlayer struct {
+	URL    string
+	Client *http.Client
+}
+
+type httpAnswer struct {
+	Action *game.Action `json:"action"`
+}
+
+// NewHTTPPlayer checks that callback is an http or https URL.
+func NewHTTPPlayer(callback string) (*HTTPPlayer, error) {
+	u, err := url.Parse(callback)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("Callback must be an http or https URL")
+	}
+	return &HTTPPlayer{URL: callback, Client: &http.Client{Timeout: DefaultTimeout}}, nil
+}
+
+func (p *HTTPPlayer) Act(turn Turn) (game.Action, error) {
+	body, err := json.Marshal(turn)
+	if err != nil {
+		return game.Action{}, err
+	}
+	resp, err := p.Client.Post(p.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return game.Action{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return game.Action{}, fmt.Errorf("Bot answered %v", resp.Status)
+	}
+	var answer httpAnswer
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil || answer.Action == nil {
+		return game.Action{}, fmt.Errorf("Bot answered without an action")
+	}
+	return *answer.Action, nil
+}
@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cardGame/deck/game"
+)
+
+func TestHTTPPlayer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var turn Turn
+		if err := json.NewDecoder(r.Body).Decode(&turn); err != nil || turn.Player != "ann" {
+			http.Error(w, "bad turn", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"action": turn.Actions[len(turn.Actions)-1]})
+	}))
+	defer server.Close()
+
+	player, err := NewHTTPPlayer(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	turn := Turn{Player: "ann", Actions: []game.Action{{Type: "draw"}, {Type: "stand"}}}
+	if action, err := player.Act(turn); err != nil || action.Type != "stand" {
+		t.Errorf("Unexpected answer %+v: %v", action, err)
+	}
+	if _, err := player.Act(Turn{Player: "bob"}); err == nil {
+		t.Errorf("A failed callback should be an error")
+	}
+
+	if _, err := NewHTTPPlayer("ftp://example.com/bot"); err == nil {
+		t.Errorf("Accepted a callback that is not http")
+	}
+}
@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"fmt"
+	"math/rand"
+
+	"cardGame/deck/game"
+)
+
+// Turn is what a bot is sent when it is to act: the game as its player
+// sees it and the actions open to them.
+type Turn struct {
+	GameType string        `json:"game_type"`
+	GameID   string        `json:"game_id"`
+	Player   string        `json:"player"`
+	Version  int           `json:"version"`
+	View     interface{}   `json:"view"`
+	Actions  []game.Action `json:"actions"`
+	// Sample is set for bots run in the server when the game is a
+	// game.Determinizer. It returns a determinization of the game for
+	// Player, or nil once the game has moved on from this turn.
+	Sample func(rng *rand.Rand) game.Game `json:"-"`
+}
+
+// Player is a bot. Act chooses one of the turn's actions; anything else,
+// or an error, forfeits the game. Players are called from many games at
+// once, so they must be safe for concurrent use.
+type Player interface {
+	Act(turn Turn) (game.Action, error)
+}
+
+// PlayerFunc lets a function be used as a Player.
+type PlayerFunc func(turn Turn) (game.Action, error)
+
+func (f PlayerFunc) Act(turn Turn) (game.Action, error) {
+	return f(turn)
+}
+
+// First always takes the first legal action.
+var First = PlayerFunc(func(turn Turn) (game.Action, error) {
+	if len(turn.Actions) == 0 {
+		return game.Action{}, fmt.Errorf("No legal actions")
+	}
+	return turn.Actions[0], nil
+})
+
+// Random takes a legal action at random.
+var Random = PlayerFunc(func(turn Turn) (game.Action, error) {
+	if len(turn.Actions) == 0 {
+		return game.Action{}, fmt.Errorf("No legal actions")
+	}
+	return turn.Actions[rand.Intn(len(turn.Actions))], nil
+})
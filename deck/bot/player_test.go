@@ -0,0 +1,22 @@
+package bot
+
+import (
+	"testing"
+
+	"cardGame/deck/game"
+)
+
+func TestBuiltInPlayers(t *testing.T) {
+	turn := Turn{Actions: []game.Action{{Type: "draw"}, {Type: "stand"}}}
+	if action, err := First.Act(turn); err != nil || action.Type != "draw" {
+		t.Errorf("First took %+v: %v", action, err)
+	}
+	for i := 0; i < 20; i++ {
+		if action, err := Random.Act(turn); err != nil || (action.Type != "draw" && action.Type != "stand") {
+			t.Fatalf("Random took %+v: %v", action, err)
+		}
+	}
+	if _, err := Random.Act(Turn{}); err == nil {
+		t.Errorf("Random acted without legal actions")
+	}
+}
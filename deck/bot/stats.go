@@ -0,0 +1,36 @@
+package bot
+
+import "math"
+
+// Z95 is the normal quantile for a 95% confidence interval.
+const Z95 = 1.959964
+
+// Record is how one bot fared over a number of games. Errors counts the
+// games it forfeited by failing to act, which are also losses.
+type Record struct {
+	Games  int `json:"games"`
+	Wins   int `json:"wins"`
+	Draws  int `json:"draws"`
+	Losses int `json:"losses"`
+	Errors int `json:"errors"`
+}
+
+// WinRate returns the share of games won with its Wilson score interval at
+// normal quantile z.
+func (r Record) WinRate(z float64) (rate, low, high float64) {
+	if r.Games == 0 {
+		return 0, 0, 1
+	}
+	return Wilson(r.Wins, r.Games, z)
+}
+
+// Wilson returns the proportion of successes in n trials and its Wilson
+// score interval, which stays inside [0, 1] and behaves for small n.
+func Wilson(successes, n int, z float64) (p, low, high float64) {
+	total := float64(n)
+	p = float64(successes) / total
+	z2 := z * z
+	center := (p + z2/(2*total)) / (1 + z2/total)
+	margin := z / (1 + z2/total) * math.Sqrt(p*(1-p)/total+z2/(4*total*total))
+	return p, math.Max(0, center-margin), math.Min(1, center+margin)
+}
@@ -0,0 +1,19 @@
+package bot
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilson(t *testing.T) {
+	p, low, high := Wilson(50, 100, Z95)
+	if p != 0.5 || math.Abs(low-0.4038) > 0.001 || math.Abs(high-0.5962) > 0.001 {
+		t.Errorf("Unexpected interval %v [%v, %v]", p, low, high)
+	}
+	if _, low, high := Wilson(0, 10, Z95); low != 0 || high <= 0 || high >= 0.5 {
+		t.Errorf("Unexpected interval for no wins [%v, %v]", low, high)
+	}
+	if rate, low, high := (Record{}).WinRate(Z95); rate != 0 || low != 0 || high != 1 {
+		t.Errorf("No games should tell nothing: %v [%v, %v]", rate, low, high)
+	}
+}
@@ -0,0 +1,206 @@
+package bridge
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type CallKind int
+
+const (
+	Pass CallKind = iota
+	Bid
+	Double
+	Redouble
+)
+
+// Call is one call of the auction. Level and Strain are only set for bids.
+type Call struct {
+	Kind   CallKind
+	Level  int
+	Strain Suit
+}
+
+func (c Call) String() string {
+	switch c.Kind {
+	case Bid:
+		return strconv.Itoa(c.Level) + c.Strain.String()
+	case Double:
+		return "X"
+	case Redouble:
+		return "XX"
+	}
+	return "Pass"
+}
+
+// rank orders bids from 1C (0) to 7NT (34).
+func (c Call) rank() int {
+	return (c.Level-1)*5 + int(c.Strain)
+}
+
+// ParseCall reads calls such as "1NT", "4S", "Pass", "X" or "XX".
+func ParseCall(text string) (Call, error) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	switch text {
+	case "P", "PASS":
+		return Call{Kind: Pass}, nil
+	case "X", "D", "DBL", "DOUBLE":
+		return Call{Kind: Double}, nil
+	case "XX", "R", "RDBL", "REDOUBLE":
+		return Call{Kind: Redouble}, nil
+	}
+
+	if len(text) < 2 {
+		return Call{}, fmt.Errorf("Invalid call %q", text)
+	}
+	level, err := strconv.Atoi(text[:1])
+	if err != nil || level < 1 || level > 7 {
+		return Call{}, fmt.Errorf("Invalid call %q", text)
+	}
+	strain, err := ParseSuit(text[1:])
+	if err != nil {
+		return Call{}, fmt.Errorf("Invalid call %q", text)
+	}
+	return Call{Kind: Bid, Level: level, Strain: strain}, nil
+}
+
+func (c Call) MarshalText() ([]byte, error) { return []byte(c.String()), nil }
+
+func (c *Call) UnmarshalText(text []byte) error {
+	call, err := ParseCall(string(text))
+	*c = call
+	return err
+}
+
+// Auction records the calls made so far, starting with the dealer.
+type Auction struct {
+	Dealer        Seat          `json:"dealer"`
+	Vulnerability Vulnerability `json:"vulnerability"`
+	Calls         []Call        `json:"calls"`
+}
+
+func NewAuction(dealer Seat, vulnerability Vulnerability) *Auction {
+	return &Auction{Dealer: dealer, Vulnerability: vulnerability, Calls: []Call{}}
+}
+
+// Turn returns the seat due to call next.
+func (a *Auction) Turn() Seat {
+	return Seat((int(a.Dealer) + len(a.Calls)) % 4)
+}
+
+// Complete reports whether the auction has ended, either after four passes
+// or after three passes following a bid.
+func (a *Auction) Complete() bool {
+	n := len(a.Calls)
+	if n < 4 {
+		return false
+	}
+	for _, call := range a.Calls[n-3:] {
+		if call.Kind != Pass {
+			return false
+		}
+	}
+	return true
+}
+
+// lastAction returns the index of the last call that was not a pass, or -1.
+func (a *Auction) lastAction() int {
+	for i := len(a.Calls) - 1; i >= 0; i-- {
+		if a.Calls[i].Kind != Pass {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *Auction) lastBid() int {
+	for i := len(a.Calls) - 1; i >= 0; i-- {
+		if a.Calls[i].Kind == Bid {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *Auction) seatOf(index int) Seat {
+	return Seat((int(a.Dealer) + index) % 4)
+}
+
+// Check returns an error when call is not legal for the player whose turn it
+// is.
+func (a *Auction) Check(call Call) error {
+	if a.Complete() {
+		return fmt.Errorf("Auction is complete")
+	}
+
+	turn := a.Turn()
+	last := a.lastAction()
+	opponents := last >= 0 && a.seatOf(last).NorthSouth() != turn.NorthSouth()
+
+	switch call.Kind {
+	case Pass:
+		return nil
+	case Bid:
+		if call.Level < 1 || call.Level > 7 || call.Strain < Clubs || call.Strain > NoTrump {
+			return fmt.Errorf("Invalid bid %v", call)
+		}
+		if bid := a.lastBid(); bid >= 0 && call.rank() <= a.Calls[bid].rank() {
+			return fmt.Errorf("%v is not higher than %v", call, a.Calls[bid])
+		}
+		return nil
+	case Double:
+		if !opponents || a.Calls[last].Kind != Bid {
+			return fmt.Errorf("Double is only allowed over an opponent's bid")
+		}
+		return nil
+	case Redouble:
+		if !opponents || a.Calls[last].Kind != Double {
+			return fmt.Errorf("Redouble is only allowed over an opponent's double")
+		}
+		return nil
+	}
+	return fmt.Errorf("Invalid call %v", call)
+}
+
+// Call makes the next call if it is legal.
+func (a *Auction) Call(call Call) error {
+	if err := a.Check(call); err != nil {
+		return err
+	}
+	a.Calls = append(a.Calls, call)
+	return nil
+}
+
+// Contract returns the final contract of a complete auction. A passed out
+// auction returns the zero Contract. The declarer is the player of the
+// declaring side who first named the final strain.
+func (a *Auction) Contract() (Contract, error) {
+	if !a.Complete() {
+		return Contract{}, fmt.Errorf("Auction is not complete")
+	}
+
+	last := a.lastBid()
+	if last < 0 {
+		return Contract{}, nil
+	}
+
+	final := a.Calls[last]
+	contract := Contract{Level: final.Level, Strain: final.Strain}
+	side := a.seatOf(last).NorthSouth()
+	for i, call := range a.Calls[:last+1] {
+		if call.Kind == Bid && call.Strain == final.Strain && a.seatOf(i).NorthSouth() == side {
+			contract.Declarer = a.seatOf(i)
+			break
+		}
+	}
+	for _, call := range a.Calls[last+1:] {
+		switch call.Kind {
+		case Double:
+			contract.Doubled = 1
+		case Redouble:
+			contract.Doubled = 2
+		}
+	}
+	return contract, nil
+}
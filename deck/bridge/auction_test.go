@@ -0,0 +1,88 @@
+package bridge
+
+import (
+	"strings"
+	"testing"
+)
+
+func playAuction(t *testing.T, dealer Seat, calls string) *Auction {
+	t.Helper()
+	auction := NewAuction(dealer, VulnerableNone)
+	for _, text := range strings.Fields(calls) {
+		call, err := ParseCall(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := auction.Call(call); err != nil {
+			t.Fatalf("Call %v rejected: %v", text, err)
+		}
+	}
+	return auction
+}
+
+func TestAuctionContract(t *testing.T) {
+	tests := []struct {
+		dealer Seat
+		calls  string
+		want   string
+	}{
+		{North, "1NT P 3NT P P P", "3NT-N"},
+		{East, "P 1S 2H 2S P 4S P P P", "4S-S"},
+		{North, "1H P 1S P 2S P 4H P P P", "4H-N"},
+		{West, "1D X XX P P P", "1DXX-W"},
+		{South, "1C 1H X 2H 3C X P P P", "3CX-S"},
+		{North, "P P P P", "Pass"},
+	}
+
+	for _, test := range tests {
+		auction := playAuction(t, test.dealer, test.calls)
+		if !auction.Complete() {
+			t.Fatalf("Auction %q should be complete", test.calls)
+		}
+		contract, err := auction.Contract()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contract.String() != test.want {
+			t.Errorf("Auction %q: got %v want %v", test.calls, contract, test.want)
+		}
+	}
+}
+
+func TestAuctionCheck(t *testing.T) {
+	tests := []struct {
+		calls string
+		next  string
+	}{
+		{"1NT", "1S"},
+		{"1NT", "1NT"},
+		{"1NT P", "X"},
+		{"1NT", "XX"},
+		{"1NT X", "X"},
+		{"P", "XX"},
+		{"1C P P P", "1D"},
+		{"", "8C"},
+	}
+
+	for _, test := range tests {
+		auction := playAuction(t, North, test.calls)
+		call, err := ParseCall(test.next)
+		if err == nil {
+			err = auction.Check(call)
+		}
+		if err == nil {
+			t.Errorf("%v after %q should be illegal", test.next, test.calls)
+		}
+	}
+
+	auction := playAuction(t, North, "1NT P P")
+	if auction.Turn() != West || auction.Complete() {
+		t.Errorf("Unexpected auction state: turn %v, complete %v", auction.Turn(), auction.Complete())
+	}
+	if _, err := auction.Contract(); err == nil {
+		t.Errorf("Contract accepted an incomplete auction")
+	}
+	if err := auction.Call(Call{Kind: Double}); err != nil {
+		t.Errorf("Balancing double rejected: %v", err)
+	}
+}
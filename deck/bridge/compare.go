@@ -0,0 +1,69 @@
+package bridge
+
+import (
+	"github.com/google/uuid"
+)
+
+// impScale holds the lowest score difference worth each IMP from 1 to 24.
+var impScale = []int{
+	20, 50, 90, 130, 170, 220, 270, 320, 370, 430, 500, 600,
+	750, 900, 1100, 1300, 1500, 1750, 2000, 2250, 2500, 3000, 3500, 4000,
+}
+
+// IMPs converts a score difference to International Match Points, keeping
+// its sign.
+func IMPs(diff int) int {
+	sign := 1
+	if diff < 0 {
+		sign, diff = -1, -diff
+	}
+	imps := 0
+	for imps < len(impScale) && diff >= impScale[imps] {
+		imps++
+	}
+	return sign * imps
+}
+
+// BoardResult is the outcome of one board at one table. Score, Matchpoints
+// and IMPs are from North-South's point of view.
+type BoardResult struct {
+	TableID     uuid.UUID `json:"table_id"`
+	Contract    Contract  `json:"contract"`
+	Tricks      int       `json:"tricks"`
+	Score       int       `json:"score"`
+	Matchpoints float64   `json:"matchpoints"`
+	IMPs        float64   `json:"imps"`
+}
+
+// NewBoardResult scores a played contract for North-South.
+func NewBoardResult(tableID uuid.UUID, contract Contract, tricks int, vulnerability Vulnerability) BoardResult {
+	score := contract.Score(tricks, vulnerability.Vulnerable(contract.Declarer))
+	if !contract.Declarer.NorthSouth() {
+		score = -score
+	}
+	return BoardResult{TableID: tableID, Contract: contract, Tricks: tricks, Score: score}
+}
+
+// CompareResults sets the matchpoints and cross-IMPs of every result of the
+// same board. A pair gets one matchpoint for each table it beat and half for
+// each tie; cross-IMPs are averaged over the other tables.
+func CompareResults(results []BoardResult) {
+	for i := range results {
+		results[i].Matchpoints, results[i].IMPs = 0, 0
+		for j := range results {
+			if i == j {
+				continue
+			}
+			switch {
+			case results[i].Score > results[j].Score:
+				results[i].Matchpoints++
+			case results[i].Score == results[j].Score:
+				results[i].Matchpoints += 0.5
+			}
+			results[i].IMPs += float64(IMPs(results[i].Score - results[j].Score))
+		}
+		if len(results) > 1 {
+			results[i].IMPs /= float64(len(results) - 1)
+		}
+	}
+}
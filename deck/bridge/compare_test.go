@@ -0,0 +1,41 @@
+package bridge
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestIMPs(t *testing.T) {
+	tests := map[int]int{0: 0, 10: 0, 20: 1, 50: 2, -110: -3, 420: 9, 430: 10, 620: 12, 1430: 16, -5000: -24}
+	for diff, want := range tests {
+		if got := IMPs(diff); got != want {
+			t.Errorf("IMPs(%v): got %v want %v", diff, got, want)
+		}
+	}
+}
+
+func TestCompareResults(t *testing.T) {
+	fourSpades, _ := ParseContract("4S-N")
+	threeNT, _ := ParseContract("3NT-S")
+	twoHearts, _ := ParseContract("2HX-W")
+
+	results := []BoardResult{
+		NewBoardResult(uuid.New(), fourSpades, 10, VulnerableNone),
+		NewBoardResult(uuid.New(), threeNT, 10, VulnerableNone),
+		NewBoardResult(uuid.New(), fourSpades, 10, VulnerableNone),
+		NewBoardResult(uuid.New(), twoHearts, 8, VulnerableNone),
+	}
+	CompareResults(results)
+
+	wantScores := []int{420, 430, 420, -470}
+	wantMatchpoints := []float64{1.5, 3, 1.5, 0}
+	for i, result := range results {
+		if result.Score != wantScores[i] || result.Matchpoints != wantMatchpoints[i] {
+			t.Errorf("Result %v: got score %v and %v matchpoints, want %v and %v", i, result.Score, result.Matchpoints, wantScores[i], wantMatchpoints[i])
+		}
+	}
+	if results[1].IMPs != 14.0/3 {
+		t.Errorf("Unexpected cross-IMPs for 3NT: %v", results[1].IMPs)
+	}
+}
@@ -0,0 +1,427 @@
+package bridge
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Constraint decides whether a deal qualifies. Constraints are evaluated on
+// precomputed hand statistics so the dealer can reject deals cheaply.
+type Constraint interface {
+	Match(stats *DealStats) bool
+}
+
+type handStats struct {
+	hcp     int
+	lengths [4]int
+	cards   uint64
+}
+
+type DealStats struct {
+	hands [4]handStats
+}
+
+func NewDealStats(deal Deal) *DealStats {
+	stats := &DealStats{}
+	for seat, hand := range deal.Hands {
+		for _, c := range hand {
+			stats.add(Seat(seat), cardIndex(c))
+		}
+	}
+	return stats
+}
+
+func (d *DealStats) add(seat Seat, index int) {
+	h := &d.hands[seat]
+	h.cards |= 1 << uint(index)
+	h.lengths[index/13]++
+	if rank := index%13 + 2; rank > 10 {
+		h.hcp += rank - 10
+	}
+}
+
+type Comparison struct {
+	Min int
+	Max int
+}
+
+func (c Comparison) holds(value int) bool { return value >= c.Min && value <= c.Max }
+
+type HCP struct {
+	Seat  Seat
+	Range Comparison
+}
+
+func (c HCP) Match(d *DealStats) bool { return c.Range.holds(d.hands[c.Seat].hcp) }
+
+type SuitLength struct {
+	Seat  Seat
+	Suit  Suit
+	Range Comparison
+}
+
+func (c SuitLength) Match(d *DealStats) bool {
+	return c.Range.holds(d.hands[c.Seat].lengths[c.Suit])
+}
+
+// Shape matches suit lengths. With AnyOrder the pattern is compared as a
+// sorted shape such as 4-4-3-2, otherwise it is spades, hearts, diamonds,
+// clubs; -1 in a position matches any length.
+type Shape struct {
+	Seat     Seat
+	Pattern  [4]int
+	AnyOrder bool
+}
+
+func (c Shape) Match(d *DealStats) bool {
+	l := d.hands[c.Seat].lengths
+	lengths := []int{l[Spades], l[Hearts], l[Diamonds], l[Clubs]}
+	pattern := c.Pattern[:]
+	if c.AnyOrder {
+		sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+	}
+	for i, want := range pattern {
+		if want >= 0 && lengths[i] != want {
+			return false
+		}
+	}
+	return true
+}
+
+type Balanced struct {
+	Seat Seat
+	Semi bool
+}
+
+var balancedShapes = [][4]int{{4, 3, 3, 3}, {4, 4, 3, 2}, {5, 3, 3, 2}}
+var semiBalancedShapes = [][4]int{{5, 4, 2, 2}, {6, 3, 2, 2}}
+
+func (c Balanced) Match(d *DealStats) bool {
+	shapes := balancedShapes
+	if c.Semi {
+		shapes = append(shapes, semiBalancedShapes...)
+	}
+	for _, shape := range shapes {
+		if (Shape{Seat: c.Seat, Pattern: shape, AnyOrder: true}).Match(d) {
+			return true
+		}
+	}
+	return false
+}
+
+type HasCards struct {
+	Seat  Seat
+	Cards uint64
+}
+
+func (c HasCards) Match(d *DealStats) bool { return d.hands[c.Seat].cards&c.Cards == c.Cards }
+
+type And []Constraint
+
+func (c And) Match(d *DealStats) bool {
+	for _, inner := range c {
+		if !inner.Match(d) {
+			return false
+		}
+	}
+	return true
+}
+
+type Or []Constraint
+
+func (c Or) Match(d *DealStats) bool {
+	for _, inner := range c {
+		if inner.Match(d) {
+			return true
+		}
+	}
+	return false
+}
+
+type Not struct{ Inner Constraint }
+
+func (c Not) Match(d *DealStats) bool { return !c.Inner.Match(d) }
+
+type Any struct{}
+
+func (Any) Match(*DealStats) bool { return true }
+
+// ParseConstraint compiles expressions such as
+//
+//	N hcp 15-17 and N balanced and S spades >= 5
+//	(E shape 5=5=x=x or W has AS,KS) and not N hearts < 2
+//
+// Conditions start with a seat and test hcp, a suit length, shape,
+// balanced, semibalanced or has. They combine with and, or, not and
+// parentheses. An empty expression matches every deal.
+func ParseConstraint(expr string) (Constraint, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return Any{}, nil
+	}
+
+	p := &parser{tokens: tokens}
+	c, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Unexpected %q in constraint", p.tokens[p.pos])
+	}
+	return c, nil
+}
+
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		ch := rune(expr[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case strings.ContainsRune("()-,", ch):
+			tokens = append(tokens, string(ch))
+			i++
+		case strings.ContainsRune("<>=!&|", ch):
+			j := i + 1
+			for j < len(expr) && strings.ContainsRune("=&|", rune(expr[j])) && j-i < 2 {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		case unicode.IsLetter(ch) || unicode.IsDigit(ch):
+			j := i
+			for j < len(expr) {
+				c := rune(expr[j])
+				if unicode.IsLetter(c) || unicode.IsDigit(c) {
+					j++
+				} else if c == '=' && (unicode.IsDigit(ch) || ch == 'x') && j+1 < len(expr) && (unicode.IsDigit(rune(expr[j+1])) || expr[j+1] == 'x') {
+					j++
+				} else {
+					break
+				}
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("Unexpected character %q in constraint", ch)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return strings.ToLower(p.tokens[p.pos])
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) or() (Constraint, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	terms := Or{left}
+	for p.peek() == "or" || p.peek() == "||" {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, right)
+	}
+	if len(terms) == 1 {
+		return left, nil
+	}
+	return terms, nil
+}
+
+func (p *parser) and() (Constraint, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	terms := And{left}
+	for p.peek() == "and" || p.peek() == "&&" {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, right)
+	}
+	if len(terms) == 1 {
+		return left, nil
+	}
+	return terms, nil
+}
+
+func (p *parser) unary() (Constraint, error) {
+	switch p.peek() {
+	case "not", "!":
+		p.next()
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{inner}, nil
+	case "(":
+		p.next()
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("Missing closing parenthesis in constraint")
+		}
+		return inner, nil
+	}
+	return p.condition()
+}
+
+func (p *parser) condition() (Constraint, error) {
+	seat, err := ParseSeat(p.next())
+	if err != nil {
+		return nil, err
+	}
+
+	feature := p.next()
+	switch feature {
+	case "hcp":
+		r, err := p.comparison()
+		return HCP{Seat: seat, Range: r}, err
+	case "spades", "hearts", "diamonds", "clubs":
+		suit, _ := ParseSuit(feature)
+		r, err := p.comparison()
+		return SuitLength{Seat: seat, Suit: suit, Range: r}, err
+	case "balanced":
+		return Balanced{Seat: seat}, nil
+	case "semibalanced":
+		return Balanced{Seat: seat, Semi: true}, nil
+	case "shape":
+		return p.shape(seat)
+	case "has":
+		c := HasCards{Seat: seat}
+		for {
+			card, err := ParseCard(p.next())
+			if err != nil {
+				return nil, err
+			}
+			c.Cards |= 1 << uint(cardIndex(card))
+			if p.peek() != "," {
+				return c, nil
+			}
+			p.next()
+		}
+	}
+	return nil, fmt.Errorf("Unknown hand feature %q", feature)
+}
+
+func (p *parser) comparison() (Comparison, error) {
+	op := p.peek()
+	if op != "" && strings.ContainsAny(op[:1], "<>=!") {
+		p.next()
+	} else {
+		op = "="
+	}
+
+	value, err := strconv.Atoi(p.next())
+	if err != nil {
+		return Comparison{}, fmt.Errorf("Expected a number in constraint")
+	}
+
+	switch op {
+	case "=", "==":
+		if p.peek() == "-" {
+			p.next()
+			max, err := strconv.Atoi(p.next())
+			if err != nil {
+				return Comparison{}, fmt.Errorf("Expected a number in constraint")
+			}
+			return Comparison{Min: value, Max: max}, nil
+		}
+		return Comparison{Min: value, Max: value}, nil
+	case ">=":
+		return Comparison{Min: value, Max: 40}, nil
+	case ">":
+		return Comparison{Min: value + 1, Max: 40}, nil
+	case "<=":
+		return Comparison{Min: 0, Max: value}, nil
+	case "<":
+		return Comparison{Min: 0, Max: value - 1}, nil
+	}
+	return Comparison{}, fmt.Errorf("Unsupported comparison %q", op)
+}
+
+func (p *parser) shape(seat Seat) (Constraint, error) {
+	token := p.next()
+	c := Shape{Seat: seat}
+
+	var parts []string
+	if strings.Contains(token, "=") {
+		parts = strings.Split(token, "=")
+	} else {
+		c.AnyOrder = true
+		parts = strings.Split(token, "")
+	}
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("Invalid shape %q", token)
+	}
+
+	total := 0
+	for i, part := range parts {
+		if part == "x" {
+			c.Pattern[i] = -1
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid shape %q", token)
+		}
+		c.Pattern[i] = n
+		total += n
+	}
+	if c.AnyOrder {
+		sort.Sort(sort.Reverse(sort.IntSlice(c.Pattern[:])))
+		if total != 13 {
+			return nil, fmt.Errorf("Invalid shape %q", token)
+		}
+	}
+	return c, nil
+}
+
+// requiredCards collects the cards a top-level conjunction forces into each
+// hand, so the dealer can place them before shuffling the rest.
+func requiredCards(c Constraint) [4]uint64 {
+	var required [4]uint64
+	switch c := c.(type) {
+	case HasCards:
+		required[c.Seat] |= c.Cards
+	case And:
+		for _, inner := range c {
+			for seat, cards := range requiredCards(inner) {
+				required[seat] |= cards
+			}
+		}
+	}
+	return required
+}
@@ -0,0 +1,81 @@
+package dds
+
+import (
+	"cardGame/deck/bridge"
+)
+
+var strains = []bridge.Suit{bridge.Clubs, bridge.Diamonds, bridge.Hearts, bridge.Spades, bridge.NoTrump}
+
+// Table holds double-dummy tricks indexed by strain (clubs, diamonds, hearts,
+// spades, notrump) and declarer (north, east, south, west).
+type Table struct {
+	Tricks [5][4]int `json:"tricks"`
+}
+
+func (t Table) TricksFor(strain bridge.Suit, declarer bridge.Seat) int {
+	return t.Tricks[strain][declarer]
+}
+
+type Analysis struct {
+	Table Table `json:"table"`
+	Par   Par   `json:"par"`
+	Nodes int   `json:"nodes"`
+}
+
+// Tricks returns how many tricks declarer takes in strain with best play on
+// both sides, the opening lead coming from declarer's left.
+func Tricks(hands [4]bridge.Hand, strain bridge.Suit, declarer bridge.Seat) (int, error) {
+	s, err := newSolver(hands, strain)
+	if err != nil {
+		return 0, err
+	}
+	tricks, _ := declarerTricks(s, declarer, -1)
+	return tricks, nil
+}
+
+// declarerTricks also returns the North-South trick count, which makes a good
+// first guess for the next declarer in the same strain.
+func declarerTricks(s *solver, declarer bridge.Seat, guess int) (int, int) {
+	ns := s.nsTricks(int(declarer.Next()), guess)
+	if declarer.NorthSouth() {
+		return ns, ns
+	}
+	return s.tricksLeft(0) - ns, ns
+}
+
+// Analyze fills the full 20 cell trick table for a deal. The transposition
+// table is shared by the four declarers of each strain.
+func Analyze(hands [4]bridge.Hand) (Table, int, error) {
+	var table Table
+	nodes := 0
+	for _, strain := range strains {
+		s, err := newSolver(hands, strain)
+		if err != nil {
+			return Table{}, 0, err
+		}
+		guess := -1
+		for declarer := bridge.North; declarer <= bridge.West; declarer++ {
+			table.Tricks[strain][declarer], guess = declarerTricks(s, declarer, guess)
+		}
+		nodes += s.nodes
+	}
+	return table, nodes, nil
+}
+
+// AnalyzeDeal runs Analyze and adds the par result for the deal's board.
+func AnalyzeDeal(deal bridge.Deal) (Analysis, error) {
+	table, nodes, err := Analyze(deal.Hands)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	board := deal.Board
+	if board <= 0 {
+		board = 1
+	}
+	return Analysis{
+		Table: table,
+		Par:   CalculatePar(table, bridge.BoardVulnerability(board), deal.Dealer),
+		Nodes: nodes,
+	}, nil
+}
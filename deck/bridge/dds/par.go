@@ -0,0 +1,92 @@
+package dds
+
+import (
+	"cardGame/deck/bridge"
+)
+
+// Par is the contract both sides reach when each bids its makeable contracts
+// and doubles the opponents' sacrifices. Score is from North-South's point of
+// view.
+type Par struct {
+	Score    int             `json:"score"`
+	Contract bridge.Contract `json:"contract"`
+}
+
+type parSide struct {
+	best       [5]bridge.Seat
+	tricks     [5]int
+	vulnerable bool
+}
+
+// CalculatePar solves the bidding as a two-player game over the 35 possible
+// contracts. A side may outbid the current contract or pass; a contract that
+// makes is left undoubled and one that fails is doubled. The side of the
+// dealer acts first.
+func CalculatePar(table Table, vulnerability bridge.Vulnerability, dealer bridge.Seat) Par {
+	var sides [2]parSide
+	for side := 0; side < 2; side++ {
+		first, second := bridge.Seat(side), bridge.Seat(side+2)
+		sides[side].vulnerable = vulnerability.Vulnerable(first)
+		for _, strain := range strains {
+			declarer := first
+			if table.Tricks[strain][second] > table.Tricks[strain][first] {
+				declarer = second
+			}
+			sides[side].best[strain] = declarer
+			sides[side].tricks[strain] = table.Tricks[strain][declarer]
+		}
+	}
+
+	contractFor := func(rank, side int) (bridge.Contract, int) {
+		level, strain := rank/5+1, strains[rank%5]
+		contract := bridge.Contract{Level: level, Strain: strain, Declarer: sides[side].best[strain]}
+		tricks := sides[side].tricks[strain]
+		if tricks < level+6 {
+			contract.Doubled = 1
+		}
+		return contract, contract.Score(tricks, sides[side].vulnerable)
+	}
+
+	type outcome struct {
+		score    int
+		contract bridge.Contract
+	}
+
+	// memo[rank+1][side][opened] is the outcome, scored for side, when side is
+	// to act over the contract of the given rank held by the other side.
+	var memo [36][2][2]*outcome
+	var solve func(rank, side, opened int) outcome
+	solve = func(rank, side, opened int) outcome {
+		if cached := memo[rank+1][side][opened]; cached != nil {
+			return *cached
+		}
+
+		var best outcome
+		switch {
+		case rank >= 0:
+			contract, score := contractFor(rank, 1-side)
+			best = outcome{score: -score, contract: contract}
+		case opened == 0:
+			passed := solve(-1, 1-side, 1)
+			best = outcome{score: -passed.score, contract: passed.contract}
+		}
+
+		for next := rank + 1; next < 35; next++ {
+			reply := solve(next, 1-side, 1)
+			if -reply.score > best.score {
+				best = outcome{score: -reply.score, contract: reply.contract}
+			}
+		}
+
+		memo[rank+1][side][opened] = &best
+		return best
+	}
+
+	first := int(dealer) % 2
+	result := solve(-1, first, 0)
+	score := result.score
+	if first == 1 {
+		score = -score
+	}
+	return Par{Score: score, Contract: result.contract}
+}
@@ -0,0 +1,484 @@
+package dds
+
+import (
+	"fmt"
+	"math/bits"
+
+	"cardGame/deck/bridge"
+)
+
+// card is one card in play. Suits follow bridge.Suit and ranks run from 0 for
+// the two to 12 for the ace.
+type card struct {
+	seat int8
+	suit int8
+	rank int8
+}
+
+// ranks marks, per suit, the cards whose exact rank decided a search result.
+type ranks [4]uint16
+
+func (r *ranks) add(other ranks) {
+	for suit := range r {
+		r[suit] |= other[suit]
+	}
+}
+
+// ttKey groups positions at the start of a trick by who is on lead and how
+// many cards each hand holds in each suit.
+type ttKey struct {
+	lengths uint64
+	leader  int8
+}
+
+// ttEntry stores a bound that holds for every position with the same suit
+// lengths in which the top depth[suit] cards of each suit have the same
+// owners. Cards below that depth never decided a trick during the search, so
+// it does not matter who holds them.
+type ttEntry struct {
+	depth  [4]uint8
+	owners [4]uint32
+	lower  int8
+	upper  int8
+}
+
+type solver struct {
+	hands   [4][4]uint16
+	trump   int
+	inTrick [4]uint16
+	trick   [4]card
+	buffers [52][13]card
+	played  int
+	tt      map[ttKey][]ttEntry
+	nodes   int
+}
+
+func newSolver(hands [4]bridge.Hand, strain bridge.Suit) (*solver, error) {
+	s := &solver{trump: int(strain), tt: make(map[ttKey][]ttEntry)}
+
+	var seen [4]uint16
+	for seat, hand := range hands {
+		if len(hand) != len(hands[0]) {
+			return nil, fmt.Errorf("All hands must hold the same number of cards")
+		}
+		for _, c := range hand {
+			suit := bridge.SuitOf(c)
+			rank := c.Rank() - 2
+			if suit < 0 || rank < 0 {
+				return nil, fmt.Errorf("Invalid card %q", c.Code)
+			}
+			bit := uint16(1) << uint(rank)
+			if seen[suit]&bit != 0 {
+				return nil, fmt.Errorf("Card %q appears more than once", c.Code)
+			}
+			seen[suit] |= bit
+			s.hands[seat][suit] |= bit
+		}
+	}
+	if len(hands[0]) == 0 {
+		return nil, fmt.Errorf("Hands are empty")
+	}
+
+	return s, nil
+}
+
+func (s *solver) tricksLeft(seat int) int {
+	n := 0
+	for _, holding := range s.hands[seat] {
+		n += bits.OnesCount16(holding)
+	}
+	return n
+}
+
+func (s *solver) present(suit int) uint16 {
+	return s.hands[0][suit] | s.hands[1][suit] | s.hands[2][suit] | s.hands[3][suit]
+}
+
+// topCards returns the n highest unplayed cards of suit.
+func (s *solver) topCards(suit, n int) uint16 {
+	all := s.present(suit)
+	var top uint16
+	for rank := 12; rank >= 0 && n > 0; rank-- {
+		bit := uint16(1) << uint(rank)
+		if all&bit != 0 {
+			top |= bit
+			n--
+		}
+	}
+	return top
+}
+
+// nsTricks finds the number of tricks North-South take with leader on lead,
+// by binary search over null-window searches that share one transposition
+// table. The first probe is at guess, usually the answer for a neighbouring
+// leader.
+func (s *solver) nsTricks(leader, guess int) int {
+	lo, hi := 0, s.tricksLeft(leader)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if guess > lo && guess <= hi {
+			mid, guess = guess, -1
+		}
+		if ok, _ := s.makes(leader, mid); ok {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
+
+// makes reports whether North-South can take at least need of the remaining
+// tricks with leader to play to the next trick, together with the cards whose
+// ranks the answer depends on.
+func (s *solver) makes(leader, need int) (bool, ranks) {
+	if need <= 0 {
+		return true, ranks{}
+	}
+	remaining := s.tricksLeft(leader)
+	if need > remaining {
+		return false, ranks{}
+	}
+
+	var sure [2]int
+	var sureRanks [2]ranks
+	for side := 0; side < 2; side++ {
+		sure[side], sureRanks[side] = s.sureTrumpTricks(side)
+	}
+	if quick, quickRanks := s.quickTricks(leader); quick > sure[leader%2] {
+		sure[leader%2], sureRanks[leader%2] = quick, quickRanks
+	}
+	if sure[0] >= need {
+		return true, sureRanks[0]
+	}
+	if remaining-sure[1] < need {
+		return false, sureRanks[1]
+	}
+
+	key, owners := s.key(leader)
+	entries := s.tt[key]
+	for _, e := range entries {
+		if !e.matches(owners) {
+			continue
+		}
+		if int(e.lower) >= need {
+			return true, s.depthRanks(e.depth)
+		}
+		if int(e.upper) < need {
+			return false, s.depthRanks(e.depth)
+		}
+	}
+
+	result, relevant := s.play(leader, 0, need)
+
+	entry := ttEntry{lower: 0, upper: int8(remaining)}
+	if result {
+		entry.lower = int8(need)
+	} else {
+		entry.upper = int8(need - 1)
+	}
+	for suit := 0; suit < 4; suit++ {
+		if relevant[suit] == 0 {
+			continue
+		}
+		lowest := uint16(1) << uint(bits.TrailingZeros16(relevant[suit]))
+		entry.depth[suit] = uint8(bits.OnesCount16(s.present(suit) &^ (lowest - 1)))
+		entry.owners[suit] = owners[suit] & (1<<(2*uint(entry.depth[suit])) - 1)
+	}
+	for i, e := range entries {
+		if e.depth == entry.depth && e.owners == entry.owners {
+			if entry.lower > e.lower {
+				entries[i].lower = entry.lower
+			}
+			if entry.upper < e.upper {
+				entries[i].upper = entry.upper
+			}
+			return result, s.depthRanks(entry.depth)
+		}
+	}
+	s.tt[key] = append(entries, entry)
+
+	return result, s.depthRanks(entry.depth)
+}
+
+func (e ttEntry) matches(owners [4]uint32) bool {
+	for suit := 0; suit < 4; suit++ {
+		if owners[suit]&(1<<(2*uint(e.depth[suit]))-1) != e.owners[suit] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *solver) depthRanks(depth [4]uint8) ranks {
+	var r ranks
+	for suit := 0; suit < 4; suit++ {
+		r[suit] = s.topCards(suit, int(depth[suit]))
+	}
+	return r
+}
+
+// quickTricks counts the tricks leader can cash from the top without giving up
+// the lead. A side suit winner only counts while every opponent who still
+// holds trumps also has to follow suit.
+func (s *solver) quickTricks(leader int) (int, ranks) {
+	total := 0
+	var used ranks
+	for suit := 0; suit < 4; suit++ {
+		holding := s.hands[leader][suit]
+		if holding == 0 {
+			continue
+		}
+		others := s.present(suit) &^ holding
+
+		winners := 0
+		for rank := 12; rank >= 0; rank-- {
+			bit := uint16(1) << uint(rank)
+			if holding&bit != 0 {
+				winners++
+			} else if others&bit != 0 {
+				break
+			}
+		}
+
+		if s.trump != 4 && suit != s.trump {
+			for _, opponent := range []int{(leader + 1) % 4, (leader + 3) % 4} {
+				if s.hands[opponent][s.trump] == 0 {
+					continue
+				}
+				if length := bits.OnesCount16(s.hands[opponent][suit]); length < winners {
+					winners = length
+				}
+			}
+		}
+		total += winners
+		used[suit] = s.topCards(suit, winners)
+	}
+	return total, used
+}
+
+// sureTrumpTricks counts the longest unbroken run of top trumps held in one
+// hand of side. Those cards win whenever they are played, so the side takes at
+// least that many tricks whatever happens.
+func (s *solver) sureTrumpTricks(side int) (int, ranks) {
+	var used ranks
+	if s.trump == 4 {
+		return 0, used
+	}
+	best := 0
+	for _, seat := range []int{side, side + 2} {
+		others := s.present(s.trump) &^ s.hands[seat][s.trump]
+		run := 0
+		for rank := 12; rank >= 0; rank-- {
+			bit := uint16(1) << uint(rank)
+			if s.hands[seat][s.trump]&bit != 0 {
+				run++
+			} else if others&bit != 0 {
+				break
+			}
+		}
+		if run > best {
+			best = run
+		}
+	}
+	used[s.trump] = s.topCards(s.trump, best)
+	return best, used
+}
+
+func (s *solver) play(leader, pos, need int) (bool, ranks) {
+	if pos == 4 {
+		win := s.trick[s.winnerIndex()]
+		byRank := false
+		for _, c := range s.trick {
+			if c != win && c.suit == win.suit {
+				byRank = true
+			}
+		}
+
+		trick := s.trick
+		for _, c := range trick {
+			s.inTrick[c.suit] &^= 1 << uint(c.rank)
+		}
+		if win.seat%2 == 0 {
+			need--
+		}
+		result, relevant := s.makes(int(win.seat), need)
+		s.trick = trick
+		for _, c := range trick {
+			s.inTrick[c.suit] |= 1 << uint(c.rank)
+		}
+
+		if byRank {
+			relevant[win.suit] |= 1 << uint(win.rank)
+		}
+		return result, relevant
+	}
+
+	s.nodes++
+	seat := (leader + pos) % 4
+	maximizing := seat%2 == 0
+	moves := s.moves(seat, pos)
+
+	var relevant ranks
+	for _, c := range moves {
+		bit := uint16(1) << uint(c.rank)
+		s.hands[seat][c.suit] &^= bit
+		s.inTrick[c.suit] |= bit
+		s.trick[pos] = c
+		s.played++
+
+		result, childRanks := s.play(leader, pos+1, need)
+
+		s.played--
+		s.inTrick[c.suit] &^= bit
+		s.hands[seat][c.suit] |= bit
+
+		if result == maximizing {
+			return result, childRanks
+		}
+		relevant.add(childRanks)
+	}
+	return !maximizing, relevant
+}
+
+func (s *solver) winnerIndex() int {
+	best := 0
+	for i, c := range s.trick[1:] {
+		if s.beats(c, s.trick[best]) {
+			best = i + 1
+		}
+	}
+	return best
+}
+
+func (s *solver) winner() int {
+	return int(s.trick[s.winnerIndex()].seat)
+}
+
+func (s *solver) beats(c, best card) bool {
+	return c.suit == best.suit && c.rank > best.rank || int(c.suit) == s.trump && int(best.suit) != s.trump
+}
+
+// moves lists the distinct cards seat may play, most promising first. Cards
+// of one hand with no unplayed card of another hand between them are
+// equivalent, so only the highest of each such sequence is tried.
+func (s *solver) moves(seat, pos int) []card {
+	moves := s.buffers[s.played][:0]
+
+	first, last := 0, 3
+	if pos > 0 {
+		led := int(s.trick[0].suit)
+		if s.hands[seat][led] != 0 {
+			first, last = led, led
+		}
+	}
+
+	for suit := first; suit <= last; suit++ {
+		holding := s.hands[seat][suit]
+		if holding == 0 {
+			continue
+		}
+		present := s.present(suit) | s.inTrick[suit]
+		previousOwn := false
+		for rank := 12; rank >= 0; rank-- {
+			bit := uint16(1) << uint(rank)
+			if present&bit == 0 {
+				continue
+			}
+			own := holding&bit != 0
+			if own && !previousOwn {
+				moves = append(moves, card{seat: int8(seat), suit: int8(suit), rank: int8(rank)})
+			}
+			previousOwn = own
+		}
+	}
+
+	s.order(moves, seat, pos)
+	return moves
+}
+
+func (s *solver) order(moves []card, seat, pos int) {
+	var weights [13]int
+	if pos == 0 {
+		for i, c := range moves {
+			top := s.topOwner(int(c.suit))
+			switch {
+			case top == seat:
+				weights[i] = 60 + int(c.rank)
+			case top%2 == seat%2:
+				weights[i] = 40 - int(c.rank)
+			default:
+				weights[i] = 20 - int(c.rank)
+			}
+			if int(c.suit) == s.trump && top%2 != seat%2 {
+				weights[i] -= 20
+			}
+		}
+	} else {
+		current := s.trick[0]
+		for _, c := range s.trick[1:pos] {
+			if s.beats(c, current) {
+				current = c
+			}
+		}
+		partnerWinning := int(current.seat)%2 == seat%2
+		for i, c := range moves {
+			switch {
+			case partnerWinning:
+				weights[i] = -int(c.rank)
+			case s.beats(c, current):
+				weights[i] = 100 - int(c.rank)
+			default:
+				weights[i] = -int(c.rank)
+			}
+			if int(c.suit) == s.trump && !s.beats(c, current) {
+				weights[i] -= 20
+			}
+		}
+	}
+
+	for i := 1; i < len(moves); i++ {
+		for j := i; j > 0 && weights[j] > weights[j-1]; j-- {
+			moves[j], moves[j-1] = moves[j-1], moves[j]
+			weights[j], weights[j-1] = weights[j-1], weights[j]
+		}
+	}
+}
+
+// topOwner returns the seat holding the highest unplayed card of suit.
+func (s *solver) topOwner(suit int) int {
+	for rank := 12; rank >= 0; rank-- {
+		bit := uint16(1) << uint(rank)
+		for seat := 0; seat < 4; seat++ {
+			if s.hands[seat][suit]&bit != 0 {
+				return seat
+			}
+		}
+	}
+	return -1
+}
+
+// key returns the table key for a trick start together with, per suit, the
+// owner of every unplayed card from the top down, two bits per card.
+func (s *solver) key(leader int) (ttKey, [4]uint32) {
+	key := ttKey{leader: int8(leader)}
+	var owners [4]uint32
+	for suit := 0; suit < 4; suit++ {
+		for seat := 0; seat < 4; seat++ {
+			length := uint64(bits.OnesCount16(s.hands[seat][suit]))
+			key.lengths |= length << (4 * uint(suit*4+seat))
+		}
+		depth := uint(0)
+		for all := s.present(suit); all != 0; depth++ {
+			bit := uint16(1) << uint(15-bits.LeadingZeros16(all))
+			all &^= bit
+			for seat := 1; seat < 4; seat++ {
+				if s.hands[seat][suit]&bit != 0 {
+					owners[suit] |= uint32(seat) << (2 * depth)
+					break
+				}
+			}
+		}
+	}
+	return key, owners
+}
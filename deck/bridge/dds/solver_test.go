@@ -0,0 +1,157 @@
+package dds
+
+import (
+	"math/rand"
+	"testing"
+
+	"cardGame/deck/bridge"
+)
+
+func TestTricks(t *testing.T) {
+	deal, err := bridge.ParsePBNDeal("N:AKQJT98765432... .AKQJT98765432.. ..AKQJT98765432. ...AKQJT98765432")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, _, err := Analyze(deal.Hands)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := map[bridge.Suit]bridge.Seat{bridge.Spades: bridge.North, bridge.Hearts: bridge.East, bridge.Diamonds: bridge.South, bridge.Clubs: bridge.West}
+	for _, strain := range strains {
+		for declarer := bridge.North; declarer <= bridge.West; declarer++ {
+			want := 0
+			if holder, ok := owner[strain]; ok && (holder == declarer || holder == declarer.Partner()) {
+				want = 13
+			}
+			if got := table.TricksFor(strain, declarer); got != want {
+				t.Errorf("Tricks for %v in %v: got %v want %v", declarer, strain, got, want)
+			}
+		}
+	}
+}
+
+func TestTricksMatchesMinimax(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for round := 0; round < 12; round++ {
+		hands := randomEnding(rng, 4)
+		for _, strain := range strains {
+			for declarer := bridge.North; declarer <= bridge.West; declarer++ {
+				got, err := Tricks(hands, strain, declarer)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				s, _ := newSolver(hands, strain)
+				ns := minimax(s, int(declarer.Next()), 0)
+				want := ns
+				if !declarer.NorthSouth() {
+					want = 4 - ns
+				}
+				if got != want {
+					t.Fatalf("Round %v, %v declaring %v: got %v want %v", round, declarer, strain, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestAnalyzeFullDeal(t *testing.T) {
+	deal, err := bridge.ParsePBNDeal("N:AK32.KJ3.Q32.K32 QJT9.AQ2.AK4.A54 8765.T98.J98.QJT 4.7654.T765.9876")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := AnalyzeDeal(deal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, strain := range strains {
+		for declarer := bridge.North; declarer <= bridge.West; declarer++ {
+			ns := analysis.Table.TricksFor(strain, declarer)
+			partner := analysis.Table.TricksFor(strain, declarer.Partner())
+			if ns < 0 || ns > 13 || partner < 0 || partner > 13 {
+				t.Errorf("Trick count out of range for %v in %v", declarer, strain)
+			}
+		}
+	}
+
+	if _, err := Tricks([4]bridge.Hand{deal.Hands[0], deal.Hands[1], deal.Hands[2], deal.Hands[2]}, bridge.NoTrump, bridge.North); err == nil {
+		t.Errorf("Tricks accepted a deal with duplicate cards")
+	}
+}
+
+func TestCalculatePar(t *testing.T) {
+	var table Table
+	table.Tricks[bridge.Hearts] = [4]int{10, 3, 10, 3}
+	table.Tricks[bridge.Spades] = [4]int{5, 8, 5, 8}
+
+	par := CalculatePar(table, bridge.VulnerableNone, bridge.North)
+	if par.Score != 300 || par.Contract.String() != "4SX-E" {
+		t.Errorf("Unexpected par: %v %v", par.Contract, par.Score)
+	}
+
+	par = CalculatePar(table, bridge.VulnerableEW, bridge.North)
+	if par.Score != 420 || par.Contract.String() != "4H-N" {
+		t.Errorf("Unexpected par: %v %v", par.Contract, par.Score)
+	}
+
+	par = CalculatePar(Table{}, bridge.VulnerableNone, bridge.North)
+	if par.Score != 0 || par.Contract.Level != 0 {
+		t.Errorf("Unexpected par for a hand nobody can make: %v %v", par.Contract, par.Score)
+	}
+}
+
+func randomEnding(rng *rand.Rand, size int) [4]bridge.Hand {
+	indexes := rng.Perm(52)[:4*size]
+	var hands [4]bridge.Hand
+	for i, index := range indexes {
+		suit := bridge.Suit(index / 13)
+		rank := "23456789TJQKA"[index%13 : index%13+1]
+		c, _ := bridge.ParseCard(rank + suit.String())
+		hands[i%4] = append(hands[i%4], c)
+	}
+	return hands
+}
+
+// minimax plays out every legal card with no pruning or equivalence.
+func minimax(s *solver, leader, pos int) int {
+	if pos == 4 {
+		winner := s.winner()
+		trick := s.trick
+		won := 0
+		if winner%2 == 0 {
+			won = 1
+		}
+		if s.tricksLeft(winner) == 0 {
+			return won
+		}
+		result := won + minimax(s, winner, 0)
+		s.trick = trick
+		return result
+	}
+
+	seat := (leader + pos) % 4
+	best := -1
+	for suit := 0; suit < 4; suit++ {
+		if pos > 0 && int(s.trick[0].suit) != suit && s.hands[seat][s.trick[0].suit] != 0 {
+			continue
+		}
+		for rank := 0; rank < 13; rank++ {
+			bit := uint16(1) << uint(rank)
+			if s.hands[seat][suit]&bit == 0 {
+				continue
+			}
+			s.hands[seat][suit] &^= bit
+			s.trick[pos] = card{seat: int8(seat), suit: int8(suit), rank: int8(rank)}
+			value := minimax(s, leader, pos+1)
+			s.hands[seat][suit] |= bit
+			if best < 0 || (seat%2 == 0 && value > best) || (seat%2 == 1 && value < best) {
+				best = value
+			}
+		}
+	}
+	return best
+}
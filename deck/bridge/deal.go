@@ -0,0 +1,224 @@
+package bridge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"cardGame/deck/model"
+)
+
+type Seat int
+
+const (
+	North Seat = iota
+	East
+	South
+	West
+)
+
+var seatNames = []string{"N", "E", "S", "W"}
+
+func (s Seat) String() string {
+	if s < North || s > West {
+		return "?"
+	}
+	return seatNames[s]
+}
+
+func (s Seat) Next() Seat       { return (s + 1) % 4 }
+func (s Seat) Partner() Seat    { return (s + 2) % 4 }
+func (s Seat) NorthSouth() bool { return s == North || s == South }
+
+func ParseSeat(name string) (Seat, error) {
+	switch strings.ToUpper(name) {
+	case "N", "NORTH":
+		return North, nil
+	case "E", "EAST":
+		return East, nil
+	case "S", "SOUTH":
+		return South, nil
+	case "W", "WEST":
+		return West, nil
+	}
+	return North, fmt.Errorf("Invalid seat %q", name)
+}
+
+func (s Seat) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *Seat) UnmarshalText(text []byte) error {
+	seat, err := ParseSeat(string(text))
+	*s = seat
+	return err
+}
+
+// Suit orders the suits the way bridge ranks them, so it doubles as a strain
+// with NoTrump on top.
+type Suit int
+
+const (
+	Clubs Suit = iota
+	Diamonds
+	Hearts
+	Spades
+	NoTrump
+)
+
+var suitNames = []string{"C", "D", "H", "S", "NT"}
+var suitModelNames = []string{"CLUBS", "DIAMONDS", "HEARTS", "SPADES"}
+
+func (s Suit) String() string {
+	if s < Clubs || s > NoTrump {
+		return "?"
+	}
+	return suitNames[s]
+}
+
+func ParseSuit(name string) (Suit, error) {
+	switch strings.ToUpper(name) {
+	case "C", "CLUBS":
+		return Clubs, nil
+	case "D", "DIAMONDS":
+		return Diamonds, nil
+	case "H", "HEARTS":
+		return Hearts, nil
+	case "S", "SPADES":
+		return Spades, nil
+	case "N", "NT", "NOTRUMP":
+		return NoTrump, nil
+	}
+	return Clubs, fmt.Errorf("Invalid suit %q", name)
+}
+
+func (s Suit) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *Suit) UnmarshalText(text []byte) error {
+	suit, err := ParseSuit(string(text))
+	*s = suit
+	return err
+}
+
+// SuitOf returns the bridge suit of a model card.
+func SuitOf(c model.Card) Suit {
+	for i, name := range suitModelNames {
+		if c.Suit == name {
+			return Suit(i)
+		}
+	}
+	return Suit(-1)
+}
+
+// cardIndex numbers the 52 cards from the two of clubs (0) to the ace of
+// spades (51).
+func cardIndex(c model.Card) int {
+	suit := SuitOf(c)
+	rank := c.Rank()
+	if suit < 0 || rank == 0 {
+		return -1
+	}
+	return int(suit)*13 + rank - 2
+}
+
+func cardAt(index int) model.Card {
+	value := model.Values[index%13]
+	suit := suitModelNames[index/13]
+	return model.Card{Value: value, Suit: suit, Code: value[:1] + suit[:1]}
+}
+
+// ParseCard reads a card code such as "AS", "TD", "1D" or "10D".
+func ParseCard(code string) (model.Card, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) < 2 {
+		return model.Card{}, fmt.Errorf("Invalid card %q", code)
+	}
+	suit, err := ParseSuit(code[len(code)-1:])
+	if err != nil || suit == NoTrump {
+		return model.Card{}, fmt.Errorf("Invalid card %q", code)
+	}
+	rank := -1
+	switch value := code[:len(code)-1]; {
+	case value == "1" || value == "10":
+		rank = 8
+	case len(value) == 1:
+		rank = strings.Index("23456789TJQKA", value)
+	}
+	if rank < 0 {
+		return model.Card{}, fmt.Errorf("Invalid card %q", code)
+	}
+	return cardAt(int(suit)*13 + rank), nil
+}
+
+type Hand []model.Card
+
+// HCP counts Milton Work high-card points: 4 for an ACE down to 1 for a JACK.
+func (h Hand) HCP() int {
+	points := 0
+	for _, c := range h {
+		if rank := c.Rank(); rank > 10 {
+			points += rank - 10
+		}
+	}
+	return points
+}
+
+func (h Hand) Length(suit Suit) int {
+	length := 0
+	for _, c := range h {
+		if SuitOf(c) == suit {
+			length++
+		}
+	}
+	return length
+}
+
+// Shape returns the suit lengths in spades, hearts, diamonds, clubs order.
+func (h Hand) Shape() [4]int {
+	return [4]int{h.Length(Spades), h.Length(Hearts), h.Length(Diamonds), h.Length(Clubs)}
+}
+
+func (h Hand) Has(card model.Card) bool {
+	for _, c := range h {
+		if c.Code == card.Code && c.Suit == card.Suit {
+			return true
+		}
+	}
+	return false
+}
+
+type Deal struct {
+	DeckID uuid.UUID `json:"deck_id"`
+	Board  int       `json:"board"`
+	Dealer Seat      `json:"dealer"`
+	Hands  [4]Hand   `json:"hands"`
+}
+
+// DealFromDeck deals a full deck one card at a time, starting with the player
+// on the dealer's left.
+func DealFromDeck(deck model.Deck, dealer Seat) (Deal, error) {
+	if len(deck.Cards) != 52 {
+		return Deal{}, fmt.Errorf("Deck must contain 52 cards")
+	}
+
+	deal := Deal{DeckID: deck.ID, Dealer: dealer}
+	seat := dealer
+	for _, c := range deck.Cards {
+		seat = seat.Next()
+		deal.Hands[seat] = append(deal.Hands[seat], c)
+	}
+	return deal, nil
+}
+
+// Deck returns the cards in the order DealFromDeck would deal them back into
+// the same hands.
+func (d Deal) Deck() model.Deck {
+	cards := make([]model.Card, 0, 52)
+	for i := 0; i < 13; i++ {
+		seat := d.Dealer
+		for j := 0; j < 4; j++ {
+			seat = seat.Next()
+			cards = append(cards, d.Hands[seat][i])
+		}
+	}
+	return model.Deck{ID: d.DeckID, Shuffled: true, Remaining: len(cards), Cards: cards}
+}
@@ -0,0 +1,110 @@
+package bridge
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand"
+
+	"github.com/google/uuid"
+
+	"cardGame/deck/model"
+)
+
+const DefaultMaxAttempts = 1000000
+
+type DealRequest struct {
+	Constraint  Constraint
+	Count       int
+	MaxAttempts int
+	FirstBoard  int
+}
+
+type DealResult struct {
+	Deals    []Deal `json:"deals"`
+	Attempts int    `json:"attempts"`
+}
+
+// Generate deals boards until Count of them satisfy the constraint or
+// MaxAttempts shuffles have been tried. Cards the constraint demands in a
+// particular hand are placed first and only the remainder is shuffled, which
+// keeps card-specific requests from being rejected almost every time.
+func Generate(req DealRequest, rng *rand.Rand) (DealResult, error) {
+	if req.Count <= 0 {
+		return DealResult{}, fmt.Errorf("Count must be positive")
+	}
+	if req.Constraint == nil {
+		req.Constraint = Any{}
+	}
+	if req.MaxAttempts <= 0 {
+		req.MaxAttempts = DefaultMaxAttempts
+	}
+	if req.FirstBoard <= 0 {
+		req.FirstBoard = 1
+	}
+
+	required := requiredCards(req.Constraint)
+	var placed uint64
+	for seat, cards := range required {
+		if placed&cards != 0 {
+			return DealResult{}, fmt.Errorf("Constraint requires a card in two hands")
+		}
+		if bits.OnesCount64(cards) > 13 {
+			return DealResult{}, fmt.Errorf("Constraint requires more than 13 cards for %v", Seat(seat))
+		}
+		placed |= cards
+	}
+
+	var free []int
+	for _, c := range model.NewDeck(false, "").Cards {
+		if index := cardIndex(c); placed&(1<<uint(index)) == 0 {
+			free = append(free, index)
+		}
+	}
+
+	result := DealResult{}
+	for result.Attempts < req.MaxAttempts {
+		result.Attempts++
+
+		rng.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+		stats := &DealStats{}
+		next := 0
+		for seat := range stats.hands {
+			for index := 0; index < 52; index++ {
+				if required[seat]&(1<<uint(index)) != 0 {
+					stats.add(Seat(seat), index)
+				}
+			}
+			for n := bits.OnesCount64(required[seat]); n < 13; n++ {
+				stats.add(Seat(seat), free[next])
+				next++
+			}
+		}
+
+		if !req.Constraint.Match(stats) {
+			continue
+		}
+
+		board := req.FirstBoard + len(result.Deals)
+		result.Deals = append(result.Deals, stats.deal(board))
+		if len(result.Deals) == req.Count {
+			return result, nil
+		}
+	}
+
+	return result, fmt.Errorf("Only %d of %d deals matched after %d attempts", len(result.Deals), req.Count, result.Attempts)
+}
+
+// deal turns the statistics back into hands, sorted by suit and rank from
+// the top, for the given board number.
+func (d *DealStats) deal(board int) Deal {
+	deckID, _ := uuid.NewUUID()
+	deal := Deal{DeckID: deckID, Board: board, Dealer: BoardDealer(board)}
+	for seat, h := range d.hands {
+		for index := 51; index >= 0; index-- {
+			if h.cards&(1<<uint(index)) != 0 {
+				deal.Hands[seat] = append(deal.Hands[seat], cardAt(index))
+			}
+		}
+	}
+	return deal
+}
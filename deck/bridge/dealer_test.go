@@ -0,0 +1,104 @@
+package bridge
+
+import (
+	"math/rand"
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+func TestParseConstraint(t *testing.T) {
+	deal, err := ParsePBNDeal("N:AK32.KJ3.Q32.K32 QJT9.AQ2.AK4.A54 8765.T98.J98.QJT 4.7654.T765.9876")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := NewDealStats(deal)
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"N hcp 15-17", true},
+		{"N hcp >= 18", false},
+		{"N balanced and E hcp >= 20", true},
+		{"N shape 4333", true},
+		{"N shape 4=3=3=3", true},
+		{"W shape 1=4=4=4", true},
+		{"W shape x=4=x=x and W semibalanced", false},
+		{"S spades >= 4 and S has JC,QC", true},
+		{"not (N has AS or E has AS)", false},
+		{"S spades == 5 || W clubs = 4", true},
+		{"", true},
+	}
+
+	for _, test := range tests {
+		c, err := ParseConstraint(test.expr)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) failed: %v", test.expr, err)
+			continue
+		}
+		if got := c.Match(stats); got != test.want {
+			t.Errorf("Constraint %q: got %v want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, expr := range []string{"N hcp", "X hcp 5", "N shape 4432x", "N has ZZ", "(N balanced", "N balanced N"} {
+		if _, err := ParseConstraint(expr); err == nil {
+			t.Errorf("ParseConstraint(%q) accepted an invalid expression", expr)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	constraint, _ := ParseConstraint("N hcp 15-17 and N balanced and S spades >= 5 and E has AS")
+	result, err := Generate(DealRequest{Constraint: constraint, Count: 3, MaxAttempts: 200000}, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, deal := range result.Deals {
+		north := deal.Hands[North]
+		if hcp := north.HCP(); hcp < 15 || hcp > 17 {
+			t.Errorf("North has %v HCP", hcp)
+		}
+		if deal.Hands[South].Length(Spades) < 5 {
+			t.Errorf("South has %v spades", deal.Hands[South].Length(Spades))
+		}
+		if !deal.Hands[East].Has(model.Card{Value: "ACE", Suit: "SPADES", Code: "AS"}) {
+			t.Errorf("East does not hold the ace of spades")
+		}
+
+		again, err := DealFromDeck(deal.Deck(), deal.Dealer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if PBNDeal(again) != PBNDeal(deal) {
+			t.Errorf("Deal does not survive a round trip through its deck")
+		}
+	}
+
+	_, err = Generate(DealRequest{Constraint: HCP{Seat: North, Range: Comparison{Min: 38, Max: 40}}, Count: 1, MaxAttempts: 100}, rand.New(rand.NewSource(1)))
+	if err == nil {
+		t.Errorf("Generate did not report an exhausted budget")
+	}
+}
+
+func TestExportPBN(t *testing.T) {
+	deal, _ := ParsePBNDeal("E:AK32.KJ3.Q32.K32 QJT9.AQ2.AK4.A54 8765.T98.J98.QJT 4.7654.T765.9876")
+	deal.Board = 2
+	deal.Dealer = BoardDealer(2)
+
+	pbn := ExportPBN("Practice", []Deal{deal})
+	expected := `% PBN 2.1
+% EXPORT
+
+[Event "Practice"]
+[Board "2"]
+[Dealer "E"]
+[Vulnerable "NS"]
+[Deal "E:AK32.KJ3.Q32.K32 QJT9.AQ2.AK4.A54 8765.T98.J98.QJT 4.7654.T765.9876"]
+`
+	if pbn != expected {
+		t.Errorf("Unexpected PBN:\n%v", pbn)
+	}
+}
@@ -0,0 +1,168 @@
+package bridge
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Vulnerability int
+
+const (
+	VulnerableNone Vulnerability = iota
+	VulnerableNS
+	VulnerableEW
+	VulnerableBoth
+)
+
+var vulnerabilityNames = []string{"None", "NS", "EW", "All"}
+
+func (v Vulnerability) String() string {
+	if v < VulnerableNone || v > VulnerableBoth {
+		return "?"
+	}
+	return vulnerabilityNames[v]
+}
+
+func (v Vulnerability) MarshalText() ([]byte, error) { return []byte(v.String()), nil }
+
+func (v *Vulnerability) UnmarshalText(text []byte) error {
+	for i, name := range vulnerabilityNames {
+		if strings.EqualFold(name, string(text)) {
+			*v = Vulnerability(i)
+			return nil
+		}
+	}
+	if strings.EqualFold(string(text), "Both") {
+		*v = VulnerableBoth
+		return nil
+	}
+	return fmt.Errorf("Invalid vulnerability %q", text)
+}
+
+func (v Vulnerability) Vulnerable(seat Seat) bool {
+	switch v {
+	case VulnerableBoth:
+		return true
+	case VulnerableNS:
+		return seat.NorthSouth()
+	case VulnerableEW:
+		return !seat.NorthSouth()
+	}
+	return false
+}
+
+var boardVulnerability = []Vulnerability{
+	VulnerableNone, VulnerableNS, VulnerableEW, VulnerableBoth,
+	VulnerableNS, VulnerableEW, VulnerableBoth, VulnerableNone,
+	VulnerableEW, VulnerableBoth, VulnerableNone, VulnerableNS,
+	VulnerableBoth, VulnerableNone, VulnerableNS, VulnerableEW,
+}
+
+// BoardDealer and BoardVulnerability follow the standard duplicate rotation.
+func BoardDealer(board int) Seat {
+	return Seat((board - 1) % 4)
+}
+
+func BoardVulnerability(board int) Vulnerability {
+	return boardVulnerability[(board-1)%16]
+}
+
+// PBNHand renders a hand as spades.hearts.diamonds.clubs with ranks from the
+// top, for example "AKQ.JT9.876.5432".
+func PBNHand(hand Hand) string {
+	var holding [4]uint16
+	for _, c := range hand {
+		if index := cardIndex(c); index >= 0 {
+			holding[index/13] |= 1 << uint(index%13)
+		}
+	}
+
+	suits := make([]string, 0, 4)
+	for suit := Spades; suit >= Clubs; suit-- {
+		var b strings.Builder
+		for rank := 12; rank >= 0; rank-- {
+			if holding[suit]&(1<<uint(rank)) != 0 {
+				b.WriteByte("23456789TJQKA"[rank])
+			}
+		}
+		suits = append(suits, b.String())
+	}
+	return strings.Join(suits, ".")
+}
+
+// PBNDeal renders the Deal tag value, starting with the dealer.
+func PBNDeal(deal Deal) string {
+	hands := make([]string, 0, 4)
+	seat := deal.Dealer
+	for i := 0; i < 4; i++ {
+		hands = append(hands, PBNHand(deal.Hands[seat]))
+		seat = seat.Next()
+	}
+	return deal.Dealer.String() + ":" + strings.Join(hands, " ")
+}
+
+// ExportPBN writes the deals as a PBN file with one game section per board.
+func ExportPBN(event string, deals []Deal) string {
+	var b strings.Builder
+	b.WriteString("% PBN 2.1\n% EXPORT\n")
+	for _, deal := range deals {
+		board := deal.Board
+		if board <= 0 {
+			board = 1
+		}
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "[Event %q]\n", event)
+		fmt.Fprintf(&b, "[Board \"%d\"]\n", board)
+		fmt.Fprintf(&b, "[Dealer \"%v\"]\n", deal.Dealer)
+		fmt.Fprintf(&b, "[Vulnerable \"%v\"]\n", BoardVulnerability(board))
+		fmt.Fprintf(&b, "[Deal \"%s\"]\n", PBNDeal(deal))
+	}
+	return b.String()
+}
+
+// ParsePBNDeal reads a Deal tag value such as "N:AKQ.JT9.876.5432 ...".
+func ParsePBNDeal(value string) (Deal, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+	if len(parts) != 2 {
+		return Deal{}, fmt.Errorf("Invalid PBN deal %q", value)
+	}
+	first, err := ParseSeat(parts[0])
+	if err != nil {
+		return Deal{}, err
+	}
+
+	hands := strings.Fields(parts[1])
+	if len(hands) != 4 {
+		return Deal{}, fmt.Errorf("PBN deal must list four hands")
+	}
+
+	deal := Deal{Dealer: first}
+	seen := make(map[int]bool)
+	seat := first
+	for _, text := range hands {
+		suits := strings.Split(text, ".")
+		if len(suits) != 4 {
+			return Deal{}, fmt.Errorf("Invalid PBN hand %q", text)
+		}
+		for i, ranks := range suits {
+			suit := Spades - Suit(i)
+			for _, r := range ranks {
+				rank := strings.IndexRune("23456789TJQKA", r)
+				if rank < 0 {
+					return Deal{}, fmt.Errorf("Invalid PBN hand %q", text)
+				}
+				index := int(suit)*13 + rank
+				if seen[index] {
+					return Deal{}, fmt.Errorf("Card %q appears more than once", cardAt(index).Code)
+				}
+				seen[index] = true
+				deal.Hands[seat] = append(deal.Hands[seat], cardAt(index))
+			}
+		}
+		if len(deal.Hands[seat]) != 13 {
+			return Deal{}, fmt.Errorf("Hand for %v must hold 13 cards", seat)
+		}
+		seat = seat.Next()
+	}
+	return deal, nil
+}
@@ -0,0 +1,74 @@
+package bridge
+
+import (
+	"fmt"
+)
+
+// Rubber keeps a rubber bridge score sheet. Index 0 of each pair is
+// North-South and index 1 East-West. Below holds every trick score entered
+// below the line and Partscore only those of the game in progress.
+type Rubber struct {
+	Above     [2]int `json:"above"`
+	Below     [2]int `json:"below"`
+	Partscore [2]int `json:"partscore"`
+	Games     [2]int `json:"games"`
+	Finished  bool   `json:"finished"`
+}
+
+func sideOf(seat Seat) int {
+	if seat.NorthSouth() {
+		return 0
+	}
+	return 1
+}
+
+// Vulnerability follows from the games each side has won.
+func (r *Rubber) Vulnerability() Vulnerability {
+	switch {
+	case r.Games[0] > 0 && r.Games[1] > 0:
+		return VulnerableBoth
+	case r.Games[0] > 0:
+		return VulnerableNS
+	case r.Games[1] > 0:
+		return VulnerableEW
+	}
+	return VulnerableNone
+}
+
+// Record enters a played contract. The rubber ends, with a bonus of 700 or
+// 500, when a side wins its second game.
+func (r *Rubber) Record(contract Contract, tricks int) error {
+	if r.Finished {
+		return fmt.Errorf("Rubber is finished")
+	}
+	if contract.Level == 0 {
+		return nil
+	}
+
+	side := sideOf(contract.Declarer)
+	vulnerable := r.Games[side] > 0
+	if tricks < contract.Level+6 {
+		r.Above[1-side] += contract.penalty(contract.Level+6-tricks, vulnerable)
+		return nil
+	}
+
+	points := contract.trickScore()
+	r.Below[side] += points
+	r.Partscore[side] += points
+	r.Above[side] += contract.bonus(tricks, vulnerable)
+
+	if r.Partscore[side] >= 100 {
+		r.Games[side]++
+		r.Partscore = [2]int{}
+		if r.Games[side] == 2 {
+			r.Above[side] += pick(r.Games[1-side] == 0, 700, 500)
+			r.Finished = true
+		}
+	}
+	return nil
+}
+
+// Total returns each side's points so far.
+func (r *Rubber) Total() [2]int {
+	return [2]int{r.Above[0] + r.Below[0], r.Above[1] + r.Below[1]}
+}
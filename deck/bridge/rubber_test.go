@@ -0,0 +1,41 @@
+package bridge
+
+import "testing"
+
+func TestRubber(t *testing.T) {
+	var rubber Rubber
+	record := func(text string, tricks int) {
+		contract, _ := ParseContract(text)
+		if err := rubber.Record(contract, tricks); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	record("2H-N", 9)
+	if rubber.Below[0] != 60 || rubber.Above[0] != 30 || rubber.Games[0] != 0 {
+		t.Errorf("Unexpected part score: %+v", rubber)
+	}
+
+	record("2S-S", 8)
+	if rubber.Games[0] != 1 || rubber.Partscore[0] != 0 || rubber.Vulnerability() != VulnerableNS {
+		t.Errorf("Two part scores should make a game: %+v", rubber)
+	}
+
+	record("3NTX-E", 7)
+	if rubber.Above[0] != 30+300 {
+		t.Errorf("Penalty not scored above the line: %+v", rubber)
+	}
+
+	record("4S-N", 10)
+	if !rubber.Finished || rubber.Above[0] != 30+300+700 {
+		t.Errorf("Rubber should be won 2-0 with a 700 bonus: %+v", rubber)
+	}
+	if total := rubber.Total(); total[0] != 60+60+120+1030 || total[1] != 0 {
+		t.Errorf("Unexpected totals: %v", total)
+	}
+
+	contract, _ := ParseContract("1C-E")
+	if err := rubber.Record(contract, 7); err == nil {
+		t.Errorf("Record accepted a contract after the rubber finished")
+	}
+}
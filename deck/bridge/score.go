@@ -0,0 +1,146 @@
+package bridge
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Contract struct {
+	Level    int  `json:"level"`
+	Strain   Suit `json:"strain"`
+	Doubled  int  `json:"doubled"`
+	Declarer Seat `json:"declarer"`
+}
+
+func (c Contract) String() string {
+	if c.Level == 0 {
+		return "Pass"
+	}
+	return strconv.Itoa(c.Level) + c.Strain.String() + strings.Repeat("X", c.Doubled) + "-" + c.Declarer.String()
+}
+
+func (c Contract) trickValue(trick int) int {
+	switch c.Strain {
+	case Clubs, Diamonds:
+		return 20
+	case NoTrump:
+		if trick == 1 {
+			return 40
+		}
+	}
+	return 30
+}
+
+// Score returns the duplicate score for the declaring side when declarer takes
+// tricks tricks. Defeated contracts score negative.
+func (c Contract) Score(tricks int, vulnerable bool) int {
+	if c.Level == 0 {
+		return 0
+	}
+	if tricks < c.Level+6 {
+		return -c.penalty(c.Level+6-tricks, vulnerable)
+	}
+
+	points := c.trickScore()
+	score := points + c.bonus(tricks, vulnerable)
+	if points >= 100 {
+		score += pick(vulnerable, 500, 300)
+	} else {
+		score += 50
+	}
+	return score
+}
+
+// trickScore is the value of the contracted tricks, the part that counts
+// towards game.
+func (c Contract) trickScore() int {
+	points := 0
+	for trick := 1; trick <= c.Level; trick++ {
+		points += c.trickValue(trick) << uint(c.Doubled)
+	}
+	return points
+}
+
+// bonus adds up the overtricks, slam bonus and the bonus for making a doubled
+// contract. Duplicate and rubber bridge score these the same way.
+func (c Contract) bonus(tricks int, vulnerable bool) int {
+	score := 50 * c.Doubled
+	switch c.Level {
+	case 6:
+		score += pick(vulnerable, 750, 500)
+	case 7:
+		score += pick(vulnerable, 1500, 1000)
+	}
+
+	over := tricks - c.Level - 6
+	if c.Doubled == 0 {
+		score += over * c.trickValue(2)
+	} else {
+		score += over * pick(vulnerable, 200, 100) * c.Doubled
+	}
+	return score
+}
+
+func (c Contract) penalty(down int, vulnerable bool) int {
+	if c.Doubled == 0 {
+		return down * pick(vulnerable, 100, 50)
+	}
+
+	total := 0
+	for i := 1; i <= down; i++ {
+		switch {
+		case vulnerable && i == 1:
+			total += 200
+		case vulnerable:
+			total += 300
+		case i == 1:
+			total += 100
+		case i <= 3:
+			total += 200
+		default:
+			total += 300
+		}
+	}
+	return total * c.Doubled
+}
+
+func pick(vulnerable bool, yes, no int) int {
+	if vulnerable {
+		return yes
+	}
+	return no
+}
+
+// ParseContract reads contracts such as "3NT-S", "4SX-E" or "Pass".
+func ParseContract(text string) (Contract, error) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	if text == "PASS" || text == "P" {
+		return Contract{}, nil
+	}
+
+	parts := strings.Split(text, "-")
+	if len(parts) != 2 || len(parts[0]) < 2 {
+		return Contract{}, fmt.Errorf("Invalid contract %q", text)
+	}
+
+	declarer, err := ParseSeat(parts[1])
+	if err != nil {
+		return Contract{}, err
+	}
+	bid := strings.TrimRight(parts[0], "X")
+	level, err := strconv.Atoi(bid[:1])
+	if err != nil || level < 1 || level > 7 {
+		return Contract{}, fmt.Errorf("Invalid contract %q", text)
+	}
+	strain, err := ParseSuit(bid[1:])
+	if err != nil {
+		return Contract{}, fmt.Errorf("Invalid contract %q", text)
+	}
+	doubled := len(parts[0]) - len(bid)
+	if doubled > 2 {
+		return Contract{}, fmt.Errorf("Invalid contract %q", text)
+	}
+
+	return Contract{Level: level, Strain: strain, Doubled: doubled, Declarer: declarer}, nil
+}
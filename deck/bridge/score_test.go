@@ -0,0 +1,42 @@
+package bridge
+
+import "testing"
+
+func TestContractScore(t *testing.T) {
+	tests := []struct {
+		contract   string
+		tricks     int
+		vulnerable bool
+		want       int
+	}{
+		{"1NT-N", 7, false, 90},
+		{"3NT-S", 10, false, 430},
+		{"3NT-S", 9, true, 600},
+		{"4S-E", 11, true, 650},
+		{"2HX-W", 8, false, 470},
+		{"1CXX-N", 7, false, 230},
+		{"6H-N", 12, true, 1430},
+		{"7NT-S", 13, false, 1520},
+		{"3NT-S", 7, false, -100},
+		{"4SX-E", 7, false, -500},
+		{"4SX-E", 7, true, -800},
+		{"5DXX-N", 8, false, -1000},
+		{"Pass", 0, false, 0},
+	}
+
+	for _, test := range tests {
+		contract, err := ParseContract(test.contract)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := contract.Score(test.tricks, test.vulnerable); got != test.want {
+			t.Errorf("Score(%v, %v tricks, vulnerable %v): got %v want %v", test.contract, test.tricks, test.vulnerable, got, test.want)
+		}
+	}
+
+	for _, text := range []string{"8NT-N", "3NT", "3ZX-N", "3NTXXX-S"} {
+		if _, err := ParseContract(text); err == nil {
+			t.Errorf("ParseContract(%q) accepted an invalid contract", text)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package capability
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Right is what a token lets its bearer do.
+type Right string
+
+const (
+	// Read shows a deck, or a game as a spectator sees it.
+	Read Right = "read"
+	// Draw reads a deck and draws up to Limit cards from it.
+	Draw Right = "draw"
+	// Seat plays a game as the player in seat Seat.
+	Seat Right = "seat"
+	// Commentate watches a game or deck with every hand shown, after the
+	// broadcast delay.
+	Commentate Right = "commentate"
+)
+
+// MaxTTL is the longest a token may last.
+const MaxTTL = 30 * 24 * time.Hour
+
+// Claims are what a token grants, on exactly one deck or one game.
+type Claims struct {
+	ID       string     `json:"jti"`
+	Deck     *uuid.UUID `json:"deck,omitempty"`
+	GameType string     `json:"type,omitempty"`
+	Game     *uuid.UUID `json:"game,omitempty"`
+	Right    Right      `json:"right"`
+	Limit    int        `json:"limit,omitempty"`
+	// Seat is the player's position in the game's players, from 0.
+	Seat      int   `json:"seat,omitempty"`
+	IssuedAt  int64 `json:"iat"`
+	ExpiresAt int64 `json:"exp"`
+}
+
+// Validate checks that the claims name one resource and a right it
+// supports.
+func (c Claims) Validate() error {
+	if (c.Deck == nil) == (c.Game == nil) {
+		return fmt.Errorf("A token is for one deck or one game")
+	}
+	if c.Game != nil && c.GameType == "" {
+		return fmt.Errorf("A game token needs the game type")
+	}
+	switch c.Right {
+	case Read, Commentate:
+	case Draw:
+		if c.Deck == nil || c.Limit < 1 {
+			return fmt.Errorf("Draw tokens are for a deck, with a positive limit")
+		}
+	case Seat:
+		if c.Game == nil || c.Seat < 0 {
+			return fmt.Errorf("Seat tokens are for a game, with a seat from 0")
+		}
+	default:
+		return fmt.Errorf("Unknown right %q", c.Right)
+	}
+	return nil
+}
+
+// Expires returns when the token stops working.
+func (c Claims) Expires() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+// ForDeck reports whether the claims let the bearer use deckID with right.
+// Draw and commentate tokens may also read.
+func (c Claims) ForDeck(deckID uuid.UUID, right Right) bool {
+	if c.Deck == nil || *c.Deck != deckID {
+		return false
+	}
+	return c.Right == right || (right == Read && (c.Right == Draw || c.Right == Commentate))
+}
+
+// ForGame reports whether the claims are for the game gameID.
+func (c Claims) ForGame(gameType string, gameID uuid.UUID) bool {
+	return c.Game != nil && *c.Game == gameID && c.GameType == gameType
+}
@@ -0,0 +1,58 @@
+package capability
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestClaimsValidate(t *testing.T) {
+	deck, game := uuid.New(), uuid.New()
+	valid := []Claims{
+		{Deck: &deck, Right: Read},
+		{Deck: &deck, Right: Draw, Limit: 5},
+		{Game: &game, GameType: "war", Right: Read},
+		{Game: &game, GameType: "war", Right: Seat, Seat: 1},
+		{Game: &game, GameType: "war", Right: Commentate},
+	}
+	for _, c := range valid {
+		if err := c.Validate(); err != nil {
+			t.Errorf("%+v rejected: %v", c, err)
+		}
+	}
+	invalid := []Claims{
+		{Right: Read},
+		{Deck: &deck, Game: &game, GameType: "war", Right: Read},
+		{Game: &game, Right: Read},
+		{Deck: &deck, Right: Draw},
+		{Game: &game, GameType: "war", Right: Draw, Limit: 1},
+		{Deck: &deck, Right: Seat},
+		{Game: &game, GameType: "war", Right: Seat, Seat: -1},
+		{Deck: &deck, Right: "delete"},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("%+v accepted", c)
+		}
+	}
+}
+
+func TestClaimsFor(t *testing.T) {
+	deck, game := uuid.New(), uuid.New()
+	draw := Claims{Deck: &deck, Right: Draw, Limit: 2}
+	if !draw.ForDeck(deck, Draw) || !draw.ForDeck(deck, Read) || draw.ForDeck(uuid.New(), Read) {
+		t.Errorf("Draw tokens should read and draw their own deck only")
+	}
+	read := Claims{Deck: &deck, Right: Read}
+	if read.ForDeck(deck, Draw) {
+		t.Errorf("Read tokens should not draw")
+	}
+	commentate := Claims{Deck: &deck, Right: Commentate}
+	if !commentate.ForDeck(deck, Read) || !commentate.ForDeck(deck, Commentate) || commentate.ForDeck(deck, Draw) {
+		t.Errorf("Commentate tokens should read their deck but not draw")
+	}
+	seat := Claims{Game: &game, GameType: "war", Right: Seat}
+	if !seat.ForGame("war", game) || seat.ForGame("speed", game) || seat.ForDeck(deck, Read) {
+		t.Errorf("Seat tokens should only be for their game")
+	}
+}
@@ -0,0 +1,35 @@
+package capability
+
+import "time"
+
+// Revocations lists revoked token IDs until the tokens would have expired
+// anyway. It is not safe for concurrent use.
+type Revocations struct {
+	ids map[string]time.Time
+}
+
+func NewRevocations() *Revocations {
+	return &Revocations{ids: make(map[string]time.Time)}
+}
+
+func (r *Revocations) Revoke(claims Claims) {
+	r.ids[claims.ID] = claims.Expires()
+}
+
+func (r *Revocations) Revoked(id string) bool {
+	_, ok := r.ids[id]
+	return ok
+}
+
+// Prune forgets tokens that have expired.
+func (r *Revocations) Prune(now time.Time) {
+	for id, expires := range r.ids {
+		if !now.Before(expires) {
+			delete(r.ids, id)
+		}
+	}
+}
+
+func (r *Revocations) Len() int {
+	return len(r.ids)
+}
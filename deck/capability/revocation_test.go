@@ -0,0 +1,21 @@
+package capability
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevocations(t *testing.T) {
+	revocations := NewRevocations()
+	now := time.Unix(1000, 0)
+	revocations.Revoke(Claims{ID: "a", ExpiresAt: now.Add(time.Minute).Unix()})
+	revocations.Revoke(Claims{ID: "b", ExpiresAt: now.Add(time.Hour).Unix()})
+	if !revocations.Revoked("a") || revocations.Revoked("c") {
+		t.Errorf("Unexpected revocations")
+	}
+
+	revocations.Prune(now.Add(time.Minute))
+	if revocations.Revoked("a") || !revocations.Revoked("b") || revocations.Len() != 1 {
+		t.Errorf("Prune should drop only expired tokens")
+	}
+}
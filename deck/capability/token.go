@@ -0,0 +1,90 @@
+package capability
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("Invalid capability token")
+	ErrExpired      = errors.New("Capability token has expired")
+	ErrRevoked      = errors.New("Capability token has been revoked")
+)
+
+// Signer signs tokens with an HMAC-SHA256 key. A token is its claims as
+// JSON and their signature, each base64url encoded and joined by a dot.
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// NewKey returns a random signing key.
+func NewKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// NewID returns a random token ID.
+func NewID() string {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func (s *Signer) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Signer) Sign(claims Claims) (string, error) {
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	return payload + "." + s.sign(payload), nil
+}
+
+// Parse checks a token's signature and returns its claims, expired or not.
+func (s *Signer) Parse(token string) (Claims, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return Claims{}, ErrInvalidToken
+	}
+	body, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(body, &claims); err != nil || claims.Validate() != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// Verify parses a token and checks that it has not expired.
+func (s *Signer) Verify(token string, now time.Time) (Claims, error) {
+	claims, err := s.Parse(token)
+	if err != nil {
+		return Claims{}, err
+	}
+	if !now.Before(claims.Expires()) {
+		return Claims{}, ErrExpired
+	}
+	return claims, nil
+}
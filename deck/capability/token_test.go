@@ -0,0 +1,47 @@
+package capability
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner(NewKey())
+	deck := uuid.New()
+	now := time.Unix(1000, 0)
+	claims := Claims{ID: NewID(), Deck: &deck, Right: Draw, Limit: 3, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+	token, err := signer.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verified, err := signer.Verify(token, now)
+	if err != nil || verified.ID != claims.ID || *verified.Deck != deck || verified.Limit != 3 {
+		t.Fatalf("Verify failed: %+v %v", verified, err)
+	}
+	if _, err := signer.Verify(token, now.Add(time.Hour)); err != ErrExpired {
+		t.Errorf("Expired token accepted: %v", err)
+	}
+	if _, err := signer.Parse(token); err != nil {
+		t.Errorf("Parse should accept expired tokens: %v", err)
+	}
+
+	if _, err := NewSigner(NewKey()).Verify(token, now); err != ErrInvalidToken {
+		t.Errorf("Token signed with another key accepted")
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+	claims.Limit = 52
+	forged, _ := signer.Sign(claims)
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+	if _, err := signer.Verify(forgedPayload+"."+signature, now); err != ErrInvalidToken {
+		t.Errorf("Token with altered claims accepted")
+	}
+	for _, bad := range []string{"", payload, payload + ".", "." + signature, "x.y"} {
+		if _, err := signer.Verify(bad, now); err != ErrInvalidToken {
+			t.Errorf("Malformed token %q accepted", bad)
+		}
+	}
+}
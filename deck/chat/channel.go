@@ -0,0 +1,55 @@
+package chat
+
+import "time"
+
+// Channel is one chat's messages and who may not post in or read it. It is
+// not safe for concurrent use.
+type Channel struct {
+	Log *Log
+	// muted players may read until their mute ends; banned ones may do
+	// nothing until unbanned.
+	muted  map[string]time.Time
+	banned map[string]bool
+}
+
+func NewChannel(history int) *Channel {
+	return &Channel{Log: NewLog(history), muted: make(map[string]time.Time), banned: make(map[string]bool)}
+}
+
+func (c *Channel) Mute(player string, until time.Time) {
+	c.muted[player] = until
+}
+
+func (c *Channel) Unmute(player string) {
+	delete(c.muted, player)
+}
+
+func (c *Channel) Ban(player string) {
+	c.banned[player] = true
+}
+
+func (c *Channel) Unban(player string) {
+	delete(c.banned, player)
+}
+
+// CanRead checks that player is not banned.
+func (c *Channel) CanRead(player string) error {
+	if c.banned[player] {
+		return ErrBanned
+	}
+	return nil
+}
+
+// CanPost checks that player is neither banned nor muted at now.
+func (c *Channel) CanPost(player string, now time.Time) error {
+	if err := c.CanRead(player); err != nil {
+		return err
+	}
+	if until, ok := c.muted[player]; ok {
+		if now.Before(until) {
+			return ErrMuted
+		}
+		delete(c.muted, player)
+	}
+	return nil
+}
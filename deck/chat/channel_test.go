@@ -0,0 +1,36 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChannel(t *testing.T) {
+	c := NewChannel(10)
+	now := time.Unix(0, 0)
+
+	c.Mute("ann", now.Add(time.Minute))
+	if err := c.CanPost("ann", now); err != ErrMuted {
+		t.Errorf("Muted player posted: %v", err)
+	}
+	if err := c.CanRead("ann"); err != nil {
+		t.Errorf("Muted player cannot read: %v", err)
+	}
+	if err := c.CanPost("ann", now.Add(time.Minute)); err != nil {
+		t.Errorf("Mute should end: %v", err)
+	}
+	c.Mute("ann", now.Add(time.Hour))
+	c.Unmute("ann")
+	if err := c.CanPost("ann", now); err != nil {
+		t.Errorf("Unmuted player cannot post: %v", err)
+	}
+
+	c.Ban("bob")
+	if c.CanRead("bob") != ErrBanned || c.CanPost("bob", now) != ErrBanned {
+		t.Errorf("Banned player may still chat")
+	}
+	c.Unban("bob")
+	if c.CanPost("bob", now) != nil {
+		t.Errorf("Unbanned player cannot post")
+	}
+}
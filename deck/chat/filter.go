@@ -0,0 +1,44 @@
+package chat
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Filter moderates a message before it is posted, returning the text to
+// post in its place or an error to refuse it.
+type Filter interface {
+	Filter(player, text string) (string, error)
+}
+
+// WordList is a Filter that masks listed words, matched whole and ignoring
+// case, or with Block refuses messages containing them.
+type WordList struct {
+	pattern *regexp.Regexp
+	Block   bool
+}
+
+func NewWordList(words []string) *WordList {
+	quoted := []string{}
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return &WordList{}
+	}
+	return &WordList{pattern: regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)}
+}
+
+func (l *WordList) Filter(player, text string) (string, error) {
+	if l.pattern == nil || !l.pattern.MatchString(text) {
+		return text, nil
+	}
+	if l.Block {
+		return "", ErrBlocked
+	}
+	return l.pattern.ReplaceAllStringFunc(text, func(word string) string {
+		return strings.Repeat("*", len([]rune(word)))
+	}), nil
+}
@@ -0,0 +1,21 @@
+package chat
+
+import "testing"
+
+func TestWordList(t *testing.T) {
+	list := NewWordList([]string{"darn", " heck ", ""})
+	if text, err := list.Filter("ann", "Darn it, what the HECK"); text != "**** it, what the ****" || err != nil {
+		t.Errorf("Filter = %q %v", text, err)
+	}
+	if text, _ := list.Filter("ann", "darning socks"); text != "darning socks" {
+		t.Errorf("Only whole words should be masked: %q", text)
+	}
+
+	list.Block = true
+	if _, err := list.Filter("ann", "darn"); err != ErrBlocked {
+		t.Errorf("Blocking list returned %v", err)
+	}
+	if text, err := NewWordList(nil).Filter("ann", "anything"); text != "anything" || err != nil {
+		t.Errorf("Empty list changed the message: %q %v", text, err)
+	}
+}
@@ -0,0 +1,42 @@
+package chat
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows each player Messages messages in any window of Per.
+type Limiter struct {
+	mu       sync.Mutex
+	Messages int
+	Per      time.Duration
+	sent     map[string][]time.Time
+}
+
+func NewLimiter(messages int, per time.Duration) *Limiter {
+	return &Limiter{Messages: messages, Per: per, sent: make(map[string][]time.Time)}
+}
+
+// DefaultLimiter allows five messages every ten seconds.
+func DefaultLimiter() *Limiter {
+	return NewLimiter(5, 10*time.Second)
+}
+
+// Allow records a message from player at now, unless they have already
+// sent their allowance in the window ending then.
+func (l *Limiter) Allow(player string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	recent := []time.Time{}
+	for _, t := range l.sent[player] {
+		if now.Sub(t) < l.Per {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= l.Messages {
+		l.sent[player] = recent
+		return false
+	}
+	l.sent[player] = append(recent, now)
+	return true
+}
@@ -0,0 +1,26 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(2, 10*time.Second)
+	start := time.Unix(0, 0)
+	if !l.Allow("ann", start) || !l.Allow("ann", start.Add(time.Second)) {
+		t.Fatalf("Allowance refused")
+	}
+	if l.Allow("ann", start.Add(2*time.Second)) {
+		t.Errorf("Third message in the window allowed")
+	}
+	if !l.Allow("bob", start.Add(2*time.Second)) {
+		t.Errorf("Another player was limited")
+	}
+	if !l.Allow("ann", start.Add(10*time.Second)) {
+		t.Errorf("First message left the window but ann is still limited")
+	}
+	if l.Allow("ann", start.Add(10*time.Second)) {
+		t.Errorf("Allowance exceeded")
+	}
+}
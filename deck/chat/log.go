@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultHistory is how many messages a channel keeps.
+const DefaultHistory = 500
+
+// MaxLength is the longest message, in characters.
+const MaxLength = 500
+
+var (
+	ErrMuted       = errors.New("You are muted in this channel")
+	ErrBanned      = errors.New("You are banned from this channel")
+	ErrRateLimited = errors.New("Too many messages; slow down")
+	ErrBlocked     = errors.New("Message blocked by the moderation filter")
+)
+
+// Message is one chat line. Seq numbers a channel's messages from 1.
+type Message struct {
+	Seq    int       `json:"seq"`
+	Time   time.Time `json:"time"`
+	Player string    `json:"player"`
+	Text   string    `json:"text"`
+}
+
+// CheckText trims a message and checks its length.
+func CheckText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("Message is empty")
+	}
+	if utf8.RuneCountInString(text) > MaxLength {
+		return "", fmt.Errorf("Message is longer than %v characters", MaxLength)
+	}
+	return text, nil
+}
+
+// Log keeps the latest messages of a channel in a ring. It is not safe for
+// concurrent use.
+type Log struct {
+	messages []Message
+	// next is where the next message goes, and count how many are kept.
+	next, count int
+	latest      int
+}
+
+func NewLog(size int) *Log {
+	if size < 1 {
+		size = DefaultHistory
+	}
+	return &Log{messages: make([]Message, size)}
+}
+
+// Append numbers m and adds it.
+func (l *Log) Append(m Message) Message {
+	l.latest++
+	m.Seq = l.latest
+	l.messages[l.next] = m
+	l.next = (l.next + 1) % len(l.messages)
+	if l.count < len(l.messages) {
+		l.count++
+	}
+	return m
+}
+
+func (l *Log) at(i int) Message {
+	first := (l.next - l.count + len(l.messages)) % len(l.messages)
+	return l.messages[(first+i)%len(l.messages)]
+}
+
+// Since returns up to limit messages after seq, oldest first. It reports
+// false if some of them are no longer kept.
+func (l *Log) Since(seq, limit int) ([]Message, bool) {
+	messages := []Message{}
+	for i := 0; i < l.count && len(messages) < limit; i++ {
+		if m := l.at(i); m.Seq > seq {
+			messages = append(messages, m)
+		}
+	}
+	return messages, l.count == 0 || l.at(0).Seq <= seq+1
+}
+
+// Before returns up to limit messages before seq, oldest first, for paging
+// back through the history. A seq of 0 pages back from the latest.
+func (l *Log) Before(seq, limit int) []Message {
+	if seq <= 0 {
+		seq = l.latest + 1
+	}
+	end := 0
+	for end < l.count && l.at(end).Seq < seq {
+		end++
+	}
+	start := end - limit
+	if start < 0 {
+		start = 0
+	}
+	messages := []Message{}
+	for i := start; i < end; i++ {
+		messages = append(messages, l.at(i))
+	}
+	return messages
+}
@@ -0,0 +1,50 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckText(t *testing.T) {
+	if text, err := CheckText("  gg  "); text != "gg" || err != nil {
+		t.Errorf("CheckText = %q %v", text, err)
+	}
+	if _, err := CheckText(" "); err == nil {
+		t.Errorf("Empty message accepted")
+	}
+	if _, err := CheckText(strings.Repeat("é", MaxLength+1)); err == nil {
+		t.Errorf("Long message accepted")
+	}
+}
+
+func TestLog(t *testing.T) {
+	l := NewLog(3)
+	if messages, complete := l.Since(0, 10); len(messages) != 0 || !complete {
+		t.Errorf("Empty log: %v %v", messages, complete)
+	}
+	for i := 0; i < 5; i++ {
+		if m := l.Append(Message{Player: "ann", Text: "hi"}); m.Seq != i+1 {
+			t.Errorf("Message %v numbered %v", i+1, m.Seq)
+		}
+	}
+
+	if messages, complete := l.Since(2, 10); !complete || len(messages) != 3 || messages[0].Seq != 3 {
+		t.Errorf("Since(2) = %v %v", messages, complete)
+	}
+	if messages, complete := l.Since(2, 2); len(messages) != 2 || messages[1].Seq != 4 || !complete {
+		t.Errorf("Since(2) limited = %v %v", messages, complete)
+	}
+	if _, complete := l.Since(1, 10); complete {
+		t.Errorf("Since(1) should report the dropped message 2")
+	}
+
+	if page := l.Before(0, 2); len(page) != 2 || page[0].Seq != 4 || page[1].Seq != 5 {
+		t.Errorf("Before(0) = %v", page)
+	}
+	if page := l.Before(4, 2); len(page) != 1 || page[0].Seq != 3 {
+		t.Errorf("Before(4) = %v", page)
+	}
+	if page := l.Before(3, 2); len(page) != 0 {
+		t.Errorf("Before(3) = %v", page)
+	}
+}
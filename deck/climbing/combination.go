@@ -0,0 +1,185 @@
+package climbing
+
+import (
+	"fmt"
+	"sort"
+
+	"cardGame/deck/model"
+)
+
+// Order ranks cards from lowest to highest. Suits break ties between cards
+// of the same rank; with no suits listed, equal ranks never beat each other.
+type Order struct {
+	Ranks []string
+	Suits []string
+}
+
+var twoHigh = []string{"3", "4", "5", "6", "7", "8", "9", "10", "JACK", "QUEEN", "KING", "ACE", "2"}
+
+// BigTwoOrder puts the two on top and ranks diamonds, clubs, hearts, spades.
+var BigTwoOrder = Order{Ranks: twoHigh, Suits: []string{"DIAMONDS", "CLUBS", "HEARTS", "SPADES"}}
+
+// PresidentOrder puts the two on top and ignores suits.
+var PresidentOrder = Order{Ranks: twoHigh}
+
+func (o Order) rank(c model.Card) int {
+	for i, value := range o.Ranks {
+		if c.Value == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o Order) suit(c model.Card) int {
+	for i, suit := range o.Suits {
+		if c.Suit == suit {
+			return i
+		}
+	}
+	return 0
+}
+
+func (o Order) less(a, b model.Card) bool {
+	if ra, rb := o.rank(a), o.rank(b); ra != rb {
+		return ra < rb
+	}
+	return o.suit(a) < o.suit(b)
+}
+
+// Sort orders cards from lowest to highest.
+func (o Order) Sort(cards []model.Card) {
+	sort.SliceStable(cards, func(i, j int) bool { return o.less(cards[i], cards[j]) })
+}
+
+type Kind string
+
+const (
+	Single        Kind = "single"
+	Pair          Kind = "pair"
+	Triple        Kind = "triple"
+	Quad          Kind = "quad"
+	Straight      Kind = "straight"
+	Flush         Kind = "flush"
+	FullHouse     Kind = "full_house"
+	FourOfAKind   Kind = "four_of_a_kind"
+	StraightFlush Kind = "straight_flush"
+	Bomb          Kind = "bomb"
+)
+
+// fiveCardClass orders the five-card hands.
+var fiveCardClass = map[Kind]int{Straight: 1, Flush: 2, FullHouse: 3, FourOfAKind: 4, StraightFlush: 5}
+
+// Rules describe one climbing game.
+type Rules struct {
+	Order Order
+	// FiveCardHands allows straights, flushes, full houses, four of a kind
+	// with a kicker and straight flushes, as in Big Two.
+	FiveCardHands bool
+	// Bombs makes four cards of a rank beat any play that is not a higher
+	// bomb.
+	Bombs bool
+}
+
+var BigTwoRules = Rules{Order: BigTwoOrder, FiveCardHands: true}
+var PresidentRules = Rules{Order: PresidentOrder}
+
+// Combination is a classified play. Its key card, the one compared against
+// another play of the same kind, is unexported.
+type Combination struct {
+	Kind  Kind         `json:"kind"`
+	Cards []model.Card `json:"cards"`
+	key   model.Card
+}
+
+// Classify names the combination cards form under rules.
+func Classify(cards []model.Card, rules Rules) (Combination, error) {
+	order := rules.Order
+	sorted := append([]model.Card{}, cards...)
+	order.Sort(sorted)
+	for _, c := range sorted {
+		if order.rank(c) < 0 {
+			return Combination{}, fmt.Errorf("Invalid card %q", c.Code)
+		}
+	}
+
+	combination := Combination{Cards: sorted}
+	if len(sorted) == 0 {
+		return Combination{}, fmt.Errorf("No cards played")
+	}
+	combination.key = sorted[len(sorted)-1]
+
+	sameRank := order.rank(sorted[0]) == order.rank(sorted[len(sorted)-1])
+	switch {
+	case len(sorted) == 1:
+		combination.Kind = Single
+	case len(sorted) == 2 && sameRank:
+		combination.Kind = Pair
+	case len(sorted) == 3 && sameRank:
+		combination.Kind = Triple
+	case len(sorted) == 4 && sameRank && rules.Bombs:
+		combination.Kind = Bomb
+	case len(sorted) == 4 && sameRank && !rules.FiveCardHands:
+		combination.Kind = Quad
+	case len(sorted) == 5 && rules.FiveCardHands:
+		kind, key, ok := classifyFive(sorted, order)
+		if !ok {
+			return Combination{}, fmt.Errorf("Not a valid combination")
+		}
+		combination.Kind, combination.key = kind, key
+	default:
+		return Combination{}, fmt.Errorf("Not a valid combination")
+	}
+	return combination, nil
+}
+
+func classifyFive(sorted []model.Card, order Order) (Kind, model.Card, bool) {
+	flush, straight := true, true
+	counts := make(map[int][]model.Card)
+	for i, c := range sorted {
+		counts[order.rank(c)] = append(counts[order.rank(c)], c)
+		if c.Suit != sorted[0].Suit {
+			flush = false
+		}
+		if i > 0 && order.rank(c) != order.rank(sorted[i-1])+1 {
+			straight = false
+		}
+	}
+
+	top := sorted[4]
+	switch {
+	case straight && flush:
+		return StraightFlush, top, true
+	case len(counts) == 2:
+		for _, group := range counts {
+			switch len(group) {
+			case 4:
+				return FourOfAKind, group[3], true
+			case 3:
+				return FullHouse, group[2], true
+			}
+		}
+	case flush:
+		return Flush, top, true
+	case straight:
+		return Straight, top, true
+	}
+	return "", model.Card{}, false
+}
+
+// Beats reports whether play may be laid on top of current.
+func (play Combination) Beats(current Combination, rules Rules) bool {
+	if play.Kind == Bomb && current.Kind != Bomb {
+		return true
+	}
+	if current.Kind == Bomb && play.Kind != Bomb {
+		return false
+	}
+	if len(play.Cards) != len(current.Cards) {
+		return false
+	}
+	if play.Kind != current.Kind {
+		return fiveCardClass[play.Kind] > fiveCardClass[current.Kind]
+	}
+	return rules.Order.less(current.key, play.key)
+}
@@ -0,0 +1,109 @@
+package climbing
+
+import (
+	"fmt"
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+func cards(codes string) []model.Card {
+	return model.NewDeck(false, codes).Cards
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		codes string
+		rules Rules
+		kind  Kind
+	}{
+		{"7H", BigTwoRules, Single},
+		{"7H,7S", BigTwoRules, Pair},
+		{"7H,7S,7D", PresidentRules, Triple},
+		{"7H,7S,7D,7C", PresidentRules, Quad},
+		{"7H,7S,7D,7C", Rules{Order: PresidentOrder, Bombs: true}, Bomb},
+		{"3D,4C,5C,6H,7S", BigTwoRules, Straight},
+		{"1D,JC,QC,KH,AS", BigTwoRules, Straight},
+		{"JD,QC,KC,AH,2S", BigTwoRules, Straight},
+		{"3H,8H,JH,KH,2H", BigTwoRules, Flush},
+		{"9D,9H,9S,4C,4D", BigTwoRules, FullHouse},
+		{"9D,9H,9S,9C,4D", BigTwoRules, FourOfAKind},
+		{"5S,6S,7S,8S,9S", BigTwoRules, StraightFlush},
+	}
+	for _, test := range tests {
+		combination, err := Classify(cards(test.codes), test.rules)
+		if err != nil || combination.Kind != test.kind {
+			t.Errorf("Classify(%v) = %v, %v; want %v", test.codes, combination.Kind, err, test.kind)
+		}
+	}
+
+	invalid := []struct {
+		codes string
+		rules Rules
+	}{
+		{"7H,8H", BigTwoRules},
+		{"7H,7S,7D,7C", BigTwoRules},
+		{"3D,4C,5C,6H,7S", PresidentRules},
+		// Ace to five is not a straight when the two ranks above the ace.
+		{"AD,2C,3C,4H,5S", BigTwoRules},
+		{"9D,9H,9S,4C,5D", BigTwoRules},
+	}
+	for _, test := range invalid {
+		if combination, err := Classify(cards(test.codes), test.rules); err == nil {
+			t.Errorf("Classify(%v) accepted %v", test.codes, combination.Kind)
+		}
+	}
+}
+
+func TestBeats(t *testing.T) {
+	bombs := Rules{Order: PresidentOrder, Bombs: true}
+	tests := []struct {
+		play, current string
+		rules         Rules
+		beats         bool
+	}{
+		{"2D", "AS", BigTwoRules, true},
+		{"7S", "7H", BigTwoRules, true},
+		{"7H", "7S", BigTwoRules, false},
+		{"7S", "7H", PresidentRules, false},
+		{"8C,8D", "7H,7S", PresidentRules, true},
+		{"8C", "7H,7S", PresidentRules, false},
+		{"2C,2D", "AH,AS", PresidentRules, true},
+		{"3H,3S,3D,3C", "2H", bombs, true},
+		{"2H,2S", "3H,3S,3D,3C", bombs, false},
+		{"4H,4S,4D,4C", "3H,3S,3D,3C", bombs, true},
+		// Five-card hands rank straight, flush, full house, four of a kind,
+		// straight flush.
+		{"3H,8H,JH,KH,4H", "1D,JC,QC,KH,AS", BigTwoRules, true},
+		{"3D,3H,3S,4C,4D", "3C,8C,JC,KC,2C", BigTwoRules, true},
+		{"3D,4D,5D,6D,7D", "2D,2H,2S,2C,4D", BigTwoRules, true},
+		{"4D,5C,6C,7H,8S", "4C,5C,6H,7S,8D", BigTwoRules, true},
+		{"5D,5H,5S,3C,3D", "4D,4H,4S,AC,AD", BigTwoRules, true},
+		{"3D,4C,5C,6H,7S", "3H,8H,JH,KH,4H", BigTwoRules, false},
+	}
+	for _, test := range tests {
+		play, err := Classify(cards(test.play), test.rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		current, err := Classify(cards(test.current), test.rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := play.Beats(current, test.rules); got != test.beats {
+			t.Errorf("%v beats %v = %v, want %v", test.play, test.current, got, test.beats)
+		}
+	}
+}
+
+func TestOrderSort(t *testing.T) {
+	hand := cards("2S,3S,AH,3D,1C")
+	BigTwoOrder.Sort(hand)
+	var got []string
+	for _, c := range hand {
+		got = append(got, c.Code)
+	}
+	if want := "3D 3S 1C AH 2S"; fmt.Sprint(got) != "["+want+"]" {
+		t.Errorf("Sort = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,602 @@
+package climbing
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+// PlayedPile names the deck pile that played cards go to.
+const PlayedPile = "played"
+
+type phase string
+
+const (
+	phaseExchange phase = "exchange"
+	phasePlay     phase = "play"
+	phaseOver     phase = "over"
+)
+
+// variant holds what differs between the climbing games beyond the rules
+// for combinations.
+type variant struct {
+	name       string
+	rules      Rules
+	minPlayers int
+	maxPlayers int
+	// handSize is the number of cards dealt to each player, or 0 to deal
+	// the whole deck.
+	handSize int
+	// playOut keeps a hand going until one player is left holding cards, so
+	// that everyone has a finishing place. Otherwise the hand ends with the
+	// first player out.
+	playOut bool
+	// titles gives the finishing places President titles and makes the
+	// lowest swap their best cards with the highest at the next deal.
+	titles bool
+}
+
+// Game plays hands of a climbing game for the game registry. Each play must
+// beat the one before it with a higher combination of the same shape; once
+// everyone else passes, the last player to play leads a new round.
+type Game struct {
+	variant
+	rng     *rand.Rand
+	players []string
+	deck    model.Deck
+	phase   phase
+	hand    int
+	hands   int
+	scores  []int
+
+	held [][]model.Card
+	turn int
+	// top is the play to beat and leader the seat that made it.
+	top    *Combination
+	leader int
+	passed []bool
+	// opening is the card the first play of the hand must include.
+	opening *model.Card
+	// finished is the finishing order of the hand in play and roles the
+	// titles from the last one.
+	finished []int
+	roles    []Role
+	// owed counts the cards each seat must still hand back in the exchange.
+	owed []int
+}
+
+type View struct {
+	Hand      []model.Card      `json:"hand,omitempty"`
+	HandSizes map[string]int    `json:"hand_sizes"`
+	Phase     string            `json:"phase"`
+	Deal      int               `json:"deal"`
+	Turn      string            `json:"turn,omitempty"`
+	Top       *Combination      `json:"top,omitempty"`
+	Leader    string            `json:"leader,omitempty"`
+	Passed    []string          `json:"passed"`
+	Finished  []string          `json:"finished"`
+	Roles     map[string]string `json:"roles,omitempty"`
+	Scores    map[string]int    `json:"scores"`
+}
+
+// NewPresident plays President: the whole deck is dealt, twos are high and
+// suits don't count. Option "bombs" lets four of a kind beat anything.
+func NewPresident() game.Game {
+	return &Game{variant: variant{name: "President", rules: PresidentRules, minPlayers: 3, maxPlayers: 8, playOut: true, titles: true}}
+}
+
+// NewBigTwo plays Big Two: 13 cards each, twos high, spades the top suit and
+// five-card poker hands allowed.
+func NewBigTwo() game.Game {
+	return &Game{variant: variant{name: "Big Two", rules: BigTwoRules, minPlayers: 2, maxPlayers: 4, handSize: 13}}
+}
+
+// Setup deals the first hand from deck. The option "hands" sets how many
+// hands are played and "seed" fixes the shuffles after the first.
+func (g *Game) Setup(deck model.Deck, config game.Config) error {
+	n := len(config.Players)
+	if n < g.minPlayers || n > g.maxPlayers {
+		return fmt.Errorf("%v needs %v to %v players", g.name, g.minPlayers, g.maxPlayers)
+	}
+	if len(deck.Cards) < n*max(g.handSize, 1) {
+		return fmt.Errorf("Not enough cards to deal %v", g.name)
+	}
+	for _, c := range deck.Cards {
+		if g.rules.Order.rank(c) < 0 {
+			return fmt.Errorf("Invalid card %q", c.Code)
+		}
+	}
+
+	if bombs, err := strconv.ParseBool(config.Options["bombs"]); err == nil {
+		g.rules.Bombs = bombs
+	}
+	g.hands = 1
+	if hands, err := strconv.Atoi(config.Options["hands"]); err == nil && hands > 0 {
+		g.hands = hands
+	}
+	seed := time.Now().UnixNano()
+	if value, err := strconv.ParseInt(config.Options["seed"], 10, 64); err == nil {
+		seed = value
+	}
+	g.rng = rand.New(rand.NewSource(seed))
+
+	g.players = config.Players
+	g.scores = make([]int, n)
+	g.deck = deck
+	g.deck.Cards = append([]model.Card{}, deck.Cards...)
+	g.deck.Piles = nil
+	g.deal()
+	return nil
+}
+
+// deal hands out the next hand and works out who leads. After a President
+// hand the lowest players give up their best cards first.
+func (g *Game) deal() {
+	n := len(g.players)
+	g.hand++
+	g.held = make([][]model.Card, n)
+	g.passed = make([]bool, n)
+	g.finished, g.top, g.opening = nil, nil, nil
+
+	count := len(g.deck.Cards)
+	if g.handSize > 0 {
+		count = g.handSize * n
+	}
+	cards, _ := g.deck.DrawCards(count)
+	for i, c := range cards {
+		g.held[i%n] = append(g.held[i%n], c)
+	}
+	for _, hand := range g.held {
+		g.rules.Order.Sort(hand)
+	}
+
+	g.phase = phasePlay
+	if g.roles != nil {
+		g.exchange()
+		return
+	}
+
+	// The holder of the lowest card dealt leads and must play it.
+	lowest := 0
+	for seat, hand := range g.held {
+		if g.rules.Order.less(hand[0], g.held[lowest][0]) {
+			lowest = seat
+		}
+	}
+	g.turn, g.leader = lowest, lowest
+	opening := g.held[lowest][0]
+	g.opening = &opening
+}
+
+func (g *Game) Players() []string {
+	return g.players
+}
+
+func (g *Game) seat(player string) int {
+	for i, p := range g.players {
+		if p == player {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Game) LegalActions(player string) []game.Action {
+	seat := g.seat(player)
+	if seat < 0 {
+		return nil
+	}
+
+	var actions []game.Action
+	switch g.phase {
+	case phaseExchange:
+		if g.owed[seat] == 0 {
+			return nil
+		}
+		choose(len(g.held[seat]), g.owed[seat], func(indexes []int) {
+			actions = append(actions, game.Action{Type: "give", Cards: codes(g.held[seat], indexes)})
+		})
+	case phasePlay:
+		if seat != g.turn {
+			return nil
+		}
+		for _, cards := range g.candidates(seat) {
+			if _, err := g.check(cards); err == nil {
+				actions = append(actions, game.Action{Type: "play", Cards: codes(cards, nil)})
+			}
+		}
+		if g.top != nil {
+			actions = append(actions, game.Action{Type: "pass"})
+		}
+	}
+	return actions
+}
+
+// candidates lists every group of cards in the seat's hand that might form
+// a combination, lowest first.
+func (g *Game) candidates(seat int) [][]model.Card {
+	hand := g.held[seat]
+	var groups [][]model.Card
+	for start := 0; start < len(hand); {
+		end := start
+		for end < len(hand) && g.rules.Order.rank(hand[end]) == g.rules.Order.rank(hand[start]) {
+			end++
+		}
+		sameRank := hand[start:end]
+		for size := 1; size <= 4 && size <= len(sameRank); size++ {
+			choose(len(sameRank), size, func(indexes []int) {
+				groups = append(groups, pick(sameRank, indexes))
+			})
+		}
+		start = end
+	}
+	if g.rules.FiveCardHands {
+		choose(len(hand), 5, func(indexes []int) {
+			groups = append(groups, pick(hand, indexes))
+		})
+	}
+	return groups
+}
+
+// check classifies a play and makes sure it may be made now.
+func (g *Game) check(cards []model.Card) (Combination, error) {
+	combination, err := Classify(cards, g.rules)
+	if err != nil {
+		return Combination{}, err
+	}
+	if g.top != nil && !combination.Beats(*g.top, g.rules) {
+		return Combination{}, fmt.Errorf("Play does not beat the %v on the table", g.top.Kind)
+	}
+	if g.opening != nil && !contains(cards, g.opening.Code) {
+		return Combination{}, fmt.Errorf("First play must include %v", g.opening.Code)
+	}
+	return combination, nil
+}
+
+// Apply accepts the cards of a play or gift in any order.
+func (g *Game) Apply(player string, action game.Action) error {
+	seat := g.seat(player)
+	if seat < 0 {
+		return fmt.Errorf("Unknown player %v", player)
+	}
+
+	switch {
+	case action.Type == "give" && g.phase == phaseExchange && g.owed[seat] > 0:
+		cards, err := g.take(seat, action.Cards)
+		if err != nil {
+			return err
+		}
+		if len(cards) != g.owed[seat] {
+			return fmt.Errorf("Give %v cards", g.owed[seat])
+		}
+		g.give(seat, g.partner(seat), cards)
+		g.owed[seat] = 0
+		for _, owed := range g.owed {
+			if owed > 0 {
+				return nil
+			}
+		}
+		g.phase = phasePlay
+		return nil
+	case action.Type == "play" && g.phase == phasePlay && seat == g.turn:
+		cards, err := g.take(seat, action.Cards)
+		if err != nil {
+			return err
+		}
+		combination, err := g.check(cards)
+		if err != nil {
+			return err
+		}
+		g.held[seat] = remove(g.held[seat], cards)
+		g.deck.AddToPile(PlayedPile, combination.Cards...)
+		g.top, g.leader, g.opening = &combination, seat, nil
+		if len(g.held[seat]) == 0 {
+			g.finished = append(g.finished, seat)
+			if g.handOver() {
+				return nil
+			}
+		}
+		g.advance(seat)
+		return nil
+	case action.Type == "pass" && g.phase == phasePlay && seat == g.turn && g.top != nil:
+		g.passed[seat] = true
+		g.advance(seat)
+		return nil
+	}
+	return fmt.Errorf("Illegal action %q for %v", action.Type, player)
+}
+
+// take looks up the given codes in the seat's hand.
+func (g *Game) take(seat int, codes []string) ([]model.Card, error) {
+	var cards []model.Card
+	for _, code := range codes {
+		found := false
+		for _, c := range g.held[seat] {
+			if c.Code == code && !contains(cards, code) {
+				cards = append(cards, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Card %v is not in hand", code)
+		}
+	}
+	return cards, nil
+}
+
+// advance passes the turn on from seat to the next player still in the
+// round. When it comes back round to the last player to play, everyone else
+// has passed and they lead a new round, or the next player with cards does
+// if they have gone out.
+func (g *Game) advance(seat int) {
+	n := len(g.players)
+	for i := 1; i <= n; i++ {
+		next := (seat + i) % n
+		if next == g.leader {
+			g.top = nil
+			g.passed = make([]bool, n)
+			for len(g.held[next]) == 0 {
+				next = (next + 1) % n
+			}
+			g.turn = next
+			return
+		}
+		if len(g.held[next]) > 0 && !g.passed[next] {
+			g.turn = next
+			return
+		}
+	}
+}
+
+// handOver ends the hand once it is decided, scores it and deals the next
+// one or ends the game.
+func (g *Game) handOver() bool {
+	n := len(g.players)
+	if g.playOut {
+		if len(g.finished) < n-1 {
+			return false
+		}
+		for seat := range g.held {
+			if len(g.held[seat]) > 0 {
+				g.finished = append(g.finished, seat)
+			}
+		}
+		// Each place scores one point for every player finishing below it.
+		for place, seat := range g.finished {
+			g.scores[seat] += n - 1 - place
+		}
+	} else {
+		// The winner scores a point for every card left in the other hands,
+		// which each lose as many.
+		winner := g.finished[0]
+		for seat, hand := range g.held {
+			g.scores[seat] -= len(hand)
+			g.scores[winner] += len(hand)
+		}
+	}
+
+	if g.titles {
+		g.roles = Roles(n, g.finished)
+	}
+	if g.hand == g.hands {
+		g.phase = phaseOver
+		return true
+	}
+
+	var cards []model.Card
+	for _, hand := range g.held {
+		cards = append(cards, hand...)
+	}
+	played, _ := g.deck.DrawFromPile(PlayedPile, len(g.deck.Piles[PlayedPile]))
+	cards = append(cards, played...)
+	cards = append(cards, g.deck.Cards...)
+	g.rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	g.deck.Cards, g.deck.Remaining = cards, len(cards)
+	g.deal()
+	return true
+}
+
+func (g *Game) Terminal() bool {
+	return g.phase == phaseOver
+}
+
+func (g *Game) Scores() map[string]int {
+	scores := make(map[string]int)
+	for seat, p := range g.players {
+		scores[p] = g.scores[seat]
+	}
+	return scores
+}
+
+// Determinize deals the other hands again from the unseen cards, the other
+// hands and any cards left undealt. The player to lead the first play keeps
+// the opening card, since everyone knows they hold it.
+func (g *Game) Determinize(player string, rng *rand.Rand) game.Game {
+	clone := *g
+	clone.rng = rng
+	clone.deck = g.deck.Clone()
+	clone.scores = append([]int{}, g.scores...)
+	clone.passed = append([]bool{}, g.passed...)
+	clone.finished = append([]int(nil), g.finished...)
+	clone.owed = append([]int(nil), g.owed...)
+
+	opening := -1
+	clone.held = make([][]model.Card, len(g.held))
+	unseen := []*[]model.Card{&clone.deck.Cards}
+	for seat, hand := range g.held {
+		clone.held[seat] = append([]model.Card{}, hand...)
+		if g.players[seat] == player {
+			continue
+		}
+		if g.opening != nil && contains(hand, g.opening.Code) {
+			opening = seat
+			clone.held[seat] = remove(hand, []model.Card{*g.opening})
+		}
+		unseen = append(unseen, &clone.held[seat])
+	}
+	game.Redeal(rng, unseen...)
+	if opening >= 0 {
+		clone.held[opening] = append(clone.held[opening], *g.opening)
+	}
+	for _, hand := range clone.held {
+		g.rules.Order.Sort(hand)
+	}
+	return &clone
+}
+
+// View shows a player their own hand and everyone the hand sizes, the play
+// to beat and the finishing order so far.
+func (g *Game) View(player string) interface{} {
+	view := View{
+		HandSizes: make(map[string]int),
+		Phase:     string(g.phase),
+		Deal:      g.hand,
+		Top:       g.top,
+		Passed:    []string{},
+		Finished:  []string{},
+		Scores:    g.Scores(),
+	}
+	for seat, p := range g.players {
+		view.HandSizes[p] = len(g.held[seat])
+		if g.passed[seat] {
+			view.Passed = append(view.Passed, p)
+		}
+	}
+	for _, seat := range g.finished {
+		view.Finished = append(view.Finished, g.players[seat])
+	}
+	if g.roles != nil {
+		view.Roles = make(map[string]string)
+		for seat, role := range g.roles {
+			view.Roles[g.players[seat]] = string(role)
+		}
+	}
+	if g.phase == phasePlay {
+		view.Turn = g.players[g.turn]
+		if g.top != nil {
+			view.Leader = g.players[g.leader]
+		}
+	}
+	if seat := g.seat(player); seat >= 0 {
+		view.Hand = g.held[seat]
+	}
+	return view
+}
+
+// choose calls fn with every k-element subset of the indexes 0 to n-1, in
+// increasing order.
+func choose(n, k int, fn func(indexes []int)) {
+	indexes := make([]int, k)
+	var rec func(start, depth int)
+	rec = func(start, depth int) {
+		if depth == k {
+			fn(append([]int{}, indexes...))
+			return
+		}
+		for i := start; i <= n-(k-depth); i++ {
+			indexes[depth] = i
+			rec(i+1, depth+1)
+		}
+	}
+	rec(0, 0)
+}
+
+func pick(cards []model.Card, indexes []int) []model.Card {
+	picked := make([]model.Card, len(indexes))
+	for i, index := range indexes {
+		picked[i] = cards[index]
+	}
+	return picked
+}
+
+// codes returns the codes of the cards at indexes, or of all of them when
+// indexes is nil.
+func codes(cards []model.Card, indexes []int) []string {
+	if indexes != nil {
+		cards = pick(cards, indexes)
+	}
+	result := make([]string, len(cards))
+	for i, c := range cards {
+		result[i] = c.Code
+	}
+	return result
+}
+
+func contains(cards []model.Card, code string) bool {
+	for _, c := range cards {
+		if c.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(hand, cards []model.Card) []model.Card {
+	rest := make([]model.Card, 0, len(hand))
+	for _, c := range hand {
+		if !contains(cards, c.Code) {
+			rest = append(rest, c)
+		}
+	}
+	return rest
+}
+
+// CompareRequest is the request of the "compare" tool: a play, by card code,
+// and the play it must beat, if any.
+type CompareRequest struct {
+	Play    []string `json:"play"`
+	Current []string `json:"current"`
+	Bombs   bool     `json:"bombs"`
+}
+
+// Comparison is a classified play and whether it beats the one on the table.
+type Comparison struct {
+	Play    Combination  `json:"play"`
+	Current *Combination `json:"current,omitempty"`
+	Beats   bool         `json:"beats"`
+}
+
+// Tool offers "compare", which classifies a play under the variant's rules
+// and, unless there is no current play, checks it against the current one.
+func (g *Game) Tool(name string, request json.RawMessage) (interface{}, error) {
+	if name != "compare" {
+		return nil, game.ErrUnknownTool
+	}
+	var compare CompareRequest
+	if err := json.Unmarshal(request, &compare); err != nil {
+		return nil, fmt.Errorf("Invalid request body")
+	}
+	rules := g.rules
+	rules.Bombs = rules.Bombs || compare.Bombs
+
+	playCards, err := model.CardsFromCodes(compare.Play)
+	if err != nil {
+		return nil, err
+	}
+	combination, err := Classify(playCards, rules)
+	if err != nil {
+		return nil, err
+	}
+	comparison := Comparison{Play: combination, Beats: true}
+	if len(compare.Current) == 0 {
+		return comparison, nil
+	}
+
+	currentCards, err := model.CardsFromCodes(compare.Current)
+	if err != nil {
+		return nil, err
+	}
+	top, err := Classify(currentCards, rules)
+	if err != nil {
+		return nil, err
+	}
+	comparison.Current = &top
+	comparison.Beats = combination.Beats(top, rules)
+	return comparison, nil
+}
@@ -0,0 +1,292 @@
+package climbing
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+// dealt returns a deck that deals the given hands round the table.
+func dealt(hands ...string) model.Deck {
+	split := make([][]string, len(hands))
+	for i, hand := range hands {
+		split[i] = strings.Split(hand, ",")
+	}
+	var codes []string
+	for i := range split[0] {
+		for _, hand := range split {
+			codes = append(codes, hand[i])
+		}
+	}
+	return model.NewDeck(false, strings.Join(codes, ","))
+}
+
+// playOut has whoever may act take their first legal action until the game
+// ends.
+func playOut(t *testing.T, g game.Game) {
+	for moves := 0; !g.Terminal(); moves++ {
+		if moves > 2000 {
+			t.Fatalf("Game did not finish")
+		}
+		acted := false
+		for _, player := range g.Players() {
+			if actions := g.LegalActions(player); len(actions) > 0 {
+				if err := g.Apply(player, actions[0]); err != nil {
+					t.Fatalf("%v %+v: %v", player, actions[0], err)
+				}
+				acted = true
+				break
+			}
+		}
+		if !acted {
+			t.Fatalf("Nobody can act: %+v", g.View(""))
+		}
+	}
+}
+
+func TestBigTwoRounds(t *testing.T) {
+	deck := dealt(
+		"3D,4C,5C,6C,7C,9H,9S,KD,KH,AS,2H,2S,8D",
+		"3C,3H,4D,5D,6D,7D,8C,8H,1C,JC,QC,KC,AC",
+	)
+	g := NewBigTwo()
+	if err := g.Setup(deck, game.Config{Players: []string{"ann", "bob", "cat", "dan", "eve"}}); err == nil {
+		t.Errorf("Setup accepted 5 players")
+	}
+	if err := g.Setup(deck, game.Config{Players: []string{"ann", "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ann holds the three of diamonds, so she leads with it and may not pass.
+	actions := g.LegalActions("ann")
+	for _, action := range actions {
+		if action.Type == "pass" || !strings.Contains(strings.Join(action.Cards, ","), "3D") {
+			t.Fatalf("Unexpected opening action %+v", action)
+		}
+	}
+	if len(g.LegalActions("bob")) != 0 {
+		t.Errorf("Bob may act out of turn")
+	}
+	if err := g.Apply("ann", game.Action{Type: "play", Cards: []string{"4C"}}); err == nil {
+		t.Errorf("Opening play without the three of diamonds accepted")
+	}
+	if err := g.Apply("ann", game.Action{Type: "play", Cards: []string{"7C", "3D", "5C", "4C", "6C"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Apply("bob", game.Action{Type: "play", Cards: []string{"3C", "3H"}}); err == nil {
+		t.Errorf("Pair accepted on a straight")
+	}
+	if err := g.Apply("bob", game.Action{Type: "play", Cards: []string{"8C", "1C", "JC", "QC", "KC"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ann can't beat the flush, so everyone else has passed once she does
+	// and Bob leads again.
+	actions = g.LegalActions("ann")
+	if len(actions) != 1 || actions[0].Type != "pass" {
+		t.Fatalf("Ann should only be able to pass: %+v", actions)
+	}
+	if err := g.Apply("ann", actions[0]); err != nil {
+		t.Fatal(err)
+	}
+	view := g.View("bob").(View)
+	if view.Turn != "bob" || view.Top != nil || len(view.Passed) != 0 {
+		t.Fatalf("Round did not reset: %+v", view)
+	}
+	for _, action := range g.LegalActions("bob") {
+		if action.Type == "pass" {
+			t.Errorf("Leader may pass")
+		}
+	}
+
+	if err := g.Apply("bob", game.Action{Type: "play", Cards: []string{"3C", "3H"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply("ann", game.Action{Type: "play", Cards: []string{"9S", "9H"}}); err != nil {
+		t.Fatal(err)
+	}
+	view = g.View("ann").(View)
+	if view.Top == nil || view.Top.Kind != Pair || view.Leader != "ann" || view.HandSizes["ann"] != 6 || len(view.Hand) != 6 {
+		t.Errorf("Unexpected view: %+v", view)
+	}
+
+	playOut(t, g)
+	scores := g.Scores()
+	if scores["ann"]+scores["bob"] != 0 || (scores["ann"] <= 0 && scores["bob"] <= 0) {
+		t.Errorf("Unexpected scores: %v", scores)
+	}
+}
+
+func TestPresidentHands(t *testing.T) {
+	g := NewPresident()
+	config := game.Config{Players: []string{"ann", "bob", "cat", "dan"}, Options: map[string]string{"hands": "2", "seed": "5"}}
+	if err := g.Setup(model.NewSeededDeck(5, ""), config); err != nil {
+		t.Fatal(err)
+	}
+
+	for g.View("").(View).Deal == 1 {
+		playOut(t, &firstHand{g})
+	}
+
+	view := g.View("").(View)
+	if view.Phase != string(phaseExchange) || len(view.Roles) != 4 {
+		t.Fatalf("Second hand did not start with an exchange: %+v", view)
+	}
+	var president, vice, scum string
+	for player, role := range view.Roles {
+		switch Role(role) {
+		case President:
+			president = player
+		case VicePresident:
+			vice = player
+		case Scum:
+			scum = player
+		}
+	}
+	if view.HandSizes[president] != 15 || view.HandSizes[vice] != 14 || view.HandSizes[scum] != 11 {
+		t.Errorf("Best cards were not handed up: %v", view.HandSizes)
+	}
+	if len(g.LegalActions(scum)) != 0 {
+		t.Errorf("Scum may act during the exchange")
+	}
+
+	hand := g.View(president).(View).Hand
+	if err := g.Apply(president, game.Action{Type: "give", Cards: []string{hand[0].Code}}); err == nil {
+		t.Errorf("President gave back one card")
+	}
+	if err := g.Apply(president, game.Action{Type: "give", Cards: []string{hand[1].Code, hand[0].Code}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply(vice, g.LegalActions(vice)[0]); err != nil {
+		t.Fatal(err)
+	}
+	view = g.View(scum).(View)
+	if view.Phase != string(phasePlay) || view.Turn != scum || view.HandSizes[scum] != 13 {
+		t.Errorf("Scum does not lead after the exchange: %+v", view)
+	}
+	if !contains(view.Hand, hand[0].Code) || !contains(view.Hand, hand[1].Code) {
+		t.Errorf("Scum did not get the President's card %v: %v", hand[0].Code, view.Hand)
+	}
+
+	playOut(t, g)
+	total := 0
+	for _, score := range g.Scores() {
+		total += score
+	}
+	if total != 12 {
+		t.Errorf("Two hands of four should share 12 points: %v", g.Scores())
+	}
+}
+
+// firstHand stops playOut once the first hand of a game is over.
+type firstHand struct {
+	game.Game
+}
+
+func (f *firstHand) Terminal() bool {
+	return f.Game.View("").(View).Deal > 1
+}
+
+func TestPresidentBombs(t *testing.T) {
+	deck := dealt("3C,5C,7C,7D,7H,7S", "4C,6C,2C,2D,2H,8C", "3D,4D,5D,6D,8D,9D")
+	g := NewPresident()
+	if err := g.Setup(deck, game.Config{Players: []string{"ann", "bob", "cat"}, Options: map[string]string{"bombs": "true"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ann and Cat both hold a three; Ann, the first seat, leads.
+	if g.View("").(View).Turn != "ann" {
+		t.Fatalf("Ann should lead: %+v", g.View(""))
+	}
+	if err := g.Apply("ann", game.Action{Type: "play", Cards: []string{"3C"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply("bob", game.Action{Type: "play", Cards: []string{"2C"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply("cat", game.Action{Type: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply("ann", game.Action{Type: "play", Cards: []string{"7C", "7D", "7H", "7S"}}); err != nil {
+		t.Fatal(err)
+	}
+	if g.View("").(View).Top.Kind != Bomb {
+		t.Errorf("Four sevens should be a bomb: %+v", g.View(""))
+	}
+	if err := g.Apply("bob", game.Action{Type: "play", Cards: []string{"2D", "2H"}}); err == nil {
+		t.Errorf("Pair accepted on a bomb")
+	}
+}
+
+func TestDeterminize(t *testing.T) {
+	g := NewBigTwo()
+	if err := g.Setup(model.NewSeededDeck(3, ""), game.Config{Players: []string{"ann", "bob", "cat"}}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := json.Marshal(g.View("bob"))
+	own, _ := json.Marshal(g.View("ann"))
+	leader := g.View("").(View).Turn
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		d := g.(game.Determinizer).Determinize("ann", rng)
+		if view, _ := json.Marshal(d.View("ann")); string(view) != string(own) {
+			t.Fatalf("Determinize changed what ann sees:\n%s\n%s", view, own)
+		}
+		if len(d.LegalActions(leader)) == 0 {
+			t.Fatalf("The leader lost the opening card")
+		}
+		for moves := 0; !d.Terminal(); moves++ {
+			if moves > 500 {
+				t.Fatalf("Determinization did not finish")
+			}
+			player := d.View("").(View).Turn
+			d.Apply(player, d.LegalActions(player)[0])
+		}
+	}
+	if after, _ := json.Marshal(g.View("bob")); string(after) != string(before) {
+		t.Errorf("Playing a determinization changed the game")
+	}
+}
+
+func TestGame_Tool(t *testing.T) {
+	compare := func(g game.Game, request string) (Comparison, error) {
+		comparison, err := g.(game.Toolbox).Tool("compare", json.RawMessage(request))
+		if err != nil {
+			return Comparison{}, err
+		}
+		return comparison.(Comparison), nil
+	}
+
+	comparison, err := compare(NewBigTwo(), `{"play": ["3D", "3H", "3S", "4C", "4D"], "current": ["3C", "8C", "JC", "KC", "2C"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.Play.Kind != FullHouse || comparison.Current.Kind != Flush || !comparison.Beats {
+		t.Errorf("Tool returned unexpected comparison: %+v", comparison)
+	}
+
+	comparison, err = compare(NewPresident(), `{"play": ["7S"], "current": ["7H"]}`)
+	if err != nil || comparison.Beats {
+		t.Errorf("Equal ranks beat each other in President: %+v, %v", comparison, err)
+	}
+	comparison, err = compare(NewPresident(), `{"play": ["5S", "5H", "5D", "5C"], "current": ["2H"], "bombs": true}`)
+	if err != nil || comparison.Play.Kind != Bomb || !comparison.Beats {
+		t.Errorf("Bomb did not beat a single: %+v, %v", comparison, err)
+	}
+
+	if _, err := compare(NewBigTwo(), `{"play": ["7S", "8S"]}`); err == nil {
+		t.Errorf("Tool accepted an invalid combination")
+	}
+	if _, err := compare(NewBigTwo(), `{"play": ["7S", "7S"]}`); err == nil {
+		t.Errorf("Tool accepted a duplicate card")
+	}
+	if _, err := NewBigTwo().(game.Toolbox).Tool("score", json.RawMessage(`{}`)); err != game.ErrUnknownTool {
+		t.Errorf("Tool ran an unknown tool: %v", err)
+	}
+}
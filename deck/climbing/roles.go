@@ -0,0 +1,90 @@
+package climbing
+
+import (
+	"cardGame/deck/model"
+)
+
+type Role string
+
+const (
+	President     Role = "president"
+	VicePresident Role = "vice_president"
+	Citizen       Role = "citizen"
+	ViceScum      Role = "vice_scum"
+	Scum          Role = "scum"
+)
+
+// Roles titles n seats by their finishing order, first to last. With fewer
+// than four players there are no vice titles.
+func Roles(n int, finished []int) []Role {
+	roles := make([]Role, n)
+	for place, seat := range finished {
+		switch {
+		case place == 0:
+			roles[seat] = President
+		case place == n-1:
+			roles[seat] = Scum
+		case n >= 4 && place == 1:
+			roles[seat] = VicePresident
+		case n >= 4 && place == n-2:
+			roles[seat] = ViceScum
+		default:
+			roles[seat] = Citizen
+		}
+	}
+	return roles
+}
+
+// swaps lists who gives their best cards to whom at the start of a hand and
+// how many.
+var swaps = []struct {
+	from, to Role
+	count    int
+}{
+	{Scum, President, 2},
+	{ViceScum, VicePresident, 1},
+}
+
+func (g *Game) seatOf(role Role) int {
+	for seat, r := range g.roles {
+		if r == role {
+			return seat
+		}
+	}
+	return -1
+}
+
+// partner is the seat a President or Vice President hands cards back to.
+func (g *Game) partner(seat int) int {
+	for _, swap := range swaps {
+		if g.roles[seat] == swap.to {
+			return g.seatOf(swap.from)
+		}
+	}
+	return -1
+}
+
+// exchange makes the Scum and Vice Scum hand over their best cards. The
+// President and Vice President then give back as many of their choice, and
+// the Scum leads.
+func (g *Game) exchange() {
+	g.owed = make([]int, len(g.players))
+	for _, swap := range swaps {
+		from, to := g.seatOf(swap.from), g.seatOf(swap.to)
+		if from < 0 || to < 0 {
+			continue
+		}
+		hand := g.held[from]
+		g.give(from, to, append([]model.Card{}, hand[len(hand)-swap.count:]...))
+		g.owed[to] = swap.count
+	}
+	g.phase = phaseExchange
+	g.turn = g.seatOf(Scum)
+	g.leader = g.turn
+}
+
+func (g *Game) give(from, to int, cards []model.Card) {
+	g.held[from] = remove(g.held[from], cards)
+	g.held[to] = append(g.held[to], cards...)
+	g.rules.Order.Sort(g.held[to])
+}
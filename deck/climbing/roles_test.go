@@ -0,0 +1,20 @@
+package climbing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoles(t *testing.T) {
+	got := Roles(5, []int{3, 0, 4, 1, 2})
+	want := []Role{VicePresident, ViceScum, Scum, President, Citizen}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Roles(5) = %v, want %v", got, want)
+	}
+
+	got = Roles(3, []int{2, 1, 0})
+	want = []Role{Scum, Citizen, President}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Roles(3) = %v, want %v", got, want)
+	}
+}
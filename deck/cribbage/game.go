@@ -0,0 +1,455 @@
+package cribbage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+const handSize = 6
+
+type phase string
+
+const (
+	phaseDiscard phase = "discard"
+	phaseCut     phase = "cut"
+	phasePegging phase = "pegging"
+	phaseOver    phase = "over"
+)
+
+// Show is the scoring of one hand after pegging.
+type Show struct {
+	Starter model.Card           `json:"starter"`
+	Hands   map[string]Breakdown `json:"hands"`
+	Crib    Breakdown            `json:"crib"`
+}
+
+// Game plays two-player cribbage to 121 for the game registry. The first
+// player deals first; the deal alternates and the cards are reshuffled for
+// every hand after the first.
+type Game struct {
+	rng     *rand.Rand
+	players []string
+	deck    model.Deck
+	dealer  int
+	phase   phase
+	scores  [2]int
+
+	hands   [2][]model.Card
+	crib    []model.Card
+	starter *model.Card
+	// discarded is what each player put in the crib, which only they know.
+	discarded [2][]model.Card
+
+	// Pegging state: the cards each player still holds, the cards played
+	// since the count was last reset, who played last and who has said go.
+	pegs     [2][]model.Card
+	sequence []model.Card
+	turn     int
+	last     int
+	said     [2]bool
+	lastShow *Show
+}
+
+type View struct {
+	Hand     []model.Card   `json:"hand,omitempty"`
+	Pegging  []model.Card   `json:"pegging,omitempty"`
+	Dealer   string         `json:"dealer"`
+	Phase    string         `json:"phase"`
+	Turn     string         `json:"turn,omitempty"`
+	Starter  *model.Card    `json:"starter,omitempty"`
+	Crib     int            `json:"crib"`
+	Count    int            `json:"count"`
+	Sequence []model.Card   `json:"sequence"`
+	Scores   map[string]int `json:"scores"`
+	LastShow *Show          `json:"last_show,omitempty"`
+}
+
+func NewGame() game.Game {
+	return &Game{}
+}
+
+// Setup deals the first hand from deck. The "seed" option fixes the shuffles
+// of later hands.
+func (g *Game) Setup(deck model.Deck, config game.Config) error {
+	if len(config.Players) != 2 {
+		return fmt.Errorf("Cribbage needs 2 players")
+	}
+	if len(deck.Cards) != 52 {
+		return fmt.Errorf("Cribbage needs a full deck")
+	}
+
+	seed := time.Now().UnixNano()
+	if value, err := strconv.ParseInt(config.Options["seed"], 10, 64); err == nil {
+		seed = value
+	}
+	g.rng = rand.New(rand.NewSource(seed))
+	g.players = config.Players
+	g.deck = deck
+	g.deck.Cards = append([]model.Card{}, deck.Cards...)
+	g.deal()
+	return nil
+}
+
+func (g *Game) deal() {
+	g.crib, g.starter, g.sequence = nil, nil, nil
+	g.said = [2]bool{}
+	for i := range g.hands {
+		g.hands[i], g.discarded[i] = nil, nil
+	}
+	for i := 0; i < 2*handSize; i++ {
+		cards, _ := g.deck.DrawCards(1)
+		player := (g.dealer + 1 + i) % 2
+		g.hands[player] = append(g.hands[player], cards[0])
+	}
+	g.phase = phaseDiscard
+}
+
+func (g *Game) Players() []string {
+	return g.players
+}
+
+func (g *Game) seat(player string) int {
+	for i, p := range g.players {
+		if p == player {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Game) LegalActions(player string) []game.Action {
+	seat := g.seat(player)
+	if seat < 0 {
+		return nil
+	}
+
+	var actions []game.Action
+	switch g.phase {
+	case phaseDiscard:
+		hand := g.hands[seat]
+		if len(hand) != handSize {
+			return nil
+		}
+		for i := range hand {
+			for j := i + 1; j < len(hand); j++ {
+				actions = append(actions, game.Action{Type: "discard", Cards: []string{hand[i].Code, hand[j].Code}})
+			}
+		}
+	case phaseCut:
+		if seat != 1-g.dealer {
+			return nil
+		}
+		// The amount is how many cards to lift, leaving at least four in
+		// each packet. Any amount may be posted; the one listed cuts the
+		// pack in half.
+		actions = append(actions, game.Action{Type: "cut", Amount: len(g.deck.Cards) / 2})
+	case phasePegging:
+		if seat != g.turn {
+			return nil
+		}
+		for _, c := range g.pegs[seat] {
+			if Count(g.sequence)+Value(c) <= MaxCount {
+				actions = append(actions, game.Action{Type: "play", Cards: []string{c.Code}})
+			}
+		}
+		if len(actions) == 0 {
+			actions = append(actions, game.Action{Type: "go"})
+		}
+	}
+	return actions
+}
+
+func (g *Game) Apply(player string, action game.Action) error {
+	seat := g.seat(player)
+	if action.Type == "cut" && g.phase == phaseCut && seat == 1-g.dealer {
+		return g.cut(action.Amount)
+	}
+	if !game.IsLegal(g, player, action) {
+		return fmt.Errorf("Illegal action %q for %v", action.Type, player)
+	}
+
+	switch action.Type {
+	case "discard":
+		for _, code := range action.Cards {
+			card, rest := take(g.hands[seat], code)
+			g.hands[seat] = rest
+			g.crib = append(g.crib, card)
+			g.discarded[seat] = append(g.discarded[seat], card)
+		}
+		if len(g.crib) == 4 {
+			g.phase = phaseCut
+		}
+	case "play":
+		card, rest := take(g.pegs[seat], action.Cards[0])
+		g.pegs[seat] = rest
+		g.sequence = append(g.sequence, card)
+		g.last = seat
+		if g.peg(seat, PegPoints(g.sequence)) {
+			return nil
+		}
+		if Count(g.sequence) == MaxCount {
+			g.resetCount()
+			g.turn = 1 - seat
+		} else if !g.said[1-seat] {
+			g.turn = 1 - seat
+		}
+		g.settle()
+	case "go":
+		g.sayGo(seat)
+		g.settle()
+	}
+	return nil
+}
+
+// cut lifts amount cards off the top and turns up the next one as the
+// starter. A jack scores his heels for the dealer.
+func (g *Game) cut(amount int) error {
+	if amount < 4 || amount > len(g.deck.Cards)-4 {
+		return fmt.Errorf("Cut must leave at least 4 cards in each packet")
+	}
+	starter := g.deck.Cards[amount]
+	g.starter = &starter
+	g.deck.Cards = append(g.deck.Cards[:amount:amount], g.deck.Cards[amount+1:]...)
+	g.deck.Remaining = len(g.deck.Cards)
+
+	if starter.Value == "JACK" && g.peg(g.dealer, 2) {
+		return nil
+	}
+	for i := range g.hands {
+		g.pegs[i] = append([]model.Card{}, g.hands[i]...)
+	}
+	g.turn = 1 - g.dealer
+	g.last = -1
+	g.phase = phasePegging
+	return nil
+}
+
+// peg adds points for seat and reports whether that won the game.
+func (g *Game) peg(seat, points int) bool {
+	g.scores[seat] += points
+	if g.scores[seat] >= Target {
+		g.scores[seat] = Target
+		g.phase = phaseOver
+		return true
+	}
+	return false
+}
+
+func (g *Game) canPlay(seat int) bool {
+	for _, c := range g.pegs[seat] {
+		if Count(g.sequence)+Value(c) <= MaxCount {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Game) resetCount() {
+	g.sequence = nil
+	g.said = [2]bool{}
+}
+
+// sayGo passes play to the opponent if they can still play. Otherwise the
+// last player to play pegs one for the go, the count starts again and the
+// other player leads.
+func (g *Game) sayGo(seat int) {
+	if g.canPlay(1 - seat) {
+		g.said[seat] = true
+		g.turn = 1 - seat
+		return
+	}
+	last := g.last
+	if last >= 0 && g.peg(last, 1) {
+		return
+	}
+	g.resetCount()
+	g.turn = 1 - last
+	if last < 0 {
+		g.turn = 1 - seat
+	}
+}
+
+// settle says go for a player with no cards left and ends pegging, with a
+// point for the last card, once both hands are played out.
+func (g *Game) settle() {
+	for g.phase == phasePegging {
+		if len(g.pegs[0]) == 0 && len(g.pegs[1]) == 0 {
+			if len(g.sequence) > 0 && g.peg(g.last, 1) {
+				return
+			}
+			g.show()
+			return
+		}
+		if len(g.pegs[g.turn]) > 0 {
+			return
+		}
+		g.sayGo(g.turn)
+	}
+}
+
+// show counts the non-dealer's hand, then the dealer's and the crib, and
+// deals the next hand if nobody has won.
+func (g *Game) show() {
+	show := &Show{Starter: *g.starter, Hands: make(map[string]Breakdown)}
+	g.lastShow = show
+
+	for _, seat := range []int{1 - g.dealer, g.dealer} {
+		b := ScoreHand(g.hands[seat], *g.starter, false)
+		show.Hands[g.players[seat]] = b
+		if g.peg(seat, b.Total) {
+			return
+		}
+	}
+	show.Crib = ScoreHand(g.crib, *g.starter, true)
+	if g.peg(g.dealer, show.Crib.Total) {
+		return
+	}
+
+	cards := append(append(append(g.hands[0], g.hands[1]...), g.crib...), *g.starter)
+	cards = append(cards, g.deck.Cards...)
+	g.rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	g.deck.Cards, g.deck.Remaining = cards, len(cards)
+	g.dealer = 1 - g.dealer
+	g.deal()
+}
+
+func (g *Game) Terminal() bool {
+	return g.phase == phaseOver
+}
+
+func (g *Game) Scores() map[string]int {
+	return map[string]int{g.players[0]: g.scores[0], g.players[1]: g.scores[1]}
+}
+
+// View shows a player their own hand. The crib stays face down until the
+// show, which is then reported in LastShow.
+func (g *Game) View(player string) interface{} {
+	view := View{
+		Dealer:   g.players[g.dealer],
+		Phase:    string(g.phase),
+		Starter:  g.starter,
+		Crib:     len(g.crib),
+		Count:    Count(g.sequence),
+		Sequence: g.sequence,
+		Scores:   g.Scores(),
+		LastShow: g.lastShow,
+	}
+	if g.phase == phasePegging {
+		view.Turn = g.players[g.turn]
+	}
+	if seat := g.seat(player); seat >= 0 {
+		view.Hand = g.hands[seat]
+		view.Pegging = g.pegs[seat]
+	}
+	return view
+}
+
+// Determinize deals the cards player has not seen again: the opponent's
+// unplayed cards, their discards to the crib and the pack, including the
+// starter until it is cut.
+func (g *Game) Determinize(player string, rng *rand.Rand) game.Game {
+	clone := *g
+	clone.rng = rng
+	clone.deck = g.deck.Clone()
+	clone.crib = append([]model.Card(nil), g.crib...)
+	clone.sequence = append([]model.Card(nil), g.sequence...)
+	for i := range g.hands {
+		clone.hands[i] = append([]model.Card(nil), g.hands[i]...)
+		clone.pegs[i] = append([]model.Card(nil), g.pegs[i]...)
+		clone.discarded[i] = append([]model.Card(nil), g.discarded[i]...)
+	}
+	if g.phase == phaseOver {
+		return &clone
+	}
+
+	seat := g.seat(player)
+	var known []model.Card
+	if seat >= 0 {
+		known = g.discarded[seat]
+	}
+	crib := without(g.crib, known)
+	unseen := []*[]model.Card{&clone.deck.Cards, &crib}
+	var played [2][]model.Card
+	for i := range g.hands {
+		if i == seat {
+			continue
+		}
+		if g.phase == phasePegging {
+			// Cards already pegged were shown, so only the rest are hidden.
+			played[i] = without(g.hands[i], g.pegs[i])
+			unseen = append(unseen, &clone.pegs[i])
+		} else {
+			unseen = append(unseen, &clone.hands[i])
+		}
+	}
+	game.Redeal(rng, unseen...)
+
+	clone.crib = append(append([]model.Card{}, known...), crib...)
+	if g.phase == phasePegging {
+		for i := range g.hands {
+			if i != seat {
+				clone.hands[i] = append(played[i], clone.pegs[i]...)
+			}
+		}
+	}
+	return &clone
+}
+
+// ScoreRequest is the request of the "score" tool: a four-card hand or crib
+// and the starter, by card code.
+type ScoreRequest struct {
+	Hand    []string `json:"hand"`
+	Starter string   `json:"starter"`
+	Crib    bool     `json:"crib"`
+}
+
+// Tool offers "score", which counts a hand or crib with its starter.
+func (g *Game) Tool(name string, request json.RawMessage) (interface{}, error) {
+	if name != "score" {
+		return nil, game.ErrUnknownTool
+	}
+	var score ScoreRequest
+	if err := json.Unmarshal(request, &score); err != nil {
+		return nil, fmt.Errorf("Invalid request body")
+	}
+	if len(score.Hand) != 4 {
+		return nil, fmt.Errorf("A hand has 4 cards")
+	}
+
+	cards, err := model.CardsFromCodes(append(score.Hand, score.Starter))
+	if err != nil {
+		return nil, err
+	}
+	return ScoreHand(cards[:4], cards[4], score.Crib), nil
+}
+
+// without returns the cards that are not among known.
+func without(cards, known []model.Card) []model.Card {
+	var rest []model.Card
+	for _, c := range cards {
+		seen := false
+		for _, k := range known {
+			seen = seen || k.Code == c.Code
+		}
+		if !seen {
+			rest = append(rest, c)
+		}
+	}
+	return rest
+}
+
+func take(cards []model.Card, code string) (model.Card, []model.Card) {
+	for i, c := range cards {
+		if c.Code == code {
+			rest := append(append([]model.Card{}, cards[:i]...), cards[i+1:]...)
+			return c, rest
+		}
+	}
+	return model.Card{}, cards
+}
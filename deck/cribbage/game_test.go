@@ -0,0 +1,205 @@
+package cribbage
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+func TestGamePlaysToTarget(t *testing.T) {
+	g := NewGame()
+	config := game.Config{Players: []string{"ann", "bob"}, Options: map[string]string{"seed": "3"}}
+	if err := g.Setup(model.NewSeededDeck(3, ""), config); err != nil {
+		t.Fatal(err)
+	}
+
+	for moves := 0; !g.Terminal(); moves++ {
+		if moves > 5000 {
+			t.Fatalf("Game did not finish")
+		}
+		acted := false
+		for _, player := range g.Players() {
+			actions := g.LegalActions(player)
+			if len(actions) == 0 {
+				continue
+			}
+			action := actions[len(actions)-1]
+			if action.Type == "cut" {
+				action.Amount = 20
+			}
+			if err := g.Apply(player, action); err != nil {
+				t.Fatalf("%v %+v: %v", player, action, err)
+			}
+			acted = true
+			break
+		}
+		if !acted {
+			t.Fatalf("Nobody can act: %+v", g.View(""))
+		}
+	}
+
+	scores := g.Scores()
+	if scores["ann"] != Target && scores["bob"] != Target {
+		t.Errorf("Nobody reached %v: %v", Target, scores)
+	}
+}
+
+func TestGamePegging(t *testing.T) {
+	// Bob (non-dealer) gets the odd cards, Ann the even ones; the starter is
+	// the card after the cut of 4.
+	bob := strings.Split("KH,QH,9C,5D,2S,3S", ",")
+	ann := strings.Split("KD,QD,8C,6S,AH,AD", ",")
+	var codes []string
+	for i := range bob {
+		codes = append(codes, bob[i], ann[i])
+	}
+	codes = append(codes, "4C", "4D", "4H", "4S", "JS", "7C")
+
+	g := NewGame()
+	if err := g.Setup(model.NewDeck(false, strings.Join(codes, ",")+",9S,9H,9D,TS"), game.Config{Players: []string{"ann", "bob"}}); err == nil {
+		t.Fatalf("Setup accepted a short deck")
+	}
+
+	deck := model.NewDeck(false, strings.Join(codes, ","))
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		seen[code] = true
+	}
+	for _, c := range model.NewDeck(false, "").Cards {
+		if !seen[c.Code] {
+			deck.Cards = append(deck.Cards, c)
+		}
+	}
+	deck.Remaining = len(deck.Cards)
+	if err := g.Setup(deck, game.Config{Players: []string{"ann", "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	play := func(player string, action game.Action) {
+		t.Helper()
+		if err := g.Apply(player, action); err != nil {
+			t.Fatalf("%v %+v: %v", player, action, err)
+		}
+	}
+	play("bob", game.Action{Type: "discard", Cards: []string{"2S", "3S"}})
+	play("ann", game.Action{Type: "discard", Cards: []string{"AH", "AD"}})
+	if err := g.Apply("bob", game.Action{Type: "cut", Amount: 2}); err == nil {
+		t.Errorf("Apply accepted a cut that leaves too few cards on top")
+	}
+	play("bob", game.Action{Type: "cut", Amount: 4})
+	if view := g.View("ann").(View); view.Starter.Code != "JS" || g.Scores()["ann"] != 2 {
+		t.Fatalf("Jack starter should score his heels for the dealer: %+v %v", view.Starter, g.Scores())
+	}
+
+	play("bob", game.Action{Type: "play", Cards: []string{"KH"}})
+	play("ann", game.Action{Type: "play", Cards: []string{"KD"}})
+	if g.Scores()["ann"] != 4 {
+		t.Errorf("Pair not pegged: %v", g.Scores())
+	}
+	play("bob", game.Action{Type: "play", Cards: []string{"9C"}})
+	if actions := g.LegalActions("ann"); len(actions) != 1 || actions[0].Type != "go" {
+		t.Fatalf("Ann should have to say go at 29: %v", actions)
+	}
+	play("ann", game.Action{Type: "go"})
+	if g.Scores()["bob"] != 1 || g.View("").(View).Count != 0 {
+		t.Fatalf("Bob should peg one for the go and the count restart: %v", g.Scores())
+	}
+
+	play("ann", game.Action{Type: "play", Cards: []string{"QD"}})
+	play("bob", game.Action{Type: "play", Cards: []string{"5D"}})
+	if g.Scores()["bob"] != 3 {
+		t.Errorf("Fifteen not pegged: %v", g.Scores())
+	}
+	play("ann", game.Action{Type: "play", Cards: []string{"8C"}})
+	play("bob", game.Action{Type: "go"})
+	play("ann", game.Action{Type: "play", Cards: []string{"6S"}})
+	if g.Scores()["ann"] != 5 {
+		t.Errorf("Ann should peg one for the go after playing out: %v", g.Scores())
+	}
+	play("bob", game.Action{Type: "play", Cards: []string{"QH"}})
+
+	view := g.View("").(View)
+	if view.LastShow == nil || view.Phase != string(phaseDiscard) || view.Dealer != "bob" {
+		t.Fatalf("Hand should have been shown and the deal passed on: %+v", view)
+	}
+	if crib := view.LastShow.Crib; crib.Fifteens != 4 || crib.Pairs != 2 || crib.Runs != 6 {
+		t.Errorf("Unexpected crib: %+v", crib)
+	}
+}
+
+func TestDeterminize(t *testing.T) {
+	g := NewGame()
+	if err := g.Setup(model.NewSeededDeck(3, ""), game.Config{Players: []string{"ann", "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Both discard, bob cuts as listed and leads, leaving ann to play.
+	annCrib := g.LegalActions("ann")[0].Cards
+	for _, player := range []string{"bob", "ann", "bob", "bob"} {
+		if err := g.Apply(player, g.LegalActions(player)[0]); err != nil {
+			t.Fatalf("%v: %v", player, err)
+		}
+	}
+	before, _ := json.Marshal(g.View("bob"))
+	own, _ := json.Marshal(g.View("ann"))
+	led := g.View("").(View).Sequence[0].Code
+
+	rng := rand.New(rand.NewSource(1))
+	dealt := false
+	for i := 0; i < 10; i++ {
+		d := g.(game.Determinizer).Determinize("ann", rng).(*Game)
+		if view, _ := json.Marshal(d.View("ann")); string(view) != string(own) {
+			t.Fatalf("Determinize changed what ann sees:\n%s\n%s", view, own)
+		}
+		if d.crib[0].Code != annCrib[0] || d.crib[1].Code != annCrib[1] || len(d.crib) != 4 {
+			t.Fatalf("Ann's discards should stay in the crib: %v", d.crib)
+		}
+		bob := d.View("bob").(View)
+		if bob.Hand[0].Code != led || len(bob.Hand) != 4 || len(bob.Pegging) != 3 {
+			t.Fatalf("Bob should still have led %v: %+v", led, bob)
+		}
+		dealt = dealt || bob.Pegging[0].Code != g.View("bob").(View).Pegging[0].Code
+		for !d.Terminal() {
+			for _, player := range d.Players() {
+				if actions := d.LegalActions(player); len(actions) > 0 {
+					d.Apply(player, actions[0])
+					break
+				}
+			}
+		}
+	}
+	if !dealt {
+		t.Errorf("Bob's cards were never dealt again")
+	}
+	if after, _ := json.Marshal(g.View("bob")); string(after) != string(before) {
+		t.Errorf("Playing a determinization changed the game")
+	}
+}
+
+func TestGame_Tool(t *testing.T) {
+	g := NewGame().(game.Toolbox)
+
+	score, err := g.Tool("score", json.RawMessage(`{"hand": ["5H", "5D", "5C", "JS"], "starter": "5S"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if breakdown := score.(Breakdown); breakdown.Total != 29 {
+		t.Errorf("Tool scored %+v, want 29", breakdown)
+	}
+
+	for _, request := range []string{
+		`{"hand": ["5H", "5D", "5C"], "starter": "5S"}`,
+		`{"hand": ["5H", "5D", "5C", "XX"], "starter": "5S"}`,
+		`{"hand": ["5H", "5D", "5C", "5S"], "starter": "5S"}`,
+	} {
+		if _, err := g.Tool("score", json.RawMessage(request)); err == nil {
+			t.Errorf("Tool scored %v", request)
+		}
+	}
+	if _, err := g.Tool("peg", json.RawMessage(`{}`)); err != game.ErrUnknownTool {
+		t.Errorf("Tool ran an unknown tool: %v", err)
+	}
+}
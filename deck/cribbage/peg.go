@@ -0,0 +1,68 @@
+package cribbage
+
+import (
+	"cardGame/deck/model"
+)
+
+const (
+	// Target is the score that wins the game.
+	Target   = 121
+	MaxCount = 31
+)
+
+// Count is the pegging total of the cards played since the last reset.
+func Count(sequence []model.Card) int {
+	count := 0
+	for _, c := range sequence {
+		count += Value(c)
+	}
+	return count
+}
+
+// PegPoints scores the last card of a pegging sequence: two for making 15 or
+// 31, two, six or twelve for a pair, pair royal or double pair royal, and
+// the length of the longest run ending with it.
+func PegPoints(sequence []model.Card) int {
+	n := len(sequence)
+	if n == 0 {
+		return 0
+	}
+
+	points := 0
+	if count := Count(sequence); count == 15 || count == MaxCount {
+		points += 2
+	}
+
+	same := 1
+	for i := n - 2; i >= 0 && rank(sequence[i]) == rank(sequence[n-1]); i-- {
+		same++
+	}
+	points += same * (same - 1)
+
+	for length := n; length >= 3; length-- {
+		if isRun(sequence[n-length:]) {
+			points += length
+			break
+		}
+	}
+	return points
+}
+
+func isRun(cards []model.Card) bool {
+	var seen [14]bool
+	low, high := 14, 0
+	for _, c := range cards {
+		r := rank(c)
+		if seen[r] {
+			return false
+		}
+		seen[r] = true
+		if r < low {
+			low = r
+		}
+		if r > high {
+			high = r
+		}
+	}
+	return high-low == len(cards)-1
+}
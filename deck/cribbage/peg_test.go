@@ -0,0 +1,26 @@
+package cribbage
+
+import "testing"
+
+func TestPegPoints(t *testing.T) {
+	tests := []struct {
+		sequence string
+		want     int
+	}{
+		{"5H,KD", 2},
+		{"7H,7D", 2},
+		{"7H,7D,7C", 6},
+		{"3H,3D,3C,3S", 12},
+		{"4H,6D,5C", 5},
+		{"3H,4D,2C,5S", 4},
+		{"3H,4D,2C,5S,6S", 5},
+		{"3H,4D,3C", 0},
+		{"KH,QD,JC,AS", 2},
+		{"2H,3D,4C,2S", 3},
+	}
+	for _, test := range tests {
+		if got := PegPoints(cards(test.sequence)); got != test.want {
+			t.Errorf("PegPoints(%v): got %v want %v", test.sequence, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package cribbage
+
+import (
+	"cardGame/deck/model"
+)
+
+// Value is a card's count towards fifteens and the pegging total: aces one,
+// pictures ten.
+func Value(c model.Card) int {
+	rank := c.Rank()
+	switch {
+	case rank == 14:
+		return 1
+	case rank > 10:
+		return 10
+	}
+	return rank
+}
+
+// rank orders cards for runs and pairs with the ace low, from 1 to 13.
+func rank(c model.Card) int {
+	if r := c.Rank(); r != 14 {
+		return r
+	}
+	return 1
+}
+
+// Breakdown itemises the points in a hand or crib.
+type Breakdown struct {
+	Fifteens int `json:"fifteens"`
+	Pairs    int `json:"pairs"`
+	Runs     int `json:"runs"`
+	Flush    int `json:"flush"`
+	Nobs     int `json:"nobs"`
+	Total    int `json:"total"`
+}
+
+// ScoreHand counts a four-card hand together with the starter. A crib only
+// scores a flush when the starter matches as well.
+func ScoreHand(hand []model.Card, starter model.Card, crib bool) Breakdown {
+	cards := append(append([]model.Card{}, hand...), starter)
+	var b Breakdown
+
+	for mask := 1; mask < 1<<uint(len(cards)); mask++ {
+		sum := 0
+		for i, c := range cards {
+			if mask&(1<<uint(i)) != 0 {
+				sum += Value(c)
+			}
+		}
+		if sum == 15 {
+			b.Fifteens += 2
+		}
+	}
+
+	var counts [14]int
+	for _, c := range cards {
+		counts[rank(c)]++
+	}
+	for _, n := range counts {
+		b.Pairs += n * (n - 1)
+	}
+	b.Runs = runPoints(counts)
+
+	if len(hand) > 0 {
+		suited := true
+		for _, c := range hand[1:] {
+			suited = suited && c.Suit == hand[0].Suit
+		}
+		switch {
+		case suited && starter.Suit == hand[0].Suit:
+			b.Flush = len(hand) + 1
+		case suited && !crib:
+			b.Flush = len(hand)
+		}
+	}
+
+	for _, c := range hand {
+		if c.Value == "JACK" && c.Suit == starter.Suit {
+			b.Nobs = 1
+		}
+	}
+
+	b.Total = b.Fifteens + b.Pairs + b.Runs + b.Flush + b.Nobs
+	return b
+}
+
+// runPoints scores every run of three or more ranks, once for each way of
+// picking the duplicated cards.
+func runPoints(counts [14]int) int {
+	total := 0
+	for start := 1; start <= 13; {
+		end, ways := start, 1
+		for end <= 13 && counts[end] > 0 {
+			ways *= counts[end]
+			end++
+		}
+		if length := end - start; length >= 3 {
+			total += length * ways
+		}
+		start = end + 1
+	}
+	return total
+}
@@ -0,0 +1,41 @@
+package cribbage
+
+import (
+	"strings"
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+func cards(codes string) []model.Card {
+	var result []model.Card
+	for _, code := range strings.Split(codes, ",") {
+		result = append(result, model.NewDeck(false, code).Cards...)
+	}
+	return result
+}
+
+func TestScoreHand(t *testing.T) {
+	tests := []struct {
+		name    string
+		hand    string
+		starter string
+		crib    bool
+		want    Breakdown
+	}{
+		{"Perfect hand", "5H,5D,5C,JS", "5S", false, Breakdown{Fifteens: 16, Pairs: 12, Nobs: 1, Total: 29}},
+		{"Double double run", "4H,4S,5D,6C", "6H", false, Breakdown{Fifteens: 8, Pairs: 4, Runs: 12, Total: 24}},
+		{"Run of three", "AS,2D,3C,KH", "QH", false, Breakdown{Fifteens: 4, Runs: 3, Total: 7}},
+		{"Four card flush", "2H,4H,8H,QH", "KS", false, Breakdown{Flush: 4, Total: 4}},
+		{"No four card flush in the crib", "2H,4H,8H,QH", "KS", true, Breakdown{}},
+		{"Five card flush in the crib", "2H,4H,8H,QH", "KH", true, Breakdown{Flush: 5, Total: 5}},
+		{"Nineteen", "2C,4D,6H,8S", "QC", false, Breakdown{}},
+	}
+
+	for _, test := range tests {
+		got := ScoreHand(cards(test.hand), cards(test.starter)[0], test.crib)
+		if got != test.want {
+			t.Errorf("%v: got %+v want %+v", test.name, got, test.want)
+		}
+	}
+}
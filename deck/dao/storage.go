@@ -2,6 +2,7 @@ package dao
 
 import (
 	"cardGame/deck/model"
+	"fmt"
 	"github.com/google/uuid"
 	"sync"
 )
@@ -29,3 +30,28 @@ func (s *DeckStorage) GetDeck(deckID uuid.UUID) (model.Deck, bool) {
 	deck, ok := s.decks[deckID]
 	return deck, ok
 }
+
+// DeleteDeck removes a deck and reports whether it was there.
+func (s *DeckStorage) DeleteDeck(deckID uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.decks[deckID]
+	delete(s.decks, deckID)
+	return ok
+}
+
+// UpdateDeck applies fn to a stored deck and saves the result, unless fn
+// returns an error. Nothing else touches the deck in between.
+func (s *DeckStorage) UpdateDeck(deckID uuid.UUID, fn func(deck *model.Deck) error) (model.Deck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deck, ok := s.decks[deckID]
+	if !ok {
+		return model.Deck{}, fmt.Errorf("Invalid Deck ID")
+	}
+	if err := fn(&deck); err != nil {
+		return model.Deck{}, err
+	}
+	s.decks[deckID] = deck
+	return deck, nil
+}
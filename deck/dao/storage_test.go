@@ -2,6 +2,7 @@ package dao
 
 import (
 	"cardGame/deck/model"
+	"fmt"
 	"github.com/google/uuid"
 	"testing"
 )
@@ -46,3 +47,44 @@ func TestDeckStorage_GetDeck(t *testing.T) {
 		t.Errorf("GetDeck failed: expected remaining cards %v, got %v", deck.Remaining, savedDeck.Remaining)
 	}
 }
+
+func TestDeckStorage_DeleteDeck(t *testing.T) {
+	storage := NewDeckStorage()
+	deck := model.NewDeck(false, "")
+	storage.SaveDeck(deck)
+
+	if !storage.DeleteDeck(deck.ID) {
+		t.Errorf("DeleteDeck failed: deck not found")
+	}
+	if _, found := storage.GetDeck(deck.ID); found {
+		t.Errorf("DeleteDeck failed: deck still stored")
+	}
+	if storage.DeleteDeck(deck.ID) {
+		t.Errorf("DeleteDeck failed: deleted a deck twice")
+	}
+}
+
+func TestDeckStorage_UpdateDeck(t *testing.T) {
+	storage := NewDeckStorage()
+	deck := model.NewDeck(false, "")
+	storage.SaveDeck(deck)
+
+	updated, err := storage.UpdateDeck(deck.ID, func(deck *model.Deck) error {
+		deck.Owner = "ann"
+		return nil
+	})
+	if saved, _ := storage.GetDeck(deck.ID); err != nil || updated.Owner != "ann" || saved.Owner != "ann" {
+		t.Errorf("UpdateDeck failed: got %v, saved %v, %v", updated.Owner, saved.Owner, err)
+	}
+
+	storage.UpdateDeck(deck.ID, func(deck *model.Deck) error {
+		deck.Owner = "bob"
+		return fmt.Errorf("Refused")
+	})
+	if saved, _ := storage.GetDeck(deck.ID); saved.Owner != "ann" {
+		t.Errorf("UpdateDeck saved a refused update")
+	}
+	if _, err := storage.UpdateDeck(uuid.New(), func(*model.Deck) error { return nil }); err == nil {
+		t.Errorf("UpdateDeck updated a non-existing deck")
+	}
+}
@@ -0,0 +1,36 @@
+package game
+
+import (
+	"math/rand"
+
+	"cardGame/deck/model"
+)
+
+// Determinizer is implemented by games with hidden cards that the built-in
+// AI can play. Determinize returns an independent copy of the game in which
+// every card player cannot see has been dealt again at random from rng, and
+// everything they can see is left as it is. Searching such copies lets the
+// AI plan without peeking at the real hands or the order of the stock. The
+// copy also draws any later shuffles from rng.
+type Determinizer interface {
+	Determinize(player string, rng *rand.Rand) Game
+}
+
+// Redeal shuffles the cards of the given piles together and deals them back
+// out, each pile getting as many cards as it had. Piles that are nil stay
+// nil.
+func Redeal(rng *rand.Rand, piles ...*[]model.Card) {
+	var pool []model.Card
+	for _, pile := range piles {
+		pool = append(pool, *pile...)
+	}
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	for _, pile := range piles {
+		if *pile == nil {
+			continue
+		}
+		n := len(*pile)
+		*pile = append([]model.Card{}, pool[:n]...)
+		pool = pool[n:]
+	}
+}
@@ -0,0 +1,35 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+func TestRedeal(t *testing.T) {
+	deck := model.NewDeck(false, "AS,KD,2C,3H,4S")
+	a, b := append([]model.Card{}, deck.Cards[:2]...), append([]model.Card{}, deck.Cards[2:]...)
+	original := a[0]
+
+	moved := false
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		x, y := append([]model.Card{}, a...), append([]model.Card{}, b...)
+		Redeal(rng, &x, &y)
+		if len(x) != 2 || len(y) != 3 {
+			t.Fatalf("Redeal changed the pile sizes: %v %v", x, y)
+		}
+		seen := make(map[string]bool)
+		for _, c := range append(x, y...) {
+			seen[c.Code] = true
+		}
+		if len(seen) != 5 {
+			t.Fatalf("Redeal lost cards: %v %v", x, y)
+		}
+		moved = moved || x[0].Code != original.Code
+	}
+	if !moved || a[0].Code != original.Code {
+		t.Errorf("Redeal should shuffle copies of the piles")
+	}
+}
@@ -0,0 +1,97 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+
+	"cardGame/deck/model"
+)
+
+// Action is a move posted by a player. Type names the move and Cards, Value
+// and Amount carry its arguments, as each game defines them.
+type Action struct {
+	Type   string   `json:"type"`
+	Cards  []string `json:"cards,omitempty"`
+	Value  string   `json:"value,omitempty"`
+	Amount int      `json:"amount,omitempty"`
+}
+
+func (a Action) Equal(other Action) bool {
+	if a.Type != other.Type || a.Value != other.Value || a.Amount != other.Amount || len(a.Cards) != len(other.Cards) {
+		return false
+	}
+	for i := range a.Cards {
+		if a.Cards[i] != other.Cards[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Config is what a game is set up with besides its deck. Options hold
+// game-specific settings such as a variant name.
+type Config struct {
+	Players []string          `json:"players"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// Game is implemented by every card game the server hosts. Implementations
+// don't need to be safe for concurrent use; the host serialises calls.
+type Game interface {
+	// Setup deals the game from deck. It is called once, before anything else.
+	Setup(deck model.Deck, config Config) error
+	Players() []string
+	// LegalActions lists what player may do now, empty when it is not their
+	// turn.
+	LegalActions(player string) []Action
+	Apply(player string, action Action) error
+	Terminal() bool
+	Scores() map[string]int
+	// View returns the state as player may see it. Anyone who is not a player
+	// gets the public view. The host encodes the view before it lets go of the
+	// game, so the view may share the game's state.
+	View(player string) interface{}
+}
+
+// DeckProvider is implemented by games that are not dealt from a single
+// standard deck. The host calls NewDeck when no deck is given at creation.
+type DeckProvider interface {
+	NewDeck(config Config) model.Deck
+}
+
+// Bank holds the chips staked in games. Reserve moves a player's chips into
+// a pot and Settle pays the whole pot out, less rake. Games the house banks
+// use SettleHouse instead: the house takes what is left of the pot after the
+// payouts, or makes up what it is short when house is negative. A key makes
+// each call idempotent, so a retried call is only applied once.
+type Bank interface {
+	Reserve(key, pot, player string, amount int64) error
+	Settle(key, pot string, payouts map[string]int64, rake int64) error
+	SettleHouse(key, pot string, payouts map[string]int64, house int64) error
+}
+
+// Banked is implemented by games played for chips. The host gives them its
+// bank, and a pot named after the game, before Setup.
+type Banked interface {
+	SetBank(bank Bank, pot string)
+}
+
+// Toolbox is implemented by games that offer tools away from any hosted
+// game, such as a hand scorer. The host serves them under
+// /games/{type}/tools/{tool} and runs them on a game that was never set up.
+type Toolbox interface {
+	Tool(name string, request json.RawMessage) (interface{}, error)
+}
+
+// ErrUnknownTool is returned by Tool for a tool the game does not offer.
+var ErrUnknownTool = errors.New("Unknown tool")
+
+// IsLegal reports whether action is among the player's legal actions.
+func IsLegal(g Game, player string, action Action) bool {
+	for _, legal := range g.LegalActions(player) {
+		if legal.Equal(action) {
+			return true
+		}
+	}
+	return false
+}
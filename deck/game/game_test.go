@@ -0,0 +1,57 @@
+package game
+
+import (
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register("highcard", NewHighCard); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("highcard", NewHighCard); err == nil {
+		t.Errorf("Register accepted a duplicate name")
+	}
+	if _, err := registry.New("poker"); err == nil {
+		t.Errorf("New returned a game for an unknown type")
+	}
+	if types := registry.Types(); len(types) != 1 || types[0] != "highcard" {
+		t.Errorf("Unexpected types: %v", types)
+	}
+}
+
+func TestHighCard(t *testing.T) {
+	g := NewHighCard()
+	deck := model.NewDeck(false, "2S,AH,KD")
+	if err := g.Setup(deck, Config{Players: []string{"ann", "bob", "cid"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Apply("bob", Action{Type: "draw"}); err == nil {
+		t.Errorf("Apply accepted a move out of turn")
+	}
+	if err := g.Apply("ann", Action{Type: "fold"}); err == nil {
+		t.Errorf("Apply accepted an unknown action")
+	}
+	for _, player := range g.Players() {
+		if !IsLegal(g, player, Action{Type: "draw"}) {
+			t.Fatalf("Draw should be legal for %v", player)
+		}
+		if err := g.Apply(player, Action{Type: "draw"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !g.Terminal() {
+		t.Fatalf("Game should be over")
+	}
+	scores := g.Scores()
+	if scores["bob"] != 1 || scores["ann"] != 0 || scores["cid"] != 0 {
+		t.Errorf("Unexpected scores: %v", scores)
+	}
+	if view := g.View("").(HighCardView); view.Drawn["cid"].Code != "KD" || view.Turn != "" {
+		t.Errorf("Unexpected view: %+v", view)
+	}
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"fmt"
+
+	"cardGame/deck/model"
+)
+
+// HighCard is the smallest useful game: each player in turn draws one card
+// and the highest rank wins. It serves as the reference implementation of
+// Game.
+type HighCard struct {
+	players []string
+	deck    model.Deck
+	drawn   map[string]model.Card
+}
+
+type HighCardView struct {
+	Drawn map[string]model.Card `json:"drawn"`
+	Turn  string                `json:"turn,omitempty"`
+}
+
+func NewHighCard() Game {
+	return &HighCard{}
+}
+
+func (g *HighCard) Setup(deck model.Deck, config Config) error {
+	if len(config.Players) < 2 {
+		return fmt.Errorf("High card needs at least 2 players")
+	}
+	if len(deck.Cards) < len(config.Players) {
+		return fmt.Errorf("Not enough cards for %v players", len(config.Players))
+	}
+	g.players = config.Players
+	g.deck = deck
+	g.drawn = make(map[string]model.Card)
+	return nil
+}
+
+func (g *HighCard) Players() []string {
+	return g.players
+}
+
+func (g *HighCard) turn() string {
+	if len(g.drawn) == len(g.players) {
+		return ""
+	}
+	return g.players[len(g.drawn)]
+}
+
+func (g *HighCard) LegalActions(player string) []Action {
+	if player == "" || player != g.turn() {
+		return nil
+	}
+	return []Action{{Type: "draw"}}
+}
+
+func (g *HighCard) Apply(player string, action Action) error {
+	if !IsLegal(g, player, action) {
+		return fmt.Errorf("Illegal action %q for %v", action.Type, player)
+	}
+	cards, _ := g.deck.DrawCards(1)
+	g.drawn[player] = cards[0]
+	return nil
+}
+
+func (g *HighCard) Terminal() bool {
+	return g.turn() == ""
+}
+
+func (g *HighCard) Scores() map[string]int {
+	best := 0
+	for _, c := range g.drawn {
+		if c.Rank() > best {
+			best = c.Rank()
+		}
+	}
+	scores := make(map[string]int)
+	for _, player := range g.players {
+		scores[player] = 0
+		if c, ok := g.drawn[player]; ok && g.Terminal() && c.Rank() == best {
+			scores[player] = 1
+		}
+	}
+	return scores
+}
+
+func (g *HighCard) View(player string) interface{} {
+	return HighCardView{Drawn: g.drawn, Turn: g.turn()}
+}
@@ -0,0 +1,57 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory returns a new game, ready for Setup.
+type Factory func() Game
+
+// Registry maps game type names to their implementations.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+	}
+}
+
+func (r *Registry) Register(name string, factory Factory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.factories[name]; exists {
+		return fmt.Errorf("Game type %q is already registered", name)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+func (r *Registry) New(name string) (Game, error) {
+	r.mu.RLock()
+	factory, found := r.factories[name]
+	r.mu.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("Unknown game type %q", name)
+	}
+	return factory(), nil
+}
+
+// Types returns the registered names in alphabetical order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,270 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cashier is the account chips are bought from and cashed out to. Its
+// balance is minus the chips in play.
+const Cashier = "cashier"
+
+// Rake is the account the house's cut of pots is paid to.
+const Rake = "rake"
+
+// House is the bankroll behind the games the house banks: it covers the
+// winnings that come out of its pots and takes the stakes lost in them. Its
+// balance is minus what the house has paid out on balance.
+const House = "house"
+
+// Entry kinds.
+const (
+	BuyIn    = "buy_in"
+	CashOut  = "cash_out"
+	Bet      = "bet"
+	Settle   = "settle"
+	Transfer = "transfer"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("Insufficient funds")
+	// ErrKeyReused means an idempotency key was sent again with a different
+	// entry.
+	ErrKeyReused = errors.New("Idempotency key already used for a different entry")
+)
+
+// mayOverdraw reports whether account may go negative. Only the cashier
+// and the house, which issue chips, may.
+func mayOverdraw(account string) bool {
+	return account == Cashier || account == House
+}
+
+func Player(name string) string {
+	return "player:" + name
+}
+
+func Pot(id string) string {
+	return "pot:" + id
+}
+
+// Posting moves Amount chips into Account, or out of it when negative.
+type Posting struct {
+	Account string `json:"account"`
+	Amount  int64  `json:"amount"`
+}
+
+// Entry is one journal entry. Its postings sum to zero, so every chip that
+// leaves one account arrives in another.
+type Entry struct {
+	ID       int       `json:"id"`
+	Key      string    `json:"key,omitempty"`
+	Kind     string    `json:"kind"`
+	Memo     string    `json:"memo,omitempty"`
+	Time     time.Time `json:"time"`
+	Postings []Posting `json:"postings"`
+}
+
+// Line is an entry as it affects one account.
+type Line struct {
+	Entry   Entry `json:"entry"`
+	Change  int64 `json:"change"`
+	Balance int64 `json:"balance"`
+}
+
+// Ledger is an append-only double-entry journal with the balances it
+// implies. It is safe for concurrent use; each entry is applied whole or not
+// at all.
+type Ledger struct {
+	mu       sync.Mutex
+	entries  []Entry
+	balances map[string]int64
+	keys     map[string]int
+}
+
+func New() *Ledger {
+	return &Ledger{
+		balances: make(map[string]int64),
+		keys:     make(map[string]int),
+	}
+}
+
+// Post records an entry. Postings to the same account are merged. An entry
+// that would leave any account but the cashier or the house negative is
+// rejected. A
+// non-empty key makes the post idempotent: posting the same entry under the
+// same key again returns the first without applying it twice.
+func (l *Ledger) Post(key, kind, memo string, postings []Posting) (Entry, error) {
+	merged, err := merge(postings)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.post(key, kind, memo, merged)
+}
+
+// Drain empties account into postings, which must add up to its balance,
+// as one entry. The balance is checked and the entry posted together, so
+// nothing can reach the account in between.
+func (l *Ledger) Drain(key, kind, memo, account string, postings []Posting) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var total int64
+	for _, p := range postings {
+		total += p.Amount
+	}
+	if i, ok := l.keys[key]; ok && key != "" {
+		total = -l.entries[i].amount(account)
+	} else if total != l.balances[account] {
+		return Entry{}, fmt.Errorf("%v holds %v, not %v", account, l.balances[account], total)
+	}
+	merged, err := merge(append(postings, Posting{Account: account, Amount: -total}))
+	if err != nil {
+		return Entry{}, err
+	}
+	return l.post(key, kind, memo, merged)
+}
+
+func (e Entry) amount(account string) int64 {
+	for _, p := range e.Postings {
+		if p.Account == account {
+			return p.Amount
+		}
+	}
+	return 0
+}
+
+func (l *Ledger) post(key, kind, memo string, merged []Posting) (Entry, error) {
+	if i, ok := l.keys[key]; ok && key != "" {
+		first := l.entries[i]
+		if first.Kind != kind || first.Memo != memo || !reflect.DeepEqual(first.Postings, merged) {
+			return Entry{}, ErrKeyReused
+		}
+		return first, nil
+	}
+	for _, p := range merged {
+		if !mayOverdraw(p.Account) && l.balances[p.Account]+p.Amount < 0 {
+			return Entry{}, fmt.Errorf("%w in %v", ErrInsufficientFunds, p.Account)
+		}
+	}
+
+	entry := Entry{ID: len(l.entries) + 1, Key: key, Kind: kind, Memo: memo, Time: time.Now(), Postings: merged}
+	for _, p := range merged {
+		l.balances[p.Account] += p.Amount
+	}
+	l.entries = append(l.entries, entry)
+	if key != "" {
+		l.keys[key] = len(l.entries) - 1
+	}
+	return entry, nil
+}
+
+// merge sums postings per account, drops those that net to zero and sorts
+// them by account, checking the entry balances.
+func merge(postings []Posting) ([]Posting, error) {
+	sums := make(map[string]int64)
+	var total int64
+	for _, p := range postings {
+		if p.Account == "" || strings.TrimSpace(p.Account) != p.Account {
+			return nil, fmt.Errorf("Invalid account %q", p.Account)
+		}
+		sums[p.Account] += p.Amount
+		total += p.Amount
+	}
+	if total != 0 {
+		return nil, fmt.Errorf("Entry does not balance: postings sum to %v", total)
+	}
+
+	merged := []Posting{}
+	for account, amount := range sums {
+		if amount != 0 {
+			merged = append(merged, Posting{Account: account, Amount: amount})
+		}
+	}
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("Entry moves no chips")
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Account < merged[j].Account })
+	return merged, nil
+}
+
+func (l *Ledger) Balance(account string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.balances[account]
+}
+
+// Balances returns every account with a non-zero balance.
+func (l *Ledger) Balances() map[string]int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	balances := make(map[string]int64)
+	for account, balance := range l.balances {
+		if balance != 0 {
+			balances[account] = balance
+		}
+	}
+	return balances
+}
+
+// History lists the entries touching account, oldest first, with its
+// running balance.
+func (l *Ledger) History(account string) []Line {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lines := []Line{}
+	var balance int64
+	for _, e := range l.entries {
+		for _, p := range e.Postings {
+			if p.Account == account {
+				balance += p.Amount
+				lines = append(lines, Line{Entry: e, Change: p.Amount, Balance: balance})
+			}
+		}
+	}
+	return lines
+}
+
+func (l *Ledger) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Entry{}, l.entries...)
+}
+
+// Audit replays the journal and checks that every entry balances, that no
+// account but the cashier or the house ever went negative and that the replayed balances
+// match the ledger's.
+func (l *Ledger) Audit() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	replayed := make(map[string]int64)
+	for _, e := range l.entries {
+		var total int64
+		for _, p := range e.Postings {
+			total += p.Amount
+			replayed[p.Account] += p.Amount
+			if !mayOverdraw(p.Account) && replayed[p.Account] < 0 {
+				return fmt.Errorf("Entry %v overdraws %v", e.ID, p.Account)
+			}
+		}
+		if total != 0 {
+			return fmt.Errorf("Entry %v does not balance", e.ID)
+		}
+	}
+	for account, balance := range l.balances {
+		if replayed[account] != balance {
+			return fmt.Errorf("Balance of %v is %v but the journal gives %v", account, balance, replayed[account])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package ledger
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestPostBalancesAndIdempotency(t *testing.T) {
+	l := New()
+	buyIn := []Posting{{Account: Cashier, Amount: -500}, {Account: Player("ann"), Amount: 500}}
+	first, err := l.Post("buy-1", BuyIn, "", buyIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := l.Post("buy-1", BuyIn, "", buyIn)
+	if err != nil || again.ID != first.ID || l.Balance(Player("ann")) != 500 {
+		t.Errorf("Retried post applied twice: %+v %v", again, l.Balance(Player("ann")))
+	}
+	if _, err := l.Post("buy-1", BuyIn, "", []Posting{{Account: Cashier, Amount: -600}, {Account: Player("ann"), Amount: 600}}); err != ErrKeyReused {
+		t.Errorf("Reused key with a different entry: %v", err)
+	}
+
+	if _, err := l.Post("", Transfer, "", []Posting{{Account: Player("ann"), Amount: -100}}); err == nil {
+		t.Errorf("Post accepted an unbalanced entry")
+	}
+	if _, err := l.Post("", Bet, "", []Posting{{Account: Player("ann"), Amount: -501}, {Account: Pot("1"), Amount: 501}}); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("Post let ann overdraw: %v", err)
+	}
+	if l.Balance(Pot("1")) != 0 {
+		t.Errorf("Rejected entry was partly applied")
+	}
+
+	l.Post("", Bet, "", []Posting{{Account: Player("ann"), Amount: -200}, {Account: Pot("1"), Amount: 200}})
+	history := l.History(Player("ann"))
+	if len(history) != 2 || history[1].Change != -200 || history[1].Balance != 300 {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+	if err := l.Audit(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	l := New()
+	l.Post("", BuyIn, "", []Posting{{Account: Cashier, Amount: -100}, {Account: Pot("p"), Amount: 100}})
+
+	payout := []Posting{{Account: Player("bob"), Amount: 90}, {Account: Rake, Amount: 10}}
+	if _, err := l.Drain("", Settle, "", Pot("p"), payout[:1]); err == nil {
+		t.Errorf("Drain left chips in the pot")
+	}
+	if _, err := l.Drain("settle-p", Settle, "", Pot("p"), payout); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Drain("settle-p", Settle, "", Pot("p"), payout); err != nil {
+		t.Errorf("Retried drain failed: %v", err)
+	}
+	if l.Balance(Pot("p")) != 0 || l.Balance(Player("bob")) != 90 || l.Balance(Rake) != 10 || len(l.Entries()) != 2 {
+		t.Errorf("Unexpected balances: %v", l.Balances())
+	}
+}
+
+func TestConcurrentPostsNeverOverdraw(t *testing.T) {
+	l := New()
+	l.Post("", BuyIn, "", []Posting{{Account: Cashier, Amount: -100}, {Account: Player("ann"), Amount: 100}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Post("", Bet, "", []Posting{{Account: Player("ann"), Amount: -10}, {Account: Pot("p"), Amount: 10}})
+		}()
+	}
+	wg.Wait()
+	if l.Balance(Player("ann")) != 0 || l.Balance(Pot("p")) != 100 {
+		t.Errorf("Unexpected balances: %v", l.Balances())
+	}
+	if err := l.Audit(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,135 @@
+package lobby
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultBandWidth is the width of the skill bands the queue groups by.
+const DefaultBandWidth = 200
+
+// Ticket is a player waiting in the matchmaking queue for a game of Size
+// players. Players who give a Skill are only grouped with others in the same
+// band; those who don't are grouped with each other.
+type Ticket struct {
+	Player   string    `json:"player"`
+	GameType string    `json:"game_type"`
+	Size     int       `json:"size"`
+	Skill    *int      `json:"skill,omitempty"`
+	Queued   time.Time `json:"queued"`
+}
+
+type bucket struct {
+	gameType string
+	size     int
+	band     int
+	banded   bool
+}
+
+// Queue groups tickets first come, first served. It is not safe for
+// concurrent use.
+type Queue struct {
+	BandWidth int
+	buckets   map[bucket][]Ticket
+}
+
+func NewQueue() *Queue {
+	return &Queue{BandWidth: DefaultBandWidth, buckets: make(map[bucket][]Ticket)}
+}
+
+func (q *Queue) bucket(t Ticket) bucket {
+	b := bucket{gameType: t.GameType, size: t.Size}
+	if t.Skill != nil {
+		b.banded = true
+		b.band = *t.Skill / q.BandWidth
+		if *t.Skill < 0 && *t.Skill%q.BandWidth != 0 {
+			b.band--
+		}
+	}
+	return b
+}
+
+// Band returns the skill band a ticket is matched in, or false if it has no
+// skill.
+func (q *Queue) Band(t Ticket) (int, bool) {
+	b := q.bucket(t)
+	return b.band, b.banded
+}
+
+// Add queues a ticket. Once its bucket holds enough players for a game,
+// they are taken off the queue and returned in the order they queued.
+func (q *Queue) Add(t Ticket) ([]Ticket, error) {
+	if t.Size < 1 {
+		return nil, fmt.Errorf("A game needs at least 1 player")
+	}
+	if q.Contains(t.Player) {
+		return nil, fmt.Errorf("%v is already queued", t.Player)
+	}
+
+	b := q.bucket(t)
+	q.buckets[b] = append(q.buckets[b], t)
+	if len(q.buckets[b]) < t.Size {
+		return nil, nil
+	}
+	group := q.buckets[b][:t.Size]
+	q.buckets[b] = q.buckets[b][t.Size:]
+	if len(q.buckets[b]) == 0 {
+		delete(q.buckets, b)
+	}
+	return group, nil
+}
+
+// Return puts a group Add took off the queue back at the front of its
+// bucket, in the order it queued.
+func (q *Queue) Return(group []Ticket) {
+	for i := len(group) - 1; i >= 0; i-- {
+		b := q.bucket(group[i])
+		q.buckets[b] = append([]Ticket{group[i]}, q.buckets[b]...)
+	}
+}
+
+// Remove takes player's ticket off the queue and reports whether they had
+// one.
+func (q *Queue) Remove(player string) bool {
+	for b, tickets := range q.buckets {
+		for i, t := range tickets {
+			if t.Player != player {
+				continue
+			}
+			q.buckets[b] = append(tickets[:i:i], tickets[i+1:]...)
+			if len(q.buckets[b]) == 0 {
+				delete(q.buckets, b)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (q *Queue) Contains(player string) bool {
+	_, ok := q.Ticket(player)
+	return ok
+}
+
+// Ticket returns player's ticket if they are queued.
+func (q *Queue) Ticket(player string) (Ticket, bool) {
+	for _, tickets := range q.buckets {
+		for _, t := range tickets {
+			if t.Player == player {
+				return t, true
+			}
+		}
+	}
+	return Ticket{}, false
+}
+
+// Waiting counts the tickets queued for a game type.
+func (q *Queue) Waiting(gameType string) int {
+	count := 0
+	for b, tickets := range q.buckets {
+		if b.gameType == gameType {
+			count += len(tickets)
+		}
+	}
+	return count
+}
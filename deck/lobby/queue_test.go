@@ -0,0 +1,54 @@
+package lobby
+
+import "testing"
+
+func skill(n int) *int {
+	return &n
+}
+
+func TestQueueGroupsByTypeAndBand(t *testing.T) {
+	q := NewQueue()
+	add := func(ticket Ticket) []Ticket {
+		t.Helper()
+		group, err := q.Add(ticket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return group
+	}
+
+	if group := add(Ticket{Player: "ann", GameType: "war", Size: 2, Skill: skill(1450)}); group != nil {
+		t.Fatalf("Matched a lone player: %v", group)
+	}
+	if group := add(Ticket{Player: "bob", GameType: "war", Size: 2, Skill: skill(1650)}); group != nil {
+		t.Errorf("Matched players from different bands: %v", group)
+	}
+	if group := add(Ticket{Player: "cat", GameType: "speed", Size: 2, Skill: skill(1500)}); group != nil {
+		t.Errorf("Matched players for different games: %v", group)
+	}
+	if group := add(Ticket{Player: "dan", GameType: "war", Size: 2}); group != nil {
+		t.Errorf("Matched a player without skill with a banded one: %v", group)
+	}
+	if _, err := q.Add(Ticket{Player: "ann", GameType: "war", Size: 2}); err == nil {
+		t.Errorf("Queued ann twice")
+	}
+
+	group := add(Ticket{Player: "eve", GameType: "war", Size: 2, Skill: skill(1599)})
+	if len(group) != 2 || group[0].Player != "ann" || group[1].Player != "eve" {
+		t.Fatalf("Expected ann and eve, got %v", group)
+	}
+	if q.Contains("ann") || q.Waiting("war") != 2 {
+		t.Errorf("Matched players should leave the queue: %v waiting", q.Waiting("war"))
+	}
+	q.Return(group[:1])
+	if group := add(Ticket{Player: "fay", GameType: "war", Size: 2, Skill: skill(1500)}); len(group) != 2 || group[0].Player != "ann" {
+		t.Errorf("A returned ticket should keep its place: %v", group)
+	}
+
+	if band, ok := q.Band(Ticket{Skill: skill(-1)}); !ok || band != -1 {
+		t.Errorf("Negative skill should round down, got band %v", band)
+	}
+	if !q.Remove("dan") || q.Remove("dan") {
+		t.Errorf("Remove should succeed once")
+	}
+}
@@ -0,0 +1,197 @@
+package lobby
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Visibility says who can find and join a room.
+type Visibility string
+
+const (
+	// Public rooms are listed in the lobby.
+	Public Visibility = "public"
+	// Private rooms are not listed; anyone given the room ID may join.
+	Private Visibility = "private"
+	// Invite rooms are not listed and need the room's invite code to join.
+	Invite Visibility = "invite"
+)
+
+type Status string
+
+const (
+	Waiting Status = "waiting"
+	Playing Status = "playing"
+)
+
+var (
+	ErrRoomNotFound   = errors.New("Room not found")
+	ErrBadInviteCode  = errors.New("Wrong invite code")
+	ErrNotHost        = errors.New("Only the host may do that")
+	ErrNotMember      = errors.New("Player is not in the room")
+	ErrAlreadyStarted = errors.New("Game has already started")
+)
+
+type Member struct {
+	Player string `json:"player"`
+	Ready  bool   `json:"ready"`
+}
+
+// Room gathers players for one game. The first member is the host; when
+// the host leaves, the next member to have joined takes over.
+type Room struct {
+	ID         uuid.UUID         `json:"id"`
+	GameType   string            `json:"game_type"`
+	Capacity   int               `json:"capacity"`
+	Visibility Visibility        `json:"visibility"`
+	InviteCode string            `json:"invite_code,omitempty"`
+	Options    map[string]string `json:"options,omitempty"`
+	Members    []Member          `json:"members"`
+	Status     Status            `json:"status"`
+	GameID     *uuid.UUID        `json:"game_id,omitempty"`
+	Created    time.Time         `json:"created"`
+}
+
+func NewRoom(host, gameType string, capacity int, visibility Visibility, options map[string]string) (*Room, error) {
+	if host == "" {
+		return nil, fmt.Errorf("A room needs a host")
+	}
+	if capacity < 1 {
+		return nil, fmt.Errorf("Capacity must be at least 1")
+	}
+	switch visibility {
+	case "":
+		visibility = Public
+	case Public, Private, Invite:
+	default:
+		return nil, fmt.Errorf("Unknown visibility %q", visibility)
+	}
+
+	room := &Room{
+		ID:         uuid.New(),
+		GameType:   gameType,
+		Capacity:   capacity,
+		Visibility: visibility,
+		Options:    options,
+		Members:    []Member{{Player: host}},
+		Status:     Waiting,
+		Created:    time.Now(),
+	}
+	if visibility == Invite {
+		room.InviteCode = newInviteCode()
+	}
+	return room, nil
+}
+
+// inviteAlphabet leaves out letters and digits that are easily confused.
+const inviteAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+func newInviteCode() string {
+	code := make([]byte, 6)
+	rand.Read(code)
+	for i := range code {
+		code[i] = inviteAlphabet[int(code[i])%len(inviteAlphabet)]
+	}
+	return string(code)
+}
+
+func (r *Room) Host() string {
+	if len(r.Members) == 0 {
+		return ""
+	}
+	return r.Members[0].Player
+}
+
+func (r *Room) member(player string) int {
+	for i, m := range r.Members {
+		if m.Player == player {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *Room) Has(player string) bool {
+	return r.member(player) >= 0
+}
+
+// Players lists the members in the order they joined.
+func (r *Room) Players() []string {
+	players := make([]string, len(r.Members))
+	for i, m := range r.Members {
+		players[i] = m.Player
+	}
+	return players
+}
+
+func (r *Room) Join(player, inviteCode string) error {
+	switch {
+	case r.Status != Waiting:
+		return ErrAlreadyStarted
+	case r.Has(player):
+		return nil
+	case r.Visibility == Invite && inviteCode != r.InviteCode:
+		return ErrBadInviteCode
+	case len(r.Members) >= r.Capacity:
+		return fmt.Errorf("Room is full")
+	}
+	r.Members = append(r.Members, Member{Player: player})
+	return nil
+}
+
+// Leave removes player and reports whether the room is now empty.
+func (r *Room) Leave(player string) (bool, error) {
+	i := r.member(player)
+	if i < 0 {
+		return false, ErrNotMember
+	}
+	r.Members = append(r.Members[:i:i], r.Members[i+1:]...)
+	return len(r.Members) == 0, nil
+}
+
+func (r *Room) SetReady(player string, ready bool) error {
+	i := r.member(player)
+	if i < 0 {
+		return ErrNotMember
+	}
+	if r.Status != Waiting {
+		return ErrAlreadyStarted
+	}
+	r.Members[i].Ready = ready
+	return nil
+}
+
+// AllReady reports whether every member is ready.
+func (r *Room) AllReady() bool {
+	for _, m := range r.Members {
+		if !m.Ready {
+			return false
+		}
+	}
+	return len(r.Members) > 0
+}
+
+// Full reports whether the room has reached capacity.
+func (r *Room) Full() bool {
+	return len(r.Members) >= r.Capacity
+}
+
+// Listed reports whether the room shows in the lobby.
+func (r *Room) Listed() bool {
+	return r.Visibility == Public && r.Status == Waiting
+}
+
+// ViewFor returns the room as player sees it: only members see the invite
+// code.
+func (r *Room) ViewFor(player string) Room {
+	view := *r
+	view.Members = append([]Member{}, r.Members...)
+	if !r.Has(player) {
+		view.InviteCode = ""
+	}
+	return view
+}
@@ -0,0 +1,73 @@
+package lobby
+
+import "testing"
+
+func TestRoomJoinLeave(t *testing.T) {
+	room, err := NewRoom("ann", "cribbage", 2, Invite, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(room.InviteCode) != 6 {
+		t.Fatalf("Invite room has no code: %q", room.InviteCode)
+	}
+	if view := room.ViewFor("bob"); view.InviteCode != "" {
+		t.Errorf("Invite code shown to a non-member")
+	}
+
+	if err := room.Join("bob", "WRONG1"); err != ErrBadInviteCode {
+		t.Errorf("Join with a wrong code: %v", err)
+	}
+	if err := room.Join("bob", room.InviteCode); err != nil {
+		t.Fatal(err)
+	}
+	if err := room.Join("cat", room.InviteCode); err == nil {
+		t.Errorf("Join accepted a player into a full room")
+	}
+	if !room.Full() || room.AllReady() {
+		t.Errorf("Room should be full and not ready: %+v", room.Members)
+	}
+
+	if empty, err := room.Leave("ann"); err != nil || empty {
+		t.Fatalf("Leave: %v %v", empty, err)
+	}
+	if room.Host() != "bob" {
+		t.Errorf("Host should pass to bob, got %v", room.Host())
+	}
+	if _, err := room.Leave("ann"); err != ErrNotMember {
+		t.Errorf("Leave twice: %v", err)
+	}
+	if empty, _ := room.Leave("bob"); !empty {
+		t.Errorf("Room should be empty")
+	}
+}
+
+func TestRoomReady(t *testing.T) {
+	if _, err := NewRoom("ann", "war", 0, Public, nil); err == nil {
+		t.Errorf("NewRoom accepted a capacity of 0")
+	}
+	if _, err := NewRoom("ann", "war", 2, "secret", nil); err == nil {
+		t.Errorf("NewRoom accepted an unknown visibility")
+	}
+
+	room, _ := NewRoom("ann", "war", 2, "", nil)
+	if room.Visibility != Public || !room.Listed() {
+		t.Errorf("Rooms should default to public: %+v", room)
+	}
+	room.Join("bob", "")
+	if err := room.SetReady("cat", true); err != ErrNotMember {
+		t.Errorf("SetReady for a non-member: %v", err)
+	}
+	room.SetReady("ann", true)
+	room.SetReady("bob", true)
+	if !room.AllReady() {
+		t.Errorf("Room should be ready: %+v", room.Members)
+	}
+
+	room.Status = Playing
+	if err := room.Join("cat", ""); err != ErrAlreadyStarted {
+		t.Errorf("Join after the start: %v", err)
+	}
+	if room.Listed() {
+		t.Errorf("A playing room should not be listed")
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"math/rand"
 	"strings"
 	"time"
@@ -8,6 +9,9 @@ import (
 	"github.com/google/uuid"
 )
 
+var Values = []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "JACK", "QUEEN", "KING", "ACE"}
+var Suits = []string{"SPADES", "DIAMONDS", "CLUBS", "HEARTS"}
+
 type Card struct {
 	Value string `json:"value"`
 	Suit  string `json:"suit"`
@@ -15,22 +19,44 @@ type Card struct {
 }
 
 type Deck struct {
-	ID        uuid.UUID `json:"deck_id"`
-	Shuffled  bool      `json:"shuffled"`
-	Remaining int       `json:"remaining"`
-	Cards     []Card    `json:"cards"`
+	ID        uuid.UUID         `json:"deck_id"`
+	Shuffled  bool              `json:"shuffled"`
+	Remaining int               `json:"remaining"`
+	Cards     []Card            `json:"cards"`
+	Piles     map[string][]Card `json:"piles,omitempty"`
+	// Owner is the player ID of the account the deck belongs to, if any.
+	Owner string `json:"owner,omitempty"`
+	// Key is the ID of the API key that created the deck, and Grants the
+	// other keys it was shared with.
+	Key    string   `json:"key,omitempty"`
+	Grants []string `json:"grants,omitempty"`
+	// Game is the hosted game or bridge table the deck was dealt to. Its cards
+	// belong to the game from then on, so the deck is not drawn from or dealt
+	// again. A bridge deck is released when its last table records a result.
+	Game *uuid.UUID `json:"game,omitempty"`
 }
 
 func NewDeck(shuffled bool, cards string) Deck {
+	var rng *rand.Rand
+	if shuffled {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return newDeck(rng, cards)
+}
+
+// NewSeededDeck returns a shuffled deck whose order is fully determined by seed,
+// so the same seed always reproduces the same deal.
+func NewSeededDeck(seed int64, cards string) Deck {
+	return newDeck(rand.New(rand.NewSource(seed)), cards)
+}
+
+func newDeck(rng *rand.Rand, cards string) Deck {
 	var deckID uuid.UUID
 	deckID, _ = uuid.NewUUID()
 
 	var allCards []Card
-	values := []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "JACK", "QUEEN", "KING", "ACE"}
-	suits := []string{"SPADES", "DIAMONDS", "CLUBS", "HEARTS"}
-
-	for _, suit := range suits {
-		for _, value := range values {
+	for _, suit := range Suits {
+		for _, value := range Values {
 			card := Card{Value: value, Suit: suit, Code: value[:1] + strings.ToUpper(suit[:1])}
 			allCards = append(allCards, card)
 		}
@@ -40,21 +66,49 @@ func NewDeck(shuffled bool, cards string) Deck {
 		allCards = filterDeck(allCards, cards)
 	}
 
-	if shuffled {
-		rand.Seed(time.Now().UnixNano())
-		rand.Shuffle(len(allCards), func(i, j int) {
+	if rng != nil {
+		rng.Shuffle(len(allCards), func(i, j int) {
 			allCards[i], allCards[j] = allCards[j], allCards[i]
 		})
 	}
 
 	return Deck{
 		ID:        deckID,
-		Shuffled:  shuffled,
+		Shuffled:  rng != nil,
 		Remaining: len(allCards),
 		Cards:     allCards,
 	}
 }
 
+// CardFromCode returns the card with the given code, such as "AS" or "1D" for
+// the ten of diamonds.
+func CardFromCode(code string) (Card, bool) {
+	cards := filterDeck(newDeck(nil, "").Cards, code)
+	if len(cards) != 1 {
+		return Card{}, false
+	}
+	return cards[0], true
+}
+
+// CardsFromCodes returns the cards with the given codes, refusing unknown
+// codes and cards given twice.
+func CardsFromCodes(codes []string) ([]Card, error) {
+	seen := make(map[string]bool)
+	var cards []Card
+	for _, code := range codes {
+		c, ok := CardFromCode(code)
+		if !ok {
+			return nil, fmt.Errorf("Invalid card code %q", code)
+		}
+		if seen[c.Code] {
+			return nil, fmt.Errorf("Card %v appears twice", c.Code)
+		}
+		seen[c.Code] = true
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
+
 func filterDeck(allCards []Card, cards string) []Card {
 	cardCodes := strings.Split(cards, ",")
 	var filteredDeck []Card
@@ -82,3 +136,93 @@ func (d *Deck) DrawCards(count int) ([]Card, bool) {
 
 	return drawnCards, true
 }
+
+// AddToPile puts cards face up on top of the named pile, creating it if needed.
+func (d *Deck) AddToPile(name string, cards ...Card) {
+	if d.Piles == nil {
+		d.Piles = make(map[string][]Card)
+	}
+	d.Piles[name] = append(d.Piles[name], cards...)
+}
+
+// DrawFromPile takes count cards off the top of the named pile, the top card
+// last.
+func (d *Deck) DrawFromPile(name string, count int) ([]Card, bool) {
+	pile := d.Piles[name]
+	if count > len(pile) {
+		return nil, false
+	}
+
+	drawnCards := append([]Card{}, pile[len(pile)-count:]...)
+	d.Piles[name] = pile[:len(pile)-count]
+	return drawnCards, true
+}
+
+// TopOfPile returns the top card of the named pile without removing it.
+func (d *Deck) TopOfPile(name string) (Card, bool) {
+	pile := d.Piles[name]
+	if len(pile) == 0 {
+		return Card{}, false
+	}
+	return pile[len(pile)-1], true
+}
+
+// Clone returns a copy of the deck that shares no cards or piles with it.
+func (d Deck) Clone() Deck {
+	clone := d
+	clone.Cards = append([]Card{}, d.Cards...)
+	clone.Grants = append([]string(nil), d.Grants...)
+	if d.Piles != nil {
+		clone.Piles = make(map[string][]Card, len(d.Piles))
+		for name, pile := range d.Piles {
+			clone.Piles[name] = append([]Card{}, pile...)
+		}
+	}
+	return clone
+}
+
+// UsableBy reports whether player may draw from and deal the deck. Anyone
+// may use a deck without an owner.
+func (d Deck) UsableBy(player string) bool {
+	return d.Owner == "" || d.Owner == player
+}
+
+// KeyAllows reports whether the API key keyID may read, draw from and
+// delete the deck. Decks created without a key are open to all.
+func (d Deck) KeyAllows(keyID string) bool {
+	if d.Key == "" || d.Key == keyID {
+		return true
+	}
+	for _, grant := range d.Grants {
+		if grant == keyID {
+			return true
+		}
+	}
+	return false
+}
+
+// Rank returns the card's position in Values counting from 2, so ACE ranks 14.
+// It returns 0 for cards with an unknown value.
+func (c Card) Rank() int {
+	for i, value := range Values {
+		if c.Value == value {
+			return i + 2
+		}
+	}
+	return 0
+}
+
+// SuitIndex returns the card's position in Suits, or -1 for an unknown suit.
+func (c Card) SuitIndex() int {
+	for i, suit := range Suits {
+		if c.Suit == suit {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsRed reports whether the card belongs to one of the red suits.
+func (c Card) IsRed() bool {
+	return c.Suit == "DIAMONDS" || c.Suit == "HEARTS"
+}
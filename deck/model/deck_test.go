@@ -77,3 +77,115 @@ func isShuffled(cards []Card) bool {
 	}
 	return true
 }
+
+func TestNewSeededDeck(t *testing.T) {
+	first := NewSeededDeck(42, "")
+	second := NewSeededDeck(42, "")
+	assertDeckProperties(t, first, 52, true)
+
+	for i := range first.Cards {
+		if first.Cards[i] != second.Cards[i] {
+			t.Fatalf("Seeded decks differ at position %v: got %v want %v", i, second.Cards[i], first.Cards[i])
+		}
+	}
+}
+
+func TestCardRank(t *testing.T) {
+	deck := NewDeck(false, "2S,1D,AH")
+	expected := []int{2, 10, 14}
+
+	for i, card := range deck.Cards {
+		if card.Rank() != expected[i] {
+			t.Errorf("Unexpected rank for %v: got %v want %v", card.Code, card.Rank(), expected[i])
+		}
+	}
+
+	if !deck.Cards[1].IsRed() || deck.Cards[0].IsRed() {
+		t.Errorf("Unexpected colour for %v or %v", deck.Cards[0].Code, deck.Cards[1].Code)
+	}
+}
+
+func TestPiles(t *testing.T) {
+	deck := NewDeck(false, "AS,KD,2C")
+	cards, _ := deck.DrawCards(3)
+	deck.AddToPile("discard", cards...)
+
+	if top, ok := deck.TopOfPile("discard"); !ok || top.Code != "2C" {
+		t.Errorf("TopOfPile returned %v, want 2C", top.Code)
+	}
+	drawn, ok := deck.DrawFromPile("discard", 2)
+	if !ok || len(drawn) != 2 || drawn[0].Code != "KD" || drawn[1].Code != "2C" {
+		t.Errorf("DrawFromPile returned unexpected cards: %v", drawn)
+	}
+	if _, ok := deck.DrawFromPile("discard", 2); ok {
+		t.Errorf("DrawFromPile took more cards than the pile holds")
+	}
+	if _, ok := deck.TopOfPile("missing"); ok {
+		t.Errorf("TopOfPile found a card on a pile that doesn't exist")
+	}
+}
+
+func TestClone(t *testing.T) {
+	deck := NewDeck(false, "AS,KD,2C,3H")
+	cards, _ := deck.DrawCards(2)
+	deck.AddToPile("discard", cards...)
+	deck.DrawFromPile("discard", 1)
+
+	clone := deck.Clone()
+	clone.Cards[0] = Card{Code: "XX"}
+	clone.AddToPile("discard", Card{Code: "YY"})
+	if deck.Cards[0].Code != "2C" || len(deck.Piles["discard"]) != 1 {
+		t.Errorf("Changing the clone changed the deck: %+v", deck)
+	}
+	if deck.Piles["discard"][:2][1].Code == "YY" {
+		t.Errorf("The clone shares a pile with the deck")
+	}
+}
+
+func TestCardFromCode(t *testing.T) {
+	card, ok := CardFromCode("qh")
+	if !ok || card.Value != "QUEEN" || card.Suit != "HEARTS" {
+		t.Errorf("CardFromCode(qh) returned %v", card)
+	}
+	if _, ok := CardFromCode("ZZ"); ok {
+		t.Errorf("CardFromCode accepted an unknown code")
+	}
+	if _, ok := CardFromCode("AS,KD"); ok {
+		t.Errorf("CardFromCode accepted a list of codes")
+	}
+}
+
+func TestCardsFromCodes(t *testing.T) {
+	cards, err := CardsFromCodes([]string{"qh", "2C"})
+	if err != nil || len(cards) != 2 || cards[0].Code != "QH" || cards[1].Code != "2C" {
+		t.Errorf("CardsFromCodes returned %v, %v", cards, err)
+	}
+	if _, err := CardsFromCodes([]string{"QH", "ZZ"}); err == nil {
+		t.Errorf("CardsFromCodes accepted an unknown code")
+	}
+	if _, err := CardsFromCodes([]string{"QH", "qh"}); err == nil {
+		t.Errorf("CardsFromCodes accepted a card twice")
+	}
+}
+
+func TestUsableBy(t *testing.T) {
+	deck := NewDeck(false, "AS")
+	if !deck.UsableBy("") || !deck.UsableBy("ann") {
+		t.Errorf("Anyone should be able to use an unowned deck")
+	}
+	deck.Owner = "ann"
+	if !deck.UsableBy("ann") || deck.UsableBy("bob") || deck.UsableBy("") {
+		t.Errorf("Only the owner should be able to use an owned deck")
+	}
+}
+
+func TestKeyAllows(t *testing.T) {
+	deck := NewDeck(false, "AS")
+	if !deck.KeyAllows("") || !deck.KeyAllows("k1") {
+		t.Errorf("Any key should be able to use a deck created without one")
+	}
+	deck.Key, deck.Grants = "k1", []string{"k2"}
+	if !deck.KeyAllows("k1") || !deck.KeyAllows("k2") || deck.KeyAllows("k3") || deck.KeyAllows("") {
+		t.Errorf("Only the creating and granted keys should be able to use the deck")
+	}
+}
@@ -0,0 +1,221 @@
+package rating
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Systems a leaderboard can be ranked by.
+const (
+	SystemElo    = "elo"
+	SystemGlicko = "glicko"
+)
+
+// Rating is a player's standing in one game type under both systems.
+type Rating struct {
+	Player     string    `json:"player"`
+	Elo        float64   `json:"elo"`
+	Glicko     Glicko    `json:"glicko"`
+	Games      int       `json:"games"`
+	Wins       int       `json:"wins"`
+	Losses     int       `json:"losses"`
+	Draws      int       `json:"draws"`
+	LastPlayed time.Time `json:"last_played"`
+}
+
+// Change is a player's ratings after one game.
+type Change struct {
+	Time   time.Time `json:"time"`
+	GameID string    `json:"game_id,omitempty"`
+	Place  int       `json:"place"`
+	Elo    float64   `json:"elo"`
+	Glicko float64   `json:"glicko"`
+}
+
+// Board keeps the ratings for one game type. It is not safe for concurrent
+// use.
+type Board struct {
+	K       float64
+	Tau     float64
+	ratings map[string]*Rating
+	history map[string][]Change
+}
+
+func NewBoard() *Board {
+	return &Board{
+		K:       DefaultK,
+		Tau:     DefaultTau,
+		ratings: make(map[string]*Rating),
+		history: make(map[string][]Change),
+	}
+}
+
+func (b *Board) rating(player string) *Rating {
+	r, ok := b.ratings[player]
+	if !ok {
+		r = &Rating{Player: player, Elo: InitialRating, Glicko: NewGlicko()}
+		b.ratings[player] = r
+	}
+	return r
+}
+
+// Record rates a finished game from each player's place, 1 being best and
+// equal places a tie. Two-player games are plain Elo and Glicko-2; larger
+// ones use the free-for-all extensions.
+func (b *Board) Record(places map[string]int, at time.Time, gameID string) ([]Rating, error) {
+	if len(places) < 2 {
+		return nil, fmt.Errorf("A rated game needs at least 2 players")
+	}
+	for p, place := range places {
+		if p == "" || place < 1 {
+			return nil, fmt.Errorf("Every player needs a name and a place from 1")
+		}
+	}
+
+	elo := make(map[string]float64)
+	glicko := make(map[string]Glicko)
+	best := 0
+	for p, place := range places {
+		r := b.rating(p)
+		elo[p], glicko[p] = r.Elo, r.Glicko
+		if best == 0 || place < best {
+			best = place
+		}
+	}
+	elo = MultiElo(elo, places, b.K)
+	glicko = MultiGlicko(glicko, places, b.Tau)
+
+	winners := 0
+	for _, place := range places {
+		if place == best {
+			winners++
+		}
+	}
+	players := make([]string, 0, len(places))
+	for p := range places {
+		players = append(players, p)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		if places[players[i]] != places[players[j]] {
+			return places[players[i]] < places[players[j]]
+		}
+		return players[i] < players[j]
+	})
+
+	updated := make([]Rating, 0, len(players))
+	for _, p := range players {
+		r := b.rating(p)
+		r.Elo, r.Glicko = elo[p], glicko[p]
+		r.Games++
+		r.LastPlayed = at
+		switch {
+		case places[p] != best:
+			r.Losses++
+		case winners > 1:
+			r.Draws++
+		default:
+			r.Wins++
+		}
+		b.history[p] = append(b.history[p], Change{Time: at, GameID: gameID, Place: places[p], Elo: r.Elo, Glicko: r.Glicko.Rating})
+		updated = append(updated, *r)
+	}
+	return updated, nil
+}
+
+func (b *Board) Get(player string) (Rating, bool) {
+	r, ok := b.ratings[player]
+	if !ok {
+		return Rating{}, false
+	}
+	return *r, true
+}
+
+func (b *Board) History(player string) []Change {
+	return append([]Change{}, b.history[player]...)
+}
+
+// Query selects a page of a leaderboard. Since, if set, limits it to players
+// who have played since then.
+type Query struct {
+	System string
+	Since  time.Time
+	Offset int
+	Limit  int
+}
+
+// Standing is a leaderboard row. Games and Change count only the games in
+// the query's window.
+type Standing struct {
+	Rank   int     `json:"rank"`
+	Rating Rating  `json:"rating"`
+	Score  float64 `json:"score"`
+	Games  int     `json:"games"`
+	Change float64 `json:"change"`
+}
+
+type Page struct {
+	System    string     `json:"system"`
+	Total     int        `json:"total"`
+	Offset    int        `json:"offset"`
+	Standings []Standing `json:"standings"`
+}
+
+// Leaderboard ranks players by Elo, or by Glicko-2 rating less twice its
+// deviation, so that players with few games are not ranked above those
+// whose strength is known.
+func (b *Board) Leaderboard(q Query) (Page, error) {
+	var score func(r Rating) float64
+	var value func(c Change) float64
+	switch q.System {
+	case SystemElo:
+		score = func(r Rating) float64 { return r.Elo }
+		value = func(c Change) float64 { return c.Elo }
+	case "", SystemGlicko:
+		q.System = SystemGlicko
+		score = func(r Rating) float64 { return r.Glicko.Rating - 2*r.Glicko.Deviation }
+		value = func(c Change) float64 { return c.Glicko }
+	default:
+		return Page{}, fmt.Errorf("Unknown rating system %q", q.System)
+	}
+	if q.Offset < 0 || q.Limit < 0 {
+		return Page{}, fmt.Errorf("Offset and limit cannot be negative")
+	}
+
+	var standings []Standing
+	for p, r := range b.ratings {
+		s := Standing{Rating: *r, Score: score(*r)}
+		before := float64(InitialRating)
+		for _, c := range b.history[p] {
+			if c.Time.Before(q.Since) {
+				before = value(c)
+			} else {
+				s.Games++
+			}
+		}
+		if s.Games == 0 {
+			continue
+		}
+		s.Change = value(b.history[p][len(b.history[p])-1]) - before
+		standings = append(standings, s)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Score != standings[j].Score {
+			return standings[i].Score > standings[j].Score
+		}
+		return standings[i].Rating.Player < standings[j].Rating.Player
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+
+	page := Page{System: q.System, Total: len(standings), Offset: q.Offset, Standings: []Standing{}}
+	if q.Offset < len(standings) {
+		end := len(standings)
+		if q.Limit > 0 && q.Offset+q.Limit < end {
+			end = q.Offset + q.Limit
+		}
+		page.Standings = standings[q.Offset:end]
+	}
+	return page, nil
+}
@@ -0,0 +1,53 @@
+package rating
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoardLeaderboard(t *testing.T) {
+	b := NewBoard()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := b.Record(map[string]int{"ann": 1}, start, ""); err == nil {
+		t.Errorf("Record accepted a one-player game")
+	}
+	b.Record(map[string]int{"ann": 1, "bob": 2}, start, "g1")
+	b.Record(map[string]int{"ann": 1, "cat": 2}, start.Add(24*time.Hour), "g2")
+	updated, err := b.Record(map[string]int{"bob": 1, "cat": 1}, start.Add(48*time.Hour), "g3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated[0].Draws != 1 || updated[1].Draws != 1 {
+		t.Errorf("Shared first place should be a draw: %+v", updated)
+	}
+
+	ann, _ := b.Get("ann")
+	if ann.Wins != 2 || ann.Games != 2 || len(b.History("ann")) != 2 {
+		t.Errorf("Unexpected record for ann: %+v", ann)
+	}
+
+	page, err := b.Leaderboard(Query{System: SystemElo, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.Standings) != 2 || page.Standings[0].Rating.Player != "ann" || page.Standings[0].Rank != 1 {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+	if page, _ := b.Leaderboard(Query{Offset: 2, Limit: 2}); len(page.Standings) != 1 || page.Standings[0].Rank != 3 {
+		t.Errorf("Unexpected second page: %+v", page)
+	}
+
+	recent, _ := b.Leaderboard(Query{System: SystemElo, Since: start.Add(36 * time.Hour)})
+	if recent.Total != 2 {
+		t.Fatalf("Only bob and cat played in the window: %+v", recent)
+	}
+	for _, s := range recent.Standings {
+		if s.Games != 1 || s.Change == 0 && s.Rating.Player == "bob" {
+			t.Errorf("Unexpected window standing: %+v", s)
+		}
+	}
+	if _, err := b.Leaderboard(Query{System: "trueskill"}); err == nil {
+		t.Errorf("Leaderboard accepted an unknown system")
+	}
+}
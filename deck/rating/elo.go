@@ -0,0 +1,52 @@
+package rating
+
+import "math"
+
+// DefaultK is the Elo K-factor: the most a rating moves in one game.
+const DefaultK = 32
+
+// InitialRating is where every player starts, in both systems.
+const InitialRating = 1500
+
+// Expected is the score a player rated a expects against one rated b.
+func Expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Elo returns the new ratings of a and b after a game in which a scored
+// score: 1 for a win, 0.5 for a draw, 0 for a loss.
+func Elo(a, b, score, k float64) (float64, float64) {
+	change := k * (score - Expected(a, b))
+	return a + change, b - change
+}
+
+// pairScore is what the player placed first scores against the other in a
+// free-for-all: lower places are better and equal places draw.
+func pairScore(place, other int) float64 {
+	switch {
+	case place < other:
+		return 1
+	case place > other:
+		return 0
+	}
+	return 0.5
+}
+
+// MultiElo extends Elo to free-for-all games by treating the result as a
+// game between every pair of players, each against the others' ratings
+// before the game. K is shared out among the opponents, so a player's
+// rating moves by at most k. With two players it is plain Elo.
+func MultiElo(ratings map[string]float64, places map[string]int, k float64) map[string]float64 {
+	updated := make(map[string]float64, len(ratings))
+	n := len(places)
+	for p := range places {
+		var change float64
+		for q := range places {
+			if p != q {
+				change += pairScore(places[p], places[q]) - Expected(ratings[p], ratings[q])
+			}
+		}
+		updated[p] = ratings[p] + k/float64(n-1)*change
+	}
+	return updated
+}
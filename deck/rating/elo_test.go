@@ -0,0 +1,45 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestElo(t *testing.T) {
+	if e := Expected(1500, 1500); e != 0.5 {
+		t.Errorf("Equal ratings should expect 0.5, got %v", e)
+	}
+	a, b := Elo(1600, 1400, 1, DefaultK)
+	if !near(a, 1607.69, 0.01) || !near(a+b, 3000, 1e-9) {
+		t.Errorf("Unexpected update: %v %v", a, b)
+	}
+}
+
+func TestMultiElo(t *testing.T) {
+	ratings := map[string]float64{"ann": 1600, "bob": 1400}
+	two := MultiElo(ratings, map[string]int{"ann": 1, "bob": 2}, DefaultK)
+	a, b := Elo(1600, 1400, 1, DefaultK)
+	if !near(two["ann"], a, 1e-9) || !near(two["bob"], b, 1e-9) {
+		t.Errorf("Two-player MultiElo should be Elo: %v", two)
+	}
+
+	ratings = map[string]float64{"ann": 1500, "bob": 1500, "cat": 1500, "dan": 1500}
+	four := MultiElo(ratings, map[string]int{"ann": 1, "bob": 2, "cat": 2, "dan": 4}, DefaultK)
+	if four["ann"] <= four["bob"] || four["bob"] != four["cat"] || four["cat"] <= four["dan"] {
+		t.Errorf("Ratings should follow places: %v", four)
+	}
+	if !near(four["ann"]-1500, 16, 1e-9) {
+		t.Errorf("Beating everyone should gain k/2 at equal ratings: %v", four["ann"])
+	}
+	var total float64
+	for _, r := range four {
+		total += r
+	}
+	if !near(total, 6000, 1e-9) {
+		t.Errorf("Points should be conserved: %v", total)
+	}
+}
@@ -0,0 +1,116 @@
+package rating
+
+import "math"
+
+const (
+	// glickoScale converts between the Glicko and Glicko-2 scales.
+	glickoScale = 173.7178
+	// DefaultTau constrains how fast volatility changes.
+	DefaultTau = 0.5
+	// InitialDeviation and InitialVolatility are a new player's.
+	InitialDeviation  = 350
+	InitialVolatility = 0.06
+	convergence       = 0.000001
+)
+
+// Glicko is a Glicko-2 rating: the rating, its deviation (the uncertainty in
+// it, as one standard deviation) and the player's volatility.
+type Glicko struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+func NewGlicko() Glicko {
+	return Glicko{Rating: InitialRating, Deviation: InitialDeviation, Volatility: InitialVolatility}
+}
+
+// Result is one game of a rating period: the opponent's rating going in and
+// the player's score against them.
+type Result struct {
+	Opponent Glicko
+	Score    float64
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// Update rates one rating period, following Glickman's "Example of the
+// Glicko-2 system". A period with no games only widens the deviation.
+func (r Glicko) Update(results []Result, tau float64) Glicko {
+	mu := (r.Rating - InitialRating) / glickoScale
+	phi := r.Deviation / glickoScale
+	if len(results) == 0 {
+		phi = math.Sqrt(phi*phi + r.Volatility*r.Volatility)
+		return Glicko{Rating: r.Rating, Deviation: phi * glickoScale, Volatility: r.Volatility}
+	}
+
+	var vInverse, sum float64
+	for _, result := range results {
+		muJ := (result.Opponent.Rating - InitialRating) / glickoScale
+		gJ := g(result.Opponent.Deviation / glickoScale)
+		e := 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+		vInverse += gJ * gJ * e * (1 - e)
+		sum += gJ * (result.Score - e)
+	}
+	v := 1 / vInverse
+	delta := v * sum
+
+	sigma := volatility(delta, phi, v, r.Volatility, tau)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * sum
+
+	return Glicko{Rating: mu*glickoScale + InitialRating, Deviation: phi * glickoScale, Volatility: sigma}
+}
+
+// volatility finds the new volatility by the Illinois algorithm.
+func volatility(delta, phi, v, sigma, tau float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > convergence {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
+
+// MultiGlicko rates a free-for-all game as one rating period in which each
+// player played every other, scored by their places.
+func MultiGlicko(ratings map[string]Glicko, places map[string]int, tau float64) map[string]Glicko {
+	updated := make(map[string]Glicko, len(ratings))
+	for p := range places {
+		var results []Result
+		for q := range places {
+			if p != q {
+				results = append(results, Result{Opponent: ratings[q], Score: pairScore(places[p], places[q])})
+			}
+		}
+		updated[p] = ratings[p].Update(results, tau)
+	}
+	return updated
+}
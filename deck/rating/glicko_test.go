@@ -0,0 +1,34 @@
+package rating
+
+import "testing"
+
+// TestGlickmanExample checks the worked example in Glickman's description
+// of Glicko-2.
+func TestGlickmanExample(t *testing.T) {
+	player := Glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	results := []Result{
+		{Opponent: Glicko{Rating: 1400, Deviation: 30}, Score: 1},
+		{Opponent: Glicko{Rating: 1550, Deviation: 100}, Score: 0},
+		{Opponent: Glicko{Rating: 1700, Deviation: 300}, Score: 0},
+	}
+	updated := player.Update(results, 0.5)
+	if !near(updated.Rating, 1464.06, 0.01) || !near(updated.Deviation, 151.52, 0.01) || !near(updated.Volatility, 0.05999, 0.00001) {
+		t.Errorf("Unexpected update: %+v", updated)
+	}
+
+	idle := player.Update(nil, 0.5)
+	if idle.Rating != 1500 || idle.Deviation <= 200 {
+		t.Errorf("An idle period should only widen the deviation: %+v", idle)
+	}
+}
+
+func TestMultiGlicko(t *testing.T) {
+	ratings := map[string]Glicko{"ann": NewGlicko(), "bob": NewGlicko(), "cat": NewGlicko()}
+	updated := MultiGlicko(ratings, map[string]int{"ann": 1, "bob": 2, "cat": 3}, DefaultTau)
+	if !(updated["ann"].Rating > 1500 && near(updated["bob"].Rating, 1500, 1e-6) && updated["cat"].Rating < 1500) {
+		t.Errorf("Ratings should follow places: %+v", updated)
+	}
+	if updated["ann"].Deviation >= InitialDeviation {
+		t.Errorf("Playing should narrow the deviation: %+v", updated["ann"])
+	}
+}
@@ -0,0 +1,76 @@
+package replay
+
+import (
+	"time"
+
+	"cardGame/deck/game"
+)
+
+// DefaultSize is how many events a game keeps for replay.
+const DefaultSize = 256
+
+// Kind says what happened in an event.
+type Kind string
+
+const (
+	Action Kind = "action"
+	// Timeout is the default action played for a player whose clock ran out.
+	Timeout Kind = "timeout"
+	// Forfeit is a player losing the game on time.
+	Forfeit Kind = "forfeit"
+)
+
+// Event is one change to a game. Seq numbers a game's events from 1 and is
+// the game's version after the event.
+type Event struct {
+	Seq    int          `json:"seq"`
+	Time   time.Time    `json:"time"`
+	Kind   Kind         `json:"kind"`
+	Player string       `json:"player"`
+	Action *game.Action `json:"action,omitempty"`
+}
+
+// Buffer keeps the latest events of a game in a ring, dropping the oldest
+// when it is full. It is not safe for concurrent use.
+type Buffer struct {
+	events []Event
+	// next is where the next event goes, and count how many are kept.
+	next, count int
+	latest      int
+}
+
+func NewBuffer(size int) *Buffer {
+	if size < 1 {
+		size = DefaultSize
+	}
+	return &Buffer{events: make([]Event, size)}
+}
+
+func (b *Buffer) Append(e Event) {
+	b.events[b.next] = e
+	b.next = (b.next + 1) % len(b.events)
+	if b.count < len(b.events) {
+		b.count++
+	}
+	b.latest = e.Seq
+}
+
+// Latest returns the sequence number of the last event, or 0.
+func (b *Buffer) Latest() int {
+	return b.latest
+}
+
+// Since returns the events after seq, oldest first. It reports false if
+// some of them have already been dropped, in which case the caller must
+// start again from the current state.
+func (b *Buffer) Since(seq int) ([]Event, bool) {
+	events := []Event{}
+	first := (b.next - b.count + len(b.events)) % len(b.events)
+	for i := 0; i < b.count; i++ {
+		if e := b.events[(first+i)%len(b.events)]; e.Seq > seq {
+			events = append(events, e)
+		}
+	}
+	missing := b.latest - seq
+	return events, missing <= len(events)
+}
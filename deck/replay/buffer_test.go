@@ -0,0 +1,30 @@
+package replay
+
+import "testing"
+
+func TestBuffer(t *testing.T) {
+	b := NewBuffer(3)
+	if events, complete := b.Since(0); len(events) != 0 || !complete || b.Latest() != 0 {
+		t.Errorf("Empty buffer: %v %v", events, complete)
+	}
+	for seq := 1; seq <= 5; seq++ {
+		b.Append(Event{Seq: seq, Kind: Action, Player: "ann"})
+	}
+	if b.Latest() != 5 {
+		t.Errorf("Latest %v, want 5", b.Latest())
+	}
+
+	events, complete := b.Since(2)
+	if !complete || len(events) != 3 || events[0].Seq != 3 || events[2].Seq != 5 {
+		t.Errorf("Since(2) = %v %v", events, complete)
+	}
+	if events, complete := b.Since(4); !complete || len(events) != 1 || events[0].Seq != 5 {
+		t.Errorf("Since(4) = %v %v", events, complete)
+	}
+	if events, complete := b.Since(5); !complete || len(events) != 0 {
+		t.Errorf("Since(5) = %v %v", events, complete)
+	}
+	if _, complete := b.Since(1); complete {
+		t.Errorf("Since(1) should report the dropped event 2")
+	}
+}
@@ -0,0 +1,307 @@
+package rummy
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+const (
+	// DiscardPile names the deck pile gin discards go to.
+	DiscardPile = "discard"
+	// StockPile is the value that draws from the undealt cards.
+	StockPile = "stock"
+	handSize  = 10
+)
+
+type phase string
+
+const (
+	phaseOffer   phase = "offer"
+	phaseDraw    phase = "draw"
+	phaseDiscard phase = "discard"
+	phaseOver    phase = "over"
+)
+
+// GinGame plays one hand of two-player gin rummy for the game registry. The
+// first player deals and the second is offered the upcard first.
+type GinGame struct {
+	rules   Rules
+	players []string
+	deck    model.Deck
+	hands   map[string][]model.Card
+	turn    int
+	phase   phase
+	passes  int
+	// taken is the card just drawn from the discard pile, which may not be
+	// discarded straight back.
+	taken string
+	// pickedUp holds the cards each player took from the discard pile and
+	// still holds. Both players saw them taken.
+	pickedUp map[string][]model.Card
+	result   *KnockResult
+	winner   string
+}
+
+type GinView struct {
+	Hand        []model.Card            `json:"hand,omitempty"`
+	Arrangement *Arrangement            `json:"arrangement,omitempty"`
+	HandSizes   map[string]int          `json:"hand_sizes"`
+	Discard     []model.Card            `json:"discard"`
+	Stock       int                     `json:"stock"`
+	Turn        string                  `json:"turn,omitempty"`
+	Phase       string                  `json:"phase"`
+	Hands       map[string][]model.Card `json:"hands,omitempty"`
+	Result      *KnockResult            `json:"result,omitempty"`
+	Winner      string                  `json:"winner,omitempty"`
+}
+
+func NewGinGame() game.Game {
+	return &GinGame{}
+}
+
+// Setup reads the options "wild" (comma separated card values), "ace_high"
+// and "knock_limit".
+func (g *GinGame) Setup(deck model.Deck, config game.Config) error {
+	if len(config.Players) != 2 {
+		return fmt.Errorf("Gin rummy needs 2 players")
+	}
+	if len(deck.Cards) < 2*handSize+3 {
+		return fmt.Errorf("Not enough cards to deal gin rummy")
+	}
+
+	g.rules = GinRules
+	if wild := config.Options["wild"]; wild != "" {
+		g.rules.Wild = strings.Split(strings.ToUpper(wild), ",")
+	}
+	if aceHigh, err := strconv.ParseBool(config.Options["ace_high"]); err == nil {
+		g.rules.AceHigh = aceHigh
+	}
+	if limit, err := strconv.Atoi(config.Options["knock_limit"]); err == nil && limit >= 0 {
+		g.rules.KnockLimit = limit
+	}
+
+	g.players = config.Players
+	g.deck = deck
+	g.deck.Cards = append([]model.Card{}, deck.Cards...)
+	g.deck.Piles = nil
+	g.hands = make(map[string][]model.Card)
+	g.pickedUp = make(map[string][]model.Card)
+	for i := 0; i < 2*handSize; i++ {
+		cards, _ := g.deck.DrawCards(1)
+		player := g.players[(i+1)%2]
+		g.hands[player] = append(g.hands[player], cards[0])
+	}
+	upcard, _ := g.deck.DrawCards(1)
+	g.deck.AddToPile(DiscardPile, upcard...)
+
+	g.turn = 1
+	g.phase = phaseOffer
+	return nil
+}
+
+func (g *GinGame) Players() []string {
+	return g.players
+}
+
+func (g *GinGame) current() string {
+	return g.players[g.turn]
+}
+
+func (g *GinGame) LegalActions(player string) []game.Action {
+	if g.phase == phaseOver || player != g.current() {
+		return nil
+	}
+
+	var actions []game.Action
+	switch g.phase {
+	case phaseOffer:
+		actions = append(actions, game.Action{Type: "draw", Value: DiscardPile}, game.Action{Type: "pass"})
+	case phaseDraw:
+		actions = append(actions, game.Action{Type: "draw", Value: StockPile})
+		if g.passes < 2 && len(g.deck.Piles[DiscardPile]) > 0 {
+			actions = append(actions, game.Action{Type: "draw", Value: DiscardPile})
+		}
+	case phaseDiscard:
+		hand := g.hands[player]
+		for i, c := range hand {
+			if c.Code == g.taken {
+				continue
+			}
+			actions = append(actions, game.Action{Type: "discard", Cards: []string{c.Code}})
+			if arrangement, err := Arrange(without(hand, i), g.rules); err == nil && arrangement.Points <= g.rules.KnockLimit {
+				actions = append(actions, game.Action{Type: "knock", Cards: []string{c.Code}})
+			}
+		}
+	}
+	return actions
+}
+
+func (g *GinGame) Apply(player string, action game.Action) error {
+	if !game.IsLegal(g, player, action) {
+		return fmt.Errorf("Illegal action %q for %v", action.Type, player)
+	}
+
+	switch action.Type {
+	case "pass":
+		g.passes++
+		g.turn = 1 - g.turn
+		if g.passes == 2 {
+			g.phase = phaseDraw
+		}
+	case "draw":
+		var cards []model.Card
+		if action.Value == DiscardPile {
+			cards, _ = g.deck.DrawFromPile(DiscardPile, 1)
+			g.taken = cards[0].Code
+			g.pickedUp[player] = append(g.pickedUp[player], cards[0])
+		} else {
+			cards, _ = g.deck.DrawCards(1)
+			g.taken = ""
+		}
+		g.hands[player] = append(g.hands[player], cards[0])
+		g.passes = 0
+		g.phase = phaseDiscard
+	case "discard", "knock":
+		hand := g.hands[player]
+		for i, c := range hand {
+			if c.Code == action.Cards[0] {
+				g.hands[player] = without(hand, i)
+				g.deck.AddToPile(DiscardPile, c)
+				g.pickedUp[player] = withoutCode(g.pickedUp[player], c.Code)
+				break
+			}
+		}
+		if action.Type == "knock" {
+			return g.knock(player)
+		}
+		g.turn = 1 - g.turn
+		g.phase = phaseDraw
+		// The hand is a draw once only two cards are left in the stock.
+		if g.deck.Remaining <= 2 {
+			g.phase = phaseOver
+		}
+	}
+	return nil
+}
+
+func (g *GinGame) knock(player string) error {
+	defender := g.players[1-g.turn]
+	result, err := Knock(g.hands[player], g.hands[defender], g.rules)
+	if err != nil {
+		return err
+	}
+
+	g.result = &result
+	g.winner = player
+	if result.Winner == Defender {
+		g.winner = defender
+	}
+	g.phase = phaseOver
+	return nil
+}
+
+func (g *GinGame) Terminal() bool {
+	return g.phase == phaseOver
+}
+
+// Scores give the hand's points to its winner. A hand that runs out of
+// stock scores nothing.
+func (g *GinGame) Scores() map[string]int {
+	scores := map[string]int{g.players[0]: 0, g.players[1]: 0}
+	if g.result != nil {
+		scores[g.winner] = g.result.Points
+	}
+	return scores
+}
+
+// Determinize deals the opponent's hand again from the unseen cards, their
+// hand and the stock. Cards the opponent picked up from the discard pile
+// stay in their hand.
+func (g *GinGame) Determinize(player string, rng *rand.Rand) game.Game {
+	clone := *g
+	clone.deck = g.deck.Clone()
+	clone.hands = make(map[string][]model.Card)
+	clone.pickedUp = make(map[string][]model.Card)
+	hands := make([][]model.Card, len(g.players))
+	unseen := []*[]model.Card{&clone.deck.Cards}
+	for i, p := range g.players {
+		clone.pickedUp[p] = append([]model.Card{}, g.pickedUp[p]...)
+		if p == player || g.phase == phaseOver {
+			hands[i] = append([]model.Card{}, g.hands[p]...)
+			continue
+		}
+		for _, c := range g.hands[p] {
+			if !containsCode(g.pickedUp[p], c.Code) {
+				hands[i] = append(hands[i], c)
+			}
+		}
+		unseen = append(unseen, &hands[i])
+	}
+	game.Redeal(rng, unseen...)
+	for i, p := range g.players {
+		clone.hands[p] = hands[i]
+		if p != player && g.phase != phaseOver {
+			clone.hands[p] = append(clone.pickedUp[p], hands[i]...)
+		}
+	}
+	return &clone
+}
+
+// View shows a player their own hand and best arrangement. Both hands are
+// shown to everyone once the hand is over.
+func (g *GinGame) View(player string) interface{} {
+	view := GinView{
+		HandSizes: make(map[string]int),
+		Discard:   g.deck.Piles[DiscardPile],
+		Stock:     g.deck.Remaining,
+		Phase:     string(g.phase),
+		Result:    g.result,
+		Winner:    g.winner,
+	}
+	for _, p := range g.players {
+		view.HandSizes[p] = len(g.hands[p])
+	}
+
+	if g.phase == phaseOver {
+		view.Hands = g.hands
+	} else {
+		view.Turn = g.current()
+	}
+	if hand, seated := g.hands[player]; seated {
+		view.Hand = hand
+		if arrangement, err := Arrange(hand, g.rules); err == nil {
+			view.Arrangement = &arrangement
+		}
+	}
+	return view
+}
+
+func without(hand []model.Card, i int) []model.Card {
+	rest := make([]model.Card, 0, len(hand)-1)
+	rest = append(rest, hand[:i]...)
+	return append(rest, hand[i+1:]...)
+}
+
+// withoutCode returns cards without the first card with the given code.
+func withoutCode(cards []model.Card, code string) []model.Card {
+	for i, c := range cards {
+		if c.Code == code {
+			return without(cards, i)
+		}
+	}
+	return cards
+}
+
+func containsCode(cards []model.Card, code string) bool {
+	for _, c := range cards {
+		if c.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package rummy
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+func TestGinGame(t *testing.T) {
+	bob := strings.Split("AS,2S,3S,7H,7D,7C,JD,QD,KD,5C", ",")
+	ann := strings.Split("4H,9S,8C,2H,6D,KS,QH,9C,3C,1C", ",")
+	var codes []string
+	for i := range bob {
+		codes = append(codes, bob[i], ann[i])
+	}
+	codes = append(codes, "4D", "9H", "8H", "6H", "5H")
+
+	g := NewGinGame()
+	if err := g.Setup(model.NewDeck(false, strings.Join(codes, ",")), game.Config{Players: []string{"ann", "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	play := func(player string, action game.Action) {
+		t.Helper()
+		if err := g.Apply(player, action); err != nil {
+			t.Fatalf("%v %v: %v", player, action.Type, err)
+		}
+	}
+
+	if err := g.Apply("bob", game.Action{Type: "draw", Value: StockPile}); err == nil {
+		t.Errorf("Apply drew from the stock before the upcard was offered")
+	}
+	play("bob", game.Action{Type: "pass"})
+	play("ann", game.Action{Type: "draw", Value: DiscardPile})
+	if err := g.Apply("ann", game.Action{Type: "discard", Cards: []string{"4D"}}); err == nil {
+		t.Errorf("Apply let ann discard the card she just took")
+	}
+	play("ann", game.Action{Type: "discard", Cards: []string{"KS"}})
+
+	view := g.View("bob").(GinView)
+	if len(view.Hand) != 10 || view.Arrangement.Points != 5 || view.Discard[len(view.Discard)-1].Code != "KS" || view.Hands != nil {
+		t.Errorf("Unexpected view for bob: %+v", view)
+	}
+
+	play("bob", game.Action{Type: "draw", Value: StockPile})
+	if err := g.Apply("bob", game.Action{Type: "knock", Cards: []string{"7H"}}); err == nil {
+		t.Errorf("Apply let bob knock with too much deadwood")
+	}
+	play("bob", game.Action{Type: "knock", Cards: []string{"9H"}})
+
+	if !g.Terminal() {
+		t.Fatalf("Hand should be over after a knock")
+	}
+	if scores := g.Scores(); scores["bob"] != 38-5 || scores["ann"] != 0 {
+		t.Errorf("Unexpected scores: %v", scores)
+	}
+	if view := g.View("").(GinView); len(view.Hands["ann"]) != 10 || view.Winner != "bob" {
+		t.Errorf("Hands not revealed after the knock: %+v", view)
+	}
+}
+
+func TestGinGameOptions(t *testing.T) {
+	g := NewGinGame().(*GinGame)
+	options := map[string]string{"wild": "2,jack", "ace_high": "true", "knock_limit": "5"}
+	if err := g.Setup(model.NewDeck(true, ""), game.Config{Players: []string{"ann", "bob"}, Options: options}); err != nil {
+		t.Fatal(err)
+	}
+	if !g.rules.AceHigh || g.rules.KnockLimit != 5 || len(g.rules.Wild) != 2 || g.rules.Wild[1] != "JACK" {
+		t.Errorf("Options not applied: %+v", g.rules)
+	}
+	if err := g.Setup(model.NewDeck(true, ""), game.Config{Players: []string{"ann"}}); err == nil {
+		t.Errorf("Setup accepted a single player")
+	}
+}
+
+func TestGinDeterminize(t *testing.T) {
+	g := NewGinGame()
+	if err := g.Setup(model.NewSeededDeck(3, ""), game.Config{Players: []string{"ann", "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := json.Marshal(g.View("bob"))
+	own, _ := json.Marshal(g.View("ann"))
+	bob := g.View("bob").(GinView).Hand[0].Code
+
+	rng := rand.New(rand.NewSource(1))
+	dealt := false
+	for i := 0; i < 10; i++ {
+		d := g.(game.Determinizer).Determinize("ann", rng)
+		if view, _ := json.Marshal(d.View("ann")); string(view) != string(own) {
+			t.Fatalf("Determinize changed what ann sees:\n%s\n%s", view, own)
+		}
+		dealt = dealt || d.View("bob").(GinView).Hand[0].Code != bob
+		for !d.Terminal() {
+			player := d.View("").(GinView).Turn
+			actions := d.LegalActions(player)
+			d.Apply(player, actions[0])
+		}
+	}
+	if !dealt {
+		t.Errorf("Bob's hand was never dealt again")
+	}
+	if after, _ := json.Marshal(g.View("bob")); string(after) != string(before) {
+		t.Errorf("Playing a determinization changed the game")
+	}
+}
+
+func TestGinDeterminize_KeepsPickedUpCards(t *testing.T) {
+	g := NewGinGame()
+	if err := g.Setup(model.NewSeededDeck(3, ""), game.Config{Players: []string{"ann", "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+	discard := g.View("ann").(GinView).Discard
+	upcard := discard[len(discard)-1].Code
+	if err := g.Apply("bob", game.Action{Type: "draw", Value: DiscardPile}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply("bob", g.LegalActions("bob")[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		d := g.(game.Determinizer).Determinize("ann", rng)
+		kept := false
+		for _, c := range d.View("bob").(GinView).Hand {
+			kept = kept || c.Code == upcard
+		}
+		if !kept {
+			t.Fatalf("Determinize dealt away %s, which bob took from the discard pile", upcard)
+		}
+	}
+}
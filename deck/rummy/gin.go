@@ -0,0 +1,63 @@
+package rummy
+
+import (
+	"fmt"
+
+	"cardGame/deck/model"
+)
+
+const (
+	GinBonus      = 25
+	UndercutBonus = 25
+)
+
+type Side string
+
+const (
+	Knocker  Side = "knocker"
+	Defender Side = "defender"
+)
+
+// KnockResult scores a hand of gin that ended with a knock.
+type KnockResult struct {
+	Knocker  Arrangement `json:"knocker"`
+	Defender Arrangement `json:"defender"`
+	Gin      bool        `json:"gin"`
+	Undercut bool        `json:"undercut"`
+	Winner   Side        `json:"winner"`
+	Points   int         `json:"points"`
+}
+
+// Knock scores the knocker's hand against the defender's. The defender may
+// lay off onto the knocker's melds unless the knocker has gin. A defender
+// with as little deadwood as the knocker, or less, undercuts.
+func Knock(knocker, defender []model.Card, rules Rules) (KnockResult, error) {
+	knock, err := Arrange(knocker, rules)
+	if err != nil {
+		return KnockResult{}, err
+	}
+	if knock.Points > rules.KnockLimit {
+		return KnockResult{}, fmt.Errorf("Cannot knock with %v deadwood", knock.Points)
+	}
+
+	result := KnockResult{Knocker: knock, Gin: knock.Points == 0}
+	if result.Gin {
+		result.Defender, err = Arrange(defender, rules)
+	} else {
+		result.Defender, err = ArrangeWithLayOffs(defender, knock.Melds, rules)
+	}
+	if err != nil {
+		return KnockResult{}, err
+	}
+
+	switch {
+	case result.Gin:
+		result.Winner, result.Points = Knocker, GinBonus+result.Defender.Points
+	case knock.Points < result.Defender.Points:
+		result.Winner, result.Points = Knocker, result.Defender.Points-knock.Points
+	default:
+		result.Undercut = true
+		result.Winner, result.Points = Defender, knock.Points-result.Defender.Points+UndercutBonus
+	}
+	return result, nil
+}
@@ -0,0 +1,33 @@
+package rummy
+
+import "testing"
+
+func TestKnock(t *testing.T) {
+	tests := []struct {
+		name     string
+		knocker  string
+		defender string
+		winner   Side
+		points   int
+		gin      bool
+		undercut bool
+	}{
+		{"Gin", "AS,2S,3S,7H,7D,7C,JD,QD,KD,1D", "AH,2H,3C,4C,5C,6S,8S,9S,KH,QC", Knocker, 25 + 1 + 2 + 6 + 8 + 9 + 10 + 10, true, false},
+		{"Knock", "AS,2S,3S,7H,7D,7C,JD,QD,KD,4H", "4S,5S,8C,9C,1C,KH,QH,8D,8H,6C", Knocker, 10 + 10 + 8 + 8 + 6 - 4, false, false},
+		{"Undercut", "AS,2S,3S,7H,7D,7C,JD,QD,KD,9H", "4S,5S,8C,9C,1C,KH,KS,KC,3H,2H", Defender, 9 - 5 + 25, false, true},
+	}
+
+	for _, test := range tests {
+		result, err := Knock(hand(test.knocker), hand(test.defender), GinRules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Winner != test.winner || result.Points != test.points || result.Gin != test.gin || result.Undercut != test.undercut {
+			t.Errorf("%v: got %v scoring %v (gin %v, undercut %v), want %v scoring %v", test.name, result.Winner, result.Points, result.Gin, result.Undercut, test.winner, test.points)
+		}
+	}
+
+	if _, err := Knock(hand("AS,2S,3S,7H,7D,7C,JD,QD,KH,KC"), hand("AH"), GinRules); err == nil {
+		t.Errorf("Knock accepted 20 deadwood")
+	}
+}
@@ -0,0 +1,406 @@
+package rummy
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+
+	"cardGame/deck/model"
+)
+
+type MeldKind string
+
+const (
+	Set MeldKind = "set"
+	Run MeldKind = "run"
+)
+
+// Meld is a set or run. Low and High are the ranks it covers, counting the
+// ace as 1 at the bottom of a run and 14 at the top; a set has Low == High.
+// Wild cards stand in for the ranks no natural card covers.
+type Meld struct {
+	Kind  MeldKind     `json:"kind"`
+	Cards []model.Card `json:"cards"`
+	Suit  string       `json:"suit,omitempty"`
+	Low   int          `json:"low"`
+	High  int          `json:"high"`
+}
+
+// Rules hold the variant settings that melding depends on.
+type Rules struct {
+	// AceLow allows A-2-3 and AceHigh allows Q-K-A. A run never wraps round
+	// from king to two.
+	AceLow  bool
+	AceHigh bool
+	// Wild lists card values, such as "2", that may stand in for any card.
+	Wild       []string
+	KnockLimit int
+}
+
+var GinRules = Rules{AceLow: true, KnockLimit: 10}
+
+func (r Rules) isWild(c model.Card) bool {
+	for _, value := range r.Wild {
+		if c.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rules) runBounds() (int, int) {
+	low, high := 2, 13
+	if r.AceLow {
+		low = 1
+	}
+	if r.AceHigh {
+		high = 14
+	}
+	return low, high
+}
+
+// Value is a card's deadwood count: aces one, pictures ten.
+func Value(c model.Card) int {
+	rank := c.Rank()
+	switch {
+	case rank == 14:
+		return 1
+	case rank > 10:
+		return 10
+	}
+	return rank
+}
+
+// lowRank counts the ace as 1.
+func lowRank(c model.Card) int {
+	if rank := c.Rank(); rank != 14 {
+		return rank
+	}
+	return 1
+}
+
+// Arrangement splits a hand into melds, cards laid off onto an opponent's
+// melds and deadwood.
+type Arrangement struct {
+	Melds    []Meld       `json:"melds"`
+	LaidOff  []LayOff     `json:"laid_off,omitempty"`
+	Deadwood []model.Card `json:"deadwood"`
+	Points   int          `json:"points"`
+}
+
+// LayOff records cards added to the opponent's meld at index Target.
+type LayOff struct {
+	Target int          `json:"target"`
+	Cards  []model.Card `json:"cards"`
+}
+
+// candidate is a meld or lay-off the search may pick: a group of natural
+// cards by index plus a number of wild cards.
+type candidate struct {
+	mask   uint32
+	wilds  int
+	kind   MeldKind
+	suit   string
+	low    int
+	high   int
+	target int
+	slot   uint32
+	// slots maps each rank of a run, from low, to a natural card index or -1
+	// for a wild.
+	slots []int
+}
+
+type arranger struct {
+	rules     Rules
+	naturals  []model.Card
+	wilds     []model.Card
+	targets   []Meld
+	byCard    [][]int
+	cands     []candidate
+	wildCost  []int
+	memo      map[uint64]choice
+	canAbsorb bool
+}
+
+type choice struct {
+	cost int
+	pick int
+}
+
+// maxNaturals bounds the hand size the search accepts.
+const maxNaturals = 24
+
+// Arrange returns the arrangement of hand with the least deadwood.
+func Arrange(hand []model.Card, rules Rules) (Arrangement, error) {
+	return arrange(hand, rules, nil)
+}
+
+// ArrangeWithLayOffs also lets the hand lay cards off onto the given melds,
+// as the defender does after the opponent knocks.
+func ArrangeWithLayOffs(hand []model.Card, melds []Meld, rules Rules) (Arrangement, error) {
+	return arrange(hand, rules, melds)
+}
+
+func arrange(hand []model.Card, rules Rules, targets []Meld) (Arrangement, error) {
+	a := &arranger{rules: rules, targets: targets, memo: make(map[uint64]choice)}
+	for _, c := range hand {
+		if rules.isWild(c) {
+			a.wilds = append(a.wilds, c)
+		} else if c.Rank() > 0 {
+			a.naturals = append(a.naturals, c)
+		} else {
+			return Arrangement{}, fmt.Errorf("Invalid card %q", c.Code)
+		}
+	}
+	if len(a.naturals) > maxNaturals || len(targets) > 16 {
+		return Arrangement{}, fmt.Errorf("Hand is too large to arrange")
+	}
+
+	// Melds take the most valuable wild cards first, so leftover ones count
+	// as little as possible.
+	sort.SliceStable(a.wilds, func(i, j int) bool { return Value(a.wilds[i]) > Value(a.wilds[j]) })
+	a.wildCost = make([]int, len(a.wilds)+1)
+	for i := 1; i <= len(a.wilds); i++ {
+		a.wildCost[i] = a.wildCost[i-1] + Value(a.wilds[len(a.wilds)-i])
+	}
+	for _, target := range targets {
+		if target.Kind == Run || len(target.Cards) < 4 {
+			a.canAbsorb = true
+		}
+	}
+
+	a.byCard = make([][]int, len(a.naturals))
+	a.addSets()
+	a.addRuns()
+	a.addLayOffs()
+
+	full := uint32(1)<<uint(len(a.naturals)) - 1
+	return a.build(full, len(a.wilds), 0), nil
+}
+
+func (a *arranger) add(c candidate) {
+	index := len(a.cands)
+	a.cands = append(a.cands, c)
+	for m := c.mask; m != 0; m &= m - 1 {
+		i := bits.TrailingZeros32(m)
+		a.byCard[i] = append(a.byCard[i], index)
+	}
+}
+
+// subsets calls fn with every non-empty subset of the given card indexes.
+func subsets(indexes []int, fn func(mask uint32, size int)) {
+	for m := 1; m < 1<<uint(len(indexes)); m++ {
+		var mask uint32
+		for i, index := range indexes {
+			if m&(1<<uint(i)) != 0 {
+				mask |= 1 << uint(index)
+			}
+		}
+		fn(mask, bits.OnesCount32(mask))
+	}
+}
+
+func (a *arranger) addSets() {
+	byRank := make(map[int][]int)
+	for i, c := range a.naturals {
+		byRank[lowRank(c)] = append(byRank[lowRank(c)], i)
+	}
+	for rank, indexes := range byRank {
+		subsets(indexes, func(mask uint32, size int) {
+			for w := 0; size+w <= 4 && w <= len(a.wilds); w++ {
+				if size+w >= 3 {
+					a.add(candidate{mask: mask, wilds: w, kind: Set, low: rank, high: rank, target: -1})
+				}
+			}
+		})
+	}
+}
+
+// position returns the index of the natural card of suit at rank, or -1.
+func (a *arranger) position(suit string, rank int) int {
+	for i, c := range a.naturals {
+		if c.Suit != suit {
+			continue
+		}
+		if r := c.Rank(); r == rank || (rank == 1 && r == 14) {
+			return i
+		}
+	}
+	return -1
+}
+
+// window returns the candidate covering ranks low to high of suit, filling
+// the gaps with wild cards, or false when no natural card is in it or there
+// are too few wild cards.
+func (a *arranger) window(suit string, low, high int) (candidate, bool) {
+	c := candidate{kind: Run, suit: suit, low: low, high: high, target: -1}
+	for rank := low; rank <= high; rank++ {
+		index := a.position(suit, rank)
+		if index >= 0 {
+			c.mask |= 1 << uint(index)
+		} else {
+			c.wilds++
+		}
+		c.slots = append(c.slots, index)
+	}
+	return c, c.mask != 0 && c.wilds <= len(a.wilds)
+}
+
+func (a *arranger) addRuns() {
+	low, high := a.rules.runBounds()
+	for _, suit := range model.Suits {
+		for start := low; start <= high; start++ {
+			for end := start + 2; end <= high && end-start < 13; end++ {
+				if c, ok := a.window(suit, start, end); ok {
+					a.add(c)
+				}
+			}
+		}
+	}
+}
+
+// addLayOffs adds the groups that extend each target meld. Each end of a run
+// and each set takes at most one group.
+func (a *arranger) addLayOffs() {
+	low, high := a.rules.runBounds()
+	for t, target := range a.targets {
+		if target.Kind == Set {
+			var indexes []int
+			for i, c := range a.naturals {
+				if lowRank(c) == target.Low {
+					indexes = append(indexes, i)
+				}
+			}
+			room := 4 - len(target.Cards)
+			subsets(indexes, func(mask uint32, size int) {
+				for w := 0; size+w <= room && w <= len(a.wilds); w++ {
+					a.add(candidate{mask: mask, wilds: w, kind: Set, low: target.Low, high: target.Low, target: t, slot: 1 << uint(2*t)})
+				}
+			})
+			continue
+		}
+
+		for start := target.Low - 1; start >= low && target.High-start < 13; start-- {
+			if c, ok := a.window(target.Suit, start, target.Low-1); ok {
+				c.target, c.slot = t, 1<<uint(2*t)
+				a.add(c)
+			}
+		}
+		for end := target.High + 1; end <= high && end-target.Low < 13; end++ {
+			if c, ok := a.window(target.Suit, target.High+1, end); ok {
+				c.target, c.slot = t, 1<<uint(2*t+1)
+				a.add(c)
+			}
+		}
+	}
+}
+
+// search returns the least deadwood for the natural cards in mask with wilds
+// wild cards left, when the lay-off slots in used are taken.
+func (a *arranger) search(mask uint32, wilds int, used uint32) choice {
+	if mask == 0 {
+		if wilds > 0 && a.canAbsorb {
+			return choice{pick: -1}
+		}
+		return choice{cost: a.wildCost[wilds], pick: -1}
+	}
+
+	key := uint64(mask)<<40 | uint64(used)<<8 | uint64(wilds)
+	if cached, ok := a.memo[key]; ok {
+		return cached
+	}
+
+	i := bits.TrailingZeros32(mask)
+	best := choice{cost: Value(a.naturals[i]) + a.search(mask&^(1<<uint(i)), wilds, used).cost, pick: -1}
+	for _, index := range a.byCard[i] {
+		c := a.cands[index]
+		if c.mask&^mask != 0 || c.wilds > wilds || c.slot&used != 0 {
+			continue
+		}
+		if cost := a.search(mask&^c.mask, wilds-c.wilds, used|c.slot).cost; cost < best.cost {
+			best = choice{cost: cost, pick: index}
+		}
+	}
+
+	a.memo[key] = best
+	return best
+}
+
+// build follows the search's choices to the arrangement itself.
+func (a *arranger) build(mask uint32, wilds int, used uint32) Arrangement {
+	result := Arrangement{Melds: []Meld{}, Deadwood: []model.Card{}}
+	nextWild := 0
+	takeWild := func() model.Card {
+		c := a.wilds[nextWild]
+		nextWild++
+		return c
+	}
+
+	for mask != 0 {
+		best := a.search(mask, wilds, used)
+		i := bits.TrailingZeros32(mask)
+		if best.pick < 0 {
+			result.Deadwood = append(result.Deadwood, a.naturals[i])
+			mask &^= 1 << uint(i)
+			continue
+		}
+
+		c := a.cands[best.pick]
+		meld := Meld{Kind: c.kind, Suit: c.suit, Low: c.low, High: c.high}
+		if c.kind == Run {
+			for _, slot := range c.slots {
+				if slot >= 0 {
+					meld.Cards = append(meld.Cards, a.naturals[slot])
+				} else {
+					meld.Cards = append(meld.Cards, takeWild())
+				}
+			}
+		} else {
+			for m := c.mask; m != 0; m &= m - 1 {
+				meld.Cards = append(meld.Cards, a.naturals[bits.TrailingZeros32(m)])
+			}
+			for w := 0; w < c.wilds; w++ {
+				meld.Cards = append(meld.Cards, takeWild())
+			}
+		}
+
+		if c.target >= 0 {
+			result.LaidOff = append(result.LaidOff, LayOff{Target: c.target, Cards: meld.Cards})
+		} else {
+			result.Melds = append(result.Melds, meld)
+		}
+		mask &^= c.mask
+		wilds -= c.wilds
+		used |= c.slot
+	}
+
+	if nextWild < len(a.wilds) {
+		rest := a.wilds[nextWild:]
+		switch {
+		case a.canAbsorb:
+			result.LaidOff = append(result.LaidOff, LayOff{Target: a.absorbTarget(), Cards: rest})
+		default:
+			result.Deadwood = append(result.Deadwood, rest...)
+		}
+	}
+	for _, c := range result.Deadwood {
+		result.Points += Value(c)
+	}
+	return result
+}
+
+// absorbTarget picks the target meld that leftover wild cards are laid off
+// onto.
+func (a *arranger) absorbTarget() int {
+	for t, target := range a.targets {
+		if target.Kind == Run {
+			return t
+		}
+	}
+	for t, target := range a.targets {
+		if len(target.Cards) < 4 {
+			return t
+		}
+	}
+	return 0
+}
@@ -0,0 +1,88 @@
+package rummy
+
+import (
+	"strings"
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+func hand(codes string) []model.Card {
+	var cards []model.Card
+	for _, code := range strings.Split(codes, ",") {
+		cards = append(cards, model.NewDeck(false, code).Cards...)
+	}
+	return cards
+}
+
+func TestArrange(t *testing.T) {
+	tests := []struct {
+		name   string
+		hand   string
+		rules  Rules
+		points int
+		melds  int
+	}{
+		{"Gin", "AS,2S,3S,7H,7D,7C,JD,QD,KD,1D", GinRules, 0, 3},
+		{"Deadwood", "AS,2S,3S,7H,7D,KC,QH,4D,9C,5H", GinRules, 52, 1},
+		{"Card in set or run", "7H,7D,7C,8H,9H", GinRules, 14, 1},
+		{"Set beats run when it leaves less", "5H,6H,7H,7D,7C", GinRules, 11, 1},
+		{"Run and set side by side", "5H,6H,7H,7D,7C,7S,8H", GinRules, 0, 2},
+		{"Ace high not allowed", "QS,KS,AS", GinRules, 21, 0},
+		{"Ace high", "QS,KS,AS", Rules{AceHigh: true}, 0, 1},
+		{"No wrap round", "KS,AS,2S", Rules{AceLow: true, AceHigh: true}, 13, 0},
+		{"Wild goes where it saves most", "4H,6H,2C,KD,KS", Rules{AceLow: true, Wild: []string{"2"}}, 10, 1},
+		{"Wild makes a set", "KD,KS,2C,9H,9S", Rules{AceLow: true, Wild: []string{"2"}}, 18, 1},
+	}
+
+	for _, test := range tests {
+		arrangement, err := Arrange(hand(test.hand), test.rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if arrangement.Points != test.points || len(arrangement.Melds) != test.melds {
+			t.Errorf("%v: got %v deadwood in %v melds, want %v in %v: %+v", test.name, arrangement.Points, len(arrangement.Melds), test.points, test.melds, arrangement)
+		}
+
+		count := len(arrangement.Deadwood)
+		for _, meld := range arrangement.Melds {
+			count += len(meld.Cards)
+		}
+		if count != len(hand(test.hand)) {
+			t.Errorf("%v: arrangement has %v cards, hand has %v", test.name, count, len(hand(test.hand)))
+		}
+	}
+}
+
+func TestArrangeWildRun(t *testing.T) {
+	arrangement, _ := Arrange(hand("4H,6H,2C"), Rules{Wild: []string{"2"}})
+	if len(arrangement.Melds) != 1 {
+		t.Fatalf("Expected one run: %+v", arrangement)
+	}
+	meld := arrangement.Melds[0]
+	if meld.Kind != Run || meld.Low != 4 || meld.High != 6 || meld.Cards[1].Code != "2C" {
+		t.Errorf("Wild card not placed in the gap: %+v", meld)
+	}
+}
+
+func TestArrangeWithLayOffs(t *testing.T) {
+	melds := []Meld{
+		{Kind: Run, Suit: "HEARTS", Low: 4, High: 6, Cards: hand("4H,5H,6H")},
+		{Kind: Set, Low: 9, High: 9, Cards: hand("9S,9D,9C")},
+	}
+
+	arrangement, err := ArrangeWithLayOffs(hand("3H,7H,8H,9C,KC,QC"), melds, GinRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arrangement.Points != 20 {
+		t.Errorf("Got %v deadwood, want 20: %+v", arrangement.Points, arrangement)
+	}
+	laid := 0
+	for _, layOff := range arrangement.LaidOff {
+		laid += len(layOff.Cards)
+	}
+	if laid != 4 {
+		t.Errorf("Laid off %v cards, want 4: %+v", laid, arrangement.LaidOff)
+	}
+}
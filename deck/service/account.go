@@ -0,0 +1,194 @@
+package service
+
+import (
+	"cardGame/deck/account"
+	"cardGame/deck/turn"
+	"sync"
+	"time"
+)
+
+// SweepInterval is how often expired sessions and old login failures are
+// cleared out.
+const SweepInterval = time.Hour
+
+// AccountService registers players and keeps their sessions. Password
+// hashing is slow on purpose, so it runs outside the lock.
+type AccountService struct {
+	mu       sync.Mutex
+	accounts map[string]account.Account
+	sessions *account.Sessions
+	logins   *account.Limiter
+	swept    time.Time
+	clock    turn.Clock
+	// decoy is checked against for unknown players, so a failed login takes
+	// as long whether or not the player exists.
+	decoy string
+}
+
+func NewAccountService() *AccountService {
+	decoy, _ := account.HashPassword("decoy password")
+	return &AccountService{
+		accounts: make(map[string]account.Account),
+		sessions: account.NewSessions(),
+		logins:   account.NewLimiter(),
+		clock:    turn.SystemClock,
+		decoy:    decoy,
+	}
+}
+
+// SetClock replaces the clock that expires sessions, for tests.
+func (s *AccountService) SetClock(clock turn.Clock) {
+	s.clock = clock
+}
+
+// Register creates an account for a new player ID.
+func (s *AccountService) Register(id, password string, profile account.Profile) (account.Account, error) {
+	if err := account.ValidateID(id); err != nil {
+		return account.Account{}, err
+	}
+	if err := account.ValidatePassword(password); err != nil {
+		return account.Account{}, err
+	}
+	if err := profile.Validate(); err != nil {
+		return account.Account{}, err
+	}
+	if s.Registered(id) {
+		return account.Account{}, account.ErrAccountExists
+	}
+	hash, err := account.HashPassword(password)
+	if err != nil {
+		return account.Account{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[id]; ok {
+		return account.Account{}, account.ErrAccountExists
+	}
+	a := account.Account{ID: id, Profile: profile, Created: s.clock.Now(), PasswordHash: hash}
+	s.accounts[id] = a
+	return a, nil
+}
+
+// Registered reports whether id belongs to an account.
+func (s *AccountService) Registered(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.accounts[id]
+	return ok
+}
+
+func (s *AccountService) Account(id string) (account.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		return account.Account{}, account.ErrAccountNotFound
+	}
+	return a, nil
+}
+
+// checkPassword returns the account if password is right.
+func (s *AccountService) checkPassword(id, password string) (account.Account, error) {
+	a, err := s.Account(id)
+	hash := a.PasswordHash
+	if err != nil {
+		hash = s.decoy
+	}
+	if !account.CheckPassword(hash, password) || err != nil {
+		return account.Account{}, account.ErrBadCredentials
+	}
+	return a, nil
+}
+
+// Login checks a player's password and starts a session. After too many
+// failed attempts the player ID is locked out for a while, whether or not
+// the password is right.
+func (s *AccountService) Login(id, password string) (account.Session, error) {
+	s.mu.Lock()
+	s.sweep()
+	err := s.logins.Attempt(id, s.clock.Now())
+	s.mu.Unlock()
+	if err != nil {
+		return account.Session{}, err
+	}
+
+	if _, err := s.checkPassword(id, password); err != nil {
+		return account.Session{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logins.Succeed(id)
+	return s.sessions.Start(id, s.clock.Now())
+}
+
+// sweep clears out expired sessions and old login failures, at most once
+// every SweepInterval. Callers hold s.mu.
+func (s *AccountService) sweep() {
+	now := s.clock.Now()
+	if now.Sub(s.swept) < SweepInterval {
+		return
+	}
+	s.swept = now
+	s.sessions.Sweep(now)
+	s.logins.Sweep(now)
+}
+
+// Authenticate returns the live session for token.
+func (s *AccountService) Authenticate(token string) (account.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions.Check(token, s.clock.Now())
+}
+
+// Logout ends the session for token.
+func (s *AccountService) Logout(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions.Revoke(token)
+}
+
+// LogoutAll ends every session of the player and returns how many ended.
+func (s *AccountService) LogoutAll(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions.RevokeAll(id)
+}
+
+func (s *AccountService) UpdateProfile(id string, profile account.Profile) (account.Account, error) {
+	if err := profile.Validate(); err != nil {
+		return account.Account{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		return account.Account{}, account.ErrAccountNotFound
+	}
+	a.Profile = profile
+	s.accounts[id] = a
+	return a, nil
+}
+
+// ChangePassword replaces the player's password and ends all their
+// sessions.
+func (s *AccountService) ChangePassword(id, old, password string) error {
+	if err := account.ValidatePassword(password); err != nil {
+		return err
+	}
+	if _, err := s.checkPassword(id, old); err != nil {
+		return err
+	}
+	hash, err := account.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.accounts[id]
+	a.PasswordHash = hash
+	s.accounts[id] = a
+	s.sessions.RevokeAll(id)
+	return nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"cardGame/deck/account"
+	"cardGame/deck/turn"
+	"testing"
+	"time"
+)
+
+func newTestAccountService() (*AccountService, *turn.FakeClock) {
+	account.Iterations = 1000
+	service := NewAccountService()
+	clock := turn.NewFakeClock(time.Unix(0, 0))
+	service.SetClock(clock)
+	return service, clock
+}
+
+func TestAccountService_Register(t *testing.T) {
+	service, _ := newTestAccountService()
+	ann, err := service.Register("ann", "ann's password", account.Profile{DisplayName: "Ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ann.ID != "ann" || ann.Profile.DisplayName != "Ann" || ann.PasswordHash == "ann's password" {
+		t.Errorf("Unexpected account %+v", ann)
+	}
+	if _, err := service.Register("ann", "another password", account.Profile{}); err != account.ErrAccountExists {
+		t.Errorf("Registered a taken ID: %v", err)
+	}
+	if _, err := service.Register("Bob", "bob's password", account.Profile{}); err == nil {
+		t.Errorf("Registered an invalid ID")
+	}
+	if _, err := service.Register("bob", "short", account.Profile{}); err == nil {
+		t.Errorf("Registered a short password")
+	}
+	if !service.Registered("ann") || service.Registered("bob") {
+		t.Errorf("Registered is wrong")
+	}
+}
+
+func TestAccountService_Sessions(t *testing.T) {
+	service, clock := newTestAccountService()
+	service.Register("ann", "ann's password", account.Profile{})
+
+	if _, err := service.Login("ann", "wrong password"); err != account.ErrBadCredentials {
+		t.Errorf("Logged in with a wrong password: %v", err)
+	}
+	if _, err := service.Login("bob", "ann's password"); err != account.ErrBadCredentials {
+		t.Errorf("Logged in as an unknown player: %v", err)
+	}
+	first, err := service.Login("ann", "ann's password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := service.Login("ann", "ann's password")
+	if session, err := service.Authenticate(first.Token); err != nil || session.Player != "ann" {
+		t.Errorf("Authenticate failed: %+v %v", session, err)
+	}
+
+	service.Logout(first.Token)
+	if _, err := service.Authenticate(first.Token); err == nil {
+		t.Errorf("Session survived logout")
+	}
+	clock.Advance(account.DefaultSessionTTL)
+	if _, err := service.Authenticate(second.Token); err == nil {
+		t.Errorf("Session outlived its expiry")
+	}
+
+	third, _ := service.Login("ann", "ann's password")
+	if n := service.LogoutAll("ann"); n != 1 {
+		t.Errorf("LogoutAll ended %v sessions, want 1", n)
+	}
+	if _, err := service.Authenticate(third.Token); err == nil {
+		t.Errorf("Session survived LogoutAll")
+	}
+}
+
+func TestAccountService_LoginLimit(t *testing.T) {
+	service, clock := newTestAccountService()
+	service.Register("ann", "ann's password", account.Profile{})
+
+	for i := 0; i < account.DefaultMaxFailures; i++ {
+		if _, err := service.Login("ann", "guess"); err != account.ErrBadCredentials {
+			t.Fatalf("Guess %v returned %v", i+1, err)
+		}
+	}
+	if _, err := service.Login("ann", "ann's password"); err != account.ErrTooManyAttempts {
+		t.Errorf("Logged in while locked out: %v", err)
+	}
+
+	clock.Advance(account.DefaultLockout)
+	if _, err := service.Login("ann", "ann's password"); err != nil {
+		t.Errorf("Still locked out after the lockout: %v", err)
+	}
+	for i := 0; i < account.DefaultMaxFailures-1; i++ {
+		service.Login("ann", "guess")
+	}
+	if _, err := service.Login("ann", "ann's password"); err != nil {
+		t.Errorf("A successful login did not clear the failures: %v", err)
+	}
+}
+
+func TestAccountService_ChangePassword(t *testing.T) {
+	service, _ := newTestAccountService()
+	service.Register("ann", "ann's password", account.Profile{})
+	session, _ := service.Login("ann", "ann's password")
+
+	if err := service.ChangePassword("ann", "wrong password", "new password"); err != account.ErrBadCredentials {
+		t.Errorf("Changed password without the old one: %v", err)
+	}
+	if err := service.ChangePassword("ann", "ann's password", "new password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Authenticate(session.Token); err == nil {
+		t.Errorf("Sessions should end when the password changes")
+	}
+	if _, err := service.Login("ann", "ann's password"); err == nil {
+		t.Errorf("Old password still works")
+	}
+	if _, err := service.Login("ann", "new password"); err != nil {
+		t.Errorf("New password does not work: %v", err)
+	}
+}
+
+func TestAccountService_UpdateProfile(t *testing.T) {
+	service, _ := newTestAccountService()
+	service.Register("ann", "ann's password", account.Profile{})
+
+	updated, err := service.UpdateProfile("ann", account.Profile{DisplayName: "Ann", Country: "NZ"})
+	if err != nil || updated.Profile.Country != "NZ" {
+		t.Fatalf("UpdateProfile failed: %+v %v", updated, err)
+	}
+	if stored, _ := service.Account("ann"); stored.Profile.DisplayName != "Ann" {
+		t.Errorf("Profile not stored: %+v", stored)
+	}
+	if _, err := service.UpdateProfile("ann", account.Profile{Country: "NZL"}); err == nil {
+		t.Errorf("Invalid profile accepted")
+	}
+	if _, err := service.UpdateProfile("bob", account.Profile{}); err != account.ErrAccountNotFound {
+		t.Errorf("Updated an unknown account: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"cardGame/deck/apikey"
+	"cardGame/deck/turn"
+	"fmt"
+	"sync"
+)
+
+// APIKeyService issues and checks the API keys that callers present.
+type APIKeyService struct {
+	mu    sync.Mutex
+	keys  *apikey.Keys
+	clock turn.Clock
+}
+
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{keys: apikey.NewKeys(), clock: turn.SystemClock}
+}
+
+// Issue creates a key with the named scopes and returns it with the key
+// itself, which is shown only this once.
+func (s *APIKeyService) Issue(name string, scopes []string) (apikey.Key, string, error) {
+	if name == "" {
+		return apikey.Key{}, "", fmt.Errorf("A key needs a name")
+	}
+	parsed, err := apikey.ParseScopes(scopes)
+	if err != nil {
+		return apikey.Key{}, "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, raw := s.keys.Issue(name, parsed, s.clock.Now())
+	return key, raw, nil
+}
+
+// Import adds an admin key chosen by the operator.
+func (s *APIKeyService) Import(name, raw string) (apikey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys.Import(name, raw, []apikey.Scope{apikey.Admin}, s.clock.Now())
+}
+
+func (s *APIKeyService) Check(raw string) (apikey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys.Check(raw)
+}
+
+func (s *APIKeyService) Get(id string) (apikey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys.Get(id)
+}
+
+func (s *APIKeyService) List() []apikey.Key {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys.List()
+}
+
+func (s *APIKeyService) Revoke(id string) (apikey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys.Revoke(id, s.clock.Now())
+}
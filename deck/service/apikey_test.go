@@ -0,0 +1,41 @@
+package service
+
+import (
+	"cardGame/deck/apikey"
+	"strings"
+	"testing"
+)
+
+func TestAPIKeyService(t *testing.T) {
+	service := NewAPIKeyService()
+	key, raw, err := service.Issue("team-a", []string{"create", "draw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.Has(apikey.Draw) || key.Has(apikey.Admin) {
+		t.Errorf("Unexpected scopes %v", key.Scopes)
+	}
+	if _, _, err := service.Issue("team-b", []string{"root"}); err == nil {
+		t.Errorf("Issued a key with an unknown scope")
+	}
+	if _, _, err := service.Issue("", []string{"draw"}); err == nil {
+		t.Errorf("Issued a key without a name")
+	}
+
+	admin, err := service.Import("admin", strings.Repeat("a", apikey.MinLength))
+	if err != nil || !admin.Has(apikey.Admin) {
+		t.Fatalf("Import failed: %+v %v", admin, err)
+	}
+	if checked, err := service.Check(raw); err != nil || checked.ID != key.ID {
+		t.Errorf("Check failed: %+v %v", checked, err)
+	}
+	if _, err := service.Revoke(key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Check(raw); err != apikey.ErrInvalidKey {
+		t.Errorf("Revoked key accepted")
+	}
+	if list := service.List(); len(list) != 2 {
+		t.Errorf("Unexpected keys %+v", list)
+	}
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"cardGame/deck/bot"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"sync"
+)
+
+const (
+	// MaxArenaGames and MaxArenaParallel bound a single arena run.
+	MaxArenaGames    = 10000
+	MaxArenaParallel = 32
+	// DefaultArenaMoves is how many actions a game may last before it is
+	// called a draw.
+	DefaultArenaMoves = 10000
+	// ArenaChips is each entrant's play money for games played for chips,
+	// more than a run can lose.
+	ArenaChips = 1 << 40
+)
+
+var ErrArenaRunNotFound = errors.New("Arena run not found")
+
+// ArenaConfig describes an arena run: Games games of GameType between Bots,
+// Parallel at a time. A bot may be listed more than once.
+type ArenaConfig struct {
+	GameType string            `json:"game_type"`
+	Bots     []string          `json:"bots"`
+	Games    int               `json:"games"`
+	Parallel int               `json:"parallel,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+	MaxMoves int               `json:"max_moves,omitempty"`
+}
+
+// ArenaEntrant is how one seat of the run has fared, with its win rate and
+// the 95% Wilson interval around it. Player is the entrant's name in games.
+type ArenaEntrant struct {
+	Player string `json:"player"`
+	Bot    string `json:"bot"`
+	bot.Record
+	WinRate float64 `json:"win_rate"`
+	Low     float64 `json:"low"`
+	High    float64 `json:"high"`
+}
+
+// ArenaRun is an arena run's progress. Failed counts games that could not
+// be played to the end, and Error is the last reason one failed.
+type ArenaRun struct {
+	ID       uuid.UUID      `json:"id"`
+	GameType string         `json:"game_type"`
+	Games    int            `json:"games"`
+	Played   int            `json:"played"`
+	Failed   int            `json:"failed"`
+	Error    string         `json:"error,omitempty"`
+	Done     bool           `json:"done"`
+	Entrants []ArenaEntrant `json:"entrants"`
+}
+
+type arenaRun struct {
+	mu      sync.Mutex
+	run     ArenaRun
+	records []bot.Record
+	done    chan struct{}
+}
+
+// ArenaService plays bots against each other headlessly. Each run hosts its
+// games on a GameService of its own, so they are created and dealt as any
+// other game but are not rated, watched or kept once the run ends.
+type ArenaService struct {
+	mu       sync.Mutex
+	bots     *BotService
+	registry *game.Registry
+	runs     map[uuid.UUID]*arenaRun
+}
+
+func NewArenaService(bots *BotService, registry *game.Registry) *ArenaService {
+	return &ArenaService{bots: bots, registry: registry, runs: make(map[uuid.UUID]*arenaRun)}
+}
+
+// Start checks config and starts the run in the background.
+func (s *ArenaService) Start(config ArenaConfig) (ArenaRun, error) {
+	if len(config.Bots) < 1 {
+		return ArenaRun{}, fmt.Errorf("Arena needs at least one bot")
+	}
+	if config.Games < 1 || config.Games > MaxArenaGames {
+		return ArenaRun{}, fmt.Errorf("Games must be between 1 and %v", MaxArenaGames)
+	}
+	if config.Parallel == 0 {
+		config.Parallel = 1
+	}
+	if config.Parallel < 1 || config.Parallel > MaxArenaParallel {
+		return ArenaRun{}, fmt.Errorf("Parallel must be between 1 and %v", MaxArenaParallel)
+	}
+	if config.MaxMoves == 0 {
+		config.MaxMoves = DefaultArenaMoves
+	}
+	if config.MaxMoves < 1 {
+		return ArenaRun{}, fmt.Errorf("Max moves must be positive")
+	}
+
+	players := make([]bot.Player, len(config.Bots))
+	entrants := make([]ArenaEntrant, len(config.Bots))
+	for i, name := range config.Bots {
+		player, err := s.bots.Player(name)
+		if err != nil {
+			return ArenaRun{}, fmt.Errorf("Unknown bot %q", name)
+		}
+		players[i] = player
+		entrants[i] = ArenaEntrant{Player: fmt.Sprintf("%v-%v", name, i+1), Bot: name}
+	}
+
+	games := NewGameService(dao.NewDeckStorage(), s.registry)
+	games.SetBank(arenaBank(entrants))
+	// Set up one game before starting, so a game type that cannot be played
+	// with these bots fails now rather than in every game of the run.
+	probe, err := games.CreateGame(config.GameType, nil, game.Config{Players: seating(entrants, 0), Options: config.Options})
+	if err != nil {
+		return ArenaRun{}, err
+	}
+	games.remove(probe.ID)
+
+	run := &arenaRun{
+		run:     ArenaRun{ID: uuid.New(), GameType: config.GameType, Games: config.Games, Entrants: entrants},
+		records: make([]bot.Record, len(entrants)),
+		done:    make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.runs[run.run.ID] = run
+	s.mu.Unlock()
+
+	go run.play(games, config, players)
+	return run.state(), nil
+}
+
+// Run returns a run's progress.
+func (s *ArenaService) Run(id uuid.UUID) (ArenaRun, error) {
+	s.mu.Lock()
+	run, found := s.runs[id]
+	s.mu.Unlock()
+	if !found {
+		return ArenaRun{}, ErrArenaRunNotFound
+	}
+	return run.state(), nil
+}
+
+// Wait blocks until a run is done and returns its results.
+func (s *ArenaService) Wait(id uuid.UUID) (ArenaRun, error) {
+	s.mu.Lock()
+	run, found := s.runs[id]
+	s.mu.Unlock()
+	if !found {
+		return ArenaRun{}, ErrArenaRunNotFound
+	}
+	<-run.done
+	return run.state(), nil
+}
+
+// arenaBank is the play money for games played for chips: a ledger of the
+// run's own, in which each entrant holds ArenaChips.
+func arenaBank(entrants []ArenaEntrant) *LedgerService {
+	bank := NewLedgerService()
+	for _, entrant := range entrants {
+		bank.BuyIn("", entrant.Player, ArenaChips)
+	}
+	return bank
+}
+
+// seating rotates the entrants by one seat each game, so no bot always acts
+// first.
+func seating(entrants []ArenaEntrant, n int) []string {
+	players := make([]string, len(entrants))
+	for i := range entrants {
+		players[i] = entrants[(i+n)%len(entrants)].Player
+	}
+	return players
+}
+
+func (r *arenaRun) play(games *GameService, config ArenaConfig, players []bot.Player) {
+	seats := make(map[string]int)
+	for i, entrant := range r.run.Entrants {
+		seats[entrant.Player] = i
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < config.Parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range next {
+				outcome, err := playArenaGame(games, config, seating(r.run.Entrants, n), seats, players)
+				r.report(outcome, err)
+			}
+		}()
+	}
+	for n := 0; n < config.Games; n++ {
+		next <- n
+	}
+	close(next)
+	wg.Wait()
+
+	r.mu.Lock()
+	r.run.Done = true
+	r.mu.Unlock()
+	close(r.done)
+}
+
+// arenaOutcome is one game's result by seat: 1 for a win, 0 for a draw and
+// -1 for a loss. Forfeit is the seat whose bot failed to act, or -1.
+type arenaOutcome struct {
+	results map[int]int
+	forfeit int
+}
+
+// playArenaGame plays one game to the end, asking each player's bot in turn.
+// A bot that errs or picks an illegal action loses and the rest win.
+func playArenaGame(games *GameService, config ArenaConfig, order []string, seats map[string]int, players []bot.Player) (arenaOutcome, error) {
+	created, err := games.CreateGame(config.GameType, nil, game.Config{Players: order, Options: config.Options})
+	if err != nil {
+		return arenaOutcome{}, err
+	}
+	defer games.remove(created.ID)
+
+	for moves := 0; moves < config.MaxMoves; moves++ {
+		state, err := games.State(config.GameType, created.ID, "")
+		if err != nil {
+			return arenaOutcome{}, err
+		}
+		if state.Terminal {
+			return scoreOutcome(state.Scores, seats), nil
+		}
+
+		var t bot.Turn
+		for _, player := range order {
+			if t, err = games.Turn(config.GameType, created.ID, player); err == nil && len(t.Actions) > 0 {
+				break
+			}
+		}
+		if len(t.Actions) == 0 {
+			return arenaOutcome{}, fmt.Errorf("Nobody can act in a game that has not ended")
+		}
+
+		player := t.Player
+		seat := seats[player]
+		action, err := players[seat].Act(t)
+		if err == nil {
+			_, err = games.Apply(config.GameType, created.ID, player, action)
+		}
+		if err != nil {
+			return forfeitOutcome(seat, seats), nil
+		}
+	}
+	// A game that runs past the move limit is a draw.
+	return scoreOutcome(nil, seats), nil
+}
+
+// scoreOutcome makes the highest scorers the winners, or draws every seat if
+// all are level. Alone at the table, a bot wins with a positive score.
+func scoreOutcome(scores map[string]int, seats map[string]int) arenaOutcome {
+	outcome := arenaOutcome{results: make(map[int]int), forfeit: -1}
+	if len(seats) == 1 {
+		for player, seat := range seats {
+			outcome.results[seat] = sign(scores[player])
+		}
+		return outcome
+	}
+
+	best, worst, first := 0, 0, true
+	for player := range seats {
+		if score := scores[player]; first || score > best {
+			best = score
+		}
+		if score := scores[player]; first || score < worst {
+			worst = score
+		}
+		first = false
+	}
+	for player, seat := range seats {
+		switch {
+		case best == worst:
+			outcome.results[seat] = 0
+		case scores[player] == best:
+			outcome.results[seat] = 1
+		default:
+			outcome.results[seat] = -1
+		}
+	}
+	return outcome
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+func forfeitOutcome(forfeit int, seats map[string]int) arenaOutcome {
+	outcome := arenaOutcome{results: make(map[int]int), forfeit: forfeit}
+	for _, seat := range seats {
+		outcome.results[seat] = 1
+	}
+	outcome.results[forfeit] = -1
+	return outcome
+}
+
+func (r *arenaRun) report(outcome arenaOutcome, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.run.Played++
+	if err != nil {
+		r.run.Failed++
+		r.run.Error = err.Error()
+		return
+	}
+	for seat, result := range outcome.results {
+		record := &r.records[seat]
+		record.Games++
+		switch result {
+		case 1:
+			record.Wins++
+		case 0:
+			record.Draws++
+		default:
+			record.Losses++
+		}
+		if seat == outcome.forfeit {
+			record.Errors++
+		}
+	}
+}
+
+func (r *arenaRun) state() ArenaRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run := r.run
+	run.Entrants = make([]ArenaEntrant, len(r.run.Entrants))
+	for i, entrant := range r.run.Entrants {
+		entrant.Record = r.records[i]
+		entrant.WinRate, entrant.Low, entrant.High = entrant.Record.WinRate(bot.Z95)
+		run.Entrants[i] = entrant
+	}
+	return run
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"cardGame/deck/baccarat"
+	"cardGame/deck/bot"
+	"cardGame/deck/game"
+	"cardGame/deck/speed"
+	"fmt"
+	"github.com/google/uuid"
+	"testing"
+)
+
+func newTestArena() *ArenaService {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	registry.Register("baccarat", baccarat.NewGame)
+	registry.Register("speed", speed.NewGame)
+
+	bots := NewBotService()
+	bots.RegisterPlayer("first", bot.First)
+	bots.RegisterPlayer("random", bot.Random)
+	bots.RegisterPlayer("broken", bot.PlayerFunc(func(turn bot.Turn) (game.Action, error) {
+		return game.Action{}, fmt.Errorf("Broken")
+	}))
+	return NewArenaService(bots, registry)
+}
+
+func TestArenaService_Run(t *testing.T) {
+	arena := newTestArena()
+	started, err := arena.Start(ArenaConfig{GameType: "highcard", Bots: []string{"first", "random"}, Games: 200, Parallel: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := arena.Wait(started.ID)
+	if err != nil || !run.Done || run.Played != 200 || run.Failed != 0 {
+		t.Fatalf("Unexpected run %+v: %v", run, err)
+	}
+
+	a, b := run.Entrants[0], run.Entrants[1]
+	if a.Player != "first-1" || b.Player != "random-2" || a.Games != 200 || b.Games != 200 {
+		t.Errorf("Unexpected entrants %+v", run.Entrants)
+	}
+	if a.Wins != b.Losses || a.Draws != b.Draws || a.Wins+a.Draws+a.Losses != 200 {
+		t.Errorf("Results do not add up: %+v", run.Entrants)
+	}
+	if a.Low > a.WinRate || a.High < a.WinRate || a.High-a.Low > 0.2 {
+		t.Errorf("Unexpected interval %+v", a)
+	}
+	if got, _ := arena.Run(started.ID); got.Played != 200 {
+		t.Errorf("Run lost the results: %+v", got)
+	}
+}
+
+func TestArenaService_Forfeit(t *testing.T) {
+	arena := newTestArena()
+	started, err := arena.Start(ArenaConfig{GameType: "speed", Bots: []string{"broken", "first"}, Games: 10, Parallel: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, _ := arena.Wait(started.ID)
+	if broken := run.Entrants[0]; broken.Losses != 10 || broken.Errors != 10 || run.Entrants[1].Wins != 10 {
+		t.Errorf("The broken bot should forfeit every game: %+v", run.Entrants)
+	}
+}
+
+func TestArenaService_MaxMoves(t *testing.T) {
+	arena := newTestArena()
+	started, _ := arena.Start(ArenaConfig{GameType: "speed", Bots: []string{"first", "first"}, Games: 4, MaxMoves: 1})
+	run, _ := arena.Wait(started.ID)
+	if run.Entrants[0].Draws != 4 || run.Entrants[1].Draws != 4 {
+		t.Errorf("Games past the move limit should be draws: %+v", run.Entrants)
+	}
+}
+
+func TestArenaService_Start(t *testing.T) {
+	arena := newTestArena()
+	bad := []ArenaConfig{
+		{GameType: "highcard", Bots: []string{"first", "nobody"}, Games: 1},
+		{GameType: "highcard", Bots: []string{"first", "random"}, Games: 0},
+		{GameType: "highcard", Bots: []string{"first", "random"}, Games: 1, Parallel: MaxArenaParallel + 1},
+		{GameType: "poker", Bots: []string{"first", "random"}, Games: 1},
+		{GameType: "highcard", Bots: []string{"first"}, Games: 1},
+	}
+	for _, config := range bad {
+		if _, err := arena.Start(config); err == nil {
+			t.Errorf("Start accepted %+v", config)
+		}
+	}
+	if _, err := arena.Run(uuid.New()); err != ErrArenaRunNotFound {
+		t.Errorf("Found a run that does not exist")
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"cardGame/deck/account"
+	"cardGame/deck/bot"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrBotNotFound = errors.New("Bot not found")
+
+// BotInfo describes a registered bot. Callback is empty for bots built into
+// the server.
+type BotInfo struct {
+	Name     string `json:"name"`
+	Callback string `json:"callback,omitempty"`
+}
+
+type registeredBot struct {
+	info   BotInfo
+	player bot.Player
+}
+
+// BotService keeps the bots the arena can play: Go players registered at
+// startup and HTTP callbacks registered through the API.
+type BotService struct {
+	mu   sync.Mutex
+	bots map[string]registeredBot
+}
+
+func NewBotService() *BotService {
+	return &BotService{bots: make(map[string]registeredBot)}
+}
+
+// RegisterPlayer adds a bot implemented in Go, replacing any of that name.
+func (s *BotService) RegisterPlayer(name string, player bot.Player) error {
+	if err := account.ValidateID(name); err != nil {
+		return fmt.Errorf("Bot names are 2 to 32 lower case letters, digits, underscores or hyphens")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bots[name] = registeredBot{info: BotInfo{Name: name}, player: player}
+	return nil
+}
+
+// Register adds a bot that is POSTed each turn at callback. It may not
+// replace a bot built into the server.
+func (s *BotService) Register(name, callback string) (BotInfo, error) {
+	player, err := bot.NewHTTPPlayer(callback)
+	if err != nil {
+		return BotInfo{}, err
+	}
+	if err := account.ValidateID(name); err != nil {
+		return BotInfo{}, fmt.Errorf("Bot names are 2 to 32 lower case letters, digits, underscores or hyphens")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, found := s.bots[name]; found && existing.info.Callback == "" {
+		return BotInfo{}, fmt.Errorf("Bot %v is built in", name)
+	}
+	info := BotInfo{Name: name, Callback: callback}
+	s.bots[name] = registeredBot{info: info, player: player}
+	return info, nil
+}
+
+// Remove unregisters a bot registered with a callback.
+func (s *BotService) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, found := s.bots[name]
+	if !found {
+		return ErrBotNotFound
+	}
+	if existing.info.Callback == "" {
+		return fmt.Errorf("Bot %v is built in", name)
+	}
+	delete(s.bots, name)
+	return nil
+}
+
+// Player returns the bot registered as name.
+func (s *BotService) Player(name string) (bot.Player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	registered, found := s.bots[name]
+	if !found {
+		return nil, ErrBotNotFound
+	}
+	return registered.player, nil
+}
+
+// List returns every registered bot by name.
+func (s *BotService) List() []BotInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []BotInfo{}
+	for _, registered := range s.bots {
+		list = append(list, registered.info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"cardGame/deck/bot"
+	"testing"
+)
+
+func TestBotService(t *testing.T) {
+	service := NewBotService()
+	if err := service.RegisterPlayer("random", bot.Random); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Register("random", "http://localhost:9000/bot"); err == nil {
+		t.Errorf("A callback replaced a built-in bot")
+	}
+	if _, err := service.Register("Bad Name", "http://localhost:9000/bot"); err == nil {
+		t.Errorf("Accepted an invalid bot name")
+	}
+	if _, err := service.Register("remote", "localhost:9000"); err == nil {
+		t.Errorf("Accepted a callback without a scheme")
+	}
+
+	info, err := service.Register("remote", "http://localhost:9000/bot")
+	if err != nil || info.Callback != "http://localhost:9000/bot" {
+		t.Fatalf("Register failed: %v %+v", err, info)
+	}
+	if list := service.List(); len(list) != 2 || list[0].Name != "random" || list[1].Name != "remote" {
+		t.Errorf("Unexpected list %+v", list)
+	}
+	if _, err := service.Player("remote"); err != nil {
+		t.Errorf("Player failed: %v", err)
+	}
+
+	if err := service.Remove("random"); err == nil {
+		t.Errorf("Removed a built-in bot")
+	}
+	if err := service.Remove("remote"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Player("remote"); err != ErrBotNotFound {
+		t.Errorf("Expected ErrBotNotFound, got %v", err)
+	}
+}
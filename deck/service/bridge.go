@@ -0,0 +1,376 @@
+package service
+
+import (
+	"cardGame/deck/bridge"
+	"cardGame/deck/bridge/dds"
+	"cardGame/deck/dao"
+	"cardGame/deck/model"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// BridgeTable is one table playing one board: the deck it was dealt from, the
+// players in each seat, the auction and, once the table reports its tricks,
+// the result.
+type BridgeTable struct {
+	ID       uuid.UUID              `json:"id"`
+	DeckID   uuid.UUID              `json:"deck_id"`
+	Board    int                    `json:"board"`
+	Players  map[bridge.Seat]string `json:"players"`
+	RubberID *uuid.UUID             `json:"rubber_id,omitempty"`
+	Auction  *bridge.Auction        `json:"auction"`
+	Turn     *bridge.Seat           `json:"turn,omitempty"`
+	Contract *bridge.Contract       `json:"contract,omitempty"`
+	Result   *bridge.BoardResult    `json:"result,omitempty"`
+}
+
+// BridgeHand is the hand dealt to one seat at a table.
+type BridgeHand struct {
+	Seat bridge.Seat `json:"seat"`
+	Hand bridge.Hand `json:"hand"`
+}
+
+// ErrNotYourSeat means a player tried to call, look at a seat or record a
+// result for a seat they are not sitting in.
+var ErrNotYourSeat = errors.New("That seat is not yours")
+
+// ErrDealInPlay means a table is still playing a deck, so analysing it would
+// give the hands away.
+var ErrDealInPlay = errors.New("A table is still playing this deal")
+
+// MaxDealCount and MaxDealAttempts bound one DealBoards request, so a single
+// call cannot keep the server shuffling indefinitely.
+const (
+	MaxDealCount    = 100
+	MaxDealAttempts = 1000000
+)
+
+type boardKey struct {
+	deckID uuid.UUID
+	board  int
+}
+
+type BridgeService struct {
+	mu      sync.Mutex
+	storage *dao.DeckStorage
+	rng     *rand.Rand
+	tables  map[uuid.UUID]*BridgeTable
+	rubbers map[uuid.UUID]*bridge.Rubber
+	results map[boardKey][]bridge.BoardResult
+}
+
+func NewBridgeService(storage *dao.DeckStorage) *BridgeService {
+	return &BridgeService{
+		storage: storage,
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		tables:  make(map[uuid.UUID]*BridgeTable),
+		rubbers: make(map[uuid.UUID]*bridge.Rubber),
+		results: make(map[boardKey][]bridge.BoardResult),
+	}
+}
+
+// DealBoards generates count deals matching the constraint expression and
+// stores each one as a deck, so the boards can later be fetched or replayed by
+// deck ID. Deals are generated with their own random source, so other tables
+// are not held up while a hard constraint is searched. The decks belong to
+// the player owner and the API key keyID, as CreateDeckFor would make them.
+func (s *BridgeService) DealBoards(owner, keyID, constraint string, count, maxAttempts, firstBoard int) (bridge.DealResult, error) {
+	if count < 1 || count > MaxDealCount {
+		return bridge.DealResult{}, fmt.Errorf("Count must be between 1 and %v", MaxDealCount)
+	}
+	if maxAttempts < 1 || maxAttempts > MaxDealAttempts {
+		return bridge.DealResult{}, fmt.Errorf("Max attempts must be between 1 and %v", MaxDealAttempts)
+	}
+	parsed, err := bridge.ParseConstraint(constraint)
+	if err != nil {
+		return bridge.DealResult{}, err
+	}
+
+	s.mu.Lock()
+	rng := rand.New(rand.NewSource(s.rng.Int63()))
+	s.mu.Unlock()
+
+	result, err := bridge.Generate(bridge.DealRequest{
+		Constraint:  parsed,
+		Count:       count,
+		MaxAttempts: maxAttempts,
+		FirstBoard:  firstBoard,
+	}, rng)
+
+	for _, deal := range result.Deals {
+		deck := deal.Deck()
+		deck.Owner, deck.Key = owner, keyID
+		s.storage.SaveDeck(deck)
+	}
+	return result, err
+}
+
+// Deck returns a stored deck.
+func (s *BridgeService) Deck(deckID uuid.UUID) (model.Deck, bool) {
+	return s.storage.GetDeck(deckID)
+}
+
+// AnalyzeDeck deals a stored deck as the given board and runs double-dummy
+// analysis on it. It is refused while any table dealt from the deck has yet
+// to record its result.
+func (s *BridgeService) AnalyzeDeck(deckID uuid.UUID, board int) (dds.Analysis, error) {
+	deck, found := s.storage.GetDeck(deckID)
+	if !found {
+		return dds.Analysis{}, fmt.Errorf("Invalid Deck ID")
+	}
+	if s.inPlay(deckID) {
+		return dds.Analysis{}, ErrDealInPlay
+	}
+	if deck.Game != nil {
+		return dds.Analysis{}, ErrDeckInGame
+	}
+
+	deal, err := bridge.DealFromDeck(deck, bridge.BoardDealer(board))
+	if err != nil {
+		return dds.Analysis{}, err
+	}
+	deal.Board = board
+	return dds.AnalyzeDeal(deal)
+}
+
+// inPlay reports whether a table dealt from deckID is still playing.
+func (s *BridgeService) inPlay(deckID uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.playing(deckID)
+}
+
+// playing is inPlay for callers holding the lock.
+func (s *BridgeService) playing(deckID uuid.UUID) bool {
+	for _, table := range s.tables {
+		if table.DeckID == deckID && table.Result == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// StartTable opens a table for a stored deck dealt as the given board, with a
+// player in every seat. Dealer and vulnerability follow the board number,
+// except that a table playing a rubber takes its vulnerability from the
+// rubber score. The deck is marked as in play, so it cannot be drawn from or
+// dealt, until every table on it has recorded its result; other tables may
+// play it meanwhile, as in duplicate.
+func (s *BridgeService) StartTable(deckID uuid.UUID, board int, players map[bridge.Seat]string, rubberID *uuid.UUID) (BridgeTable, error) {
+	for seat := bridge.North; seat <= bridge.West; seat++ {
+		if players[seat] == "" {
+			return BridgeTable{}, fmt.Errorf("Every seat needs a player")
+		}
+	}
+	deck, found := s.storage.GetDeck(deckID)
+	if !found {
+		return BridgeTable{}, fmt.Errorf("Invalid Deck ID")
+	}
+	if _, err := bridge.DealFromDeck(deck, bridge.BoardDealer(board)); err != nil {
+		return BridgeTable{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vulnerability := bridge.BoardVulnerability(board)
+	if rubberID != nil {
+		rubber, found := s.rubbers[*rubberID]
+		if !found {
+			return BridgeTable{}, fmt.Errorf("Invalid Rubber ID")
+		}
+		if rubber.Finished {
+			return BridgeTable{}, fmt.Errorf("Rubber is finished")
+		}
+		vulnerability = rubber.Vulnerability()
+	}
+
+	id := uuid.New()
+	if _, err := s.storage.UpdateDeck(deckID, func(stored *model.Deck) error {
+		if stored.Game == nil {
+			stored.Game = &id
+			return nil
+		}
+		if other, ok := s.tables[*stored.Game]; ok && other.DeckID == deckID {
+			return nil
+		}
+		return ErrDeckInGame
+	}); err != nil {
+		return BridgeTable{}, err
+	}
+
+	table := &BridgeTable{
+		ID:       id,
+		DeckID:   deckID,
+		Board:    board,
+		Players:  make(map[bridge.Seat]string, len(players)),
+		RubberID: rubberID,
+		Auction:  bridge.NewAuction(bridge.BoardDealer(board), vulnerability),
+	}
+	for seat := bridge.North; seat <= bridge.West; seat++ {
+		table.Players[seat] = players[seat]
+	}
+	s.tables[table.ID] = table
+	return table.view(), nil
+}
+
+func (s *BridgeService) GetTable(tableID uuid.UUID) (BridgeTable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, found := s.tables[tableID]
+	if !found {
+		return BridgeTable{}, fmt.Errorf("Invalid Table ID")
+	}
+	return table.view(), nil
+}
+
+// MakeCall adds the next call to a table's auction, made by the player in
+// the seat whose turn it is.
+func (s *BridgeService) MakeCall(tableID uuid.UUID, player string, call bridge.Call) (BridgeTable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, found := s.tables[tableID]
+	if !found {
+		return BridgeTable{}, fmt.Errorf("Invalid Table ID")
+	}
+	if !table.Auction.Complete() && table.Players[table.Auction.Turn()] != player {
+		return BridgeTable{}, ErrNotYourSeat
+	}
+	if err := table.Auction.Call(call); err != nil {
+		return BridgeTable{}, err
+	}
+	if table.Auction.Complete() {
+		contract, _ := table.Auction.Contract()
+		table.Contract = &contract
+	}
+	return table.view(), nil
+}
+
+// Hand returns the hand dealt to a seat at a table, to the player sitting
+// there.
+func (s *BridgeService) Hand(tableID uuid.UUID, seat bridge.Seat, player string) (BridgeHand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, found := s.tables[tableID]
+	if !found {
+		return BridgeHand{}, fmt.Errorf("Invalid Table ID")
+	}
+	if table.Players[seat] != player {
+		return BridgeHand{}, ErrNotYourSeat
+	}
+	deck, found := s.storage.GetDeck(table.DeckID)
+	if !found {
+		return BridgeHand{}, fmt.Errorf("Invalid Deck ID")
+	}
+	deal, err := bridge.DealFromDeck(deck, bridge.BoardDealer(table.Board))
+	if err != nil {
+		return BridgeHand{}, err
+	}
+	return BridgeHand{Seat: seat, Hand: deal.Hands[seat]}, nil
+}
+
+// RecordResult scores the tricks declarer took at a table whose auction is
+// complete, adds it to the board's results and, for rubber tables, to the
+// rubber score. player must sit on the declaring side; an empty player
+// records on the server's authority. The last table on a deck to record its
+// result releases the deck.
+func (s *BridgeService) RecordResult(tableID uuid.UUID, player string, tricks int) (BridgeTable, error) {
+	if tricks < 0 || tricks > 13 {
+		return BridgeTable{}, fmt.Errorf("Tricks must be between 0 and 13")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, found := s.tables[tableID]
+	if !found {
+		return BridgeTable{}, fmt.Errorf("Invalid Table ID")
+	}
+	if table.Contract == nil {
+		return BridgeTable{}, fmt.Errorf("Auction is not complete")
+	}
+	if table.Result != nil {
+		return BridgeTable{}, fmt.Errorf("Result already recorded")
+	}
+	declarer := table.Contract.Declarer
+	if player != "" && table.Players[declarer] != player && table.Players[declarer.Partner()] != player {
+		return BridgeTable{}, ErrNotYourSeat
+	}
+
+	if table.RubberID != nil {
+		if err := s.rubbers[*table.RubberID].Record(*table.Contract, tricks); err != nil {
+			return BridgeTable{}, err
+		}
+	}
+
+	result := bridge.NewBoardResult(table.ID, *table.Contract, tricks, table.Auction.Vulnerability)
+	table.Result = &result
+	key := boardKey{table.DeckID, table.Board}
+	s.results[key] = append(s.results[key], result)
+	if !s.playing(table.DeckID) {
+		s.storage.UpdateDeck(table.DeckID, func(stored *model.Deck) error {
+			if stored.Game != nil {
+				if marked, ok := s.tables[*stored.Game]; ok && marked.DeckID == table.DeckID {
+					stored.Game = nil
+				}
+			}
+			return nil
+		})
+	}
+	return table.view(), nil
+}
+
+// BoardResults returns every recorded result of a board with matchpoints and
+// cross-IMPs against the other tables.
+func (s *BridgeService) BoardResults(deckID uuid.UUID, board int) []bridge.BoardResult {
+	s.mu.Lock()
+	results := append([]bridge.BoardResult{}, s.results[boardKey{deckID, board}]...)
+	s.mu.Unlock()
+
+	bridge.CompareResults(results)
+	return results
+}
+
+func (s *BridgeService) NewRubber() (uuid.UUID, bridge.Rubber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := uuid.New()
+	s.rubbers[id] = &bridge.Rubber{}
+	return id, bridge.Rubber{}
+}
+
+func (s *BridgeService) GetRubber(rubberID uuid.UUID) (bridge.Rubber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rubber, found := s.rubbers[rubberID]
+	if !found {
+		return bridge.Rubber{}, fmt.Errorf("Invalid Rubber ID")
+	}
+	return *rubber, nil
+}
+
+// view copies a table so callers never share the auction with the service.
+func (t *BridgeTable) view() BridgeTable {
+	view := *t
+	view.Players = make(map[bridge.Seat]string, len(t.Players))
+	for seat, player := range t.Players {
+		view.Players[seat] = player
+	}
+	auction := *t.Auction
+	auction.Calls = append([]bridge.Call{}, t.Auction.Calls...)
+	view.Auction = &auction
+	if !auction.Complete() {
+		turn := auction.Turn()
+		view.Turn = &turn
+	}
+	return view
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"cardGame/deck/bridge"
+	"cardGame/deck/dao"
+	"cardGame/deck/model"
+	"github.com/google/uuid"
+	"testing"
+)
+
+func TestBridgeService_DealBoards(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := NewBridgeService(storage)
+
+	result, err := service.DealBoards("", "", "N hcp 15-17 and N balanced", 2, 100000, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Deals) != 2 || result.Deals[0].Board != 5 {
+		t.Fatalf("DealBoards failed: got %v deals starting at board %v", len(result.Deals), result.Deals[0].Board)
+	}
+
+	for _, deal := range result.Deals {
+		deck, found := storage.GetDeck(deal.DeckID)
+		if !found {
+			t.Fatalf("DealBoards failed: deck not saved to storage")
+		}
+		stored, _ := bridge.DealFromDeck(deck, deal.Dealer)
+		if bridge.PBNDeal(stored) != bridge.PBNDeal(deal) {
+			t.Errorf("DealBoards failed: stored deck deals different hands")
+		}
+	}
+
+	if _, err := service.DealBoards("", "", "N hcp", 1, 10, 1); err == nil {
+		t.Errorf("DealBoards failed: accepted an invalid constraint")
+	}
+	if _, err := service.DealBoards("", "", "", MaxDealCount+1, 10, 1); err == nil {
+		t.Errorf("DealBoards failed: accepted more than %v deals", MaxDealCount)
+	}
+	if _, err := service.DealBoards("", "", "", 1, MaxDealAttempts+1, 1); err == nil {
+		t.Errorf("DealBoards failed: accepted more than %v attempts", MaxDealAttempts)
+	}
+}
+
+func TestBridgeService_AnalyzeDeck(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := NewBridgeService(storage)
+
+	deal, _ := bridge.ParsePBNDeal("E:AKQJT98765432... .AKQJT98765432.. ..AKQJT98765432. ...AKQJT98765432")
+	deal.DeckID = uuid.New()
+	storage.SaveDeck(deal.Deck())
+
+	analysis, err := service.AnalyzeDeck(deal.DeckID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := analysis.Table.TricksFor(bridge.Spades, bridge.East); got != 13 {
+		t.Errorf("AnalyzeDeck failed: East makes %v tricks in spades, want 13", got)
+	}
+
+	if _, err := service.AnalyzeDeck(uuid.New(), 1); err == nil {
+		t.Errorf("AnalyzeDeck failed: accepted an unknown deck")
+	}
+}
+
+var testSeats = map[bridge.Seat]string{bridge.North: "ann", bridge.East: "bob", bridge.South: "cat", bridge.West: "dan"}
+
+// playCalls makes each call for the player whose turn it is.
+func playCalls(t *testing.T, service *BridgeService, tableID uuid.UUID, calls ...string) BridgeTable {
+	t.Helper()
+	table, _ := service.GetTable(tableID)
+	for _, text := range calls {
+		call, _ := bridge.ParseCall(text)
+		var err error
+		if table, err = service.MakeCall(tableID, table.Players[*table.Turn], call); err != nil {
+			t.Fatalf("MakeCall(%v) failed: %v", text, err)
+		}
+	}
+	return table
+}
+
+func TestBridgeService_Tables(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := NewBridgeService(storage)
+	deck := model.NewDeck(true, "")
+	storage.SaveDeck(deck)
+
+	if _, err := service.StartTable(deck.ID, 2, map[bridge.Seat]string{bridge.North: "ann"}, nil); err == nil {
+		t.Errorf("StartTable opened a table with empty seats")
+	}
+	first, err := service.StartTable(deck.ID, 2, testSeats, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Auction.Dealer != bridge.East || first.Auction.Vulnerability != bridge.VulnerableNS || *first.Turn != bridge.East {
+		t.Errorf("StartTable failed: unexpected dealer or vulnerability %+v", first.Auction)
+	}
+	if _, err := NewDeckService(storage).DrawCards(deck, 1); err != ErrDeckInGame {
+		t.Errorf("DrawCards drew from a deck in play at a table: %v", err)
+	}
+
+	if _, err := service.RecordResult(first.ID, "", 9); err == nil {
+		t.Errorf("RecordResult accepted a table still bidding")
+	}
+	if _, err := service.MakeCall(first.ID, "bob", bridge.Call{Kind: bridge.Redouble}); err == nil {
+		t.Errorf("MakeCall accepted an illegal redouble")
+	}
+	if _, err := service.MakeCall(first.ID, "ann", bridge.Call{Kind: bridge.Pass}); err != ErrNotYourSeat {
+		t.Errorf("MakeCall accepted a call out of turn: %v", err)
+	}
+
+	hand, err := service.Hand(first.ID, bridge.South, "cat")
+	dealt, _ := bridge.DealFromDeck(deck, bridge.East)
+	if err != nil || hand.Seat != bridge.South || len(hand.Hand) != 13 || hand.Hand[0] != dealt.Hands[bridge.South][0] {
+		t.Errorf("Hand returned %+v: %v", hand, err)
+	}
+	if _, err := service.Hand(first.ID, bridge.North, "cat"); err != ErrNotYourSeat {
+		t.Errorf("Hand showed another seat: %v", err)
+	}
+
+	first = playCalls(t, service, first.ID, "P", "1NT", "P", "3NT", "P", "P", "P")
+	if first.Contract == nil || first.Contract.String() != "3NT-S" || first.Turn != nil {
+		t.Fatalf("MakeCall failed: got contract %v", first.Contract)
+	}
+	if _, err := service.RecordResult(first.ID, "bob", 10); err != ErrNotYourSeat {
+		t.Errorf("A defender recorded the result: %v", err)
+	}
+	if first, err = service.RecordResult(first.ID, "ann", 10); err != nil || first.Result.Score != 630 {
+		t.Fatalf("RecordResult failed: %v %+v", err, first.Result)
+	}
+
+	second, _ := service.StartTable(deck.ID, 2, testSeats, nil)
+	playCalls(t, service, second.ID, "P", "1NT", "P", "2C", "P", "2S", "P", "4S", "P", "P", "P")
+	service.RecordResult(second.ID, "", 10)
+
+	if stored, _ := storage.GetDeck(deck.ID); stored.Game != nil {
+		t.Errorf("RecordResult kept the deck in play after its last table")
+	}
+
+	results := service.BoardResults(deck.ID, 2)
+	if len(results) != 2 || results[0].Matchpoints != 1 || results[1].Matchpoints != 0 || results[0].IMPs != 0 {
+		t.Errorf("BoardResults failed: %+v", results)
+	}
+}
+
+func TestBridgeService_Rubber(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := NewBridgeService(storage)
+	deck := model.NewDeck(true, "")
+	storage.SaveDeck(deck)
+
+	rubberID, _ := service.NewRubber()
+	table, err := service.StartTable(deck.ID, 1, testSeats, &rubberID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playCalls(t, service, table.ID, "4H", "P", "P", "P")
+	if _, err := service.RecordResult(table.ID, "", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	rubber, _ := service.GetRubber(rubberID)
+	if rubber.Games[0] != 1 || rubber.Below[0] != 120 {
+		t.Errorf("RecordResult did not update the rubber: %+v", rubber)
+	}
+
+	next, _ := service.StartTable(deck.ID, 2, testSeats, &rubberID)
+	if next.Auction.Vulnerability != bridge.VulnerableNS {
+		t.Errorf("Rubber table has wrong vulnerability: %v", next.Auction.Vulnerability)
+	}
+
+	bad := uuid.New()
+	if _, err := service.StartTable(deck.ID, 1, testSeats, &bad); err == nil {
+		t.Errorf("StartTable accepted an unknown rubber")
+	}
+}
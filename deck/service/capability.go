@@ -0,0 +1,124 @@
+package service
+
+import (
+	"cardGame/deck/capability"
+	"cardGame/deck/turn"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultCapabilityTTL is how long a token lasts unless asked otherwise.
+const DefaultCapabilityTTL = time.Hour
+
+var ErrDrawLimit = errors.New("Capability token's draw limit reached")
+
+// CapabilityService issues signed capability tokens and keeps the state a
+// signature cannot carry: revoked tokens and how many cards each draw token
+// has drawn.
+type CapabilityService struct {
+	mu      sync.Mutex
+	signer  *capability.Signer
+	revoked *capability.Revocations
+	// drawn counts the cards drawn with each draw token until it expires.
+	drawn   map[string]int
+	expires map[string]time.Time
+	clock   turn.Clock
+}
+
+func NewCapabilityService(key []byte) *CapabilityService {
+	return &CapabilityService{
+		signer:  capability.NewSigner(key),
+		revoked: capability.NewRevocations(),
+		drawn:   make(map[string]int),
+		expires: make(map[string]time.Time),
+		clock:   turn.SystemClock,
+	}
+}
+
+// SetClock replaces the clock that expires tokens, for tests.
+func (s *CapabilityService) SetClock(clock turn.Clock) {
+	s.clock = clock
+}
+
+// Issue signs claims that last ttl, or DefaultCapabilityTTL if ttl is 0.
+func (s *CapabilityService) Issue(claims capability.Claims, ttl time.Duration) (string, capability.Claims, error) {
+	if ttl == 0 {
+		ttl = DefaultCapabilityTTL
+	}
+	if ttl < 0 || ttl > capability.MaxTTL {
+		return "", capability.Claims{}, fmt.Errorf("Tokens last from 1 second to %v", capability.MaxTTL)
+	}
+	if err := claims.Validate(); err != nil {
+		return "", capability.Claims{}, err
+	}
+	now := s.clock.Now()
+	claims.ID = capability.NewID()
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(ttl).Unix()
+	token, err := s.signer.Sign(claims)
+	return token, claims, err
+}
+
+// Verify returns the claims of a live token.
+func (s *CapabilityService) Verify(token string) (capability.Claims, error) {
+	claims, err := s.signer.Verify(token, s.clock.Now())
+	if err != nil {
+		return capability.Claims{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.revoked.Revoked(claims.ID) {
+		return capability.Claims{}, capability.ErrRevoked
+	}
+	return claims, nil
+}
+
+// Inspect returns the claims of a token the server signed, live or not.
+func (s *CapabilityService) Inspect(token string) (capability.Claims, error) {
+	return s.signer.Parse(token)
+}
+
+// Revoke stops a token working before it expires.
+func (s *CapabilityService) Revoke(claims capability.Claims) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	if s.clock.Now().Before(claims.Expires()) {
+		s.revoked.Revoke(claims)
+	}
+}
+
+// Spend counts count cards against a draw token's limit, or refunds them
+// if count is negative.
+func (s *CapabilityService) Spend(claims capability.Claims, count int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	if s.drawn[claims.ID]+count > claims.Limit {
+		return ErrDrawLimit
+	}
+	s.drawn[claims.ID] += count
+	s.expires[claims.ID] = claims.Expires()
+	return nil
+}
+
+// Remaining returns how many more cards a draw token may draw.
+func (s *CapabilityService) Remaining(claims capability.Claims) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return claims.Limit - s.drawn[claims.ID]
+}
+
+// prune forgets expired tokens.
+func (s *CapabilityService) prune() {
+	now := s.clock.Now()
+	s.revoked.Prune(now)
+	for id, expires := range s.expires {
+		if !now.Before(expires) {
+			delete(s.drawn, id)
+			delete(s.expires, id)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"cardGame/deck/capability"
+	"cardGame/deck/turn"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func TestCapabilityService(t *testing.T) {
+	service := NewCapabilityService(capability.NewKey())
+	clock := turn.NewFakeClock(time.Unix(1000, 0))
+	service.SetClock(clock)
+	deck := uuid.New()
+
+	if _, _, err := service.Issue(capability.Claims{Deck: &deck, Right: capability.Draw}, 0); err == nil {
+		t.Errorf("Issued a draw token without a limit")
+	}
+	if _, _, err := service.Issue(capability.Claims{Deck: &deck, Right: capability.Read}, 31*24*time.Hour); err == nil {
+		t.Errorf("Issued a token for longer than the maximum")
+	}
+	token, claims, err := service.Issue(capability.Claims{Deck: &deck, Right: capability.Draw, Limit: 3}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.ID == "" || !claims.Expires().Equal(time.Unix(1000, 0).Add(DefaultCapabilityTTL)) {
+		t.Errorf("Unexpected claims %+v", claims)
+	}
+	if verified, err := service.Verify(token); err != nil || verified.ID != claims.ID {
+		t.Errorf("Verify failed: %+v %v", verified, err)
+	}
+
+	if err := service.Spend(claims, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Spend(claims, 2); err != ErrDrawLimit {
+		t.Errorf("Spent past the limit: %v", err)
+	}
+	service.Spend(claims, -1)
+	if remaining := service.Remaining(claims); remaining != 2 {
+		t.Errorf("Remaining %v, want 2", remaining)
+	}
+
+	service.Revoke(claims)
+	if _, err := service.Verify(token); err != capability.ErrRevoked {
+		t.Errorf("Revoked token accepted: %v", err)
+	}
+	if _, err := service.Inspect(token); err != nil {
+		t.Errorf("Inspect should accept revoked tokens: %v", err)
+	}
+
+	clock.Advance(DefaultCapabilityTTL)
+	if _, err := service.Verify(token); err != capability.ErrExpired {
+		t.Errorf("Expired token accepted: %v", err)
+	}
+	service.Revoke(claims)
+	if service.revoked.Len() != 0 || len(service.drawn) != 0 {
+		t.Errorf("Expired tokens should be forgotten")
+	}
+}
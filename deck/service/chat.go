@@ -0,0 +1,269 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cardGame/deck/chat"
+	"cardGame/deck/turn"
+)
+
+// ChatPageSize is the most messages one request returns.
+const ChatPageSize = 100
+
+var (
+	ErrNotInChannel = errors.New("Player is not in this room or at this table")
+	// ErrChatClosed is returned for the chat of a table whose game is over.
+	ErrChatClosed = errors.New("The game is over and its chat is closed")
+)
+
+// ChatChannel names a chat: a lobby room's, or a table's, which is the game
+// it plays. A room's channel has no game type.
+type ChatChannel struct {
+	GameType string    `json:"game_type,omitempty"`
+	ID       uuid.UUID `json:"id"`
+}
+
+// ChatPage is a run of messages, oldest first. Complete is false if some
+// messages after the ones asked for are no longer kept, and Before, if
+// set, pages further back through the history.
+type ChatPage struct {
+	Messages []chat.Message `json:"messages"`
+	Complete bool           `json:"complete"`
+	Before   int            `json:"before,omitempty"`
+}
+
+// ChatService keeps a chat channel for each room and table, open to the
+// players in it. Every message passes the rate limit and the moderation
+// filter, if there is one. A table's channel is dropped when its game ends.
+type ChatService struct {
+	mu       sync.Mutex
+	lobby    *LobbyService
+	games    *GameService
+	channels map[ChatChannel]*chat.Channel
+	limiter  *chat.Limiter
+	filter   chat.Filter
+	clock    turn.Clock
+	// historySize is how many messages each channel keeps.
+	historySize int
+}
+
+func NewChatService(lobby *LobbyService, games *GameService) *ChatService {
+	s := &ChatService{
+		lobby:       lobby,
+		games:       games,
+		channels:    make(map[ChatChannel]*chat.Channel),
+		limiter:     chat.DefaultLimiter(),
+		clock:       turn.SystemClock,
+		historySize: chat.DefaultHistory,
+	}
+	games.OnFinish(func(state GameState) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.channels, ChatChannel{GameType: state.Type, ID: state.ID})
+	})
+	return s
+}
+
+// SetHistorySize sets how many messages channels opened from now on keep.
+func (s *ChatService) SetHistorySize(size int) {
+	s.historySize = size
+}
+
+// SetFilter sets the moderation filter every message passes.
+func (s *ChatService) SetFilter(filter chat.Filter) {
+	s.filter = filter
+}
+
+// SetLimiter replaces the rate limit.
+func (s *ChatService) SetLimiter(limiter *chat.Limiter) {
+	s.limiter = limiter
+}
+
+// SetClock replaces the clock that stamps messages and ends mutes, for
+// tests.
+func (s *ChatService) SetClock(clock turn.Clock) {
+	s.clock = clock
+}
+
+// players returns who may chat in channel: a room's members or a table's
+// players. A finished table has no chat.
+func (s *ChatService) players(channel ChatChannel) ([]string, error) {
+	if channel.GameType != "" {
+		state, err := s.games.State(channel.GameType, channel.ID, "")
+		if err == nil && state.Terminal {
+			return nil, ErrChatClosed
+		}
+		return state.Players, err
+	}
+	room, err := s.lobby.Room(channel.ID, "")
+	return room.Players(), err
+}
+
+// Member reports whether player may chat in channel.
+func (s *ChatService) Member(channel ChatChannel, player string) (bool, error) {
+	players, err := s.players(channel)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range players {
+		if p == player {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Moderator reports whether player moderates channel: a room's host does.
+func (s *ChatService) Moderator(channel ChatChannel, player string) bool {
+	if channel.GameType != "" || player == "" {
+		return false
+	}
+	room, err := s.lobby.Room(channel.ID, "")
+	return err == nil && room.Host() == player
+}
+
+// open returns channel's chat for player, opening it on first use.
+func (s *ChatService) open(channel ChatChannel, player string) (*chat.Channel, error) {
+	member, err := s.Member(channel, player)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, ErrNotInChannel
+	}
+	return s.channel(channel)
+}
+
+// channel returns channel's chat, opening it if need be. A table's chat is
+// only opened while its game is in play, checked under the lock so that it
+// is not opened again after the game ends. The caller must not hold the
+// service's lock.
+func (s *ChatService) channel(channel ChatChannel) (*chat.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.channels[channel]; ok {
+		return c, nil
+	}
+	if channel.GameType != "" {
+		state, err := s.games.State(channel.GameType, channel.ID, "")
+		if err != nil {
+			return nil, err
+		}
+		if state.Terminal {
+			return nil, ErrChatClosed
+		}
+	}
+	c := chat.NewChannel(s.historySize)
+	s.channels[channel] = c
+	return c, nil
+}
+
+// Post adds a message from player, after the moderation filter.
+func (s *ChatService) Post(channel ChatChannel, player, text string) (chat.Message, error) {
+	c, err := s.open(channel, player)
+	if err != nil {
+		return chat.Message{}, err
+	}
+	if text, err = chat.CheckText(text); err != nil {
+		return chat.Message{}, err
+	}
+	if s.filter != nil {
+		if text, err = s.filter.Filter(player, text); err != nil {
+			return chat.Message{}, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	if err := c.CanPost(player, now); err != nil {
+		return chat.Message{}, err
+	}
+	if !s.limiter.Allow(player, now) {
+		return chat.Message{}, chat.ErrRateLimited
+	}
+	return c.Log.Append(chat.Message{Time: now, Player: player, Text: text}), nil
+}
+
+func pageSize(limit int) int {
+	if limit < 1 || limit > ChatPageSize {
+		return ChatPageSize
+	}
+	return limit
+}
+
+// Since returns up to limit messages after since, for player to catch up.
+func (s *ChatService) Since(channel ChatChannel, player string, since, limit int) (ChatPage, error) {
+	c, err := s.open(channel, player)
+	if err != nil {
+		return ChatPage{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := c.CanRead(player); err != nil {
+		return ChatPage{}, err
+	}
+	messages, complete := c.Log.Since(since, pageSize(limit))
+	return ChatPage{Messages: messages, Complete: complete}, nil
+}
+
+// History returns up to limit messages before before, or the latest if it
+// is 0.
+func (s *ChatService) History(channel ChatChannel, player string, before, limit int) (ChatPage, error) {
+	c, err := s.open(channel, player)
+	if err != nil {
+		return ChatPage{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := c.CanRead(player); err != nil {
+		return ChatPage{}, err
+	}
+	limit = pageSize(limit)
+	page := ChatPage{Messages: c.Log.Before(before, limit), Complete: true}
+	if n := len(page.Messages); n == limit && page.Messages[0].Seq > 1 {
+		page.Before = page.Messages[0].Seq
+	}
+	return page, nil
+}
+
+// Mute stops player posting in channel for d. Bans and mutes apply whether
+// or not the player is in the room or at the table yet.
+func (s *ChatService) Mute(channel ChatChannel, player string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("Mute needs a positive duration")
+	}
+	return s.moderate(channel, func(c *chat.Channel) { c.Mute(player, s.clock.Now().Add(d)) })
+}
+
+func (s *ChatService) Unmute(channel ChatChannel, player string) error {
+	return s.moderate(channel, func(c *chat.Channel) { c.Unmute(player) })
+}
+
+// Ban stops player reading or posting in channel until they are unbanned.
+func (s *ChatService) Ban(channel ChatChannel, player string) error {
+	return s.moderate(channel, func(c *chat.Channel) { c.Ban(player) })
+}
+
+func (s *ChatService) Unban(channel ChatChannel, player string) error {
+	return s.moderate(channel, func(c *chat.Channel) { c.Unban(player) })
+}
+
+func (s *ChatService) moderate(channel ChatChannel, fn func(*chat.Channel)) error {
+	if _, err := s.players(channel); err != nil {
+		return err
+	}
+	c, err := s.channel(channel)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(c)
+	return nil
+}
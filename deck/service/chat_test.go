@@ -0,0 +1,108 @@
+package service
+
+import (
+	"cardGame/deck/chat"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/lobby"
+	"cardGame/deck/turn"
+	"testing"
+	"time"
+)
+
+func TestChatService_Room(t *testing.T) {
+	games := newTestGameService(dao.NewDeckStorage())
+	lobbyService := NewLobbyService(games)
+	service := NewChatService(lobbyService, games)
+	clock := turn.NewFakeClock(time.Unix(0, 0))
+	service.SetClock(clock)
+	service.SetFilter(chat.NewWordList([]string{"darn"}))
+	service.SetLimiter(chat.NewLimiter(2, time.Minute))
+
+	room, _ := lobbyService.CreateRoom("ann", "highcard", 3, lobby.Public, nil)
+	lobbyService.Join(room.ID, "bob", "")
+	channel := ChatChannel{ID: room.ID}
+
+	if _, err := service.Post(channel, "cat", "hi"); err == nil {
+		t.Errorf("Player outside the room posted")
+	}
+	m, err := service.Post(channel, "bob", " darn, hi ")
+	if err != nil || m.Seq != 1 || m.Text != "****, hi" || m.Player != "bob" {
+		t.Fatalf("Post = %+v %v", m, err)
+	}
+	service.Post(channel, "bob", "again")
+	if _, err := service.Post(channel, "bob", "and again"); err != chat.ErrRateLimited {
+		t.Errorf("Rate limit not applied: %v", err)
+	}
+	if page, _ := service.Since(channel, "ann", 1, 0); len(page.Messages) != 1 || page.Messages[0].Text != "again" || !page.Complete {
+		t.Errorf("Since(1) = %+v", page)
+	}
+
+	if !service.Moderator(channel, "ann") || service.Moderator(channel, "bob") {
+		t.Errorf("Only the host should moderate the room")
+	}
+	service.Mute(channel, "bob", time.Hour)
+	clock.Advance(time.Minute)
+	if _, err := service.Post(channel, "bob", "hello?"); err != chat.ErrMuted {
+		t.Errorf("Muted player posted: %v", err)
+	}
+	if _, err := service.Since(channel, "bob", 0, 0); err != nil {
+		t.Errorf("Muted player cannot read: %v", err)
+	}
+	service.Unmute(channel, "bob")
+	service.Ban(channel, "bob")
+	if _, err := service.Since(channel, "bob", 0, 0); err != chat.ErrBanned {
+		t.Errorf("Banned player read: %v", err)
+	}
+	service.Unban(channel, "bob")
+	if _, err := service.Post(channel, "bob", "back"); err != nil {
+		t.Errorf("Unbanned player cannot post: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		clock.Advance(time.Minute)
+		service.Post(channel, "ann", "more")
+	}
+	page, _ := service.History(channel, "ann", 0, 2)
+	if len(page.Messages) != 2 || page.Messages[1].Seq != 5 || page.Before != 4 {
+		t.Fatalf("History = %+v", page)
+	}
+	if page, _ = service.History(channel, "ann", page.Before, 10); len(page.Messages) != 3 || page.Before != 0 {
+		t.Errorf("Older page = %+v", page)
+	}
+}
+
+func TestChatService_Table(t *testing.T) {
+	games := newTestGameService(dao.NewDeckStorage())
+	service := NewChatService(NewLobbyService(games), games)
+	created, _ := games.CreateGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}})
+	table := ChatChannel{GameType: "highcard", ID: created.ID}
+
+	if _, err := service.Post(table, "ann", "gl"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Post(table, "cat", "gl"); err == nil {
+		t.Errorf("Player away from the table posted")
+	}
+	if page, _ := service.Since(table, "bob", 0, 0); len(page.Messages) != 1 {
+		t.Errorf("Since = %+v", page)
+	}
+	if service.Moderator(table, "ann") {
+		t.Errorf("Players should not moderate a table")
+	}
+	if err := service.Ban(ChatChannel{GameType: "war", ID: created.ID}, "ann"); err != ErrGameNotFound {
+		t.Errorf("Banned in a missing channel: %v", err)
+	}
+
+	games.Apply("highcard", created.ID, "ann", game.Action{Type: "draw"})
+	games.Apply("highcard", created.ID, "bob", game.Action{Type: "draw"})
+	if len(service.channels) != 0 {
+		t.Errorf("The table's chat was kept after its game ended")
+	}
+	if _, err := service.Post(table, "ann", "gg"); err != ErrChatClosed {
+		t.Errorf("Posted at a finished table: %v", err)
+	}
+	if len(service.channels) != 0 {
+		t.Errorf("A finished table's chat was opened again")
+	}
+}
@@ -0,0 +1,630 @@
+package service
+
+import (
+	"cardGame/deck/bot"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+	"cardGame/deck/replay"
+	"cardGame/deck/spectate"
+	"cardGame/deck/turn"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// GameState is a hosted game as one player sees it. Version counts the
+// actions applied so far. View is encoded while the game is locked, since a
+// game's view may share its state.
+type GameState struct {
+	ID       uuid.UUID       `json:"id"`
+	Type     string          `json:"type"`
+	DeckID   uuid.UUID       `json:"deck_id"`
+	Version  int             `json:"version"`
+	Players  []string        `json:"players"`
+	View     json.RawMessage `json:"view"`
+	Actions  []game.Action   `json:"actions"`
+	Terminal bool            `json:"terminal"`
+	Scores   map[string]int  `json:"scores,omitempty"`
+	Turn     turn.Status     `json:"turn"`
+	// Forfeited names the player who lost the game by running out of time,
+	// or whose bot could not move.
+	Forfeited string `json:"forfeited,omitempty"`
+}
+
+var ErrGameNotFound = errors.New("Game not found")
+
+// DefaultRetention is how long a finished game stays available before it is
+// no longer hosted.
+const DefaultRetention = time.Hour
+
+// hostedGame serialises everything done to one game behind its mutex, so
+// each action sees the state the previous one left and is applied whole.
+type hostedGame struct {
+	mu       sync.Mutex
+	id       uuid.UUID
+	gameType string
+	deckID   uuid.UUID
+	version  int
+	game     game.Game
+	turns    *turn.Turns
+	clock    turn.Clock
+	events   *replay.Buffer
+	// watchers are sent a frame after every event.
+	watchers []func(spectate.Frame)
+	// forfeited is the player who ran out of time, or whose bot could not
+	// move, ending the game.
+	forfeited string
+	// bots play the seats they are given, and thinking marks those working
+	// out a move. think starts a bot on its move.
+	bots     map[string]bot.Player
+	thinking map[string]bool
+	think    func(player string)
+}
+
+// GameService hosts games of any type in the registry.
+type GameService struct {
+	mu       sync.Mutex
+	storage  *dao.DeckStorage
+	registry *game.Registry
+	clock    turn.Clock
+	games    map[uuid.UUID]*hostedGame
+	finished []func(GameState)
+	bank     game.Bank
+	// replaySize is how many events each game keeps for players catching up.
+	replaySize int
+	bots       *BotService
+	// retention is how long a finished game is kept.
+	retention time.Duration
+}
+
+func NewGameService(storage *dao.DeckStorage, registry *game.Registry) *GameService {
+	return &GameService{
+		storage:    storage,
+		registry:   registry,
+		clock:      turn.SystemClock,
+		games:      make(map[uuid.UUID]*hostedGame),
+		replaySize: replay.DefaultSize,
+		retention:  DefaultRetention,
+	}
+}
+
+// SetRetention sets how long games that finish from now on are kept.
+func (s *GameService) SetRetention(retention time.Duration) {
+	s.retention = retention
+}
+
+// SetReplaySize sets how many events games created from now on keep.
+func (s *GameService) SetReplaySize(size int) {
+	s.replaySize = size
+}
+
+// SetClock replaces the clock that times moves, for tests.
+func (s *GameService) SetClock(clock turn.Clock) {
+	s.clock = clock
+}
+
+// SetBank gives the service the bank that games played for chips stake
+// them through.
+func (s *GameService) SetBank(bank game.Bank) {
+	s.bank = bank
+}
+
+// SetBots gives the service the bots that may be seated at its games.
+func (s *GameService) SetBots(bots *BotService) {
+	s.bots = bots
+}
+
+// OnFinish registers fn to be called with the public state of every game
+// that ends, whether by an action or by a player running out of time. It is
+// called without any game locked, so it may use the service.
+func (s *GameService) OnFinish(fn func(GameState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finished = append(s.finished, fn)
+}
+
+// finish tells the listeners a game ended and stops hosting it once the
+// retention period is over.
+func (s *GameService) finish(state GameState) {
+	s.mu.Lock()
+	listeners := append([]func(GameState){}, s.finished...)
+	s.mu.Unlock()
+	for _, fn := range listeners {
+		fn(state)
+	}
+	s.clock.AfterFunc(s.retention, func() {
+		s.remove(state.ID)
+	})
+}
+
+func (s *GameService) Types() []string {
+	return s.registry.Types()
+}
+
+// CheckType returns an error unless gameType is registered.
+func (s *GameService) CheckType(gameType string) error {
+	for _, t := range s.registry.Types() {
+		if t == gameType {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown game type %q", gameType)
+}
+
+// Tool runs one of the tools a game type offers. It returns
+// game.ErrUnknownTool for a game type or tool that does not exist.
+func (s *GameService) Tool(gameType, name string, request json.RawMessage) (interface{}, error) {
+	g, err := s.registry.New(gameType)
+	if err != nil {
+		return nil, game.ErrUnknownTool
+	}
+	toolbox, ok := g.(game.Toolbox)
+	if !ok {
+		return nil, game.ErrUnknownTool
+	}
+	return toolbox.Tool(name, request)
+}
+
+// Deck returns a stored deck.
+func (s *GameService) Deck(deckID uuid.UUID) (model.Deck, bool) {
+	return s.storage.GetDeck(deckID)
+}
+
+// CheckPlayers checks that a game of gameType can be set up for count
+// players with options, without hosting it.
+func (s *GameService) CheckPlayers(gameType string, count int, options map[string]string) error {
+	g, err := s.registry.New(gameType)
+	if err != nil {
+		return err
+	}
+	players := make([]string, count)
+	for i := range players {
+		players[i] = fmt.Sprintf("player-%v", i+1)
+	}
+	config := game.Config{Players: players, Options: options}
+	deck := model.NewDeck(true, "")
+	if provider, ok := g.(game.DeckProvider); ok {
+		deck = provider.NewDeck(config)
+	}
+	return g.Setup(deck, config)
+}
+
+// CreateGame sets up a new game of the given type from a stored deck, which
+// is marked as dealt to the game and cannot be used again. When deckID is
+// nil it deals a freshly shuffled deck, or the game's own if it is a
+// DeckProvider, and saves it to storage.
+func (s *GameService) CreateGame(gameType string, deckID *uuid.UUID, config game.Config) (GameState, error) {
+	return s.CreateTimedGame(gameType, deckID, config, turn.Control{})
+}
+
+// CreateTimedGame creates a game whose moves are timed by control. A player
+// who runs out of time has the control's default action taken for them.
+func (s *GameService) CreateTimedGame(gameType string, deckID *uuid.UUID, config game.Config, control turn.Control) (GameState, error) {
+	if err := control.Validate(); err != nil {
+		return GameState{}, err
+	}
+	g, err := s.registry.New(gameType)
+	if err != nil {
+		return GameState{}, err
+	}
+
+	var deck model.Deck
+	if deckID != nil {
+		var found bool
+		if deck, found = s.storage.GetDeck(*deckID); !found {
+			return GameState{}, fmt.Errorf("Invalid Deck ID")
+		}
+		if deck.Game != nil {
+			return GameState{}, ErrDeckInGame
+		}
+	} else if provider, ok := g.(game.DeckProvider); ok {
+		deck = provider.NewDeck(config)
+	} else {
+		deck = model.NewDeck(true, "")
+	}
+
+	id := uuid.New()
+	if banked, ok := g.(game.Banked); ok {
+		if s.bank == nil {
+			return GameState{}, fmt.Errorf("%v is played for chips, but there is no bank", gameType)
+		}
+		banked.SetBank(s.bank, id.String())
+	}
+	if err := g.Setup(deck, config); err != nil {
+		return GameState{}, err
+	}
+	if deckID == nil {
+		deck.Game = &id
+		s.storage.SaveDeck(deck)
+	} else if _, err := s.storage.UpdateDeck(deck.ID, func(stored *model.Deck) error {
+		if stored.Game != nil {
+			return ErrDeckInGame
+		}
+		stored.Game = &id
+		return nil
+	}); err != nil {
+		return GameState{}, err
+	}
+
+	hosted := &hostedGame{id: id, gameType: gameType, deckID: deck.ID, game: g, clock: s.clock, events: replay.NewBuffer(s.replaySize)}
+	hosted.think = func(player string) {
+		go s.botMove(hosted, player)
+	}
+	hosted.turns = turn.NewTurns(control, s.clock, g.Players(), func(player string, generation int) {
+		if hosted.expire(player, generation) {
+			s.finish(hosted.publicState())
+		}
+	})
+	hosted.mu.Lock()
+	hosted.turns.Update(turn.ToAct(g), "")
+	hosted.mu.Unlock()
+	s.mu.Lock()
+	s.games[hosted.id] = hosted
+	s.mu.Unlock()
+
+	return hosted.state(""), nil
+}
+
+func (s *GameService) find(gameType string, gameID uuid.UUID) (*hostedGame, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hosted, found := s.games[gameID]
+	if !found || hosted.gameType != gameType {
+		return nil, ErrGameNotFound
+	}
+	return hosted, nil
+}
+
+// remove stops hosting a game.
+func (s *GameService) remove(gameID uuid.UUID) {
+	s.mu.Lock()
+	hosted, found := s.games[gameID]
+	delete(s.games, gameID)
+	s.mu.Unlock()
+	if found {
+		hosted.mu.Lock()
+		hosted.turns.Stop()
+		hosted.mu.Unlock()
+	}
+}
+
+// State returns the game as player sees it. An empty player gets the public
+// view.
+func (s *GameService) State(gameType string, gameID uuid.UUID, player string) (GameState, error) {
+	hosted, err := s.find(gameType, gameID)
+	if err != nil {
+		return GameState{}, err
+	}
+
+	hosted.mu.Lock()
+	defer hosted.mu.Unlock()
+	return hosted.state(player), nil
+}
+
+// Apply plays one action for player and returns the new state as they see it.
+// Concurrent actions on the same game are applied one at a time in the order
+// they take the game's lock; an action that is no longer legal by its turn
+// fails and leaves the game as it was.
+func (s *GameService) Apply(gameType string, gameID uuid.UUID, player string, action game.Action) (GameState, error) {
+	hosted, err := s.find(gameType, gameID)
+	if err != nil {
+		return GameState{}, err
+	}
+
+	hosted.mu.Lock()
+	state, err := hosted.play(player, action)
+	finished := err == nil && state.Terminal
+	hosted.mu.Unlock()
+
+	if finished {
+		s.finish(hosted.publicState())
+	}
+	return state, err
+}
+
+func (h *hostedGame) play(player string, action game.Action) (GameState, error) {
+	if h.over() {
+		return GameState{}, fmt.Errorf("Game is over")
+	}
+	if !h.turns.Acting(player) {
+		return GameState{}, fmt.Errorf("It is not %v's turn", player)
+	}
+	if err := h.apply(player, action, replay.Action); err != nil {
+		return GameState{}, err
+	}
+	return h.state(player), nil
+}
+
+func (h *hostedGame) over() bool {
+	return h.forfeited != "" || h.game.Terminal()
+}
+
+// apply plays an action and restarts the clocks for whoever is to act next.
+func (h *hostedGame) apply(player string, action game.Action, kind replay.Kind) error {
+	if err := h.game.Apply(player, action); err != nil {
+		return err
+	}
+	event := h.record(kind, player, &action)
+	if h.game.Terminal() {
+		h.turns.Stop()
+	} else {
+		h.turns.Update(turn.ToAct(h.game), player)
+	}
+	h.publish(event)
+	h.prompt()
+	return nil
+}
+
+// expire runs when a player's clock runs out. Unless they moved in the
+// meantime, they forfeit or have the default action played for them. It
+// reports whether that ended the game.
+func (h *hostedGame) expire(player string, generation int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.over() || !h.turns.Expire(player, generation) {
+		return false
+	}
+	if h.turns.Forfeits() {
+		h.forfeit(player)
+		return true
+	}
+	if action, ok := h.turns.DefaultAction(h.game.LegalActions(player)); ok {
+		h.apply(player, action, replay.Timeout)
+	}
+	return h.over()
+}
+
+// forfeit ends the game with player losing it.
+func (h *hostedGame) forfeit(player string) {
+	h.forfeited = player
+	event := h.record(replay.Forfeit, player, nil)
+	h.turns.Stop()
+	h.publish(event)
+}
+
+// record moves the game to its next version and logs the event.
+func (h *hostedGame) record(kind replay.Kind, player string, action *game.Action) replay.Event {
+	h.version++
+	event := replay.Event{Seq: h.version, Time: h.clock.Now(), Kind: kind, Player: player, Action: action}
+	h.events.Append(event)
+	return event
+}
+
+// frame is the game as it stands, for spectators: the public state, and
+// every player's view for commentators.
+func (h *hostedGame) frame(event *replay.Event) spectate.Frame {
+	hands := make(map[string]interface{})
+	for _, player := range h.game.Players() {
+		hands[player] = h.game.View(player)
+	}
+	return spectate.Frame{Time: h.clock.Now(), Event: event, Public: spectate.Encode(h.state("")), Revealed: spectate.Encode(hands)}
+}
+
+func (h *hostedGame) publish(event replay.Event) {
+	if len(h.watchers) == 0 {
+		return
+	}
+	frame := h.frame(&event)
+	for _, fn := range h.watchers {
+		fn(frame)
+	}
+}
+
+// Watch sends fn a frame of the game as it stands, then one after every
+// event. fn is called with the game locked, so it must not use the game.
+func (s *GameService) Watch(gameType string, gameID uuid.UUID, fn func(spectate.Frame)) error {
+	hosted, err := s.find(gameType, gameID)
+	if err != nil {
+		return err
+	}
+
+	hosted.mu.Lock()
+	defer hosted.mu.Unlock()
+	fn(hosted.frame(nil))
+	hosted.watchers = append(hosted.watchers, fn)
+	return nil
+}
+
+// Turn returns what a bot playing player is sent: the game as they see it
+// and their legal actions, with a Sample function when the game can be
+// determinized.
+func (s *GameService) Turn(gameType string, gameID uuid.UUID, player string) (bot.Turn, error) {
+	hosted, err := s.find(gameType, gameID)
+	if err != nil {
+		return bot.Turn{}, err
+	}
+
+	hosted.mu.Lock()
+	defer hosted.mu.Unlock()
+	return hosted.botTurn(player), nil
+}
+
+func (h *hostedGame) botTurn(player string) bot.Turn {
+	state := h.state(player)
+	t := bot.Turn{
+		GameType: h.gameType,
+		GameID:   h.id.String(),
+		Player:   player,
+		Version:  state.Version,
+		View:     state.View,
+		Actions:  state.Actions,
+	}
+	if _, ok := h.game.(game.Determinizer); ok {
+		version := h.version
+		t.Sample = func(rng *rand.Rand) game.Game {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if h.version != version {
+				return nil
+			}
+			return h.game.(game.Determinizer).Determinize(player, rng)
+		}
+	}
+	return t
+}
+
+// Bot returns the bot registered as name.
+func (s *GameService) Bot(name string) (bot.Player, error) {
+	if s.bots == nil {
+		return nil, fmt.Errorf("There are no bots")
+	}
+	b, err := s.bots.Player(name)
+	if err != nil {
+		return nil, fmt.Errorf("Unknown bot %q", name)
+	}
+	return b, nil
+}
+
+// Seat has the bot registered as name play for player, who must be in the
+// game. The bot moves whenever player is to act, for as long as the game
+// lasts.
+func (s *GameService) Seat(gameType string, gameID uuid.UUID, player, name string) error {
+	b, err := s.Bot(name)
+	if err != nil {
+		return err
+	}
+	hosted, err := s.find(gameType, gameID)
+	if err != nil {
+		return err
+	}
+
+	hosted.mu.Lock()
+	defer hosted.mu.Unlock()
+	seated := false
+	for _, p := range hosted.game.Players() {
+		seated = seated || p == player
+	}
+	if !seated {
+		return fmt.Errorf("%v is not playing", player)
+	}
+	if hosted.bots == nil {
+		hosted.bots = make(map[string]bot.Player)
+		hosted.thinking = make(map[string]bool)
+	}
+	hosted.bots[player] = b
+	hosted.prompt()
+	return nil
+}
+
+// prompt starts the bots whose turn it is and who are not already thinking.
+func (h *hostedGame) prompt() {
+	if len(h.bots) == 0 || h.over() {
+		return
+	}
+	for _, player := range turn.ToAct(h.game) {
+		if h.bots[player] != nil && !h.thinking[player] {
+			h.thinking[player] = true
+			h.think(player)
+		}
+	}
+}
+
+// botMove asks a seated bot for its move and plays it. If the game moved on
+// while the bot was thinking, it is asked again; if it fails or picks an
+// illegal action, its first legal action is played for it, and if even that
+// fails it forfeits, so the game never waits on a broken bot.
+func (s *GameService) botMove(h *hostedGame, player string) {
+	for {
+		h.mu.Lock()
+		t, b := h.botTurn(player), h.bots[player]
+		if len(t.Actions) == 0 || h.over() {
+			h.thinking[player] = false
+			h.mu.Unlock()
+			return
+		}
+		h.mu.Unlock()
+
+		action, err := b.Act(t)
+
+		h.mu.Lock()
+		if h.version != t.Version {
+			h.mu.Unlock()
+			continue
+		}
+		if err != nil || !game.IsLegal(h.game, player, action) {
+			action = t.Actions[0]
+		}
+		h.thinking[player] = false
+		if _, err = h.play(player, action); err != nil && !action.Equal(t.Actions[0]) {
+			_, err = h.play(player, t.Actions[0])
+		}
+		if err != nil {
+			// Not even the first legal action could be played, so the bot
+			// forfeits rather than leave the game waiting on it.
+			h.forfeit(player)
+		}
+		finished := h.over()
+		h.mu.Unlock()
+
+		if finished {
+			s.finish(h.publicState())
+		}
+		return
+	}
+}
+
+// Replay is what a player missed: the events after the last one they saw
+// and the game as they see it now. Complete is false if the oldest of those
+// events are no longer kept, so the player must rely on the state alone.
+type Replay struct {
+	Events   []replay.Event `json:"events"`
+	Complete bool           `json:"complete"`
+	State    GameState      `json:"state"`
+}
+
+// Events returns the events after since with the current state, as player
+// sees them. The cards in other players' actions are left out, since some
+// games keep them hidden; the state shows what is public.
+func (s *GameService) Events(gameType string, gameID uuid.UUID, player string, since int) (Replay, error) {
+	hosted, err := s.find(gameType, gameID)
+	if err != nil {
+		return Replay{}, err
+	}
+
+	hosted.mu.Lock()
+	defer hosted.mu.Unlock()
+	events, complete := hosted.events.Since(since)
+	for i, e := range events {
+		if e.Action != nil && e.Player != player {
+			redacted := *e.Action
+			redacted.Cards = nil
+			events[i].Action = &redacted
+		}
+	}
+	return Replay{Events: events, Complete: complete, State: hosted.state(player)}, nil
+}
+
+func (h *hostedGame) publicState() GameState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state("")
+}
+
+func (h *hostedGame) state(player string) GameState {
+	state := GameState{
+		ID:        h.id,
+		Type:      h.gameType,
+		DeckID:    h.deckID,
+		Version:   h.version,
+		Players:   h.game.Players(),
+		View:      spectate.Encode(h.game.View(player)),
+		Actions:   h.game.LegalActions(player),
+		Terminal:  h.over(),
+		Turn:      h.turns.Status(),
+		Forfeited: h.forfeited,
+	}
+	if state.Actions == nil {
+		state.Actions = []game.Action{}
+	}
+	if state.Terminal {
+		state.Scores = h.game.Scores()
+	}
+	return state
+}
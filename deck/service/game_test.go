@@ -0,0 +1,452 @@
+package service
+
+import (
+	"cardGame/deck/baccarat"
+	"cardGame/deck/bot"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+	"cardGame/deck/replay"
+	"cardGame/deck/shedding"
+	"cardGame/deck/speed"
+	"cardGame/deck/turn"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestGameService(storage *dao.DeckStorage) *GameService {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	registry.Register("baccarat", baccarat.NewGame)
+	registry.Register("speed", speed.NewGame)
+	service := NewGameService(storage, registry)
+	service.SetBank(NewLedgerService())
+	return service
+}
+
+func TestGameService_CreateGame(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := newTestGameService(storage)
+
+	state, err := service.CreateGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := storage.GetDeck(state.DeckID); !found {
+		t.Errorf("CreateGame failed: deck not saved to storage")
+	}
+	if len(state.Players) != 2 || state.Terminal || len(state.Actions) != 0 {
+		t.Errorf("CreateGame failed: unexpected public state %+v", state)
+	}
+
+	if _, err := service.CreateGame("poker", nil, game.Config{Players: []string{"ann", "bob"}}); err == nil {
+		t.Errorf("CreateGame accepted an unknown game type")
+	}
+	missing := uuid.New()
+	if _, err := service.CreateGame("highcard", &missing, game.Config{Players: []string{"ann", "bob"}}); err == nil {
+		t.Errorf("CreateGame accepted an unknown deck")
+	}
+	if _, err := service.CreateGame("highcard", nil, game.Config{Players: []string{"ann"}}); err == nil {
+		t.Errorf("CreateGame accepted a setup the game rejects")
+	}
+}
+
+func TestGameService_Apply(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := newTestGameService(storage)
+	deck := model.NewDeck(false, "3C,QH")
+	storage.SaveDeck(deck)
+
+	created, err := service.CreateGame("highcard", &deck.ID, game.Config{Players: []string{"ann", "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := storage.GetDeck(deck.ID); stored.Game == nil || *stored.Game != created.ID {
+		t.Errorf("CreateGame did not mark the deck as dealt to the game: %v", stored.Game)
+	}
+	if _, err := service.CreateGame("highcard", &deck.ID, game.Config{Players: []string{"ann", "bob"}}); err != ErrDeckInGame {
+		t.Errorf("CreateGame dealt a deck to a second game: %v", err)
+	}
+	if _, err := NewDeckService(storage).DrawCards(deck, 1); err != ErrDeckInGame {
+		t.Errorf("DrawCards drew from a deck dealt to a game: %v", err)
+	}
+
+	state, err := service.State("highcard", created.ID, "ann")
+	if err != nil || len(state.Actions) != 1 {
+		t.Fatalf("State failed: %v %+v", err, state)
+	}
+	if _, err := service.State("bridge", created.ID, "ann"); err == nil {
+		t.Errorf("State found a game under the wrong type")
+	}
+
+	draw := game.Action{Type: "draw"}
+	if _, err := service.Apply("highcard", created.ID, "bob", draw); err == nil {
+		t.Errorf("Apply accepted a move out of turn")
+	}
+	service.Apply("highcard", created.ID, "ann", draw)
+	state, err = service.Apply("highcard", created.ID, "bob", draw)
+	if err != nil || !state.Terminal || state.Scores["bob"] != 1 {
+		t.Errorf("Apply failed: %v %+v", err, state)
+	}
+	if _, err := service.Apply("highcard", created.ID, "ann", draw); err == nil {
+		t.Errorf("Apply accepted a move after the game ended")
+	}
+}
+
+func TestGameService_DeckProvider(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := newTestGameService(storage)
+
+	state, err := service.CreateGame("baccarat", nil, game.Config{Players: []string{"ann"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deck, _ := storage.GetDeck(state.DeckID); len(deck.Cards) != 8*52 {
+		t.Errorf("CreateGame did not use the game's own deck: %v cards", len(deck.Cards))
+	}
+}
+
+func TestGameService_RacingActions(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := newTestGameService(storage)
+
+	// Ann holds 8C and Bob 6C, and the left center pile starts on 7H, so
+	// each can play there until the other has.
+	codes := strings.Split("8C,2C,3C,4C,5C,7C,9C,1C,JC,QC,KC,AC,2D,3D,4D,5D,6D,7D,8D,9D,"+
+		"6C,2H,3H,4H,5H,6H,8H,9H,1H,JH,QH,KH,AH,2S,3S,4S,5S,6S,7S,8S,"+
+		"9S,1S,JS,QS,KS,7H,1D,JD,QD,KD,AD,AS", ",")
+	deck := model.NewDeck(false, strings.Join(codes, ","))
+	storage.SaveDeck(deck)
+	created, err := service.CreateGame("speed", &deck.ID, game.Config{Players: []string{"ann", "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plays := map[string]string{"ann": "8C", "bob": "6C"}
+	errs := make(chan error, len(plays))
+	var wg sync.WaitGroup
+	for player, code := range plays {
+		wg.Add(1)
+		go func(player, code string) {
+			defer wg.Done()
+			_, err := service.Apply("speed", created.ID, player, game.Action{Type: "play", Cards: []string{code}, Value: "left"})
+			errs <- err
+		}(player, code)
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	state, _ := service.State("speed", created.ID, "")
+	if failed != 1 || state.Version != 1 {
+		t.Errorf("Expected exactly one racing play to win: %v failed, version %v", failed, state.Version)
+	}
+}
+
+// TestGameService_StateWhileBetting reads the state of a game while bets are
+// placed on it. Run with -race, it fails if a view is encoded after the game
+// is unlocked.
+func TestGameService_StateWhileBetting(t *testing.T) {
+	service := newTestGameService(dao.NewDeckStorage())
+	ledger := NewLedgerService()
+	ledger.BuyIn("", "ann", 1000)
+	service.SetBank(ledger)
+	created, err := service.CreateGame("baccarat", nil, game.Config{Players: []string{"ann"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			service.Apply("baccarat", created.ID, "ann", game.Action{Type: "bet", Value: "player", Amount: 10})
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		state, err := service.State("baccarat", created.ID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := json.Marshal(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
+
+func TestGameService_MoveTimeout(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := newTestGameService(storage)
+	clock := turn.NewFakeClock(time.Unix(0, 0))
+	service.SetClock(clock)
+
+	control := turn.Control{Move: 10 * time.Second, Default: "draw"}
+	created, err := service.CreateTimedGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}}, control)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if turn := created.Turn; len(turn.ToAct) != 1 || turn.ToAct[0] != "ann" || !turn.Deadlines["ann"].Equal(time.Unix(10, 0)) {
+		t.Errorf("Unexpected turn: %+v", turn)
+	}
+	if _, err := service.Apply("highcard", created.ID, "bob", game.Action{Type: "draw"}); err == nil {
+		t.Errorf("Bob moved out of turn")
+	}
+
+	clock.Advance(10 * time.Second)
+	state, _ := service.State("highcard", created.ID, "bob")
+	if state.Version != 1 || state.Turn.ToAct[0] != "bob" || state.Turn.Timeouts["ann"] != 1 {
+		t.Fatalf("Ann's move was not made for her: %+v", state)
+	}
+
+	clock.Advance(5 * time.Second)
+	state, err = service.Apply("highcard", created.ID, "bob", game.Action{Type: "draw"})
+	if err != nil || !state.Terminal || state.Turn.Timeouts["bob"] != 0 {
+		t.Errorf("Unexpected state: %v %+v", err, state)
+	}
+	clock.Advance(time.Minute)
+	if state, _ := service.State("highcard", created.ID, ""); state.Version != 2 {
+		t.Errorf("A timer fired after the game ended: %+v", state)
+	}
+}
+
+func TestGameService_TimeBankForfeit(t *testing.T) {
+	service := newTestGameService(dao.NewDeckStorage())
+	clock := turn.NewFakeClock(time.Unix(0, 0))
+	service.SetClock(clock)
+
+	control := turn.Control{Bank: 30 * time.Second, Increment: 5 * time.Second, Default: turn.DefaultForfeit}
+	created, err := service.CreateTimedGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}}, control)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(29 * time.Second)
+	if _, err := service.Apply("highcard", created.ID, "ann", game.Action{Type: "draw"}); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(30 * time.Second)
+	state, _ := service.State("highcard", created.ID, "")
+	if !state.Terminal || state.Forfeited != "bob" || state.Turn.Banks["ann"] != 6000 {
+		t.Fatalf("Bob did not forfeit: %+v", state)
+	}
+	if _, err := service.Apply("highcard", created.ID, "bob", game.Action{Type: "draw"}); err == nil {
+		t.Errorf("Move accepted after a forfeit")
+	}
+
+	if _, err := service.CreateTimedGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}}, turn.Control{Move: -time.Second}); err == nil {
+		t.Errorf("CreateTimedGame accepted a negative move time")
+	}
+}
+
+func TestGameService_Retention(t *testing.T) {
+	service := newTestGameService(dao.NewDeckStorage())
+	clock := turn.NewFakeClock(time.Unix(0, 0))
+	service.SetClock(clock)
+	service.SetRetention(time.Minute)
+
+	created, err := service.CreateGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.Apply("highcard", created.ID, "ann", game.Action{Type: "draw"})
+	service.Apply("highcard", created.ID, "bob", game.Action{Type: "draw"})
+
+	clock.Advance(59 * time.Second)
+	if state, err := service.State("highcard", created.ID, ""); err != nil || !state.Terminal {
+		t.Fatalf("Finished game gone before its retention: %v", err)
+	}
+	clock.Advance(time.Second)
+	if _, err := service.State("highcard", created.ID, ""); err != ErrGameNotFound {
+		t.Errorf("Finished game kept after its retention: %v", err)
+	}
+}
+
+// speedDeck stores a deck for a game of speed in which ann holds 8C and bob
+// 6C, and the left center pile starts on 7H.
+func speedDeck(storage *dao.DeckStorage) model.Deck {
+	codes := strings.Split("8C,2C,3C,4C,5C,7C,9C,1C,JC,QC,KC,AC,2D,3D,4D,5D,6D,7D,8D,9D,"+
+		"6C,2H,3H,4H,5H,6H,8H,9H,1H,JH,QH,KH,AH,2S,3S,4S,5S,6S,7S,8S,"+
+		"9S,1S,JS,QS,KS,7H,1D,JD,QD,KD,AD,AS", ",")
+	deck := model.NewDeck(false, strings.Join(codes, ","))
+	storage.SaveDeck(deck)
+	return deck
+}
+
+func TestGameService_Events(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := newTestGameService(storage)
+	clock := turn.NewFakeClock(time.Unix(0, 0))
+	service.SetClock(clock)
+
+	deck := speedDeck(storage)
+	created, _ := service.CreateGame("speed", &deck.ID, game.Config{Players: []string{"ann", "bob"}})
+	if _, err := service.Apply("speed", created.ID, "ann", game.Action{Type: "play", Cards: []string{"8C"}, Value: "left"}); err != nil {
+		t.Fatal(err)
+	}
+
+	own, err := service.Events("speed", created.ID, "ann", 0)
+	if err != nil || !own.Complete || len(own.Events) != 1 || own.State.Version != 1 {
+		t.Fatalf("Unexpected replay %+v: %v", own, err)
+	}
+	if e := own.Events[0]; e.Seq != 1 || e.Kind != replay.Action || e.Player != "ann" || e.Action.Cards[0] != "8C" {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	if other, _ := service.Events("speed", created.ID, "bob", 0); other.Events[0].Action.Cards != nil || other.Events[0].Action.Value != "left" {
+		t.Errorf("Another player's cards should be left out: %+v", other.Events[0].Action)
+	}
+	if own, _ := service.Events("speed", created.ID, "ann", 0); own.Events[0].Action.Cards == nil {
+		t.Errorf("Redacting for one player changed the stored event")
+	}
+	if caught, _ := service.Events("speed", created.ID, "ann", 1); len(caught.Events) != 0 || !caught.Complete {
+		t.Errorf("Nothing should be missed after the latest event: %+v", caught)
+	}
+
+	service.SetReplaySize(1)
+	control := turn.Control{Move: time.Second, Default: "draw"}
+	timed, _ := service.CreateTimedGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}}, control)
+	clock.Advance(time.Second)
+	service.Apply("highcard", timed.ID, "bob", game.Action{Type: "draw"})
+	replayed, _ := service.Events("highcard", timed.ID, "bob", 0)
+	if replayed.Complete || len(replayed.Events) != 1 || replayed.Events[0].Seq != 2 {
+		t.Errorf("Dropped events should be reported: %+v", replayed)
+	}
+	if all, _ := service.Events("highcard", timed.ID, "bob", 1); !all.Complete || all.Events[0].Player != "bob" {
+		t.Errorf("Unexpected replay %+v", all)
+	}
+}
+
+func TestGameService_Seat(t *testing.T) {
+	service := newTestGameService(dao.NewDeckStorage())
+	bots := NewBotService()
+	bots.RegisterPlayer("first", bot.First)
+	bots.RegisterPlayer("broken", bot.PlayerFunc(func(turn bot.Turn) (game.Action, error) {
+		return game.Action{Type: "resign"}, nil
+	}))
+	service.SetBots(bots)
+	finished := make(chan GameState, 2)
+	service.OnFinish(func(state GameState) { finished <- state })
+
+	created, _ := service.CreateGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}})
+	if err := service.Seat("highcard", created.ID, "cat", "first"); err == nil {
+		t.Errorf("Seated a bot for someone not playing")
+	}
+	if err := service.Seat("highcard", created.ID, "ann", "nobody"); err == nil {
+		t.Errorf("Seated an unknown bot")
+	}
+	if err := service.Seat("highcard", created.ID, "bob", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Apply("highcard", created.ID, "ann", game.Action{Type: "draw"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case state := <-finished:
+		if state.Version != 2 {
+			t.Errorf("Unexpected end %+v", state)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The bot never moved for bob")
+	}
+
+	// A bot that picks an illegal action has its first legal one played.
+	other, _ := service.CreateGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}})
+	service.Seat("highcard", other.ID, "ann", "broken")
+	service.Seat("highcard", other.ID, "bob", "broken")
+	select {
+	case state := <-finished:
+		if state.ID != other.ID || !state.Terminal {
+			t.Errorf("Unexpected end %+v", state)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The broken bots stalled the game")
+	}
+}
+
+// jammed offers an action it then refuses.
+type jammed struct {
+	players []string
+}
+
+func (g *jammed) Setup(deck model.Deck, config game.Config) error {
+	g.players = config.Players
+	return nil
+}
+
+func (g *jammed) Players() []string { return g.players }
+
+func (g *jammed) LegalActions(player string) []game.Action {
+	return []game.Action{{Type: "move"}}
+}
+
+func (g *jammed) Apply(player string, action game.Action) error {
+	return fmt.Errorf("Jammed")
+}
+
+func (g *jammed) Terminal() bool                 { return false }
+func (g *jammed) Scores() map[string]int         { return nil }
+func (g *jammed) View(player string) interface{} { return nil }
+
+func TestGameService_SeatForfeit(t *testing.T) {
+	registry := game.NewRegistry()
+	registry.Register("jammed", func() game.Game { return &jammed{} })
+	service := NewGameService(dao.NewDeckStorage(), registry)
+	bots := NewBotService()
+	bots.RegisterPlayer("first", bot.First)
+	service.SetBots(bots)
+	finished := make(chan GameState, 1)
+	service.OnFinish(func(state GameState) { finished <- state })
+
+	created, _ := service.CreateGame("jammed", nil, game.Config{Players: []string{"ann"}})
+	service.Seat("jammed", created.ID, "ann", "first")
+	select {
+	case state := <-finished:
+		if state.Forfeited != "ann" || !state.Terminal {
+			t.Errorf("The bot should have forfeited: %+v", state)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("A bot that cannot move stalled the game")
+	}
+}
+
+func TestGameService_Turn(t *testing.T) {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	registry.Register("crazyeights", shedding.NewGame)
+	service := NewGameService(dao.NewDeckStorage(), registry)
+
+	created, _ := service.CreateGame("crazyeights", nil, game.Config{Players: []string{"ann", "bob"}})
+	turn, err := service.Turn("crazyeights", created.ID, "ann")
+	if err != nil || turn.Player != "ann" || len(turn.Actions) == 0 || turn.Sample == nil {
+		t.Fatalf("Unexpected turn %+v: %v", turn, err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	sample := turn.Sample(rng)
+	if sample == nil || len(sample.LegalActions("ann")) != len(turn.Actions) {
+		t.Fatalf("Unexpected sample")
+	}
+	sample.Apply("ann", turn.Actions[0])
+	if state, _ := service.State("crazyeights", created.ID, ""); state.Version != 0 {
+		t.Errorf("Playing a sample changed the game")
+	}
+	service.Apply("crazyeights", created.ID, "ann", turn.Actions[0])
+	if turn.Sample(rng) != nil {
+		t.Errorf("Sampled a turn that has passed")
+	}
+
+	highcard, _ := service.CreateGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}})
+	if turn, _ := service.Turn("highcard", highcard.ID, "ann"); turn.Sample != nil {
+		t.Errorf("High card cannot be determinized")
+	}
+}
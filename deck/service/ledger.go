@@ -0,0 +1,208 @@
+package service
+
+import (
+	"cardGame/deck/game"
+	"cardGame/deck/ledger"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Statement is an account's balance and every entry that touched it.
+type Statement struct {
+	Account string        `json:"account"`
+	Balance int64         `json:"balance"`
+	History []ledger.Line `json:"history"`
+}
+
+// Audit summarises the ledger: the chips bought in and not cashed out, the
+// rake taken, what the house has paid out on balance in the games it banks,
+// the chips waiting in pots and whether the journal checks out.
+type Audit struct {
+	Entries  int              `json:"entries"`
+	InPlay   int64            `json:"in_play"`
+	Rake     int64            `json:"rake"`
+	House    int64            `json:"house"`
+	Pots     int64            `json:"pots"`
+	Balances map[string]int64 `json:"balances"`
+	Valid    bool             `json:"valid"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// LedgerService keeps player chips in a double-entry ledger. It is the
+// game.Bank that hosted games stake chips through.
+type LedgerService struct {
+	ledger *ledger.Ledger
+}
+
+var _ game.Bank = (*LedgerService)(nil)
+
+func NewLedgerService() *LedgerService {
+	return &LedgerService{ledger: ledger.New()}
+}
+
+func positive(amount int64) error {
+	if amount <= 0 {
+		return fmt.Errorf("Amount must be positive")
+	}
+	return nil
+}
+
+func named(names ...string) error {
+	for _, name := range names {
+		if name == "" {
+			return fmt.Errorf("Player and pot names are required")
+		}
+	}
+	return nil
+}
+
+// BuyIn gives player chips from the cashier.
+func (s *LedgerService) BuyIn(key, player string, amount int64) (ledger.Entry, error) {
+	if err := named(player); err != nil {
+		return ledger.Entry{}, err
+	}
+	if err := positive(amount); err != nil {
+		return ledger.Entry{}, err
+	}
+	return s.ledger.Post(key, ledger.BuyIn, "", []ledger.Posting{
+		{Account: ledger.Cashier, Amount: -amount},
+		{Account: ledger.Player(player), Amount: amount},
+	})
+}
+
+// CashOut returns player's chips to the cashier.
+func (s *LedgerService) CashOut(key, player string, amount int64) (ledger.Entry, error) {
+	if err := named(player); err != nil {
+		return ledger.Entry{}, err
+	}
+	if err := positive(amount); err != nil {
+		return ledger.Entry{}, err
+	}
+	return s.ledger.Post(key, ledger.CashOut, "", []ledger.Posting{
+		{Account: ledger.Player(player), Amount: -amount},
+		{Account: ledger.Cashier, Amount: amount},
+	})
+}
+
+// Transfer moves chips between two players.
+func (s *LedgerService) Transfer(key, from, to string, amount int64, memo string) (ledger.Entry, error) {
+	if err := named(from, to); err != nil {
+		return ledger.Entry{}, err
+	}
+	if err := positive(amount); err != nil {
+		return ledger.Entry{}, err
+	}
+	if from == to {
+		return ledger.Entry{}, fmt.Errorf("Cannot transfer to the same player")
+	}
+	return s.ledger.Post(key, ledger.Transfer, memo, []ledger.Posting{
+		{Account: ledger.Player(from), Amount: -amount},
+		{Account: ledger.Player(to), Amount: amount},
+	})
+}
+
+// Bet moves player's chips into a pot.
+func (s *LedgerService) Bet(key, pot, player string, amount int64) (ledger.Entry, error) {
+	if err := named(pot, player); err != nil {
+		return ledger.Entry{}, err
+	}
+	if err := positive(amount); err != nil {
+		return ledger.Entry{}, err
+	}
+	return s.ledger.Post(key, ledger.Bet, "", []ledger.Posting{
+		{Account: ledger.Player(player), Amount: -amount},
+		{Account: ledger.Pot(pot), Amount: amount},
+	})
+}
+
+// SettlePot pays out the whole pot, the rake to the house and the rest to
+// the winners, in one entry.
+func (s *LedgerService) SettlePot(key, pot string, payouts map[string]int64, rake int64) (ledger.Entry, error) {
+	if rake < 0 {
+		return ledger.Entry{}, fmt.Errorf("Rake cannot be negative")
+	}
+	return s.drain(key, pot, payouts, ledger.Posting{Account: ledger.Rake, Amount: rake})
+}
+
+// SettleHousePot pays out a pot the house banks in one entry. The house
+// takes what is left after the payouts, or pays in what the pot is short
+// when house is negative.
+func (s *LedgerService) SettleHousePot(key, pot string, payouts map[string]int64, house int64) (ledger.Entry, error) {
+	return s.drain(key, pot, payouts, ledger.Posting{Account: ledger.House, Amount: house})
+}
+
+// drain empties a pot into the payouts and the house's posting.
+func (s *LedgerService) drain(key, pot string, payouts map[string]int64, house ledger.Posting) (ledger.Entry, error) {
+	if err := named(pot); err != nil {
+		return ledger.Entry{}, err
+	}
+	players := make([]string, 0, len(payouts))
+	for player := range payouts {
+		players = append(players, player)
+	}
+	sort.Strings(players)
+
+	var postings []ledger.Posting
+	for _, player := range players {
+		if payouts[player] < 0 {
+			return ledger.Entry{}, fmt.Errorf("Payout to %v cannot be negative", player)
+		}
+		postings = append(postings, ledger.Posting{Account: ledger.Player(player), Amount: payouts[player]})
+	}
+	postings = append(postings, house)
+	return s.ledger.Drain(key, ledger.Settle, "", ledger.Pot(pot), postings)
+}
+
+func (s *LedgerService) Reserve(key, pot, player string, amount int64) error {
+	_, err := s.Bet(key, pot, player, amount)
+	return err
+}
+
+func (s *LedgerService) Settle(key, pot string, payouts map[string]int64, rake int64) error {
+	_, err := s.SettlePot(key, pot, payouts, rake)
+	return err
+}
+
+func (s *LedgerService) SettleHouse(key, pot string, payouts map[string]int64, house int64) error {
+	_, err := s.SettleHousePot(key, pot, payouts, house)
+	return err
+}
+
+func (s *LedgerService) statement(account string) Statement {
+	history := s.ledger.History(account)
+	statement := Statement{Account: account, History: history}
+	if len(history) > 0 {
+		statement.Balance = history[len(history)-1].Balance
+	}
+	return statement
+}
+
+func (s *LedgerService) Player(player string) Statement {
+	return s.statement(ledger.Player(player))
+}
+
+func (s *LedgerService) Pot(pot string) Statement {
+	return s.statement(ledger.Pot(pot))
+}
+
+func (s *LedgerService) Audit() Audit {
+	balances := s.ledger.Balances()
+	audit := Audit{
+		Entries:  len(s.ledger.Entries()),
+		InPlay:   -balances[ledger.Cashier],
+		Rake:     balances[ledger.Rake],
+		House:    -balances[ledger.House],
+		Balances: balances,
+		Valid:    true,
+	}
+	for account, balance := range balances {
+		if strings.HasPrefix(account, ledger.Pot("")) {
+			audit.Pots += balance
+		}
+	}
+	if err := s.ledger.Audit(); err != nil {
+		audit.Valid, audit.Error = false, err.Error()
+	}
+	return audit
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"cardGame/deck/baccarat"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// antes is a game played for chips: each player antes 10 and the first
+// player takes the pot, less 2 rake.
+type antes struct {
+	bank    game.Bank
+	pot     string
+	players []string
+	paid    map[string]bool
+	over    bool
+}
+
+func (g *antes) SetBank(bank game.Bank, pot string) {
+	g.bank, g.pot = bank, pot
+}
+
+func (g *antes) Setup(deck model.Deck, config game.Config) error {
+	g.players, g.paid = config.Players, make(map[string]bool)
+	return nil
+}
+
+func (g *antes) Players() []string { return g.players }
+
+func (g *antes) LegalActions(player string) []game.Action {
+	if g.over || g.paid[player] {
+		return nil
+	}
+	return []game.Action{{Type: "ante"}}
+}
+
+func (g *antes) Apply(player string, action game.Action) error {
+	if err := g.bank.Reserve(fmt.Sprintf("%v:ante:%v", g.pot, player), g.pot, player, 10); err != nil {
+		return err
+	}
+	g.paid[player] = true
+	if len(g.paid) == len(g.players) {
+		g.over = true
+		payout := map[string]int64{g.players[0]: int64(10*len(g.players) - 2)}
+		return g.bank.Settle(g.pot+":settle", g.pot, payout, 2)
+	}
+	return nil
+}
+
+func (g *antes) Terminal() bool                 { return g.over }
+func (g *antes) Scores() map[string]int         { return nil }
+func (g *antes) View(player string) interface{} { return nil }
+
+func TestLedgerService_BankedGame(t *testing.T) {
+	ledger := NewLedgerService()
+	registry := game.NewRegistry()
+	registry.Register("antes", func() game.Game { return &antes{} })
+	games := NewGameService(dao.NewDeckStorage(), registry)
+
+	config := game.Config{Players: []string{"ann", "bob"}}
+	if _, err := games.CreateGame("antes", nil, config); err == nil {
+		t.Errorf("Created a game played for chips without a bank")
+	}
+	games.SetBank(ledger)
+	state, err := games.CreateGame("antes", nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ledger.BuyIn("", "ann", 100)
+	if _, err := games.Apply("antes", state.ID, "bob", game.Action{Type: "ante"}); err == nil {
+		t.Errorf("Bob anted without chips")
+	}
+	ledger.BuyIn("", "bob", 100)
+	for _, player := range []string{"bob", "ann"} {
+		if _, err := games.Apply("antes", state.ID, player, game.Action{Type: "ante"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ann, bob := ledger.Player("ann"), ledger.Player("bob"); ann.Balance != 108 || bob.Balance != 90 || len(ann.History) != 3 {
+		t.Errorf("Unexpected balances: %+v %+v", ann, bob)
+	}
+	audit := ledger.Audit()
+	if !audit.Valid || audit.InPlay != 200 || audit.Rake != 2 || audit.Pots != 0 {
+		t.Errorf("Unexpected audit: %+v", audit)
+	}
+}
+
+func TestLedgerService_Baccarat(t *testing.T) {
+	ledger := NewLedgerService()
+	registry := game.NewRegistry()
+	registry.Register("baccarat", baccarat.NewGame)
+	games := NewGameService(dao.NewDeckStorage(), registry)
+	games.SetBank(ledger)
+	state, err := games.CreateGame("baccarat", nil, game.Config{Players: []string{"ann", "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ledger.BuyIn("", "ann", 1000)
+	ledger.BuyIn("", "bob", 1000)
+	if _, err := games.Apply("baccarat", state.ID, "bob", game.Action{Type: "bet", Value: "tie", Amount: 1001}); err == nil {
+		t.Errorf("Bet more chips than bob holds")
+	}
+	for round := 0; round < 5; round++ {
+		if _, err := games.Apply("baccarat", state.ID, "ann", game.Action{Type: "bet", Value: "banker", Amount: 100}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := games.Apply("baccarat", state.ID, "bob", game.Action{Type: "bet", Value: "tie", Amount: 10}); err != nil {
+			t.Fatal(err)
+		}
+		if pot := ledger.Pot(state.ID.String()); pot.Balance != 110 {
+			t.Fatalf("Bets were not staked in the pot: %+v", pot)
+		}
+		if _, err := games.Apply("baccarat", state.ID, "ann", game.Action{Type: "clear"}); err == nil {
+			t.Errorf("Cleared bets already staked")
+		}
+		if state, err = games.Apply("baccarat", state.ID, "ann", game.Action{Type: "deal"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var view baccarat.View
+	if err := json.Unmarshal(state.View, &view); err != nil {
+		t.Fatal(err)
+	}
+	net := view.Net
+	ann, bob := ledger.Player("ann"), ledger.Player("bob")
+	if ann.Balance != int64(1000+net["ann"]) || bob.Balance != int64(1000+net["bob"]) {
+		t.Errorf("Balances %v and %v don't match the table's results %v", ann.Balance, bob.Balance, net)
+	}
+	audit := ledger.Audit()
+	if !audit.Valid || audit.Pots != 0 || audit.Rake-audit.House != -int64(net["ann"]+net["bob"]) {
+		t.Errorf("Unexpected audit: %+v", audit)
+	}
+}
+
+func TestLedgerService_Settle(t *testing.T) {
+	ledger := NewLedgerService()
+	ledger.BuyIn("", "ann", 50)
+	ledger.BuyIn("", "bob", 50)
+	ledger.Bet("", "hand-1", "ann", 20)
+	ledger.Bet("", "hand-1", "bob", 20)
+
+	if _, err := ledger.SettlePot("", "hand-1", map[string]int64{"bob": 30}, 0); err == nil {
+		t.Errorf("Settle left chips in the pot")
+	}
+	if _, err := ledger.SettlePot("", "hand-1", map[string]int64{"bob": 42}, -2); err == nil {
+		t.Errorf("Settle accepted a negative rake")
+	}
+	if _, err := ledger.SettlePot("s1", "hand-1", map[string]int64{"bob": 38}, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ledger.CashOut("", "ann", 31); err == nil {
+		t.Errorf("CashOut overdrew ann")
+	}
+	if _, err := ledger.Transfer("", "bob", "bob", 5, ""); err == nil {
+		t.Errorf("Transfer to self accepted")
+	}
+	if pot := ledger.Pot("hand-1"); pot.Balance != 0 || len(pot.History) != 3 {
+		t.Errorf("Unexpected pot: %+v", pot)
+	}
+}
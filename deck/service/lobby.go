@@ -0,0 +1,315 @@
+package service
+
+import (
+	"cardGame/deck/game"
+	"cardGame/deck/lobby"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Match is a game the matchmaking queue started, or a ticket still waiting
+// for one when GameID is nil.
+type Match struct {
+	Ticket  lobby.Ticket `json:"ticket"`
+	Band    *int         `json:"band,omitempty"`
+	GameID  *uuid.UUID   `json:"game_id,omitempty"`
+	Players []string     `json:"players,omitempty"`
+}
+
+// LobbyService gathers players into rooms and the matchmaking queue, and
+// starts their games on the GameService with a freshly created deck.
+type LobbyService struct {
+	mu    sync.Mutex
+	games *GameService
+	rooms map[uuid.UUID]*lobby.Room
+	// seats maps each player to the room they are in.
+	seats   map[string]uuid.UUID
+	queue   *lobby.Queue
+	matches map[string]Match
+	skill   func(gameType, player string) (int, bool)
+	started []func(GameState)
+}
+
+func NewLobbyService(games *GameService) *LobbyService {
+	return &LobbyService{
+		games:   games,
+		rooms:   make(map[uuid.UUID]*lobby.Room),
+		seats:   make(map[string]uuid.UUID),
+		queue:   lobby.NewQueue(),
+		matches: make(map[string]Match),
+	}
+}
+
+// SetSkill gives the queue a skill signal, such as RatingService.Skill, for
+// players who queue without giving one.
+func (s *LobbyService) SetSkill(skill func(gameType, player string) (int, bool)) {
+	s.skill = skill
+}
+
+// OnStart registers fn to be called with each game the lobby starts, from a
+// room or the queue, such as RatingService.Rate.
+func (s *LobbyService) OnStart(fn func(GameState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = append(s.started, fn)
+}
+
+// begin tells the listeners a game started. Callers hold s.mu.
+func (s *LobbyService) begin(state GameState) {
+	for _, fn := range s.started {
+		fn(state)
+	}
+}
+
+func (s *LobbyService) CreateRoom(host, gameType string, capacity int, visibility lobby.Visibility, options map[string]string) (lobby.Room, error) {
+	if err := s.games.CheckType(gameType); err != nil {
+		return lobby.Room{}, err
+	}
+	room, err := lobby.NewRoom(host, gameType, capacity, visibility, options)
+	if err != nil {
+		return lobby.Room{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.free(host); err != nil {
+		return lobby.Room{}, err
+	}
+	s.rooms[room.ID] = room
+	s.seats[host] = room.ID
+	return room.ViewFor(host), nil
+}
+
+// free checks that player is not already waiting in another room.
+func (s *LobbyService) free(player string) error {
+	if roomID, ok := s.seats[player]; ok {
+		return fmt.Errorf("%v is already in room %v", player, roomID)
+	}
+	return nil
+}
+
+// ListRooms returns the public rooms still waiting for players, oldest
+// first.
+func (s *LobbyService) ListRooms(gameType string) []lobby.Room {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rooms := []lobby.Room{}
+	for _, room := range s.rooms {
+		if room.Listed() && (gameType == "" || room.GameType == gameType) {
+			rooms = append(rooms, room.ViewFor(""))
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].Created.Before(rooms[j].Created) })
+	return rooms
+}
+
+func (s *LobbyService) Room(roomID uuid.UUID, player string) (lobby.Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, found := s.rooms[roomID]
+	if !found {
+		return lobby.Room{}, lobby.ErrRoomNotFound
+	}
+	return room.ViewFor(player), nil
+}
+
+func (s *LobbyService) Join(roomID uuid.UUID, player, inviteCode string) (lobby.Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, found := s.rooms[roomID]
+	if !found {
+		return lobby.Room{}, lobby.ErrRoomNotFound
+	}
+	if !room.Has(player) {
+		if err := s.free(player); err != nil {
+			return lobby.Room{}, err
+		}
+	}
+	if err := room.Join(player, inviteCode); err != nil {
+		return lobby.Room{}, err
+	}
+	s.seats[player] = roomID
+	return room.ViewFor(player), nil
+}
+
+// Leave takes player out of a waiting room, closing it once it is empty.
+func (s *LobbyService) Leave(roomID uuid.UUID, player string) (lobby.Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, found := s.rooms[roomID]
+	if !found {
+		return lobby.Room{}, lobby.ErrRoomNotFound
+	}
+	if room.Status != lobby.Waiting {
+		return lobby.Room{}, lobby.ErrAlreadyStarted
+	}
+	empty, err := room.Leave(player)
+	if err != nil {
+		return lobby.Room{}, err
+	}
+	delete(s.seats, player)
+	if empty {
+		delete(s.rooms, roomID)
+	}
+	return room.ViewFor(player), nil
+}
+
+// SetReady marks player ready or not. The game starts as soon as a full
+// room is all ready.
+func (s *LobbyService) SetReady(roomID uuid.UUID, player string, ready bool) (lobby.Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, found := s.rooms[roomID]
+	if !found {
+		return lobby.Room{}, lobby.ErrRoomNotFound
+	}
+	if err := room.SetReady(player, ready); err != nil {
+		return lobby.Room{}, err
+	}
+	if room.Full() && room.AllReady() {
+		if err := s.start(room); err != nil {
+			room.SetReady(player, false)
+			return lobby.Room{}, err
+		}
+	}
+	return room.ViewFor(player), nil
+}
+
+// Start lets the host start the game before the room is full, once everyone
+// in it is ready.
+func (s *LobbyService) Start(roomID uuid.UUID, player string) (lobby.Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, found := s.rooms[roomID]
+	switch {
+	case !found:
+		return lobby.Room{}, lobby.ErrRoomNotFound
+	case room.Host() != player:
+		return lobby.Room{}, lobby.ErrNotHost
+	case room.Status != lobby.Waiting:
+		return lobby.Room{}, lobby.ErrAlreadyStarted
+	case !room.AllReady():
+		return lobby.Room{}, fmt.Errorf("Not everyone is ready")
+	}
+	if err := s.start(room); err != nil {
+		return lobby.Room{}, err
+	}
+	return room.ViewFor(player), nil
+}
+
+// start creates the room's game and frees its players to join other rooms.
+func (s *LobbyService) start(room *lobby.Room) error {
+	config := game.Config{Players: room.Players(), Options: room.Options}
+	state, err := s.games.CreateGame(room.GameType, nil, config)
+	if err != nil {
+		return err
+	}
+	s.begin(state)
+	room.Status, room.GameID = lobby.Playing, &state.ID
+	for _, player := range room.Players() {
+		delete(s.seats, player)
+	}
+	return nil
+}
+
+// Enqueue puts player in the matchmaking queue for a game of size players.
+// Whoever completes a group starts its game straight away; the others find
+// it with Match. A player's rating, when they have one, bands them in place
+// of the skill they give.
+func (s *LobbyService) Enqueue(player, gameType string, size int, skill *int) (Match, error) {
+	if player == "" {
+		return Match{}, fmt.Errorf("Player required")
+	}
+	if err := s.games.CheckType(gameType); err != nil {
+		return Match{}, err
+	}
+	if size > 0 {
+		if err := s.games.CheckPlayers(gameType, size, nil); err != nil {
+			return Match{}, err
+		}
+	}
+	if s.skill != nil {
+		if rated, ok := s.skill(gameType, player); ok {
+			skill = &rated
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ticket := lobby.Ticket{Player: player, GameType: gameType, Size: size, Skill: skill, Queued: time.Now()}
+	group, err := s.queue.Add(ticket)
+	if err != nil {
+		return Match{}, err
+	}
+	delete(s.matches, player)
+	if group == nil {
+		return s.ticket(ticket), nil
+	}
+
+	players := make([]string, len(group))
+	for i, t := range group {
+		players[i] = t.Player
+	}
+	state, err := s.games.CreateGame(gameType, nil, game.Config{Players: players})
+	if err != nil {
+		// The others keep their place in the queue; only the player whose
+		// ticket completed the group hears of the failure.
+		var others []lobby.Ticket
+		for _, t := range group {
+			if t.Player != player {
+				others = append(others, t)
+			}
+		}
+		s.queue.Return(others)
+		return Match{}, err
+	}
+	s.begin(state)
+	for _, t := range group {
+		match := s.ticket(t)
+		match.GameID, match.Players = &state.ID, players
+		s.matches[t.Player] = match
+	}
+	return s.matches[player], nil
+}
+
+func (s *LobbyService) ticket(t lobby.Ticket) Match {
+	match := Match{Ticket: t}
+	if band, ok := s.queue.Band(t); ok {
+		match.Band = &band
+	}
+	return match
+}
+
+// Match returns player's latest match, or their ticket while they wait.
+func (s *LobbyService) Match(player string) (Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if match, ok := s.matches[player]; ok {
+		return match, nil
+	}
+	if ticket, ok := s.queue.Ticket(player); ok {
+		return s.ticket(ticket), nil
+	}
+	return Match{}, fmt.Errorf("%v is not queued", player)
+}
+
+func (s *LobbyService) Dequeue(player string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.queue.Remove(player) {
+		return fmt.Errorf("%v is not queued", player)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"cardGame/deck/baccarat"
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/lobby"
+	"testing"
+)
+
+func TestLobbyService_Rooms(t *testing.T) {
+	games := newTestGameService(dao.NewDeckStorage())
+	service := NewLobbyService(games)
+
+	if _, err := service.CreateRoom("ann", "poker", 2, lobby.Public, nil); err == nil {
+		t.Errorf("CreateRoom accepted an unknown game type")
+	}
+	room, err := service.CreateRoom("ann", "highcard", 3, lobby.Public, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.CreateRoom("ann", "highcard", 2, lobby.Public, nil); err == nil {
+		t.Errorf("Ann was let into a second room")
+	}
+	hidden, _ := service.CreateRoom("cat", "highcard", 2, lobby.Private, nil)
+	if rooms := service.ListRooms(""); len(rooms) != 1 || rooms[0].ID != room.ID {
+		t.Errorf("ListRooms should only list the public room: %v", rooms)
+	}
+	if rooms := service.ListRooms("baccarat"); len(rooms) != 0 {
+		t.Errorf("ListRooms ignored the game type: %v", rooms)
+	}
+
+	if _, err := service.Join(room.ID, "bob", ""); err != nil {
+		t.Fatal(err)
+	}
+	service.SetReady(room.ID, "ann", true)
+	room, _ = service.SetReady(room.ID, "bob", true)
+	if room.Status != lobby.Waiting {
+		t.Fatalf("Room started before it was full")
+	}
+	if _, err := service.Start(room.ID, "bob"); err != lobby.ErrNotHost {
+		t.Errorf("Start by a guest: %v", err)
+	}
+	room, err = service.Start(room.ID, "ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if room.Status != lobby.Playing || room.GameID == nil {
+		t.Fatalf("Room did not start: %+v", room)
+	}
+	state, err := games.State("highcard", *room.GameID, "")
+	if err != nil || len(state.Players) != 2 || state.Players[0] != "ann" {
+		t.Errorf("Game not created for the room: %+v %v", state, err)
+	}
+	if _, err := service.Leave(room.ID, "ann"); err != lobby.ErrAlreadyStarted {
+		t.Errorf("Leave after the start: %v", err)
+	}
+
+	// Once playing, ann is free to wait in another room.
+	if _, err := service.Join(hidden.ID, "ann", ""); err != nil {
+		t.Errorf("Join after a game started: %v", err)
+	}
+	if _, err := service.Leave(hidden.ID, "cat"); err != nil {
+		t.Fatal(err)
+	}
+	if hidden, _ = service.Room(hidden.ID, ""); hidden.Host() != "ann" {
+		t.Errorf("Host should pass to ann: %+v", hidden)
+	}
+	service.Leave(hidden.ID, "ann")
+	if _, err := service.Room(hidden.ID, ""); err != lobby.ErrRoomNotFound {
+		t.Errorf("Empty room should close: %v", err)
+	}
+}
+
+func TestLobbyService_StartsWhenFullAndReady(t *testing.T) {
+	games := newTestGameService(dao.NewDeckStorage())
+	service := NewLobbyService(games)
+
+	room, _ := service.CreateRoom("ann", "highcard", 2, lobby.Invite, map[string]string{})
+	if _, err := service.Join(room.ID, "bob", "nope"); err != lobby.ErrBadInviteCode {
+		t.Errorf("Join with a wrong code: %v", err)
+	}
+	service.Join(room.ID, "bob", room.InviteCode)
+	service.SetReady(room.ID, "bob", true)
+	room, err := service.SetReady(room.ID, "ann", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if room.GameID == nil {
+		t.Errorf("Full, ready room did not start: %+v", room)
+	}
+}
+
+func TestLobbyService_MatchFails(t *testing.T) {
+	registry := game.NewRegistry()
+	registry.Register("baccarat", baccarat.NewGame)
+	// Without a bank, baccarat seats players but cannot be hosted.
+	service := NewLobbyService(NewGameService(dao.NewDeckStorage(), registry))
+
+	service.Enqueue("ann", "baccarat", 2, nil)
+	if _, err := service.Enqueue("bob", "baccarat", 2, nil); err == nil {
+		t.Fatalf("Enqueue started a game that could not be hosted")
+	}
+	if match, err := service.Match("ann"); err != nil || match.GameID != nil {
+		t.Errorf("Ann should still be queued: %+v %v", match, err)
+	}
+	if _, err := service.Match("bob"); err == nil {
+		t.Errorf("Bob's ticket should not be queued")
+	}
+}
+
+func TestLobbyService_RatingOverridesSkill(t *testing.T) {
+	service := NewLobbyService(newTestGameService(dao.NewDeckStorage()))
+	service.SetSkill(func(gameType, player string) (int, bool) { return 1500, player == "ann" })
+
+	claimed := 2400
+	if match, _ := service.Enqueue("ann", "highcard", 2, &claimed); match.Band == nil || *match.Band != 7 {
+		t.Errorf("Ann's rating should band them: %+v", match)
+	}
+	if match, _ := service.Enqueue("bob", "highcard", 2, &claimed); match.Band == nil || *match.Band != 12 {
+		t.Errorf("Bob has no rating, so their skill should band them: %+v", match)
+	}
+}
+
+func TestLobbyService_Matchmaking(t *testing.T) {
+	games := newTestGameService(dao.NewDeckStorage())
+	service := NewLobbyService(games)
+
+	if _, err := service.Enqueue("ann", "poker", 2, nil); err == nil {
+		t.Errorf("Enqueue accepted an unknown game type")
+	}
+	match, err := service.Enqueue("ann", "highcard", 2, nil)
+	if err != nil || match.GameID != nil {
+		t.Fatalf("Ann should be waiting: %+v %v", match, err)
+	}
+	if match, _ = service.Match("ann"); match.Ticket.Player != "ann" || match.GameID != nil {
+		t.Errorf("Match should return ann's ticket: %+v", match)
+	}
+
+	match, err = service.Enqueue("bob", "highcard", 2, nil)
+	if err != nil || match.GameID == nil {
+		t.Fatalf("Bob should complete the match: %+v %v", match, err)
+	}
+	other, _ := service.Match("ann")
+	if other.GameID == nil || *other.GameID != *match.GameID {
+		t.Errorf("Ann should see bob's game: %+v", other)
+	}
+	if _, err := games.State("highcard", *match.GameID, "ann"); err != nil {
+		t.Errorf("Matched game not hosted: %v", err)
+	}
+
+	if _, err := service.Enqueue("cat", "highcard", 60, nil); err == nil {
+		t.Errorf("Enqueue accepted more players than the game seats")
+	}
+
+	high := 2000
+	match, _ = service.Enqueue("cat", "highcard", 2, &high)
+	if match.Band == nil || *match.Band != 10 {
+		t.Errorf("Cat should be in band 10: %+v", match)
+	}
+	if err := service.Dequeue("cat"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Match("cat"); err == nil {
+		t.Errorf("Cat should no longer be queued")
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"cardGame/deck/rating"
+	"cardGame/deck/turn"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrNotRated = errors.New("Player has no rating for this game")
+
+// Windows are the leaderboard time windows.
+var Windows = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+	"year":  365 * 24 * time.Hour,
+}
+
+// PlayerRating is a player's rating in one game type and how it got there.
+type PlayerRating struct {
+	GameType string          `json:"game_type"`
+	Rating   rating.Rating   `json:"rating"`
+	History  []rating.Change `json:"history"`
+}
+
+// RatingService keeps a rating board per game type. A hosted game marked
+// with Rate, such as one the lobby started for players who joined it
+// themselves, is rated from its scores when it finishes with two or more
+// players, unless its type is excluded. Games created with a list of
+// players are not rated, since their creator names the players.
+type RatingService struct {
+	mu      sync.Mutex
+	games   *GameService
+	clock   turn.Clock
+	boards  map[string]*rating.Board
+	exclude map[string]bool
+	pending map[uuid.UUID]bool
+}
+
+func NewRatingService(games *GameService) *RatingService {
+	s := &RatingService{
+		games:   games,
+		clock:   turn.SystemClock,
+		boards:  make(map[string]*rating.Board),
+		exclude: make(map[string]bool),
+		pending: make(map[uuid.UUID]bool),
+	}
+	games.OnFinish(s.gameFinished)
+	return s
+}
+
+// SetClock replaces the clock that dates games and windows, for tests.
+func (s *RatingService) SetClock(clock turn.Clock) {
+	s.clock = clock
+}
+
+// Exclude stops games of these types being rated, for games that are not
+// played against each other.
+func (s *RatingService) Exclude(gameTypes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range gameTypes {
+		s.exclude[t] = true
+	}
+}
+
+func (s *RatingService) board(gameType string) *rating.Board {
+	b, ok := s.boards[gameType]
+	if !ok {
+		b = rating.NewBoard()
+		s.boards[gameType] = b
+	}
+	return b
+}
+
+// rated checks that gameType is a registered game type that is rated, so
+// boards are only ever made for those. Callers hold s.mu.
+func (s *RatingService) rated(gameType string) error {
+	if err := s.games.CheckType(gameType); err != nil {
+		return err
+	}
+	if s.exclude[gameType] {
+		return fmt.Errorf("%v games are not rated", gameType)
+	}
+	return nil
+}
+
+// Record rates a game from each player's place, 1 being best.
+func (s *RatingService) Record(gameType string, places map[string]int, gameID string) ([]rating.Rating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rated(gameType); err != nil {
+		return nil, err
+	}
+	return s.board(gameType).Record(places, s.clock.Now(), gameID)
+}
+
+// Places ranks the players of a finished game by score, equal scores
+// sharing a place. A player who forfeited comes last.
+func Places(state GameState) map[string]int {
+	players := append([]string{}, state.Players...)
+	score := func(p string) int {
+		if p == state.Forfeited {
+			return math.MinInt
+		}
+		return state.Scores[p]
+	}
+	sort.SliceStable(players, func(i, j int) bool { return score(players[i]) > score(players[j]) })
+
+	places := make(map[string]int)
+	for i, p := range players {
+		places[p] = i + 1
+		if i > 0 && score(p) == score(players[i-1]) {
+			places[p] = places[players[i-1]]
+		}
+	}
+	return places
+}
+
+// Rate marks a game to be rated when it finishes.
+func (s *RatingService) Rate(state GameState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[state.ID] = true
+}
+
+func (s *RatingService) gameFinished(state GameState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.pending[state.ID] {
+		return
+	}
+	delete(s.pending, state.ID)
+	if len(state.Players) >= 2 && !s.exclude[state.Type] {
+		s.board(state.Type).Record(Places(state), s.clock.Now(), state.ID.String())
+	}
+}
+
+func (s *RatingService) Player(gameType, player string) (PlayerRating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rated(gameType); err != nil {
+		return PlayerRating{}, err
+	}
+
+	b := s.board(gameType)
+	r, ok := b.Get(player)
+	if !ok {
+		return PlayerRating{}, ErrNotRated
+	}
+	return PlayerRating{GameType: gameType, Rating: r, History: b.History(player)}, nil
+}
+
+// Leaderboard returns a page of the game type's leaderboard, ranked by
+// system, counting only the games in window: day, week, month, year, or ""
+// or "all" for every game.
+func (s *RatingService) Leaderboard(gameType, system, window string, offset, limit int) (rating.Page, error) {
+	query := rating.Query{System: system, Offset: offset, Limit: limit}
+	if window != "" && window != "all" {
+		length, ok := Windows[window]
+		if !ok {
+			return rating.Page{}, fmt.Errorf("Unknown window %q", window)
+		}
+		query.Since = s.clock.Now().Add(-length)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rated(gameType); err != nil {
+		return rating.Page{}, err
+	}
+	return s.board(gameType).Leaderboard(query)
+}
+
+// Skill is a player's Glicko-2 rating in a game type, for matchmaking.
+func (s *RatingService) Skill(gameType, player string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.boards[gameType]
+	if !ok {
+		return 0, false
+	}
+	r, ok := b.Get(player)
+	if !ok {
+		return 0, false
+	}
+	return int(math.Round(r.Glicko.Rating)), true
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/lobby"
+	"cardGame/deck/turn"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func TestPlaces(t *testing.T) {
+	state := GameState{Players: []string{"ann", "bob", "cat", "dan"}, Scores: map[string]int{"ann": 3, "bob": 7, "cat": 3, "dan": 9}}
+	places := Places(state)
+	if places["dan"] != 1 || places["bob"] != 2 || places["ann"] != 3 || places["cat"] != 3 {
+		t.Errorf("Unexpected places: %v", places)
+	}
+	state.Forfeited = "dan"
+	if places := Places(state); places["dan"] != 4 || places["bob"] != 1 {
+		t.Errorf("A forfeit should place last: %v", places)
+	}
+}
+
+func TestRatingService_RatesHostedGames(t *testing.T) {
+	games := newTestGameService(dao.NewDeckStorage())
+	ratings := NewRatingService(games)
+	ratings.Exclude("baccarat")
+	clock := turn.NewFakeClock(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	ratings.SetClock(clock)
+	rooms := NewLobbyService(games)
+	rooms.OnStart(ratings.Rate)
+
+	for i := 0; i < 3; i++ {
+		room, _ := rooms.CreateRoom("ann", "highcard", 2, lobby.Public, nil)
+		rooms.Join(room.ID, "bob", "")
+		rooms.SetReady(room.ID, "ann", true)
+		room, _ = rooms.SetReady(room.ID, "bob", true)
+		games.Apply("highcard", *room.GameID, "ann", game.Action{Type: "draw"})
+		games.Apply("highcard", *room.GameID, "bob", game.Action{Type: "draw"})
+		clock.Advance(48 * time.Hour)
+	}
+	// A game whose creator named the players is not rated.
+	state, _ := games.CreateGame("highcard", nil, game.Config{Players: []string{"ann", "cat"}})
+	games.Apply("highcard", state.ID, "ann", game.Action{Type: "draw"})
+	games.Apply("highcard", state.ID, "cat", game.Action{Type: "draw"})
+
+	ann, err := ratings.Player("highcard", "ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ann.Rating.Games != 3 || len(ann.History) != 3 || ann.History[0].GameID == "" {
+		t.Errorf("Every game should be rated: %+v", ann)
+	}
+	if _, err := ratings.Player("highcard", "cat"); err != ErrNotRated {
+		t.Errorf("Cat has not played: %v", err)
+	}
+
+	page, err := ratings.Leaderboard("highcard", "elo", "week", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || page.Standings[0].Games != 3 {
+		t.Errorf("Unexpected leaderboard: %+v", page)
+	}
+	if page, _ := ratings.Leaderboard("highcard", "elo", "day", 0, 10); page.Total != 0 {
+		t.Errorf("Nobody played in the last day: %+v", page)
+	}
+	if _, err := ratings.Leaderboard("highcard", "elo", "decade", 0, 10); err == nil {
+		t.Errorf("Leaderboard accepted an unknown window")
+	}
+	if _, err := ratings.Record("baccarat", map[string]int{"ann": 1, "bob": 2}, ""); err == nil {
+		t.Errorf("Record rated an excluded game")
+	}
+	if _, err := ratings.Leaderboard("poker", "", "", 0, 10); err == nil {
+		t.Errorf("Leaderboard accepted an unknown game type")
+	}
+	if _, err := ratings.Player("poker", "ann"); err == nil {
+		t.Errorf("Player accepted an unknown game type")
+	}
+}
+
+func TestRatingService_SkillForMatchmaking(t *testing.T) {
+	games := newTestGameService(dao.NewDeckStorage())
+	ratings := NewRatingService(games)
+	lobby := NewLobbyService(games)
+	lobby.SetSkill(ratings.Skill)
+
+	for i := 0; i < 5; i++ {
+		ratings.Record("highcard", map[string]int{"ann": 1, "bob": 2}, uuid.NewString())
+	}
+	skill, _ := ratings.Skill("highcard", "ann")
+	if skill <= 1700 {
+		t.Fatalf("Ann should be rated well above 1500: %v", skill)
+	}
+
+	// Ann's rating is in a higher band than an unrated player's, so they
+	// are not matched.
+	lobby.Enqueue("ann", "highcard", 2, nil)
+	match, _ := lobby.Enqueue("cat", "highcard", 2, nil)
+	if match.GameID != nil {
+		t.Errorf("Ann was matched with an unrated player")
+	}
+	if match, _ := lobby.Match("ann"); match.Band == nil || *match.Band != skill/200 {
+		t.Errorf("Ann should be banded by rating: %+v", match)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"cardGame/deck/turn"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// DefaultResumeTTL is how long a resume token lasts after it was last used.
+const DefaultResumeTTL = 24 * time.Hour
+
+var ErrInvalidResume = errors.New("Invalid or expired resume token")
+
+// Attachment holds a player's seat in a game across dropped connections.
+// Its token is only shown when the player attaches.
+type Attachment struct {
+	Token    string    `json:"token,omitempty"`
+	GameType string    `json:"game_type"`
+	GameID   uuid.UUID `json:"game_id"`
+	Player   string    `json:"player"`
+	Expires  time.Time `json:"expires"`
+	// Ack is the last event the player confirmed seeing.
+	Ack int `json:"ack"`
+}
+
+type seat struct {
+	gameID uuid.UUID
+	player string
+}
+
+// ResumeService lets players re-attach to their seats and catch up on the
+// events they missed. Each seat has one live token; attaching again, say
+// from another device, replaces it.
+type ResumeService struct {
+	mu    sync.Mutex
+	games *GameService
+	// attachments are keyed by token hash, and seats map to their token hash.
+	attachments map[string]*Attachment
+	seats       map[seat]string
+	clock       turn.Clock
+	TTL         time.Duration
+}
+
+func NewResumeService(games *GameService) *ResumeService {
+	return &ResumeService{
+		games:       games,
+		attachments: make(map[string]*Attachment),
+		seats:       make(map[seat]string),
+		clock:       turn.SystemClock,
+		TTL:         DefaultResumeTTL,
+	}
+}
+
+// SetClock replaces the clock that expires tokens, for tests.
+func (s *ResumeService) SetClock(clock turn.Clock) {
+	s.clock = clock
+}
+
+func resumeHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Attach gives player a resume token for their seat, acknowledging every
+// event so far, and returns the game as they see it.
+func (s *ResumeService) Attach(gameType string, gameID uuid.UUID, player string) (Attachment, GameState, error) {
+	state, err := s.games.State(gameType, gameID, player)
+	if err != nil {
+		return Attachment{}, GameState{}, err
+	}
+	seated := false
+	for _, p := range state.Players {
+		seated = seated || p == player
+	}
+	if !seated {
+		return Attachment{}, GameState{}, fmt.Errorf("%v has no seat in this game", player)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return Attachment{}, GameState{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+	key := seat{gameID, player}
+	delete(s.attachments, s.seats[key])
+	attachment := &Attachment{GameType: gameType, GameID: gameID, Player: player, Expires: s.clock.Now().Add(s.TTL), Ack: state.Version}
+	s.attachments[resumeHash(token)] = attachment
+	s.seats[key] = resumeHash(token)
+
+	result := *attachment
+	result.Token = token
+	return result, state, nil
+}
+
+// Resume re-attaches the token's player to their seat and returns the
+// events after ack, or after the last acknowledged event if ack is nil.
+// The token stays valid, so a resume whose answer is lost can be retried.
+func (s *ResumeService) Resume(gameType string, gameID uuid.UUID, token string, ack *int) (Attachment, Replay, error) {
+	s.mu.Lock()
+	s.prune()
+	attachment, ok := s.attachments[resumeHash(token)]
+	if !ok || attachment.GameID != gameID || attachment.GameType != gameType {
+		s.mu.Unlock()
+		return Attachment{}, Replay{}, ErrInvalidResume
+	}
+	since := attachment.Ack
+	if ack != nil {
+		since = *ack
+	}
+	player := attachment.Player
+	s.mu.Unlock()
+
+	replayed, err := s.games.Events(gameType, gameID, player, since)
+	if err != nil {
+		return Attachment{}, Replay{}, err
+	}
+	if since < 0 || since > replayed.State.Version {
+		return Attachment{}, Replay{}, fmt.Errorf("Acknowledged event %v is not in this game", since)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attachment.Ack = since
+	attachment.Expires = s.clock.Now().Add(s.TTL)
+	return *attachment, replayed, nil
+}
+
+// prune drops expired tokens.
+func (s *ResumeService) prune() {
+	now := s.clock.Now()
+	for hash, attachment := range s.attachments {
+		if !now.Before(attachment.Expires) {
+			delete(s.attachments, hash)
+			delete(s.seats, seat{attachment.GameID, attachment.Player})
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/turn"
+	"testing"
+	"time"
+)
+
+func TestResumeService(t *testing.T) {
+	games := newTestGameService(dao.NewDeckStorage())
+	service := NewResumeService(games)
+	clock := turn.NewFakeClock(time.Unix(0, 0))
+	service.SetClock(clock)
+	created, _ := games.CreateGame("highcard", nil, game.Config{Players: []string{"ann", "bob"}})
+
+	if _, _, err := service.Attach("highcard", created.ID, "cat"); err == nil {
+		t.Errorf("Attached a player without a seat")
+	}
+	bob, state, err := service.Attach("highcard", created.ID, "bob")
+	if err != nil || bob.Token == "" || bob.Ack != 0 || len(state.Players) != 2 {
+		t.Fatalf("Attach failed: %+v %v", bob, err)
+	}
+
+	games.Apply("highcard", created.ID, "ann", game.Action{Type: "draw"})
+	games.Apply("highcard", created.ID, "bob", game.Action{Type: "draw"})
+	attachment, replayed, err := service.Resume("highcard", created.ID, bob.Token, nil)
+	if err != nil || len(replayed.Events) != 2 || !replayed.State.Terminal || attachment.Token != "" {
+		t.Fatalf("Resume failed: %+v %+v %v", attachment, replayed, err)
+	}
+	ack := 1
+	if _, replayed, _ := service.Resume("highcard", created.ID, bob.Token, &ack); len(replayed.Events) != 1 || replayed.Events[0].Player != "bob" {
+		t.Errorf("Resume from ack 1: %+v", replayed.Events)
+	}
+	if _, replayed, _ := service.Resume("highcard", created.ID, bob.Token, nil); len(replayed.Events) != 1 {
+		t.Errorf("Resume should start from the last acknowledged event: %+v", replayed.Events)
+	}
+	ack = 3
+	if _, _, err := service.Resume("highcard", created.ID, bob.Token, &ack); err == nil {
+		t.Errorf("Resumed from an event that has not happened")
+	}
+
+	again, _, _ := service.Attach("highcard", created.ID, "bob")
+	if _, _, err := service.Resume("highcard", created.ID, bob.Token, nil); err != ErrInvalidResume {
+		t.Errorf("Attaching again should replace the old token: %v", err)
+	}
+	clock.Advance(DefaultResumeTTL)
+	if _, _, err := service.Resume("highcard", created.ID, again.Token, nil); err != ErrInvalidResume {
+		t.Errorf("Expired token resumed: %v", err)
+	}
+	if len(service.attachments) != 0 || len(service.seats) != 0 {
+		t.Errorf("Expired tokens should be dropped")
+	}
+}
@@ -1,30 +1,112 @@
 package service
 
 import (
+	"cardGame/deck/access"
 	"cardGame/deck/dao"
 	"cardGame/deck/model"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"sync"
 )
 
+// ErrNotDeckOwner is returned for a deck that belongs to another player.
+var ErrNotDeckOwner = errors.New("Deck belongs to another player")
+
+// ErrDeckInGame is returned for a deck that was dealt to a hosted game.
+var ErrDeckInGame = errors.New("Deck was dealt to a hosted game")
+
 type DeckService struct {
 	mu      sync.Mutex
 	storage *dao.DeckStorage
+	// watchers are sent each deck after its cards move.
+	watchers map[uuid.UUID][]func(model.Deck)
 }
 
 func NewDeckService(storage *dao.DeckStorage) *DeckService {
 	return &DeckService{
-		storage: storage,
+		storage:  storage,
+		watchers: make(map[uuid.UUID][]func(model.Deck)),
+	}
+}
+
+// Watch sends fn the deck as it stands, then again after every draw and
+// deal. fn is called with the service locked, so it must not use it.
+func (s *DeckService) Watch(deckID uuid.UUID, fn func(model.Deck)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deck, found := s.storage.GetDeck(deckID)
+	if !found {
+		return fmt.Errorf("Invalid Deck ID")
+	}
+	fn(deck)
+	s.watchers[deckID] = append(s.watchers[deckID], fn)
+	return nil
+}
+
+// notify tells a deck's watchers its cards moved.
+func (s *DeckService) notify(deck model.Deck) {
+	for _, fn := range s.watchers[deck.ID] {
+		fn(deck)
 	}
 }
 
 func (s *DeckService) CreateDeck(shuffled bool, cards string) model.Deck {
+	return s.CreateDeckFor("", "", shuffled, cards)
+}
+
+// CreateDeckFor creates a deck owned by the player owner and created with
+// the API key keyID. Either may be empty.
+func (s *DeckService) CreateDeckFor(owner, keyID string, shuffled bool, cards string) model.Deck {
 	newDeck := model.NewDeck(shuffled, cards)
+	newDeck.Owner = owner
+	newDeck.Key = keyID
 	s.storage.SaveDeck(newDeck)
 	return newDeck
 }
 
+// Delete removes a deck.
+func (s *DeckService) Delete(deckID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.storage.DeleteDeck(deckID) {
+		return fmt.Errorf("Invalid Deck ID")
+	}
+	delete(s.watchers, deckID)
+	return nil
+}
+
+// Grant shares a deck with the API key keyID, or with revoke stops sharing
+// it.
+func (s *DeckService) Grant(deckID uuid.UUID, keyID string, revoke bool) (model.Deck, error) {
+	return s.storage.UpdateDeck(deckID, func(deck *model.Deck) error {
+		grants := []string{}
+		for _, grant := range deck.Grants {
+			if grant != keyID {
+				grants = append(grants, grant)
+			}
+		}
+		if !revoke && keyID != deck.Key {
+			grants = append(grants, keyID)
+		}
+		deck.Grants = grants
+		return nil
+	})
+}
+
+// Claim makes player the owner of a deck nobody owns yet. Claiming a deck
+// player already owns does nothing.
+func (s *DeckService) Claim(deckID uuid.UUID, player string) (model.Deck, error) {
+	return s.storage.UpdateDeck(deckID, func(deck *model.Deck) error {
+		if !deck.UsableBy(player) {
+			return ErrNotDeckOwner
+		}
+		deck.Owner = player
+		return nil
+	})
+}
+
 func (s *DeckService) GetDeck(deckID uuid.UUID) (model.Deck, bool) {
 	deck, err := s.storage.GetDeck(deckID)
 	if err != true {
@@ -34,16 +116,70 @@ func (s *DeckService) GetDeck(deckID uuid.UUID) (model.Deck, bool) {
 }
 
 func (s *DeckService) DrawCards(deck model.Deck, count int) ([]model.Card, error) {
-	deck, err := s.storage.GetDeck(deck.ID)
-	if err != true {
-		return nil, fmt.Errorf("Invalid Deck ID")
-	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	drawnCards, err1 := deck.DrawCards(count)
-	if err1 != true {
-		return nil, fmt.Errorf("Not enough cards remaining in the deck")
+	var drawnCards []model.Card
+	deck, err := s.storage.UpdateDeck(deck.ID, func(deck *model.Deck) error {
+		if deck.Game != nil {
+			return ErrDeckInGame
+		}
+		var ok bool
+		if drawnCards, ok = deck.DrawCards(count); !ok {
+			return fmt.Errorf("Not enough cards remaining in the deck")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	s.storage.SaveDeck(deck)
+	s.notify(deck)
 	return drawnCards, nil
 }
+
+// View returns the deck as viewer may see it under the default access
+// policy: the stock hidden, hands to their owners, other piles to all.
+func (s *DeckService) View(deckID uuid.UUID, viewer string) (access.DeckView, bool) {
+	deck, found := s.storage.GetDeck(deckID)
+	if !found {
+		return access.DeckView{}, false
+	}
+	return access.DefaultPolicy.Project(deck, viewer), true
+}
+
+// Deal moves count cards to each player's hand pile, one at a time round
+// the players.
+func (s *DeckService) Deal(deckID uuid.UUID, players []string, count int) (model.Deck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(players) == 0 || count < 1 {
+		return model.Deck{}, fmt.Errorf("Deal needs players and a positive count")
+	}
+	deck, err := s.storage.UpdateDeck(deckID, func(deck *model.Deck) error {
+		if deck.Game != nil {
+			return ErrDeckInGame
+		}
+		cards, ok := deck.DrawCards(len(players) * count)
+		if !ok {
+			return fmt.Errorf("Not enough cards remaining in the deck")
+		}
+
+		// Copy the piles so the stored deck only changes when it is saved.
+		piles := make(map[string][]model.Card)
+		for name, pile := range deck.Piles {
+			piles[name] = pile
+		}
+		deck.Piles = piles
+		for i, c := range cards {
+			deck.AddToPile(access.HandPile(players[i%len(players)]), c)
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Deck{}, err
+	}
+	s.notify(deck)
+	return deck, nil
+}
@@ -58,6 +58,90 @@ func TestDeckService_DrawCards(t *testing.T) {
 	}
 }
 
+func TestDeckService_Deal(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := NewDeckService(storage)
+	deck := service.CreateDeck(false, "")
+
+	dealt, err := service.Deal(deck.ID, []string{"ann", "bob"}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dealt.Remaining != 46 || len(dealt.Piles["hand:ann"]) != 3 || dealt.Piles["hand:bob"][0].Code != "3S" {
+		t.Errorf("Deal failed: %+v", dealt.Piles)
+	}
+	if _, err := service.Deal(deck.ID, []string{"ann"}, 47); err == nil {
+		t.Errorf("Deal dealt more cards than remain")
+	}
+	if _, err := service.Deal(uuid.New(), []string{"ann"}, 1); err == nil {
+		t.Errorf("Deal accepted an unknown deck")
+	}
+
+	view, found := service.View(deck.ID, "bob")
+	if !found || view.Stock.Cards != nil || len(view.Piles["hand:bob"].Cards) != 3 || view.Piles["hand:ann"].Cards != nil {
+		t.Errorf("View failed: %+v", view)
+	}
+}
+
+func TestDeckService_Claim(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := NewDeckService(storage)
+	owned := service.CreateDeckFor("ann", "", false, "")
+	if stored, _ := service.GetDeck(owned.ID); stored.Owner != "ann" {
+		t.Errorf("CreateDeckFor did not store the owner: %q", stored.Owner)
+	}
+	if _, err := service.Claim(owned.ID, "bob"); err != ErrNotDeckOwner {
+		t.Errorf("Claimed another player's deck: %v", err)
+	}
+	if _, err := service.Claim(owned.ID, "ann"); err != nil {
+		t.Errorf("Owner could not claim their own deck: %v", err)
+	}
+
+	deck := service.CreateDeck(false, "")
+	claimed, err := service.Claim(deck.ID, "bob")
+	if err != nil || claimed.Owner != "bob" {
+		t.Errorf("Claim failed: %+v %v", claimed, err)
+	}
+	if view, _ := service.View(deck.ID, ""); view.Owner != "bob" {
+		t.Errorf("View should show the owner: %+v", view)
+	}
+	if _, err := service.Claim(uuid.New(), "bob"); err == nil {
+		t.Errorf("Claimed an unknown deck")
+	}
+}
+
+func TestDeckService_Grant(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := NewDeckService(storage)
+	deck := service.CreateDeckFor("", "k1", false, "")
+
+	service.Grant(deck.ID, "k2", false)
+	granted, err := service.Grant(deck.ID, "k2", false)
+	if err != nil || len(granted.Grants) != 1 || !granted.KeyAllows("k2") {
+		t.Fatalf("Grant failed: %+v %v", granted.Grants, err)
+	}
+	if granted, _ := service.Grant(deck.ID, "k1", false); len(granted.Grants) != 1 {
+		t.Errorf("The creating key should not be granted its own deck: %v", granted.Grants)
+	}
+	revoked, _ := service.Grant(deck.ID, "k2", true)
+	if revoked.KeyAllows("k2") {
+		t.Errorf("Grant not revoked: %v", revoked.Grants)
+	}
+	if _, err := service.Grant(uuid.New(), "k2", false); err == nil {
+		t.Errorf("Granted an unknown deck")
+	}
+
+	if err := service.Delete(deck.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := service.GetDeck(deck.ID); found {
+		t.Errorf("Deleted deck still found")
+	}
+	if err := service.Delete(deck.ID); err == nil {
+		t.Errorf("Deleted a deck twice")
+	}
+}
+
 func assertDeckProperties(t *testing.T, deck model.Deck, remaining int, shuffled bool) {
 	t.Helper()
 
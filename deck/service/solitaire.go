@@ -0,0 +1,119 @@
+package service
+
+import (
+	"cardGame/deck/dao"
+	"cardGame/deck/model"
+	"cardGame/deck/solitaire"
+	"fmt"
+	"github.com/google/uuid"
+	"math/rand"
+	"time"
+)
+
+type SolitaireDeal struct {
+	DeckID   uuid.UUID         `json:"deck_id"`
+	Seed     int64             `json:"seed"`
+	Layout   solitaire.Layout  `json:"layout"`
+	Solution *solitaire.Result `json:"solution,omitempty"`
+}
+
+type SolitaireService struct {
+	storage *dao.DeckStorage
+}
+
+func NewSolitaireService(storage *dao.DeckStorage) *SolitaireService {
+	return &SolitaireService{
+		storage: storage,
+	}
+}
+
+// Deal creates a seeded deck, stores it like any other deck and lays it out.
+// The deck belongs to the player owner and the API key keyID, as
+// CreateDeckFor would make it.
+func (s *SolitaireService) Deal(owner, keyID string, variant solitaire.Variant, seed int64) (SolitaireDeal, error) {
+	deck := model.NewSeededDeck(seed, "")
+	deck.Owner, deck.Key = owner, keyID
+	layout, err := solitaire.Deal(variant, deck)
+	if err != nil {
+		return SolitaireDeal{}, err
+	}
+
+	s.storage.SaveDeck(deck)
+	return SolitaireDeal{DeckID: deck.ID, Seed: seed, Layout: layout}, nil
+}
+
+// WinnableDeal tries up to attempts random seeds and returns the first deal the
+// solver proves winnable, together with its solution. The deck is stored as
+// Deal stores it. The budget's timeout covers all the attempts together,
+// while its node limit applies to each.
+func (s *SolitaireService) WinnableDeal(owner, keyID string, variant solitaire.Variant, budget solitaire.Budget, attempts int) (SolitaireDeal, error) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var deadline time.Time
+	if budget.Timeout > 0 {
+		deadline = time.Now().Add(budget.Timeout)
+	}
+
+	for i := 0; i < attempts; i++ {
+		if !deadline.IsZero() {
+			if budget.Timeout = time.Until(deadline); budget.Timeout <= 0 {
+				return SolitaireDeal{}, fmt.Errorf("No winnable deal found in %d attempts before the timeout", i)
+			}
+		}
+		seed := rng.Int63()
+		layout, err := solitaire.Deal(variant, model.NewSeededDeck(seed, ""))
+		if err != nil {
+			return SolitaireDeal{}, err
+		}
+
+		result, err := solitaire.Solve(layout, budget)
+		if err != nil {
+			return SolitaireDeal{}, err
+		}
+		if result.Status == solitaire.StatusSolved {
+			deal, err := s.Deal(owner, keyID, variant, seed)
+			if err != nil {
+				return SolitaireDeal{}, err
+			}
+			deal.Solution = &result
+			return deal, nil
+		}
+	}
+
+	return SolitaireDeal{}, fmt.Errorf("No winnable deal found in %d attempts", attempts)
+}
+
+func (s *SolitaireService) Solve(layout solitaire.Layout, budget solitaire.Budget) (solitaire.Result, error) {
+	return solitaire.Solve(layout, budget)
+}
+
+// Deck returns a stored deck.
+func (s *SolitaireService) Deck(deckID uuid.UUID) (model.Deck, bool) {
+	return s.storage.GetDeck(deckID)
+}
+
+// SolveSeed lays out the seeded deck in memory and solves it, without storing
+// a deck.
+func (s *SolitaireService) SolveSeed(variant solitaire.Variant, seed int64, budget solitaire.Budget) (solitaire.Result, error) {
+	layout, err := solitaire.Deal(variant, model.NewSeededDeck(seed, ""))
+	if err != nil {
+		return solitaire.Result{}, err
+	}
+	return solitaire.Solve(layout, budget)
+}
+
+// SolveDeck lays out a stored full deck in its current order and solves it.
+func (s *SolitaireService) SolveDeck(deckID uuid.UUID, variant solitaire.Variant, budget solitaire.Budget) (solitaire.Result, error) {
+	deck, found := s.storage.GetDeck(deckID)
+	if !found {
+		return solitaire.Result{}, fmt.Errorf("Invalid Deck ID")
+	}
+	if deck.Game != nil {
+		return solitaire.Result{}, ErrDeckInGame
+	}
+
+	layout, err := solitaire.Deal(variant, deck)
+	if err != nil {
+		return solitaire.Result{}, err
+	}
+	return solitaire.Solve(layout, budget)
+}
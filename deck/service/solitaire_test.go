@@ -0,0 +1,67 @@
+package service
+
+import (
+	"cardGame/deck/dao"
+	"cardGame/deck/solitaire"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func TestSolitaireService_Deal(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	service := NewSolitaireService(storage)
+
+	deal, err := service.Deal("", "", solitaire.FreeCell, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := storage.GetDeck(deal.DeckID); !found {
+		t.Errorf("Deal failed: deck not saved to storage")
+	}
+
+	again, _ := service.Deal("", "", solitaire.FreeCell, 2)
+	if again.Layout.Tableau[0][0] != deal.Layout.Tableau[0][0] {
+		t.Errorf("Deal failed: same seed produced different layouts")
+	}
+
+	result, err := service.SolveDeck(deal.DeckID, solitaire.FreeCell, solitaire.Budget{MaxNodes: 200000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != solitaire.StatusSolved {
+		t.Errorf("SolveDeck failed: got status %v", result.Status)
+	}
+
+	seeded, err := service.SolveSeed(solitaire.FreeCell, 2, solitaire.Budget{MaxNodes: 200000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seeded.Status != result.Status || len(seeded.Moves) != len(result.Moves) {
+		t.Errorf("SolveSeed failed: got %v in %v moves, want %v in %v", seeded.Status, len(seeded.Moves), result.Status, len(result.Moves))
+	}
+
+	if _, err := service.SolveDeck(uuid.New(), solitaire.FreeCell, solitaire.Budget{}); err == nil || err.Error() != "Invalid Deck ID" {
+		t.Errorf("SolveDeck failed: expected 'Invalid Deck ID' error, got %v", err)
+	}
+}
+
+func TestSolitaireService_WinnableDeal(t *testing.T) {
+	service := NewSolitaireService(dao.NewDeckStorage())
+
+	deal, err := service.WinnableDeal("", "", solitaire.FreeCell, solitaire.Budget{MaxNodes: 20000, Timeout: time.Second}, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deal.Solution == nil || deal.Solution.Status != solitaire.StatusSolved {
+		t.Fatalf("WinnableDeal failed: deal has no solution")
+	}
+	if _, err := solitaire.Replay(deal.Layout, deal.Solution.Moves); err != nil {
+		t.Errorf("WinnableDeal failed: solution does not replay: %v", err)
+	}
+	start := time.Now()
+	service.WinnableDeal("", "", solitaire.Klondike, solitaire.Budget{Timeout: 100 * time.Millisecond}, 1000)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("WinnableDeal took %v on a 100ms budget", elapsed)
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"cardGame/deck/access"
+	"cardGame/deck/model"
+	"cardGame/deck/spectate"
+	"cardGame/deck/turn"
+	"errors"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// DefaultSpectatorDelay is how far behind the table feeds run unless set.
+const DefaultSpectatorDelay = 30 * time.Second
+
+var ErrSubscriptionNotFound = errors.New("Subscription not found")
+
+// Target is the game or deck a feed shows. GameType is empty for a deck.
+type Target struct {
+	GameType string    `json:"game_type,omitempty"`
+	ID       uuid.UUID `json:"id"`
+}
+
+// Subscription is one spectator watching a feed.
+type Subscription struct {
+	ID     uuid.UUID     `json:"id"`
+	Target Target        `json:"target"`
+	Role   spectate.Role `json:"role"`
+}
+
+// FeedPage is what a subscription may see of its feed after a frame.
+// Complete is false if some of the frames after it are no longer kept.
+type FeedPage struct {
+	Subscription Subscription     `json:"subscription"`
+	DelaySeconds float64          `json:"delay_seconds"`
+	Frames       []spectate.Frame `json:"frames"`
+	Complete     bool             `json:"complete"`
+}
+
+// SpectatorService runs a delayed feed for each game and deck someone
+// watches. Frames are taken as things change, and are only shown once
+// they are older than the feed's delay.
+type SpectatorService struct {
+	mu            sync.Mutex
+	games         *GameService
+	decks         *DeckService
+	feeds         map[Target]*spectate.Feed
+	subscriptions map[uuid.UUID]Subscription
+	clock         turn.Clock
+	// Delay and Size apply to feeds started from now on.
+	Delay time.Duration
+	Size  int
+}
+
+func NewSpectatorService(games *GameService, decks *DeckService) *SpectatorService {
+	return &SpectatorService{
+		games:         games,
+		decks:         decks,
+		feeds:         make(map[Target]*spectate.Feed),
+		subscriptions: make(map[uuid.UUID]Subscription),
+		clock:         turn.SystemClock,
+		Delay:         DefaultSpectatorDelay,
+		Size:          spectate.DefaultSize,
+	}
+}
+
+// SetClock replaces the clock that stamps and releases frames, for tests.
+func (s *SpectatorService) SetClock(clock turn.Clock) {
+	s.clock = clock
+}
+
+// feed returns the target's feed, starting it if nobody watches it yet.
+// A new feed starts with the target as it stands, stamped now, so the
+// delay holds for it too.
+func (s *SpectatorService) feed(target Target) (*spectate.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if feed, ok := s.feeds[target]; ok {
+		return feed, nil
+	}
+
+	feed := spectate.NewFeed(s.Size, s.Delay)
+	var err error
+	if target.GameType != "" {
+		err = s.games.Watch(target.GameType, target.ID, func(frame spectate.Frame) {
+			frame.Time = s.clock.Now()
+			feed.Append(frame)
+		})
+	} else {
+		err = s.decks.Watch(target.ID, func(deck model.Deck) {
+			feed.Append(spectate.Frame{
+				Time:     s.clock.Now(),
+				Public:   spectate.Encode(access.DefaultPolicy.Project(deck, "")),
+				Revealed: spectate.Encode(access.DefaultPolicy.Reveal(deck)),
+			})
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.feeds[target] = feed
+	return feed, nil
+}
+
+// Subscribe starts watching target as role.
+func (s *SpectatorService) Subscribe(target Target, role spectate.Role) (Subscription, error) {
+	if _, err := s.feed(target); err != nil {
+		return Subscription{}, err
+	}
+	subscription := Subscription{ID: uuid.New(), Target: target, Role: role}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[subscription.ID] = subscription
+	return subscription, nil
+}
+
+func (s *SpectatorService) subscription(target Target, id uuid.UUID) (Subscription, *spectate.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subscriptions[id]
+	if !ok || subscription.Target != target {
+		return Subscription{}, nil, ErrSubscriptionNotFound
+	}
+	return subscription, s.feeds[target], nil
+}
+
+// Feed returns the frames after since that the subscription may see now.
+func (s *SpectatorService) Feed(target Target, id uuid.UUID, since int) (FeedPage, error) {
+	subscription, feed, err := s.subscription(target, id)
+	if err != nil {
+		return FeedPage{}, err
+	}
+	frames, complete := feed.Since(since, s.clock.Now())
+	for i := range frames {
+		frames[i] = frames[i].For(subscription.Role)
+	}
+	return FeedPage{
+		Subscription: subscription,
+		DelaySeconds: feed.Delay().Seconds(),
+		Frames:       frames,
+		Complete:     complete,
+	}, nil
+}
+
+// Unsubscribe ends a subscription. The feed keeps running for the others.
+func (s *SpectatorService) Unsubscribe(target Target, id uuid.UUID) error {
+	if _, _, err := s.subscription(target, id); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, id)
+	return nil
+}
+
+// SetDelay sets the target's broadcast delay. Shortening it releases the
+// frames that are now old enough at once.
+func (s *SpectatorService) SetDelay(target Target, delay time.Duration) error {
+	feed, err := s.feed(target)
+	if err != nil {
+		return err
+	}
+	return feed.SetDelay(delay)
+}
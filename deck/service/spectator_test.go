@@ -0,0 +1,98 @@
+package service
+
+import (
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/spectate"
+	"cardGame/deck/turn"
+	"encoding/json"
+	"github.com/google/uuid"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpectatorService_Game(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	games := newTestGameService(storage)
+	service := NewSpectatorService(games, NewDeckService(storage))
+	clock := turn.NewFakeClock(time.Unix(0, 0))
+	service.SetClock(clock)
+	deck := speedDeck(storage)
+	created, _ := games.CreateGame("speed", &deck.ID, game.Config{Players: []string{"ann", "bob"}})
+	target := Target{GameType: "speed", ID: created.ID}
+
+	if _, err := service.Subscribe(Target{GameType: "speed", ID: uuid.New()}, spectate.Spectator); err != ErrGameNotFound {
+		t.Errorf("Subscribed to a missing game: %v", err)
+	}
+	watcher, _ := service.Subscribe(target, spectate.Spectator)
+	commentator, _ := service.Subscribe(target, spectate.Commentator)
+
+	clock.Advance(time.Second)
+	games.Apply("speed", created.ID, "ann", game.Action{Type: "play", Cards: []string{"8C"}, Value: "left"})
+	if page, _ := service.Feed(target, watcher.ID, 0); len(page.Frames) != 0 || page.DelaySeconds != 30 {
+		t.Errorf("Frames shown before the delay: %+v", page)
+	}
+
+	clock.Advance(DefaultSpectatorDelay)
+	page, err := service.Feed(target, watcher.ID, 0)
+	if err != nil || len(page.Frames) != 2 || !page.Complete {
+		t.Fatalf("Unexpected feed %+v: %v", page, err)
+	}
+	if f := page.Frames[1]; f.Seq != 2 || f.Event.Seq != 1 || f.Revealed != nil || f.Event.Action.Cards != nil || strings.Contains(string(f.Public), `"hand"`) {
+		t.Errorf("Spectator saw hidden cards: %+v", f)
+	}
+	if page, _ := service.Feed(target, watcher.ID, 2); len(page.Frames) != 0 || !page.Complete {
+		t.Errorf("Nothing follows frame 2: %+v", page)
+	}
+
+	page, _ = service.Feed(target, commentator.ID, 0)
+	var hands map[string]struct {
+		Hand []struct{ Code string } `json:"hand"`
+	}
+	json.Unmarshal(page.Frames[1].Revealed, &hands)
+	if page.Frames[1].Event.Action.Cards == nil || len(hands["bob"].Hand) == 0 || hands["bob"].Hand[0].Code != "6C" {
+		t.Errorf("Commentator should see the hands: %s", page.Frames[1].Revealed)
+	}
+
+	if _, err := service.Feed(Target{GameType: "speed", ID: uuid.New()}, watcher.ID, 0); err != ErrSubscriptionNotFound {
+		t.Errorf("Subscription read through another target: %v", err)
+	}
+	service.Unsubscribe(target, watcher.ID)
+	if _, err := service.Feed(target, watcher.ID, 0); err != ErrSubscriptionNotFound {
+		t.Errorf("Ended subscription still reads: %v", err)
+	}
+}
+
+func TestSpectatorService_Deck(t *testing.T) {
+	storage := dao.NewDeckStorage()
+	decks := NewDeckService(storage)
+	service := NewSpectatorService(newTestGameService(storage), decks)
+	clock := turn.NewFakeClock(time.Unix(0, 0))
+	service.SetClock(clock)
+	deck := decks.CreateDeck(false, "AS,2S,3S,4S")
+	target := Target{ID: deck.ID}
+
+	if err := service.SetDelay(target, 2*time.Hour); err == nil {
+		t.Errorf("Delay past the maximum set")
+	}
+	service.SetDelay(target, 10*time.Second)
+	commentator, _ := service.Subscribe(target, spectate.Commentator)
+	watcher, _ := service.Subscribe(target, spectate.Spectator)
+	decks.Deal(deck.ID, []string{"ann", "bob"}, 1)
+	clock.Advance(10 * time.Second)
+
+	page, _ := service.Feed(target, commentator.ID, 1)
+	if len(page.Frames) != 1 || page.Frames[0].Seq != 2 || page.DelaySeconds != 10 {
+		t.Fatalf("Unexpected feed %+v", page)
+	}
+	if !strings.Contains(string(page.Frames[0].Revealed), `"AS"`) {
+		t.Errorf("Commentator should see ann's hand: %s", page.Frames[0].Revealed)
+	}
+	if page, _ := service.Feed(target, watcher.ID, 1); strings.Contains(string(page.Frames[0].Public), `"AS"`) || page.Frames[0].Revealed != nil {
+		t.Errorf("Spectator saw a hand: %s", page.Frames[0].Public)
+	}
+	if _, err := service.Subscribe(Target{ID: uuid.New()}, spectate.Spectator); err == nil {
+		t.Errorf("Subscribed to a missing deck")
+	}
+}
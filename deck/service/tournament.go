@@ -0,0 +1,364 @@
+package service
+
+import (
+	"cardGame/deck/game"
+	"cardGame/deck/tournament"
+	"cardGame/deck/turn"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+const (
+	SingleElimination = "single_elimination"
+	DoubleElimination = "double_elimination"
+	Swiss             = "swiss"
+	Poker             = "poker"
+)
+
+var (
+	ErrTournamentNotFound = errors.New("Tournament not found")
+	// ErrNotOrganizer means someone other than the organizer reported a result.
+	ErrNotOrganizer = errors.New("Only the organizer may report results")
+	// ErrMatchHosted means a result was reported for a match whose hosted
+	// game is still being played; the game reports its own result.
+	ErrMatchHosted = errors.New("Match is being played as a hosted game")
+)
+
+// TournamentConfig describes a new tournament. Players are listed in seed
+// order. Matches of a bracket or Swiss event are hosted as games of
+// GameType when one is given, and otherwise played elsewhere and reported.
+// Rounds applies to Swiss; TableSize, Stack, Levels and Seed to poker.
+// Organizer is the player who reports results.
+type TournamentConfig struct {
+	Name      string                  `json:"name"`
+	Organizer string                  `json:"organizer,omitempty"`
+	Format    string                  `json:"format"`
+	GameType  string                  `json:"game_type,omitempty"`
+	Options   map[string]string       `json:"options,omitempty"`
+	Players   []string                `json:"players"`
+	Rounds    int                     `json:"rounds,omitempty"`
+	TableSize int                     `json:"table_size,omitempty"`
+	Stack     int                     `json:"stack,omitempty"`
+	Levels    []tournament.BlindLevel `json:"levels,omitempty"`
+	Seed      int64                   `json:"seed,omitempty"`
+}
+
+// TournamentState is a tournament's progress. Bracket and Swiss events fill
+// Matches and Standings; poker fills the table fields and Places, and Moves
+// lists the seat changes made by the last result.
+type TournamentState struct {
+	ID        uuid.UUID              `json:"id"`
+	Name      string                 `json:"name"`
+	Organizer string                 `json:"organizer,omitempty"`
+	Format    string                 `json:"format"`
+	GameType  string                 `json:"game_type,omitempty"`
+	Complete  bool                   `json:"complete"`
+	Round     int                    `json:"round,omitempty"`
+	Matches   []tournament.Match     `json:"matches,omitempty"`
+	Standings []tournament.Standing  `json:"standings,omitempty"`
+	Tables    []tournament.Table     `json:"tables,omitempty"`
+	Level     int                    `json:"level,omitempty"`
+	Blinds    *tournament.BlindLevel `json:"blinds,omitempty"`
+	Chips     map[string]int         `json:"chips,omitempty"`
+	Places    []tournament.Finish    `json:"places,omitempty"`
+	Moves     []tournament.Move      `json:"moves,omitempty"`
+}
+
+type hostedTournament struct {
+	id     uuid.UUID
+	config TournamentConfig
+	event  tournament.Event
+	poker  *tournament.MultiTable
+	moves  []tournament.Move
+}
+
+type matchRef struct {
+	tournament *hostedTournament
+	match      int
+}
+
+// TournamentService runs tournaments, hosting bracket and Swiss matches on
+// the GameService and dealing poker tables their decks from the
+// DeckService. Rounds advance as soon as results are reported, and a hosted
+// game reports its own result when it ends.
+type TournamentService struct {
+	mu          sync.Mutex
+	games       *GameService
+	decks       *DeckService
+	clock       turn.Clock
+	tournaments map[uuid.UUID]*hostedTournament
+	matches     map[uuid.UUID]matchRef
+}
+
+func NewTournamentService(games *GameService, decks *DeckService) *TournamentService {
+	s := &TournamentService{
+		games:       games,
+		decks:       decks,
+		clock:       turn.SystemClock,
+		tournaments: make(map[uuid.UUID]*hostedTournament),
+		matches:     make(map[uuid.UUID]matchRef),
+	}
+	games.OnFinish(s.gameFinished)
+	return s
+}
+
+// SetClock replaces the clock that times blind levels, for tests.
+func (s *TournamentService) SetClock(clock turn.Clock) {
+	s.clock = clock
+}
+
+func (s *TournamentService) Create(config TournamentConfig) (TournamentState, error) {
+	if config.GameType != "" {
+		if err := s.games.CheckType(config.GameType); err != nil {
+			return TournamentState{}, err
+		}
+	}
+
+	t := &hostedTournament{id: uuid.New(), config: config}
+	var err error
+	switch config.Format {
+	case SingleElimination:
+		t.event, err = tournament.NewSingleElimination(config.Players)
+	case DoubleElimination:
+		t.event, err = tournament.NewDoubleElimination(config.Players)
+	case Swiss:
+		t.event, err = tournament.NewSwiss(config.Players, config.Rounds)
+	case Poker:
+		if config.TableSize == 0 {
+			config.TableSize = 9
+		}
+		if config.Stack == 0 {
+			config.Stack = 10000
+		}
+		seed := config.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		t.poker, err = tournament.NewMultiTable(config.Players, config.TableSize, config.Stack, config.Levels, seed, s.clock.Now())
+	default:
+		err = fmt.Errorf("Unknown format %q", config.Format)
+	}
+	if err != nil {
+		return TournamentState{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.event != nil {
+		if err := s.startGames(t); err != nil {
+			s.stopGames(t)
+			return TournamentState{}, err
+		}
+	} else {
+		for _, table := range t.poker.Tables() {
+			s.newDeck(t.poker, table.Number)
+		}
+	}
+	s.tournaments[t.id] = t
+	return s.state(t), nil
+}
+
+// startGames hosts every ready match that has no game yet.
+func (s *TournamentService) startGames(t *hostedTournament) error {
+	if t.config.GameType == "" {
+		return nil
+	}
+	for _, m := range tournament.Ready(t.event) {
+		if m.GameID != nil {
+			continue
+		}
+		config := game.Config{Players: append([]string{}, m.Players[:]...), Options: t.config.Options}
+		state, err := s.games.CreateGame(t.config.GameType, nil, config)
+		if err != nil {
+			return err
+		}
+		t.event.SetGame(m.ID, state.ID)
+		s.matches[state.ID] = matchRef{tournament: t, match: m.ID}
+	}
+	return nil
+}
+
+// stopGames removes the hosted games of a tournament that failed to start.
+func (s *TournamentService) stopGames(t *hostedTournament) {
+	for _, m := range t.event.Matches() {
+		if m.GameID != nil {
+			delete(s.matches, *m.GameID)
+			s.games.remove(*m.GameID)
+		}
+	}
+}
+
+func (s *TournamentService) newDeck(poker *tournament.MultiTable, table int) {
+	deck := s.decks.CreateDeck(true, "")
+	poker.SetDeck(table, deck.ID)
+}
+
+func (s *TournamentService) find(id uuid.UUID) (*hostedTournament, error) {
+	t, found := s.tournaments[id]
+	if !found {
+		return nil, ErrTournamentNotFound
+	}
+	return t, nil
+}
+
+// organized finds a tournament for player to report a result in: the
+// organizer, or "" for the server's authority.
+func (s *TournamentService) organized(id uuid.UUID, player string) (*hostedTournament, error) {
+	t, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if player != "" && player != t.config.Organizer {
+		return nil, ErrNotOrganizer
+	}
+	return t, nil
+}
+
+func (s *TournamentService) State(id uuid.UUID) (TournamentState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.find(id)
+	if err != nil {
+		return TournamentState{}, err
+	}
+	return s.state(t), nil
+}
+
+// Report records a match result for player, an empty winner being a draw,
+// and hosts the games of any matches it makes ready. A match whose hosted
+// game is still playing cannot be reported; one left tied by its game can.
+func (s *TournamentService) Report(id uuid.UUID, player string, match int, winner string) (TournamentState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.organized(id, player)
+	if err != nil {
+		return TournamentState{}, err
+	}
+	if t.event == nil {
+		return TournamentState{}, fmt.Errorf("Poker tournaments report results by table")
+	}
+	for _, m := range t.event.Matches() {
+		if m.ID != match || m.GameID == nil {
+			continue
+		}
+		if _, playing := s.matches[*m.GameID]; playing {
+			return TournamentState{}, ErrMatchHosted
+		}
+	}
+	if err := t.event.Report(match, winner); err != nil {
+		return TournamentState{}, err
+	}
+	if err := s.startGames(t); err != nil {
+		return TournamentState{}, fmt.Errorf("Result recorded, but the next game could not start: %v", err)
+	}
+	return s.state(t), nil
+}
+
+// ReportTable records the stacks at a poker table after a hand for player,
+// rebalances the tables and deals the table a fresh deck for its next hand.
+func (s *TournamentService) ReportTable(id uuid.UUID, player string, table int, chips map[string]int) (TournamentState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.organized(id, player)
+	if err != nil {
+		return TournamentState{}, err
+	}
+	if t.poker == nil {
+		return TournamentState{}, fmt.Errorf("Only poker tournaments have tables")
+	}
+	if t.poker.Complete() {
+		return TournamentState{}, fmt.Errorf("Tournament is over")
+	}
+	moves, err := t.poker.Report(table, chips)
+	if err != nil {
+		return TournamentState{}, err
+	}
+	t.moves = moves
+	for _, tb := range t.poker.Tables() {
+		if tb.Number == table || tb.DeckID == nil {
+			s.newDeck(t.poker, tb.Number)
+		}
+	}
+	return s.state(t), nil
+}
+
+// gameFinished reports the result of a hosted tournament game: the player
+// who did not forfeit, or else the top scorer. A tied bracket game is left
+// for a result to be reported by hand.
+func (s *TournamentService) gameFinished(state GameState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ref, found := s.matches[state.ID]
+	if !found {
+		return
+	}
+	delete(s.matches, state.ID)
+
+	winner := gameWinner(state)
+	if winner == "" && ref.tournament.config.Format != Swiss {
+		return
+	}
+	if ref.tournament.event.Report(ref.match, winner) == nil {
+		s.startGames(ref.tournament)
+	}
+}
+
+// gameWinner returns the player who did not forfeit or the top scorer, or
+// "" for a tie.
+func gameWinner(state GameState) string {
+	if state.Forfeited != "" {
+		for _, p := range state.Players {
+			if p != state.Forfeited {
+				return p
+			}
+		}
+	}
+	winner, best, tied := "", 0, false
+	for p, score := range state.Scores {
+		switch {
+		case winner == "" || score > best:
+			winner, best, tied = p, score, false
+		case score == best:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return winner
+}
+
+func (s *TournamentService) state(t *hostedTournament) TournamentState {
+	state := TournamentState{
+		ID:        t.id,
+		Name:      t.config.Name,
+		Organizer: t.config.Organizer,
+		Format:    t.config.Format,
+		GameType:  t.config.GameType,
+	}
+	if t.event != nil {
+		state.Complete = t.event.Complete()
+		state.Matches = t.event.Matches()
+		state.Standings = t.event.Standings()
+		if swiss, ok := t.event.(*tournament.Swiss); ok {
+			state.Round = swiss.Round()
+		}
+		return state
+	}
+
+	level, blinds := t.poker.Level(s.clock.Now())
+	state.Complete = t.poker.Complete()
+	state.Tables = t.poker.Tables()
+	state.Level, state.Blinds = level, &blinds
+	state.Chips = t.poker.Chips()
+	state.Places = t.poker.Standings()
+	state.Moves = t.moves
+	return state
+}
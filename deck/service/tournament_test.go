@@ -0,0 +1,164 @@
+package service
+
+import (
+	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+	"cardGame/deck/tournament"
+	"cardGame/deck/turn"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestTournamentService() (*TournamentService, *GameService) {
+	storage := dao.NewDeckStorage()
+	games := newTestGameService(storage)
+	return NewTournamentService(games, NewDeckService(storage)), games
+}
+
+func TestTournamentService_HostedBracket(t *testing.T) {
+	service, games := newTestTournamentService()
+	config := TournamentConfig{Name: "Weekend", Format: SingleElimination, GameType: "highcard", Players: []string{"ann", "bob", "cat", "dan"}}
+	if _, err := service.Create(TournamentConfig{Format: "knockout", Players: config.Players}); err == nil {
+		t.Errorf("Create accepted an unknown format")
+	}
+	state, err := service.Create(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Report(state.ID, "", 1, "ann"); err != ErrMatchHosted {
+		t.Errorf("Report recorded a match whose game is playing: %v", err)
+	}
+
+	for played := 0; !state.Complete; played++ {
+		if played > 10 {
+			t.Fatalf("Tournament did not finish: %+v", state.Matches)
+		}
+		for _, m := range state.Matches {
+			if !m.Ready() {
+				continue
+			}
+			if m.GameID == nil {
+				t.Fatalf("Ready match has no game: %+v", m)
+			}
+			for _, player := range m.Players {
+				if _, err := games.Apply("highcard", *m.GameID, player, game.Action{Type: "draw"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// A tied game leaves the match to be reported by hand.
+			if state, _ = service.State(state.ID); !state.Matches[m.ID-1].Done {
+				if state, err = service.Report(state.ID, "", m.ID, m.Players[0]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+
+	final := state.Matches[len(state.Matches)-1]
+	if final.Round != 2 || final.Winner == "" || state.Standings[0].Player != final.Winner {
+		t.Errorf("Unexpected final: %+v %+v", final, state.Standings)
+	}
+}
+
+// pickyGame is high card that will not seat zed.
+type pickyGame struct {
+	game.Game
+}
+
+func (g pickyGame) Setup(deck model.Deck, config game.Config) error {
+	for _, p := range config.Players {
+		if p == "zed" {
+			return fmt.Errorf("No seat for zed")
+		}
+	}
+	return g.Game.Setup(deck, config)
+}
+
+func TestTournamentService_FailedStart(t *testing.T) {
+	service, games := newTestTournamentService()
+	games.registry.Register("picky", func() game.Game { return pickyGame{game.NewHighCard()} })
+
+	config := TournamentConfig{Format: SingleElimination, GameType: "picky", Players: []string{"ann", "bob", "zed", "dan"}}
+	if _, err := service.Create(config); err == nil {
+		t.Fatalf("Create started a tournament whose game could not be hosted")
+	}
+	if len(games.games) != 0 || len(service.matches) != 0 {
+		t.Errorf("Create left %v games behind", len(games.games))
+	}
+}
+
+func TestTournamentService_SwissReports(t *testing.T) {
+	service, _ := newTestTournamentService()
+	state, err := service.Create(TournamentConfig{Format: Swiss, Organizer: "ann", Players: []string{"ann", "bob", "cat"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Round != 1 || len(state.Matches) != 2 || state.Matches[0].GameID != nil {
+		t.Fatalf("Unexpected first round: %+v", state)
+	}
+	if _, err := service.Report(state.ID, "bob", 1, "bob"); err != ErrNotOrganizer {
+		t.Errorf("Report accepted a result from a player: %v", err)
+	}
+	if _, err := service.Report(state.ID, "", 9, "ann"); err != tournament.ErrMatchNotFound {
+		t.Errorf("Report on a missing match: %v", err)
+	}
+	if state, err = service.Report(state.ID, "ann", 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	if state.Round != 2 {
+		t.Errorf("A draw should complete round 1: %+v", state)
+	}
+}
+
+func TestTournamentService_Poker(t *testing.T) {
+	service, _ := newTestTournamentService()
+	clock := turn.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	service.SetClock(clock)
+
+	players := []string{"ann", "bob", "cat", "dan", "eve"}
+	state, err := service.Create(TournamentConfig{Format: Poker, Players: players, TableSize: 3, Stack: 1000, Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Tables) != 2 || state.Level != 1 || state.Blinds.BigBlind != 50 {
+		t.Fatalf("Unexpected start: %+v", state)
+	}
+	deck := state.Tables[0].DeckID
+	if deck == nil {
+		t.Fatalf("Table has no deck")
+	}
+
+	clock.Advance(25 * time.Minute)
+	var busted string
+	for _, p := range state.Tables[0].Seats {
+		if p != "" {
+			busted = p
+			break
+		}
+	}
+	if _, err := service.ReportTable(state.ID, "", state.Tables[0].Number, map[string]int{busted: 0}); err == nil {
+		t.Errorf("ReportTable lost a stack")
+	}
+	var winner string
+	for _, p := range state.Tables[0].Seats {
+		if p != "" && p != busted {
+			winner = p
+			break
+		}
+	}
+	state, err = service.ReportTable(state.ID, "", state.Tables[0].Number, map[string]int{busted: 0, winner: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Level != 2 || len(state.Tables) != 2 || *state.Tables[0].DeckID == *deck {
+		t.Errorf("Expected level 2 and a new deck: %+v", state)
+	}
+	if last := state.Places[len(state.Places)-1]; last.Player != busted || last.Place != 5 {
+		t.Errorf("%v should finish fifth: %+v", busted, state.Places)
+	}
+	if _, err := service.Report(state.ID, "", 1, "ann"); err == nil {
+		t.Errorf("Poker accepted a match result")
+	}
+}
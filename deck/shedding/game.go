@@ -0,0 +1,377 @@
+package shedding
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+// DiscardPile names the deck pile cards are played to. The deck's own cards
+// are the draw pile.
+const DiscardPile = "discard"
+
+// Game plays one hand of Crazy Eights for the game registry, with the action
+// cards of the chosen variant. Turn order runs through Players and reverses
+// with each reverse card; the first player to shed their hand wins.
+type Game struct {
+	rules     Rules
+	rng       *rand.Rand
+	players   []string
+	deck      model.Deck
+	hands     [][]model.Card
+	turn      int
+	direction int
+	// suit is the suit to follow, named by whoever played the last wild card.
+	suit string
+	// drawn is the card the player to move has just drawn, which is the only
+	// one they may then play.
+	drawn      *model.Card
+	winner     int
+	reshuffles int
+}
+
+type View struct {
+	Hand       []model.Card   `json:"hand,omitempty"`
+	Drawn      *model.Card    `json:"drawn,omitempty"`
+	HandSizes  map[string]int `json:"hand_sizes"`
+	Top        model.Card     `json:"top"`
+	Suit       string         `json:"suit"`
+	Turn       string         `json:"turn,omitempty"`
+	Direction  string         `json:"direction"`
+	Stock      int            `json:"stock"`
+	Discard    int            `json:"discard"`
+	Reshuffles int            `json:"reshuffles"`
+	Winner     string         `json:"winner,omitempty"`
+}
+
+func NewGame() game.Game {
+	return &Game{}
+}
+
+// Setup deals the hands and turns up the first discard, whose suit is the
+// one to follow; its action, if any, is ignored. Options: "variant"
+// (classic or action), "wild", "skip", "reverse" and "draw_two" (comma
+// separated card values, overriding the variant), "hand_size" and "seed"
+// for reshuffles.
+func (g *Game) Setup(deck model.Deck, config game.Config) error {
+	n := len(config.Players)
+	if n < 2 || n > 8 {
+		return fmt.Errorf("Crazy Eights needs 2 to 8 players")
+	}
+
+	name := config.Options["variant"]
+	if name == "" {
+		name = "classic"
+	}
+	rules, ok := Variants[name]
+	if !ok {
+		return fmt.Errorf("Unknown variant %q", name)
+	}
+	g.rules = customize(rules, config.Options)
+	handSize := g.rules.HandSize
+	if handSize == 0 {
+		handSize = 5
+		if n == 2 {
+			handSize = 7
+		}
+	}
+	if len(deck.Cards) < n*handSize+1 {
+		return fmt.Errorf("Not enough cards to deal Crazy Eights")
+	}
+
+	seed := time.Now().UnixNano()
+	if value, err := strconv.ParseInt(config.Options["seed"], 10, 64); err == nil {
+		seed = value
+	}
+	g.rng = rand.New(rand.NewSource(seed))
+	g.players = config.Players
+	g.deck = deck
+	g.deck.Cards = append([]model.Card{}, deck.Cards...)
+	g.deck.Piles = nil
+	g.hands = make([][]model.Card, n)
+	for i := 0; i < n*handSize; i++ {
+		cards, _ := g.deck.DrawCards(1)
+		g.hands[i%n] = append(g.hands[i%n], cards[0])
+	}
+	starter, _ := g.deck.DrawCards(1)
+	g.deck.AddToPile(DiscardPile, starter...)
+	g.suit = starter[0].Suit
+	g.direction = 1
+	g.winner = -1
+	return nil
+}
+
+// customize applies the options that override a variant's rules.
+func customize(rules Rules, options map[string]string) Rules {
+	values := func(option string) []string {
+		if options[option] == "" {
+			return nil
+		}
+		return strings.Split(strings.ToUpper(options[option]), ",")
+	}
+
+	if _, ok := options["wild"]; ok {
+		rules.Wild = values("wild")
+	}
+	effects := make(map[string]Effect)
+	for value, effect := range rules.Effects {
+		effects[value] = effect
+	}
+	for _, effect := range []Effect{Skip, Reverse, DrawTwo} {
+		if _, ok := options[string(effect)]; !ok {
+			continue
+		}
+		for value, e := range effects {
+			if e == effect {
+				delete(effects, value)
+			}
+		}
+		for _, value := range values(string(effect)) {
+			effects[value] = effect
+		}
+	}
+	rules.Effects = effects
+	if size, err := strconv.Atoi(options["hand_size"]); err == nil && size > 0 {
+		rules.HandSize = size
+	}
+	return rules
+}
+
+func (g *Game) Players() []string {
+	return g.players
+}
+
+func (g *Game) seat(player string) int {
+	for i, p := range g.players {
+		if p == player {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Game) top() model.Card {
+	top, _ := g.deck.TopOfPile(DiscardPile)
+	return top
+}
+
+// canDraw reports whether a card is left to draw, counting the discards
+// that a reshuffle would bring back.
+func (g *Game) canDraw() bool {
+	return len(g.deck.Cards) > 0 || len(g.deck.Piles[DiscardPile]) > 1
+}
+
+func (g *Game) LegalActions(player string) []game.Action {
+	seat := g.seat(player)
+	if g.Terminal() || seat != g.turn {
+		return nil
+	}
+
+	var actions []game.Action
+	if g.drawn != nil {
+		actions = g.plays(*g.drawn)
+		return append(actions, game.Action{Type: "pass"})
+	}
+	for _, c := range g.hands[seat] {
+		actions = append(actions, g.plays(c)...)
+	}
+	if g.canDraw() {
+		return append(actions, game.Action{Type: "draw"})
+	}
+	return append(actions, game.Action{Type: "pass"})
+}
+
+// plays lists the ways c may be played now: once, or once per suit to name
+// for a wild card.
+func (g *Game) plays(c model.Card) []game.Action {
+	if !g.rules.Matches(c, g.top(), g.suit) {
+		return nil
+	}
+	if !g.rules.IsWild(c) {
+		return []game.Action{{Type: "play", Cards: []string{c.Code}}}
+	}
+	var actions []game.Action
+	for _, suit := range model.Suits {
+		actions = append(actions, game.Action{Type: "play", Cards: []string{c.Code}, Value: suit})
+	}
+	return actions
+}
+
+// Apply accepts a wild card's suit in any case.
+func (g *Game) Apply(player string, action game.Action) error {
+	action.Value = strings.ToUpper(action.Value)
+	if !game.IsLegal(g, player, action) {
+		return fmt.Errorf("Illegal action %q for %v", action.Type, player)
+	}
+
+	seat := g.turn
+	switch action.Type {
+	case "draw":
+		if drawn := g.draw(seat, 1); len(drawn) > 0 {
+			g.drawn = &drawn[0]
+		}
+	case "pass":
+		g.drawn = nil
+		g.advance(1)
+	case "play":
+		g.play(seat, action)
+	}
+	return nil
+}
+
+func (g *Game) play(seat int, action game.Action) {
+	hand := g.hands[seat]
+	for i, c := range hand {
+		if c.Code != action.Cards[0] {
+			continue
+		}
+		g.hands[seat] = append(append([]model.Card{}, hand[:i]...), hand[i+1:]...)
+		g.deck.AddToPile(DiscardPile, c)
+		g.suit = c.Suit
+		if g.rules.IsWild(c) {
+			g.suit = action.Value
+		}
+		g.drawn = nil
+		if len(g.hands[seat]) == 0 {
+			g.winner = seat
+			return
+		}
+
+		switch g.rules.Effects[c.Value] {
+		case Skip:
+			g.advance(2)
+		case Reverse:
+			g.direction = -g.direction
+			// With two players a reverse comes straight back, like a skip.
+			if len(g.players) == 2 {
+				g.advance(2)
+			} else {
+				g.advance(1)
+			}
+		case DrawTwo:
+			g.advance(1)
+			g.draw(g.turn, 2)
+			g.advance(1)
+		default:
+			g.advance(1)
+		}
+		return
+	}
+}
+
+func (g *Game) advance(steps int) {
+	n := len(g.players)
+	g.turn = ((g.turn+steps*g.direction)%n + n) % n
+}
+
+// draw gives seat up to count cards, shuffling the discards under the top
+// card back into the draw pile whenever it runs out.
+func (g *Game) draw(seat, count int) []model.Card {
+	var drawn []model.Card
+	for i := 0; i < count; i++ {
+		if len(g.deck.Cards) == 0 {
+			g.reshuffle()
+		}
+		cards, ok := g.deck.DrawCards(1)
+		if !ok || len(cards) == 0 {
+			break
+		}
+		drawn = append(drawn, cards[0])
+	}
+	g.hands[seat] = append(g.hands[seat], drawn...)
+	return drawn
+}
+
+func (g *Game) reshuffle() {
+	discards := g.deck.Piles[DiscardPile]
+	if len(discards) < 2 {
+		return
+	}
+	cards := append([]model.Card{}, discards[:len(discards)-1]...)
+	g.deck.Piles[DiscardPile] = discards[len(discards)-1:]
+	g.rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	g.deck.Cards, g.deck.Remaining = cards, len(cards)
+	g.reshuffles++
+}
+
+func (g *Game) Terminal() bool {
+	return g.winner >= 0
+}
+
+// Scores give the winner the points left in the other hands.
+func (g *Game) Scores() map[string]int {
+	scores := make(map[string]int)
+	for _, p := range g.players {
+		scores[p] = 0
+	}
+	if g.winner < 0 {
+		return scores
+	}
+	for _, hand := range g.hands {
+		for _, c := range hand {
+			scores[g.players[g.winner]] += g.rules.Points(c)
+		}
+	}
+	return scores
+}
+
+// Determinize deals the other hands again from the unseen cards, the other
+// hands and the draw pile.
+func (g *Game) Determinize(player string, rng *rand.Rand) game.Game {
+	clone := *g
+	clone.rng = rng
+	clone.deck = g.deck.Clone()
+	clone.hands = make([][]model.Card, len(g.hands))
+	unseen := []*[]model.Card{&clone.deck.Cards}
+	for seat, hand := range g.hands {
+		clone.hands[seat] = append([]model.Card{}, hand...)
+		if g.players[seat] != player {
+			unseen = append(unseen, &clone.hands[seat])
+		}
+	}
+	game.Redeal(rng, unseen...)
+	if g.drawn != nil {
+		// The card just drawn is the last in its drawer's hand, so another
+		// player's is dealt again with it.
+		hand := clone.hands[g.turn]
+		drawn := hand[len(hand)-1]
+		clone.drawn = &drawn
+	}
+	return &clone
+}
+
+// View shows a player their own hand and the card they just drew.
+func (g *Game) View(player string) interface{} {
+	view := View{
+		HandSizes:  make(map[string]int),
+		Top:        g.top(),
+		Suit:       g.suit,
+		Direction:  "clockwise",
+		Stock:      len(g.deck.Cards),
+		Discard:    len(g.deck.Piles[DiscardPile]),
+		Reshuffles: g.reshuffles,
+	}
+	if g.direction < 0 {
+		view.Direction = "counterclockwise"
+	}
+	for seat, p := range g.players {
+		view.HandSizes[p] = len(g.hands[seat])
+	}
+	if g.Terminal() {
+		view.Winner = g.players[g.winner]
+	} else {
+		view.Turn = g.players[g.turn]
+	}
+	if seat := g.seat(player); seat >= 0 {
+		view.Hand = g.hands[seat]
+		if seat == g.turn {
+			view.Drawn = g.drawn
+		}
+	}
+	return view
+}
@@ -0,0 +1,161 @@
+package shedding
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+func apply(t *testing.T, g game.Game, player string, action game.Action) View {
+	t.Helper()
+	if err := g.Apply(player, action); err != nil {
+		t.Fatalf("%v %+v: %v", player, action, err)
+	}
+	return g.View(player).(View)
+}
+
+func TestActionCards(t *testing.T) {
+	// Ann, Bob and Cat are dealt two cards each in turn, then 4H is turned
+	// up with AH, KS and 6D to draw.
+	deck := model.NewDeck(false, "QH,7H,2H,5C,9S,3D,4H,AH,KS,6D,JD,1S")
+	config := game.Config{Players: []string{"ann", "bob", "cat"}, Options: map[string]string{"variant": "action", "hand_size": "2"}}
+	g := NewGame()
+	if err := g.Setup(deck, config); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Apply("ann", game.Action{Type: "play", Cards: []string{"5C"}}); err == nil {
+		t.Errorf("5C accepted on 4H")
+	}
+	view := apply(t, g, "ann", game.Action{Type: "play", Cards: []string{"QH"}})
+	if view.Turn != "cat" {
+		t.Fatalf("Queen did not skip Bob: %+v", view)
+	}
+
+	view = apply(t, g, "cat", game.Action{Type: "play", Cards: []string{"2H"}})
+	if view.Turn != "bob" || view.HandSizes["ann"] != 3 || view.Stock != 3 {
+		t.Fatalf("Two did not make Ann draw two and miss her turn: %+v", view)
+	}
+
+	view = apply(t, g, "bob", game.Action{Type: "play", Cards: []string{"7H"}})
+	actions := g.LegalActions("cat")
+	if len(actions) != 1 || actions[0].Type != "draw" {
+		t.Fatalf("Cat should only be able to draw: %+v", actions)
+	}
+	view = apply(t, g, "cat", actions[0])
+	if view.Drawn == nil || view.Drawn.Code != "6D" || view.Turn != "cat" {
+		t.Fatalf("Cat did not draw 6D: %+v", view)
+	}
+	if actions := g.LegalActions("cat"); len(actions) != 1 || actions[0].Type != "pass" {
+		t.Fatalf("Cat should only be able to pass: %+v", actions)
+	}
+	apply(t, g, "cat", game.Action{Type: "pass"})
+
+	view = apply(t, g, "ann", game.Action{Type: "play", Cards: []string{"AH"}})
+	if view.Direction != "counterclockwise" || view.Turn != "cat" {
+		t.Errorf("Ace did not reverse play: %+v", view)
+	}
+}
+
+func TestWildAndReshuffle(t *testing.T) {
+	deck := model.NewDeck(false, "9C,9S,5D,6D,8H")
+	config := game.Config{Players: []string{"ann", "bob"}, Options: map[string]string{"hand_size": "1", "seed": "1"}}
+	g := NewGame()
+	if err := g.Setup(deck, config); err != nil {
+		t.Fatal(err)
+	}
+
+	apply(t, g, "ann", game.Action{Type: "draw"})
+	view := apply(t, g, "ann", game.Action{Type: "play", Cards: []string{"6D"}})
+	if view.Turn != "bob" || view.Top.Code != "6D" {
+		t.Fatalf("Unexpected view: %+v", view)
+	}
+
+	view = apply(t, g, "bob", game.Action{Type: "draw"})
+	if view.Drawn.Code != "8H" {
+		t.Fatalf("Bob did not draw 8H: %+v", view)
+	}
+	if err := g.Apply("bob", game.Action{Type: "play", Cards: []string{"8H"}}); err == nil {
+		t.Errorf("Wild card accepted without a suit")
+	}
+	view = apply(t, g, "bob", game.Action{Type: "play", Cards: []string{"8H"}, Value: "clubs"})
+	if view.Suit != "CLUBS" || view.Stock != 0 {
+		t.Fatalf("Eight did not name clubs: %+v", view)
+	}
+
+	// The draw pile is empty, so Ann's draw reshuffles 5D and 6D under the
+	// eight.
+	view = apply(t, g, "ann", game.Action{Type: "draw"})
+	if view.Reshuffles != 1 || view.Discard != 1 || view.Stock != 1 || view.Top.Code != "8H" {
+		t.Fatalf("Discards were not reshuffled: %+v", view)
+	}
+	apply(t, g, "ann", game.Action{Type: "pass"})
+	apply(t, g, "bob", game.Action{Type: "draw"})
+	apply(t, g, "bob", game.Action{Type: "pass"})
+
+	// Bob's nine follows Ann's by value and the suit goes back to spades.
+	apply(t, g, "ann", game.Action{Type: "play", Cards: []string{"9C"}})
+	view = apply(t, g, "bob", game.Action{Type: "play", Cards: []string{"9S"}})
+	if view.Suit != "SPADES" || view.Turn != "ann" || view.HandSizes["bob"] != 1 {
+		t.Errorf("Unexpected view: %+v", view)
+	}
+}
+
+func TestGamePlaysOut(t *testing.T) {
+	g := NewGame()
+	config := game.Config{Players: []string{"ann", "bob", "cat", "dan"}, Options: map[string]string{"variant": "action", "seed": "2"}}
+	if err := g.Setup(model.NewSeededDeck(2, ""), config); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Setup(model.NewSeededDeck(2, ""), game.Config{Players: []string{"ann"}}); err == nil {
+		t.Errorf("Setup accepted one player")
+	}
+
+	for moves := 0; !g.Terminal(); moves++ {
+		if moves > 2000 {
+			t.Fatalf("Game did not finish")
+		}
+		player := g.View("").(View).Turn
+		actions := g.LegalActions(player)
+		if err := g.Apply(player, actions[0]); err != nil {
+			t.Fatalf("%v %+v: %v", player, actions[0], err)
+		}
+	}
+	winner := g.View("").(View).Winner
+	if g.View(winner).(View).HandSizes[winner] != 0 || g.Scores()[winner] == 0 {
+		t.Errorf("Unexpected end: %+v %v", g.View(""), g.Scores())
+	}
+}
+
+func TestDeterminize(t *testing.T) {
+	g := NewGame()
+	if err := g.Setup(model.NewSeededDeck(3, ""), game.Config{Players: []string{"ann", "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := json.Marshal(g.View("bob"))
+	own, _ := json.Marshal(g.View("ann"))
+	bob := g.View("bob").(View).Hand
+
+	rng := rand.New(rand.NewSource(1))
+	dealt := false
+	for i := 0; i < 10; i++ {
+		d := g.(game.Determinizer).Determinize("ann", rng)
+		if view, _ := json.Marshal(d.View("ann")); string(view) != string(own) {
+			t.Fatalf("Determinize changed what ann sees:\n%s\n%s", view, own)
+		}
+		dealt = dealt || d.View("bob").(View).Hand[0].Code != bob[0].Code
+		for !d.Terminal() {
+			player := d.View("").(View).Turn
+			d.Apply(player, d.LegalActions(player)[0])
+		}
+	}
+	if !dealt {
+		t.Errorf("Bob's hand was never dealt again")
+	}
+	if after, _ := json.Marshal(g.View("bob")); string(after) != string(before) {
+		t.Errorf("Playing a determinization changed the game")
+	}
+}
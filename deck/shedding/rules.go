@@ -0,0 +1,65 @@
+package shedding
+
+import (
+	"cardGame/deck/model"
+)
+
+// Effect is what an action card does to the players after the one who
+// played it.
+type Effect string
+
+const (
+	Skip    Effect = "skip"
+	Reverse Effect = "reverse"
+	DrawTwo Effect = "draw_two"
+)
+
+// Rules configure a shedding variant. Cards are matched by value.
+type Rules struct {
+	// Wild values match any card, and whoever plays one names the suit to
+	// follow.
+	Wild    []string
+	Effects map[string]Effect
+	// HandSize is the deal, or 0 for seven cards each with two players and
+	// five with more.
+	HandSize int
+}
+
+// Variants are the rule sets the "variant" option picks from.
+var Variants = map[string]Rules{
+	"classic": {Wild: []string{"8"}},
+	"action":  {Wild: []string{"8"}, Effects: map[string]Effect{"QUEEN": Skip, "ACE": Reverse, "2": DrawTwo}},
+}
+
+func (r Rules) IsWild(c model.Card) bool {
+	for _, value := range r.Wild {
+		if c.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether c may be played on top when suit is the suit to
+// follow.
+func (r Rules) Matches(c, top model.Card, suit string) bool {
+	return r.IsWild(c) || c.Suit == suit || c.Value == top.Value
+}
+
+// Points is what a card left in hand is worth to the winner: 50 for a wild
+// card, 20 for an action card, 10 for a picture, 1 for an ace and the pip
+// value otherwise.
+func (r Rules) Points(c model.Card) int {
+	switch rank := c.Rank(); {
+	case r.IsWild(c):
+		return 50
+	case r.Effects[c.Value] != "":
+		return 20
+	case rank == 14:
+		return 1
+	case rank > 10:
+		return 10
+	default:
+		return rank
+	}
+}
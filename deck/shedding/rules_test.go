@@ -0,0 +1,47 @@
+package shedding
+
+import (
+	"testing"
+
+	"cardGame/deck/model"
+)
+
+func card(code string) model.Card {
+	c, _ := model.CardFromCode(code)
+	return c
+}
+
+func TestMatches(t *testing.T) {
+	rules := Variants["classic"]
+	tests := []struct {
+		play, top, suit string
+		matches         bool
+	}{
+		{"5H", "9H", "HEARTS", true},
+		{"9S", "9H", "HEARTS", true},
+		{"5S", "9H", "HEARTS", false},
+		{"8S", "9H", "HEARTS", true},
+		// After a wild eight the named suit counts, not the eight's own.
+		{"5C", "8H", "CLUBS", true},
+		{"5H", "8H", "CLUBS", false},
+		{"8C", "8H", "CLUBS", true},
+	}
+	for _, test := range tests {
+		if got := rules.Matches(card(test.play), card(test.top), test.suit); got != test.matches {
+			t.Errorf("Matches(%v on %v, %v) = %v, want %v", test.play, test.top, test.suit, got, test.matches)
+		}
+	}
+}
+
+func TestPoints(t *testing.T) {
+	rules := Variants["action"]
+	tests := map[string]int{"8S": 50, "QH": 20, "2C": 20, "AD": 20, "KS": 10, "JH": 10, "7C": 7}
+	for code, want := range tests {
+		if got := rules.Points(card(code)); got != want {
+			t.Errorf("Points(%v) = %v, want %v", code, got, want)
+		}
+	}
+	if got := Variants["classic"].Points(card("AD")); got != 1 {
+		t.Errorf("Points(AD) = %v without actions, want 1", got)
+	}
+}
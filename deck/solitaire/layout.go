@@ -0,0 +1,143 @@
+package solitaire
+
+import (
+	"fmt"
+
+	"cardGame/deck/model"
+)
+
+type Variant string
+
+const (
+	Klondike Variant = "klondike"
+	FreeCell Variant = "freecell"
+)
+
+type Pile string
+
+const (
+	PileStock      Pile = "stock"
+	PileWaste      Pile = "waste"
+	PileTableau    Pile = "tableau"
+	PileFoundation Pile = "foundation"
+	PileCell       Pile = "cell"
+)
+
+// Layout describes a solitaire position. Every pile is listed bottom to top, so
+// the last card of a pile is the one that can be played. Foundation i holds
+// model.Suits[i].
+type Layout struct {
+	Variant     Variant        `json:"variant"`
+	DrawCount   int            `json:"draw_count,omitempty"`
+	Tableau     [][]model.Card `json:"tableau"`
+	FaceDown    []int          `json:"face_down,omitempty"`
+	Stock       []model.Card   `json:"stock,omitempty"`
+	Waste       []model.Card   `json:"waste,omitempty"`
+	Foundations [][]model.Card `json:"foundations,omitempty"`
+	Cells       []model.Card   `json:"cells,omitempty"`
+}
+
+type Move struct {
+	From      Pile `json:"from"`
+	FromIndex int  `json:"from_index"`
+	To        Pile `json:"to"`
+	ToIndex   int  `json:"to_index"`
+	Count     int  `json:"count"`
+}
+
+const freeCellCount = 4
+
+// Deal lays out a full 52 card deck for the given variant. The deck is dealt in
+// its current order, so a seeded deck always produces the same layout.
+func Deal(variant Variant, deck model.Deck) (Layout, error) {
+	if len(deck.Cards) != 52 {
+		return Layout{}, fmt.Errorf("Deck must contain 52 cards")
+	}
+	cards := deck.Cards
+
+	switch variant {
+	case Klondike:
+		layout := Layout{Variant: Klondike, DrawCount: 1, Tableau: make([][]model.Card, 7), FaceDown: make([]int, 7)}
+		next := 0
+		for row := 0; row < 7; row++ {
+			for pile := row; pile < 7; pile++ {
+				layout.Tableau[pile] = append(layout.Tableau[pile], cards[next])
+				next++
+			}
+		}
+		for pile := range layout.Tableau {
+			layout.FaceDown[pile] = len(layout.Tableau[pile]) - 1
+		}
+		for i := len(cards) - 1; i >= next; i-- {
+			layout.Stock = append(layout.Stock, cards[i])
+		}
+		return layout, nil
+	case FreeCell:
+		layout := Layout{Variant: FreeCell, Tableau: make([][]model.Card, 8)}
+		for i, card := range cards {
+			layout.Tableau[i%8] = append(layout.Tableau[i%8], card)
+		}
+		return layout, nil
+	}
+
+	return Layout{}, fmt.Errorf("Unknown solitaire variant %q", variant)
+}
+
+// card packs a rank (1 for ACE up to 13 for KING) and a suit index into one
+// byte so positions can be copied and hashed cheaply during search. Zero means
+// no card.
+type card uint8
+
+func newCard(c model.Card) (card, error) {
+	rank := c.Rank()
+	suit := c.SuitIndex()
+	if rank == 0 || suit < 0 {
+		return 0, fmt.Errorf("Invalid card %q", c.Code)
+	}
+	if rank == 14 {
+		rank = 1
+	}
+	return card(rank<<2 | suit), nil
+}
+
+func (c card) rank() int  { return int(c >> 2) }
+func (c card) suit() int  { return int(c & 3) }
+func (c card) red() bool  { return c.suit() == 1 || c.suit() == 3 }
+func (c card) none() bool { return c == 0 }
+
+func (c card) stacksOn(below card) bool {
+	return below.rank() == c.rank()+1 && below.red() != c.red()
+}
+
+func (c card) model() model.Card {
+	value := model.Values[c.rank()-2+13*boolInt(c.rank() == 1)]
+	suit := model.Suits[c.suit()]
+	return model.Card{Value: value, Suit: suit, Code: value[:1] + suit[:1]}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func toCards(cards []model.Card) ([]card, error) {
+	out := make([]card, 0, len(cards))
+	for _, c := range cards {
+		packed, err := newCard(c)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, packed)
+	}
+	return out, nil
+}
+
+func fromCards(cards []card) []model.Card {
+	out := make([]model.Card, 0, len(cards))
+	for _, c := range cards {
+		out = append(out, c.model())
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package solitaire
+
+import (
+	"time"
+)
+
+type Status string
+
+const (
+	StatusSolved     Status = "solved"
+	StatusUnwinnable Status = "unwinnable"
+	StatusUnknown    Status = "unknown"
+)
+
+// Budget caps the search. A zero field means no limit on that axis.
+type Budget struct {
+	MaxNodes int
+	Timeout  time.Duration
+}
+
+type Result struct {
+	Status Status `json:"status"`
+	Moves  []Move `json:"moves,omitempty"`
+	Nodes  int    `json:"nodes"`
+}
+
+type solver struct {
+	budget   Budget
+	deadline time.Time
+	seen     map[string]struct{}
+	path     []Move
+	nodes    int
+	exceeded bool
+}
+
+// Solve runs a depth-first search over the layout, remembering every position
+// it has already explored. Klondike is solved with full knowledge of the
+// face-down cards. The result is StatusUnwinnable only when the whole reachable
+// space was exhausted within the budget.
+func Solve(layout Layout, budget Budget) (Result, error) {
+	start, err := newState(layout)
+	if err != nil {
+		return Result{}, err
+	}
+
+	sv := &solver{budget: budget, seen: make(map[string]struct{})}
+	if budget.Timeout > 0 {
+		sv.deadline = time.Now().Add(budget.Timeout)
+	}
+
+	if sv.search(start) {
+		return Result{Status: StatusSolved, Moves: sv.path, Nodes: sv.nodes}, nil
+	}
+	if sv.exceeded {
+		return Result{Status: StatusUnknown, Nodes: sv.nodes}, nil
+	}
+	return Result{Status: StatusUnwinnable, Nodes: sv.nodes}, nil
+}
+
+func (sv *solver) search(s *state) bool {
+	if s.won() {
+		return true
+	}
+	if sv.exhausted() {
+		return false
+	}
+
+	key := s.key()
+	if _, ok := sv.seen[key]; ok {
+		return false
+	}
+	sv.seen[key] = struct{}{}
+	sv.nodes++
+
+	for _, m := range s.moves() {
+		next, err := s.apply(m)
+		if err != nil {
+			continue
+		}
+		sv.path = append(sv.path, m)
+		if sv.search(next) {
+			return true
+		}
+		sv.path = sv.path[:len(sv.path)-1]
+		if sv.exceeded {
+			return false
+		}
+	}
+	return false
+}
+
+func (sv *solver) exhausted() bool {
+	if sv.exceeded {
+		return true
+	}
+	if sv.budget.MaxNodes > 0 && sv.nodes >= sv.budget.MaxNodes {
+		sv.exceeded = true
+	}
+	if !sv.deadline.IsZero() && sv.nodes%256 == 0 && time.Now().After(sv.deadline) {
+		sv.exceeded = true
+	}
+	return sv.exceeded
+}
+
+// Replay applies moves to a layout and returns the resulting position, failing
+// on the first illegal move.
+func Replay(layout Layout, moves []Move) (Layout, error) {
+	s, err := newState(layout)
+	if err != nil {
+		return Layout{}, err
+	}
+	for _, m := range moves {
+		if s, err = s.apply(m); err != nil {
+			return Layout{}, err
+		}
+	}
+	return s.layout(), nil
+}
@@ -0,0 +1,164 @@
+package solitaire
+
+import (
+	"testing"
+	"time"
+
+	"cardGame/deck/model"
+)
+
+func TestDeal(t *testing.T) {
+	t.Run("Klondike", func(t *testing.T) {
+		layout, err := Deal(Klondike, model.NewSeededDeck(1, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, pile := range layout.Tableau {
+			if len(pile) != i+1 || layout.FaceDown[i] != i {
+				t.Errorf("Unexpected tableau pile %v: %v cards, %v face down", i, len(pile), layout.FaceDown[i])
+			}
+		}
+		if len(layout.Stock) != 24 {
+			t.Errorf("Unexpected stock size: got %v want 24", len(layout.Stock))
+		}
+	})
+
+	t.Run("FreeCell", func(t *testing.T) {
+		layout, err := Deal(FreeCell, model.NewSeededDeck(1, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(layout.Tableau[0]) != 7 || len(layout.Tableau[7]) != 6 {
+			t.Errorf("Unexpected cascade sizes: %v and %v", len(layout.Tableau[0]), len(layout.Tableau[7]))
+		}
+	})
+
+	t.Run("Partial Deck", func(t *testing.T) {
+		if _, err := Deal(Klondike, model.NewDeck(false, "AS,KD")); err == nil {
+			t.Errorf("Deal accepted a partial deck")
+		}
+	})
+}
+
+func TestReplay_SupermoveCapacity(t *testing.T) {
+	cards := func(codes string) []model.Card { return model.NewDeck(false, codes).Cards }
+	// A run of three on pile 0 can move onto the ten on pile 1. The rest of
+	// the deck fills the cells and the other piles.
+	layout := Layout{Variant: FreeCell, Tableau: make([][]model.Card, 8)}
+	layout.Tableau[0], layout.Tableau[1] = cards("9H,8S,7H"), cards("1S")
+	rest := 0
+	for _, c := range model.NewDeck(false, "").Cards {
+		switch {
+		case c.Code == "9H" || c.Code == "8S" || c.Code == "7H" || c.Code == "1S":
+		case len(layout.Cells) < 4:
+			layout.Cells = append(layout.Cells, c)
+		default:
+			pile := 2 + rest%6
+			layout.Tableau[pile] = append(layout.Tableau[pile], c)
+			rest++
+		}
+	}
+	move := Move{From: PileTableau, FromIndex: 0, To: PileTableau, ToIndex: 1, Count: 3}
+	if _, err := Replay(layout, []Move{move}); err == nil {
+		t.Errorf("Replay moved 3 cards with no free cells or empty piles")
+	}
+
+	layout.Tableau[2] = append(layout.Tableau[2], layout.Cells[:2]...)
+	layout.Cells = layout.Cells[2:]
+	if _, err := Replay(layout, []Move{move}); err != nil {
+		t.Errorf("Replay refused a move with 2 free cells: %v", err)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	t.Run("Nearly Finished FreeCell", func(t *testing.T) {
+		layout := finishedLayout(FreeCell)
+		kings := []model.Card{}
+		for suit := range layout.Foundations {
+			pile := layout.Foundations[suit]
+			kings = append(kings, pile[len(pile)-1])
+			layout.Foundations[suit] = pile[:len(pile)-1]
+		}
+		layout.Tableau[0] = kings
+
+		result, err := Solve(layout, Budget{MaxNodes: 1000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != StatusSolved || len(result.Moves) != 4 {
+			t.Fatalf("Unexpected result: %v with %v moves", result.Status, len(result.Moves))
+		}
+
+		final, err := Replay(layout, result.Moves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for suit, pile := range final.Foundations {
+			if len(pile) != 13 {
+				t.Errorf("Foundation %v not complete after replay", suit)
+			}
+		}
+	})
+
+	t.Run("Seeded FreeCell Deal", func(t *testing.T) {
+		layout, _ := Deal(FreeCell, model.NewSeededDeck(2, ""))
+		result, err := Solve(layout, Budget{MaxNodes: 200000, Timeout: 5 * time.Second})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != StatusSolved {
+			t.Fatalf("Unexpected status: got %v want %v", result.Status, StatusSolved)
+		}
+		if _, err := Replay(layout, result.Moves); err != nil {
+			t.Errorf("Solution does not replay: %v", err)
+		}
+	})
+
+	t.Run("Blocked Klondike", func(t *testing.T) {
+		layout := finishedLayout(Klondike)
+		layout.Foundations[0] = nil
+		spades := model.NewDeck(false, "2S,3S,4S,5S,6S,7S,8S,9S,1S,JS,QS,KS,AS").Cards
+		layout.Tableau[0] = spades
+		layout.FaceDown = []int{12, 0, 0, 0, 0, 0, 0}
+
+		result, err := Solve(layout, Budget{MaxNodes: 1000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != StatusUnwinnable {
+			t.Errorf("Unexpected status: got %v want %v", result.Status, StatusUnwinnable)
+		}
+	})
+
+	t.Run("Budget Exceeded", func(t *testing.T) {
+		layout, _ := Deal(Klondike, model.NewSeededDeck(3, ""))
+		result, err := Solve(layout, Budget{MaxNodes: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status == StatusUnwinnable {
+			t.Errorf("Search reported unwinnable without exhausting the position")
+		}
+	})
+
+	t.Run("Invalid Layout", func(t *testing.T) {
+		if _, err := Solve(Layout{Variant: FreeCell, Tableau: make([][]model.Card, 8)}, Budget{}); err == nil {
+			t.Errorf("Solve accepted a layout without cards")
+		}
+	})
+}
+
+func finishedLayout(variant Variant) Layout {
+	layout := Layout{Variant: variant, Foundations: make([][]model.Card, 4)}
+	if variant == Klondike {
+		layout.Tableau = make([][]model.Card, 7)
+	} else {
+		layout.Tableau = make([][]model.Card, 8)
+	}
+	for suit := range model.Suits {
+		for rank := 1; rank <= 13; rank++ {
+			layout.Foundations[suit] = append(layout.Foundations[suit], card(rank<<2|suit).model())
+		}
+	}
+	return layout
+}
@@ -0,0 +1,487 @@
+package solitaire
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cardGame/deck/model"
+)
+
+type state struct {
+	variant     Variant
+	drawCount   int
+	tableau     [][]card
+	faceDown    []int
+	stock       []card
+	waste       []card
+	foundations [4]int
+	cells       []card
+}
+
+func newState(layout Layout) (*state, error) {
+	s := &state{variant: layout.Variant, drawCount: layout.DrawCount}
+	if s.drawCount <= 0 {
+		s.drawCount = 1
+	}
+
+	seen := make(map[card]bool)
+	track := func(cards []card) error {
+		for _, c := range cards {
+			if seen[c] {
+				return fmt.Errorf("Card %q appears more than once", c.model().Code)
+			}
+			seen[c] = true
+		}
+		return nil
+	}
+
+	switch layout.Variant {
+	case Klondike:
+		if len(layout.Tableau) != 7 {
+			return nil, fmt.Errorf("Klondike needs 7 tableau piles")
+		}
+	case FreeCell:
+		if len(layout.Tableau) != 8 {
+			return nil, fmt.Errorf("FreeCell needs 8 tableau piles")
+		}
+		if len(layout.Cells) > freeCellCount {
+			return nil, fmt.Errorf("FreeCell has only %d cells", freeCellCount)
+		}
+	default:
+		return nil, fmt.Errorf("Unknown solitaire variant %q", layout.Variant)
+	}
+
+	for i, pile := range layout.Tableau {
+		cards, err := toCards(pile)
+		if err != nil {
+			return nil, err
+		}
+		if err := track(cards); err != nil {
+			return nil, err
+		}
+		s.tableau = append(s.tableau, cards)
+
+		down := 0
+		if i < len(layout.FaceDown) {
+			down = layout.FaceDown[i]
+		}
+		if down < 0 || (down >= len(cards) && len(cards) > 0) {
+			return nil, fmt.Errorf("Invalid face down count for tableau pile %d", i)
+		}
+		s.faceDown = append(s.faceDown, down)
+	}
+
+	var err error
+	if s.stock, err = toCards(layout.Stock); err != nil {
+		return nil, err
+	}
+	if s.waste, err = toCards(layout.Waste); err != nil {
+		return nil, err
+	}
+	if err := track(s.stock); err != nil {
+		return nil, err
+	}
+	if err := track(s.waste); err != nil {
+		return nil, err
+	}
+
+	s.cells = make([]card, freeCellCount)
+	for i, c := range layout.Cells {
+		if c.Code == "" {
+			continue
+		}
+		packed, err := newCard(c)
+		if err != nil {
+			return nil, err
+		}
+		if err := track([]card{packed}); err != nil {
+			return nil, err
+		}
+		s.cells[i] = packed
+	}
+
+	for _, pile := range layout.Foundations {
+		cards, err := toCards(pile)
+		if err != nil {
+			return nil, err
+		}
+		if err := track(cards); err != nil {
+			return nil, err
+		}
+		for i, c := range cards {
+			if c.rank() != i+1 || c.suit() != cards[0].suit() {
+				return nil, fmt.Errorf("Foundation piles must be built up by suit from ACE")
+			}
+		}
+		if len(cards) > 0 {
+			s.foundations[cards[0].suit()] = len(cards)
+		}
+	}
+
+	if len(seen) != 52 {
+		return nil, fmt.Errorf("Layout must account for all 52 cards, got %d", len(seen))
+	}
+
+	return s, nil
+}
+
+func (s *state) clone() *state {
+	c := *s
+	c.tableau = make([][]card, len(s.tableau))
+	for i, pile := range s.tableau {
+		c.tableau[i] = append([]card(nil), pile...)
+	}
+	c.faceDown = append([]int(nil), s.faceDown...)
+	c.stock = append([]card(nil), s.stock...)
+	c.waste = append([]card(nil), s.waste...)
+	c.cells = append([]card(nil), s.cells...)
+	return &c
+}
+
+func (s *state) won() bool {
+	for _, top := range s.foundations {
+		if top != 13 {
+			return false
+		}
+	}
+	return true
+}
+
+// key renders the position canonically. Tableau piles and free cells are
+// interchangeable, so they are sorted to let the search treat mirrored
+// positions as one.
+func (s *state) key() string {
+	piles := make([]string, len(s.tableau))
+	for i, pile := range s.tableau {
+		var b strings.Builder
+		b.WriteByte(byte('0' + s.faceDown[i]))
+		for _, c := range pile {
+			b.WriteByte(byte(c))
+		}
+		piles[i] = b.String()
+	}
+	sort.Strings(piles)
+
+	cells := append([]card(nil), s.cells...)
+	sort.Slice(cells, func(i, j int) bool { return cells[i] < cells[j] })
+
+	var b strings.Builder
+	for _, top := range s.foundations {
+		b.WriteByte(byte(top))
+	}
+	for _, c := range cells {
+		b.WriteByte(byte(c))
+	}
+	b.WriteByte('|')
+	for _, c := range s.stock {
+		b.WriteByte(byte(c))
+	}
+	b.WriteByte('|')
+	for _, c := range s.waste {
+		b.WriteByte(byte(c))
+	}
+	for _, pile := range piles {
+		b.WriteByte('|')
+		b.WriteString(pile)
+	}
+	return b.String()
+}
+
+func (s *state) layout() Layout {
+	layout := Layout{Variant: s.variant, Tableau: make([][]model.Card, len(s.tableau)), Foundations: make([][]model.Card, 4)}
+	for i, pile := range s.tableau {
+		layout.Tableau[i] = fromCards(pile)
+	}
+	for suit, top := range s.foundations {
+		for rank := 1; rank <= top; rank++ {
+			layout.Foundations[suit] = append(layout.Foundations[suit], card(rank<<2|suit).model())
+		}
+	}
+	if s.variant == Klondike {
+		layout.DrawCount = s.drawCount
+		layout.FaceDown = append([]int(nil), s.faceDown...)
+		layout.Stock = fromCards(s.stock)
+		layout.Waste = fromCards(s.waste)
+	} else {
+		layout.Cells = make([]model.Card, len(s.cells))
+		for i, c := range s.cells {
+			if !c.none() {
+				layout.Cells[i] = c.model()
+			}
+		}
+	}
+	return layout
+}
+
+func (s *state) canFound(c card) bool {
+	return !c.none() && s.foundations[c.suit()] == c.rank()-1
+}
+
+func (s *state) top(pile int) card {
+	cards := s.tableau[pile]
+	if len(cards) == 0 {
+		return 0
+	}
+	return cards[len(cards)-1]
+}
+
+// movableRun returns how many cards from the top of a tableau pile form a
+// face-up alternating sequence.
+func (s *state) movableRun(pile int) int {
+	cards := s.tableau[pile]
+	if len(cards) == 0 {
+		return 0
+	}
+	run := 1
+	for i := len(cards) - 1; i > s.faceDown[pile]; i-- {
+		if !cards[i].stacksOn(cards[i-1]) {
+			break
+		}
+		run++
+	}
+	return run
+}
+
+// capacity is how many cards may move together onto tableau pile to: any
+// number in Klondike, and in FreeCell one more than the free cells, doubled
+// for each empty pile other than the destination.
+func (s *state) capacity(to int) int {
+	if s.variant != FreeCell {
+		return 52
+	}
+	empty := s.emptyPiles()
+	if s.top(to).none() {
+		empty--
+	}
+	return (s.freeCells() + 1) << empty
+}
+
+func (s *state) freeCells() int {
+	free := 0
+	for _, c := range s.cells {
+		if c.none() {
+			free++
+		}
+	}
+	return free
+}
+
+func (s *state) emptyPiles() int {
+	empty := 0
+	for _, pile := range s.tableau {
+		if len(pile) == 0 {
+			empty++
+		}
+	}
+	return empty
+}
+
+// moves lists legal moves, most promising first.
+func (s *state) moves() []Move {
+	var foundation, tableau, rest []Move
+
+	for i := range s.tableau {
+		if s.canFound(s.top(i)) {
+			foundation = append(foundation, Move{From: PileTableau, FromIndex: i, To: PileFoundation, ToIndex: s.top(i).suit(), Count: 1})
+		}
+	}
+	for i, c := range s.cells {
+		if s.canFound(c) {
+			foundation = append(foundation, Move{From: PileCell, FromIndex: i, To: PileFoundation, ToIndex: c.suit(), Count: 1})
+		}
+	}
+	if len(s.waste) > 0 && s.canFound(s.waste[len(s.waste)-1]) {
+		top := s.waste[len(s.waste)-1]
+		foundation = append(foundation, Move{From: PileWaste, To: PileFoundation, ToIndex: top.suit(), Count: 1})
+	}
+
+	for from := range s.tableau {
+		run := s.movableRun(from)
+		pile := s.tableau[from]
+		for to := range s.tableau {
+			if to == from {
+				continue
+			}
+			target := s.top(to)
+			capacity := s.capacity(to)
+			for count := 1; count <= run && count <= capacity; count++ {
+				moving := pile[len(pile)-count]
+				if target.none() {
+					if s.variant == Klondike && moving.rank() != 13 {
+						continue
+					}
+					if count == len(pile) && s.faceDown[from] == 0 {
+						continue
+					}
+				} else if !moving.stacksOn(target) {
+					continue
+				}
+				tableau = append(tableau, Move{From: PileTableau, FromIndex: from, To: PileTableau, ToIndex: to, Count: count})
+			}
+		}
+	}
+
+	if len(s.waste) > 0 {
+		top := s.waste[len(s.waste)-1]
+		for to := range s.tableau {
+			target := s.top(to)
+			if (target.none() && top.rank() == 13) || (!target.none() && top.stacksOn(target)) {
+				tableau = append(tableau, Move{From: PileWaste, To: PileTableau, ToIndex: to, Count: 1})
+			}
+		}
+	}
+
+	for i, c := range s.cells {
+		if c.none() {
+			continue
+		}
+		emptyUsed := false
+		for to := range s.tableau {
+			target := s.top(to)
+			if target.none() {
+				if emptyUsed {
+					continue
+				}
+				emptyUsed = true
+			} else if !c.stacksOn(target) {
+				continue
+			}
+			tableau = append(tableau, Move{From: PileCell, FromIndex: i, To: PileTableau, ToIndex: to, Count: 1})
+		}
+	}
+
+	if s.variant == FreeCell {
+		for i, c := range s.cells {
+			if !c.none() {
+				continue
+			}
+			for from := range s.tableau {
+				if len(s.tableau[from]) > 0 {
+					rest = append(rest, Move{From: PileTableau, FromIndex: from, To: PileCell, ToIndex: i, Count: 1})
+				}
+			}
+			break
+		}
+	}
+
+	if s.variant == Klondike {
+		for suit, top := range s.foundations {
+			if top < 2 {
+				continue
+			}
+			c := card(top<<2 | suit)
+			for to := range s.tableau {
+				target := s.top(to)
+				if !target.none() && c.stacksOn(target) {
+					rest = append(rest, Move{From: PileFoundation, FromIndex: suit, To: PileTableau, ToIndex: to, Count: 1})
+				}
+			}
+		}
+		if len(s.stock) > 0 {
+			count := s.drawCount
+			if count > len(s.stock) {
+				count = len(s.stock)
+			}
+			rest = append(rest, Move{From: PileStock, To: PileWaste, Count: count})
+		} else if len(s.waste) > 0 {
+			rest = append(rest, Move{From: PileWaste, To: PileStock, Count: len(s.waste)})
+		}
+	}
+
+	moves := append(foundation, tableau...)
+	return append(moves, rest...)
+}
+
+func (s *state) apply(m Move) (*state, error) {
+	next := s.clone()
+	var moving []card
+
+	switch m.From {
+	case PileTableau:
+		if m.FromIndex < 0 || m.FromIndex >= len(next.tableau) {
+			return nil, fmt.Errorf("Invalid tableau pile %d", m.FromIndex)
+		}
+		pile := next.tableau[m.FromIndex]
+		if m.Count < 1 || m.Count > s.movableRun(m.FromIndex) {
+			return nil, fmt.Errorf("Cannot move %d cards from tableau pile %d", m.Count, m.FromIndex)
+		}
+		moving = append(moving, pile[len(pile)-m.Count:]...)
+		next.tableau[m.FromIndex] = pile[:len(pile)-m.Count]
+		if down := next.faceDown[m.FromIndex]; down > 0 && down == len(next.tableau[m.FromIndex]) {
+			next.faceDown[m.FromIndex]--
+		}
+	case PileWaste:
+		if m.To == PileStock {
+			if len(next.stock) > 0 || len(next.waste) == 0 {
+				return nil, fmt.Errorf("Stock can only be redealt when empty")
+			}
+			for i := len(next.waste) - 1; i >= 0; i-- {
+				next.stock = append(next.stock, next.waste[i])
+			}
+			next.waste = nil
+			return next, nil
+		}
+		if len(next.waste) == 0 {
+			return nil, fmt.Errorf("Waste is empty")
+		}
+		moving = []card{next.waste[len(next.waste)-1]}
+		next.waste = next.waste[:len(next.waste)-1]
+	case PileStock:
+		if len(next.stock) == 0 || m.To != PileWaste {
+			return nil, fmt.Errorf("Cannot draw from the stock")
+		}
+		for i := 0; i < next.drawCount && len(next.stock) > 0; i++ {
+			next.waste = append(next.waste, next.stock[len(next.stock)-1])
+			next.stock = next.stock[:len(next.stock)-1]
+		}
+		return next, nil
+	case PileCell:
+		if m.FromIndex < 0 || m.FromIndex >= len(next.cells) || next.cells[m.FromIndex].none() {
+			return nil, fmt.Errorf("Cell %d is empty", m.FromIndex)
+		}
+		moving = []card{next.cells[m.FromIndex]}
+		next.cells[m.FromIndex] = 0
+	case PileFoundation:
+		if m.FromIndex < 0 || m.FromIndex > 3 || next.foundations[m.FromIndex] == 0 {
+			return nil, fmt.Errorf("Foundation %d is empty", m.FromIndex)
+		}
+		moving = []card{card(next.foundations[m.FromIndex]<<2 | m.FromIndex)}
+		next.foundations[m.FromIndex]--
+	default:
+		return nil, fmt.Errorf("Unknown pile %q", m.From)
+	}
+
+	switch m.To {
+	case PileTableau:
+		if m.ToIndex < 0 || m.ToIndex >= len(next.tableau) {
+			return nil, fmt.Errorf("Invalid tableau pile %d", m.ToIndex)
+		}
+		if capacity := s.capacity(m.ToIndex); len(moving) > capacity {
+			return nil, fmt.Errorf("Only %d cards can move to tableau pile %d", capacity, m.ToIndex)
+		}
+		target := next.top(m.ToIndex)
+		if target.none() {
+			if next.variant == Klondike && moving[0].rank() != 13 {
+				return nil, fmt.Errorf("Only a KING can fill an empty tableau pile")
+			}
+		} else if !moving[0].stacksOn(target) {
+			return nil, fmt.Errorf("Cannot stack %s on %s", moving[0].model().Code, target.model().Code)
+		}
+		next.tableau[m.ToIndex] = append(next.tableau[m.ToIndex], moving...)
+	case PileFoundation:
+		if len(moving) != 1 || !next.canFound(moving[0]) {
+			return nil, fmt.Errorf("Cannot move to the foundation")
+		}
+		next.foundations[moving[0].suit()]++
+	case PileCell:
+		if next.variant != FreeCell || len(moving) != 1 || m.ToIndex < 0 || m.ToIndex >= len(next.cells) || !next.cells[m.ToIndex].none() {
+			return nil, fmt.Errorf("Cannot move to cell %d", m.ToIndex)
+		}
+		next.cells[m.ToIndex] = moving[0]
+	default:
+		return nil, fmt.Errorf("Cannot move to %q", m.To)
+	}
+
+	return next, nil
+}
@@ -0,0 +1,154 @@
+package spectate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"cardGame/deck/replay"
+)
+
+// DefaultSize is how many frames a feed keeps.
+const DefaultSize = 1024
+
+// MaxDelay is the longest broadcast delay a feed may have.
+const MaxDelay = time.Hour
+
+// Role is how much a spectator sees.
+type Role string
+
+const (
+	// Spectator sees what is public.
+	Spectator Role = "spectator"
+	// Commentator also sees every player's hole cards.
+	Commentator Role = "commentator"
+)
+
+// ParseRole reads a role, defaulting to Spectator.
+func ParseRole(s string) (Role, error) {
+	switch Role(s) {
+	case "", Spectator:
+		return Spectator, nil
+	case Commentator:
+		return Commentator, nil
+	}
+	return "", fmt.Errorf("Unknown role %q", s)
+}
+
+// Frame is a game or deck as it stood after one change, numbered from 1 by
+// its feed. The first frame of a feed shows how things stood when it
+// started; the later ones follow one change each. Public is what
+// anyone may see and Revealed adds the cards only their players may see.
+// Both are encoded when the frame is taken, so later changes cannot leak
+// into it.
+type Frame struct {
+	Seq      int             `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Event    *replay.Event   `json:"event,omitempty"`
+	Public   json.RawMessage `json:"public"`
+	Revealed json.RawMessage `json:"revealed,omitempty"`
+}
+
+// Encode encodes a view for a frame. Views are served as JSON already, so
+// they always encode.
+func Encode(view interface{}) json.RawMessage {
+	encoded, _ := json.Marshal(view)
+	return encoded
+}
+
+// For returns the frame as role may see it. Spectators get neither the
+// revealed view nor the cards in the event's action.
+func (f Frame) For(role Role) Frame {
+	if role == Commentator {
+		return f
+	}
+	f.Revealed = nil
+	if f.Event != nil && f.Event.Action != nil {
+		event, action := *f.Event, *f.Event.Action
+		action.Cards = nil
+		event.Action = &action
+		f.Event = &event
+	}
+	return f
+}
+
+// Feed keeps the latest frames of one game or deck in a ring and releases
+// each only once it is older than the feed's delay, so a broadcast cannot
+// be used to help a player at the table. It is safe for concurrent use.
+type Feed struct {
+	mu     sync.Mutex
+	frames []Frame
+	// next is where the next frame goes, and count how many are kept.
+	next, count int
+	latest      int
+	// dropped is the newest frame that is not kept.
+	dropped int
+	delay   time.Duration
+}
+
+func NewFeed(size int, delay time.Duration) *Feed {
+	if size < 1 {
+		size = DefaultSize
+	}
+	return &Feed{frames: make([]Frame, size), delay: delay}
+}
+
+// Append numbers frame and adds it.
+func (f *Feed) Append(frame Frame) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	frame.Seq = f.latest + 1
+	if f.count == len(f.frames) {
+		f.dropped = f.frames[f.next].Seq
+	} else {
+		f.count++
+	}
+	f.frames[f.next] = frame
+	f.next = (f.next + 1) % len(f.frames)
+	f.latest = frame.Seq
+}
+
+// Latest returns the number of the last frame taken, seen or not.
+func (f *Feed) Latest() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.latest
+}
+
+func (f *Feed) Delay() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.delay
+}
+
+func (f *Feed) SetDelay(delay time.Duration) error {
+	if delay < 0 || delay > MaxDelay {
+		return fmt.Errorf("Delay must be between 0 and %v", MaxDelay)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delay = delay
+	return nil
+}
+
+// Since returns the frames after seq that are at least the delay old at
+// now, oldest first. It reports false if some frames after seq are no
+// longer kept, in which case the last frame returned is the one to show.
+func (f *Feed) Since(seq int, now time.Time) ([]Frame, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cutoff := now.Add(-f.delay)
+	frames := []Frame{}
+	first := (f.next - f.count + len(f.frames)) % len(f.frames)
+	for i := 0; i < f.count; i++ {
+		frame := f.frames[(first+i)%len(f.frames)]
+		if frame.Time.After(cutoff) {
+			break
+		}
+		if frame.Seq > seq {
+			frames = append(frames, frame)
+		}
+	}
+	return frames, f.dropped <= seq
+}
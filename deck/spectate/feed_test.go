@@ -0,0 +1,71 @@
+package spectate
+
+import (
+	"testing"
+	"time"
+
+	"cardGame/deck/game"
+	"cardGame/deck/replay"
+)
+
+func TestParseRole(t *testing.T) {
+	if role, err := ParseRole(""); role != Spectator || err != nil {
+		t.Errorf("Empty role = %v %v", role, err)
+	}
+	if role, err := ParseRole("commentator"); role != Commentator || err != nil {
+		t.Errorf("commentator = %v %v", role, err)
+	}
+	if _, err := ParseRole("player"); err == nil {
+		t.Errorf("Unknown role parsed")
+	}
+}
+
+func TestFrameFor(t *testing.T) {
+	frame := Frame{
+		Seq:      1,
+		Event:    &replay.Event{Seq: 1, Player: "ann", Action: &game.Action{Type: "play", Cards: []string{"8C"}}},
+		Public:   Encode(map[string]int{"size": 1}),
+		Revealed: Encode(map[string][]string{"ann": {"8C"}}),
+	}
+	if shown := frame.For(Commentator); shown.Revealed == nil || shown.Event.Action.Cards == nil {
+		t.Errorf("Commentator should see everything: %+v", shown)
+	}
+	shown := frame.For(Spectator)
+	if shown.Revealed != nil || shown.Event.Action.Cards != nil || shown.Event.Action.Type != "play" {
+		t.Errorf("Spectator saw hidden cards: %+v %+v", shown, shown.Event.Action)
+	}
+	if frame.Event.Action.Cards == nil {
+		t.Errorf("Redacting changed the frame")
+	}
+}
+
+func TestFeed(t *testing.T) {
+	start := time.Unix(0, 0)
+	f := NewFeed(3, 10*time.Second)
+	if err := f.SetDelay(-time.Second); err == nil {
+		t.Errorf("Negative delay set")
+	}
+	for seq := 1; seq <= 4; seq++ {
+		f.Append(Frame{Time: start.Add(time.Duration(seq) * time.Second)})
+	}
+	if f.Latest() != 4 {
+		t.Errorf("Latest %v, want 4", f.Latest())
+	}
+
+	if frames, complete := f.Since(0, start.Add(5*time.Second)); len(frames) != 0 || complete {
+		t.Errorf("Frames shown before the delay: %v %v", frames, complete)
+	}
+	frames, complete := f.Since(2, start.Add(13*time.Second))
+	if !complete || len(frames) != 1 || frames[0].Seq != 3 {
+		t.Errorf("Since(2) = %v %v", frames, complete)
+	}
+	if frames, complete := f.Since(0, start.Add(time.Minute)); complete || len(frames) != 3 || frames[2].Seq != 4 {
+		t.Errorf("Since(0) should report the dropped frame: %v %v", frames, complete)
+	}
+
+	f.SetDelay(0)
+	if frames, _ := f.Since(3, start.Add(4*time.Second)); len(frames) != 1 {
+		t.Errorf("Without a delay frames should show at once: %v", frames)
+	}
+
+}
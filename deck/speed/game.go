@@ -0,0 +1,283 @@
+package speed
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+const (
+	HandSize  = 5
+	StockSize = 15
+	SideSize  = 5
+)
+
+// Piles are the two center piles, named by the action's value.
+var Piles = []string{"left", "right"}
+
+// Game plays two-player Speed for the game registry. There are no turns:
+// either player may play a card one rank above or below the top of either
+// center pile at any time, aces next to both kings and twos, and refills
+// their hand from their stock. The host applies actions one at a time, so
+// of two racing plays the first to arrive is applied and the second is
+// checked against the pile it left; it fails if the card no longer fits.
+// When neither player can play, both flip to turn a new card from each side
+// pile onto the center.
+type Game struct {
+	rng     *rand.Rand
+	players []string
+	hands   [2][]model.Card
+	stocks  [2][]model.Card
+	// sides are the two side piles and center the two center piles, top
+	// card last.
+	sides   [2][]model.Card
+	center  [2][]model.Card
+	flipped [2]bool
+	winner  int
+	over    bool
+}
+
+type View struct {
+	Hand      []model.Card   `json:"hand,omitempty"`
+	HandSizes map[string]int `json:"hand_sizes"`
+	Stocks    map[string]int `json:"stocks"`
+	Center    []model.Card   `json:"center"`
+	Sides     []int          `json:"sides"`
+	Stalled   bool           `json:"stalled"`
+	Flipped   []string       `json:"flipped"`
+	Winner    string         `json:"winner,omitempty"`
+}
+
+func NewGame() game.Game {
+	return &Game{}
+}
+
+// Setup deals each player a hand and a stock, then the side piles, and turns
+// one card from each side pile to start the center. Option "seed" fixes the
+// shuffle when the side piles run out.
+func (g *Game) Setup(deck model.Deck, config game.Config) error {
+	if len(config.Players) != 2 {
+		return fmt.Errorf("Speed needs 2 players")
+	}
+	if len(deck.Cards) != 52 {
+		return fmt.Errorf("Speed needs a full deck")
+	}
+
+	seed := time.Now().UnixNano()
+	if value, err := strconv.ParseInt(config.Options["seed"], 10, 64); err == nil {
+		seed = value
+	}
+	g.rng = rand.New(rand.NewSource(seed))
+	g.players = config.Players
+	g.winner = -1
+
+	cards := deck.Cards
+	deal := func(count int) []model.Card {
+		dealt := append([]model.Card{}, cards[:count]...)
+		cards = cards[count:]
+		return dealt
+	}
+	for seat := range g.players {
+		g.hands[seat] = deal(HandSize)
+		g.stocks[seat] = deal(StockSize)
+	}
+	for pile := range g.sides {
+		g.sides[pile] = deal(SideSize + 1)
+	}
+	g.turnUp()
+	return nil
+}
+
+func (g *Game) Players() []string {
+	return g.players
+}
+
+func (g *Game) seat(player string) int {
+	for i, p := range g.players {
+		if p == player {
+			return i
+		}
+	}
+	return -1
+}
+
+func pileIndex(name string) int {
+	for i, pile := range Piles {
+		if pile == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Fits reports whether c may be played on top: one rank either side, with
+// the ace between the king and the two.
+func Fits(c, top model.Card) bool {
+	diff := c.Rank() - top.Rank()
+	return diff == 1 || diff == -1 || diff == 12 || diff == -12
+}
+
+func (g *Game) top(pile int) model.Card {
+	return g.center[pile][len(g.center[pile])-1]
+}
+
+func (g *Game) plays(seat int) []game.Action {
+	var actions []game.Action
+	for _, c := range g.hands[seat] {
+		for pile, name := range Piles {
+			if Fits(c, g.top(pile)) {
+				actions = append(actions, game.Action{Type: "play", Cards: []string{c.Code}, Value: name})
+			}
+		}
+	}
+	return actions
+}
+
+func (g *Game) stalled() bool {
+	return len(g.plays(0)) == 0 && len(g.plays(1)) == 0
+}
+
+func (g *Game) LegalActions(player string) []game.Action {
+	seat := g.seat(player)
+	if seat < 0 || g.over {
+		return nil
+	}
+	if actions := g.plays(seat); len(actions) > 0 {
+		return actions
+	}
+	if g.stalled() && !g.flipped[seat] {
+		return []game.Action{{Type: "flip"}}
+	}
+	return nil
+}
+
+// Apply explains a play that fails because the pile changed under it, so a
+// player who lost a race can tell.
+func (g *Game) Apply(player string, action game.Action) error {
+	seat := g.seat(player)
+	if seat >= 0 && !g.over && action.Type == "play" && len(action.Cards) == 1 {
+		pile := pileIndex(action.Value)
+		for _, c := range g.hands[seat] {
+			if c.Code == action.Cards[0] && pile >= 0 && !Fits(c, g.top(pile)) {
+				return fmt.Errorf("%v no longer fits on the %v pile, now %v", c.Code, action.Value, g.top(pile).Code)
+			}
+		}
+	}
+	if !game.IsLegal(g, player, action) {
+		return fmt.Errorf("Illegal action %q for %v", action.Type, player)
+	}
+
+	switch action.Type {
+	case "play":
+		g.play(seat, action.Cards[0], pileIndex(action.Value))
+	case "flip":
+		g.flipped[seat] = true
+		if g.flipped[0] && g.flipped[1] {
+			g.flipped = [2]bool{}
+			g.turnUp()
+		}
+	}
+	return nil
+}
+
+func (g *Game) play(seat int, code string, pile int) {
+	hand := g.hands[seat]
+	for i, c := range hand {
+		if c.Code != code {
+			continue
+		}
+		g.hands[seat] = append(append([]model.Card{}, hand[:i]...), hand[i+1:]...)
+		g.center[pile] = append(g.center[pile], c)
+		break
+	}
+	for len(g.hands[seat]) < HandSize && len(g.stocks[seat]) > 0 {
+		g.hands[seat] = append(g.hands[seat], g.stocks[seat][0])
+		g.stocks[seat] = g.stocks[seat][1:]
+	}
+	if len(g.hands[seat]) == 0 {
+		g.winner, g.over = seat, true
+	}
+}
+
+// turnUp turns the top card of each side pile onto its center pile. Once the
+// side piles are used up, the center cards under the tops are shuffled to
+// make new ones. If there is nothing left to turn, the game ends and the
+// player holding fewer cards wins.
+func (g *Game) turnUp() {
+	if len(g.sides[0]) == 0 && len(g.sides[1]) == 0 {
+		var cards []model.Card
+		for pile := range g.center {
+			if n := len(g.center[pile]); n > 1 {
+				cards = append(cards, g.center[pile][:n-1]...)
+				g.center[pile] = g.center[pile][n-1:]
+			}
+		}
+		g.rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+		half := (len(cards) + 1) / 2
+		g.sides[0], g.sides[1] = cards[:half], cards[half:]
+	}
+	if len(g.sides[0]) == 0 && len(g.sides[1]) == 0 {
+		g.over = true
+		switch left0, left1 := g.left(0), g.left(1); {
+		case left0 < left1:
+			g.winner = 0
+		case left1 < left0:
+			g.winner = 1
+		}
+		return
+	}
+
+	for pile := range g.sides {
+		if n := len(g.sides[pile]); n > 0 {
+			g.center[pile] = append(g.center[pile], g.sides[pile][n-1])
+			g.sides[pile] = g.sides[pile][:n-1]
+		}
+	}
+}
+
+func (g *Game) left(seat int) int {
+	return len(g.hands[seat]) + len(g.stocks[seat])
+}
+
+func (g *Game) Terminal() bool {
+	return g.over
+}
+
+// Scores give the winner a point for each card the loser still holds. A
+// drawn game scores nothing.
+func (g *Game) Scores() map[string]int {
+	scores := map[string]int{g.players[0]: 0, g.players[1]: 0}
+	if g.winner >= 0 {
+		scores[g.players[g.winner]] = g.left(1 - g.winner)
+	}
+	return scores
+}
+
+func (g *Game) View(player string) interface{} {
+	view := View{
+		HandSizes: make(map[string]int),
+		Stocks:    make(map[string]int),
+		Center:    []model.Card{g.top(0), g.top(1)},
+		Sides:     []int{len(g.sides[0]), len(g.sides[1])},
+		Stalled:   !g.over && g.stalled(),
+		Flipped:   []string{},
+	}
+	for seat, p := range g.players {
+		view.HandSizes[p] = len(g.hands[seat])
+		view.Stocks[p] = len(g.stocks[seat])
+		if g.flipped[seat] {
+			view.Flipped = append(view.Flipped, p)
+		}
+	}
+	if g.winner >= 0 {
+		view.Winner = g.players[g.winner]
+	}
+	if seat := g.seat(player); seat >= 0 {
+		view.Hand = g.hands[seat]
+	}
+	return view
+}
@@ -0,0 +1,146 @@
+package speed
+
+import (
+	"strings"
+	"testing"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+// arranged returns a full deck with the given cards at the given positions
+// and the rest in order around them. Ann's hand is dealt from position 0,
+// Bob's from 20, and the left and right center cards are 45 and 51.
+func arranged(positions map[int]string) model.Deck {
+	slots := make([]string, 52)
+	used := make(map[string]bool)
+	for position, code := range positions {
+		slots[position] = code
+		used[code] = true
+	}
+	rest := model.NewDeck(false, "").Cards
+	for i := range slots {
+		for slots[i] == "" {
+			if c := rest[0]; !used[c.Code] {
+				slots[i] = c.Code
+			}
+			rest = rest[1:]
+		}
+	}
+	return model.NewDeck(false, strings.Join(slots, ","))
+}
+
+func setup(t *testing.T, deck model.Deck) *Game {
+	t.Helper()
+	g := NewGame().(*Game)
+	if err := g.Setup(deck, game.Config{Players: []string{"ann", "bob"}, Options: map[string]string{"seed": "1"}}); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestFits(t *testing.T) {
+	tests := []struct {
+		play, top string
+		fits      bool
+	}{
+		{"8C", "7H", true},
+		{"6C", "7H", true},
+		{"7C", "7H", false},
+		{"AS", "KD", true},
+		{"AS", "2D", true},
+		{"2S", "KD", false},
+	}
+	for _, test := range tests {
+		play, _ := model.CardFromCode(test.play)
+		top, _ := model.CardFromCode(test.top)
+		if got := Fits(play, top); got != test.fits {
+			t.Errorf("Fits(%v, %v) = %v, want %v", test.play, test.top, got, test.fits)
+		}
+	}
+}
+
+func TestRacingPlays(t *testing.T) {
+	g := setup(t, arranged(map[int]string{0: "8C", 1: "AS", 20: "6C", 45: "7H", 51: "KD"}))
+
+	view := g.View("ann").(View)
+	if view.Center[0].Code != "7H" || view.Center[1].Code != "KD" || view.Sides[0] != SideSize || view.Stocks["ann"] != StockSize {
+		t.Fatalf("Unexpected deal: %+v", view)
+	}
+	if !game.IsLegal(g, "bob", game.Action{Type: "play", Cards: []string{"6C"}, Value: "left"}) {
+		t.Fatalf("Bob should be able to play 6C on the left")
+	}
+
+	if err := g.Apply("ann", game.Action{Type: "play", Cards: []string{"8C"}, Value: "left"}); err != nil {
+		t.Fatal(err)
+	}
+	err := g.Apply("bob", game.Action{Type: "play", Cards: []string{"6C"}, Value: "left"})
+	if err == nil || !strings.Contains(err.Error(), "no longer fits") {
+		t.Errorf("Bob's stale play was not rejected: %v", err)
+	}
+	if err := g.Apply("ann", game.Action{Type: "play", Cards: []string{"AS"}, Value: "right"}); err != nil {
+		t.Fatal(err)
+	}
+
+	view = g.View("ann").(View)
+	if view.Center[0].Code != "8C" || view.Center[1].Code != "AS" || len(view.Hand) != HandSize || view.Stocks["ann"] != StockSize-2 {
+		t.Errorf("Unexpected view after plays: %+v", view)
+	}
+}
+
+func TestStalledFlip(t *testing.T) {
+	g := setup(t, arranged(map[int]string{45: "7H", 51: "KD"}))
+	g.hands[0] = model.NewDeck(false, "3C,4C").Cards
+	g.hands[1] = model.NewDeck(false, "9C,1C").Cards
+
+	if actions := g.LegalActions("ann"); len(actions) != 1 || actions[0].Type != "flip" {
+		t.Fatalf("Ann should only be able to flip: %+v", actions)
+	}
+	if err := g.Apply("ann", game.Action{Type: "flip"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.LegalActions("ann")) != 0 || g.View("").(View).Center[0].Code != "7H" {
+		t.Fatalf("Center turned before Bob flipped")
+	}
+	if err := g.Apply("bob", game.Action{Type: "flip"}); err != nil {
+		t.Fatal(err)
+	}
+	view := g.View("").(View)
+	if view.Center[0].Code == "7H" || view.Sides[0] != SideSize-1 || view.Sides[1] != SideSize-1 || len(view.Flipped) != 0 {
+		t.Errorf("Center was not turned: %+v", view)
+	}
+}
+
+func TestSidePilesRecycle(t *testing.T) {
+	g := setup(t, arranged(nil))
+	g.sides = [2][]model.Card{}
+	g.center[0] = append(model.NewDeck(false, "2C,3C,4C").Cards, g.center[0]...)
+
+	g.turnUp()
+	if len(g.center[0]) != 2 || len(g.center[1]) != 2 || len(g.sides[0])+len(g.sides[1]) != 1 || g.over {
+		t.Errorf("Center was not recycled: center %v sides %v", g.center, g.sides)
+	}
+}
+
+func TestPlayOut(t *testing.T) {
+	g := setup(t, model.NewSeededDeck(8, ""))
+	for moves := 0; !g.Terminal(); moves++ {
+		if moves > 1000 {
+			t.Fatalf("Game did not finish")
+		}
+		for _, player := range g.Players() {
+			if actions := g.LegalActions(player); len(actions) > 0 {
+				if err := g.Apply(player, actions[0]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	view := g.View("").(View)
+	for seat, player := range g.Players() {
+		loser := g.Players()[1-seat]
+		if player == view.Winner && g.Scores()[player] != view.HandSizes[loser]+view.Stocks[loser] {
+			t.Errorf("Unexpected scores: %v %+v", g.Scores(), view)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package tournament
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Winners = "winners"
+	Losers  = "losers"
+	Final   = "final"
+	// Reset is the second grand final of a double elimination bracket,
+	// played only if the losers' bracket champion wins the first.
+	Reset = "reset"
+)
+
+// source feeds a match slot with the winner or loser of an earlier match.
+type source struct {
+	match int
+	loser bool
+}
+
+type node struct {
+	Match
+	from  [2]*source
+	known [2]bool
+}
+
+// Bracket is a single or double elimination bracket. Players are seeded in
+// the order given, padded with byes to a power of two so that the top seeds
+// get them, and a player facing a bye advances by walkover.
+type Bracket struct {
+	players []string
+	nodes   []*node
+}
+
+func NewSingleElimination(players []string) (*Bracket, error) {
+	b, first, err := newBracket(players)
+	if err != nil {
+		return nil, err
+	}
+	b.winnersRounds(first)
+	b.settle()
+	return b, nil
+}
+
+// NewDoubleElimination builds a bracket in which a player is out after two
+// losses. Losers of each winners' round drop into the losers' bracket, in
+// reverse order on alternate rounds to put off rematches, and its champion
+// meets the winners' champion in the grand final.
+func NewDoubleElimination(players []string) (*Bracket, error) {
+	b, first, err := newBracket(players)
+	if err != nil {
+		return nil, err
+	}
+	rounds := b.winnersRounds(first)
+	champion := source{match: rounds[len(rounds)-1][0]}
+
+	lbChampion := source{match: first[0], loser: true}
+	if len(rounds) > 1 {
+		round := 1
+		lb := b.pairUp(Losers, round, first, true)
+		for j := 1; j < len(rounds); j++ {
+			drop := append([]int{}, rounds[j]...)
+			if j%2 == 1 {
+				for l, r := 0, len(drop)-1; l < r; l, r = l+1, r-1 {
+					drop[l], drop[r] = drop[r], drop[l]
+				}
+			}
+			round++
+			next := make([]int, len(lb))
+			for i := range lb {
+				next[i] = b.add(Losers, round, &source{match: lb[i]}, &source{match: drop[i], loser: true})
+			}
+			lb = next
+			if j < len(rounds)-1 {
+				round++
+				lb = b.pairUp(Losers, round, lb, false)
+			}
+		}
+		lbChampion = source{match: lb[0]}
+	}
+
+	final := b.add(Final, 1, &champion, &lbChampion)
+	b.add(Reset, 2, &source{match: final}, &source{match: final, loser: true})
+	b.settle()
+	return b, nil
+}
+
+func newBracket(players []string) (*Bracket, []int, error) {
+	if len(players) < 2 {
+		return nil, nil, fmt.Errorf("A bracket needs at least 2 players")
+	}
+	if err := distinct(players); err != nil {
+		return nil, nil, err
+	}
+
+	b := &Bracket{players: players}
+	order := seedOrder(len(players))
+	seeded := func(seed int) string {
+		if seed > len(players) {
+			return ""
+		}
+		return players[seed-1]
+	}
+	first := make([]int, len(order)/2)
+	for i := range first {
+		first[i] = b.add(Winners, 1, nil, nil)
+		n := b.nodes[first[i]]
+		n.Players = [2]string{seeded(order[2*i]), seeded(order[2*i+1])}
+		n.known = [2]bool{true, true}
+	}
+	return b, first, nil
+}
+
+func distinct(players []string) error {
+	seen := make(map[string]bool)
+	for _, p := range players {
+		if p == "" || seen[p] {
+			return fmt.Errorf("Players must be named and distinct")
+		}
+		seen[p] = true
+	}
+	return nil
+}
+
+// seedOrder places seeds 1..size, size the next power of two, so that the
+// top two seeds can only meet in the final: 1, 8, 4, 5, 2, 7, 3, 6 for 8.
+func seedOrder(players int) []int {
+	order := []int{1}
+	for len(order) < players {
+		size := 2 * len(order)
+		next := make([]int, 0, size)
+		for _, seed := range order {
+			next = append(next, seed, size+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// winnersRounds builds the winners' bracket on top of the first round and
+// returns the matches of each round.
+func (b *Bracket) winnersRounds(first []int) [][]int {
+	rounds := [][]int{first}
+	for round := first; len(round) > 1; {
+		round = b.pairUp(Winners, len(rounds)+1, round, false)
+		rounds = append(rounds, round)
+	}
+	return rounds
+}
+
+// pairUp adds a round in which the winners, or losers, of neighbouring
+// matches meet.
+func (b *Bracket) pairUp(bracket string, round int, matches []int, losers bool) []int {
+	next := make([]int, len(matches)/2)
+	for i := range next {
+		next[i] = b.add(bracket, round, &source{match: matches[2*i], loser: losers}, &source{match: matches[2*i+1], loser: losers})
+	}
+	return next
+}
+
+func (b *Bracket) add(bracket string, round int, a, c *source) int {
+	id := len(b.nodes)
+	b.nodes = append(b.nodes, &node{Match: Match{ID: id + 1, Round: round, Bracket: bracket}, from: [2]*source{a, c}})
+	return id
+}
+
+// settle fills slots whose feeding matches are decided and decides byes,
+// until nothing changes.
+func (b *Bracket) settle() {
+	for changed := true; changed; {
+		changed = false
+		for _, n := range b.nodes {
+			if n.Done {
+				continue
+			}
+			for i, from := range n.from {
+				if n.known[i] || from == nil || !b.nodes[from.match].Done {
+					continue
+				}
+				feeder := b.nodes[from.match]
+				n.Players[i], n.known[i] = feeder.Winner, true
+				if from.loser {
+					n.Players[i] = feeder.loser()
+				}
+				changed = true
+			}
+			if !n.known[0] || !n.known[1] {
+				continue
+			}
+			// The reset is only played if the grand final went to the
+			// player from the losers' bracket.
+			skip := false
+			if n.Bracket == Reset {
+				final := b.nodes[n.from[0].match]
+				skip = final.Winner == final.Players[0]
+			}
+			switch {
+			case skip:
+				n.Winner, n.Players[1] = n.Players[0], ""
+			case n.Players[0] == "":
+				n.Winner = n.Players[1]
+			case n.Players[1] == "":
+				n.Winner = n.Players[0]
+			default:
+				continue
+			}
+			n.Walkover, n.Done, changed = true, true, true
+		}
+	}
+}
+
+func (b *Bracket) Matches() []Match {
+	matches := make([]Match, len(b.nodes))
+	for i, n := range b.nodes {
+		matches[i] = n.Match
+	}
+	return matches
+}
+
+func (b *Bracket) find(id int) (*node, error) {
+	if id < 1 || id > len(b.nodes) {
+		return nil, ErrMatchNotFound
+	}
+	return b.nodes[id-1], nil
+}
+
+func (b *Bracket) Report(id int, winner string) error {
+	n, err := b.find(id)
+	if err != nil {
+		return err
+	}
+	if !n.Ready() {
+		return fmt.Errorf("Match %v is not waiting for a result", id)
+	}
+	if !n.has(winner) {
+		return fmt.Errorf("A bracket match needs a winner from %v and %v", n.Players[0], n.Players[1])
+	}
+	n.Winner, n.Done = winner, true
+	b.settle()
+	return nil
+}
+
+func (b *Bracket) SetGame(id int, gameID uuid.UUID) error {
+	n, err := b.find(id)
+	if err != nil {
+		return err
+	}
+	n.GameID = &gameID
+	return nil
+}
+
+func (b *Bracket) Complete() bool {
+	return b.nodes[len(b.nodes)-1].Done
+}
+
+// Champion returns the winner once the bracket is complete.
+func (b *Bracket) Champion() string {
+	if !b.Complete() {
+		return ""
+	}
+	return b.nodes[len(b.nodes)-1].Winner
+}
+
+// Standings rank players still in the bracket first, then the eliminated by
+// how late they went out.
+func (b *Bracket) Standings() []Standing {
+	standings := record(b.players, b.Matches())
+	lives := 1
+	if b.nodes[len(b.nodes)-1].Bracket == Reset {
+		lives = 2
+	}
+	// Matches are in round order within each bracket and the winners'
+	// bracket comes first, so counting losses in order finds the match
+	// each player went out in.
+	losses := make(map[string]int)
+	out := make(map[string]int)
+	for i, n := range b.nodes {
+		if !n.Done || n.Walkover {
+			continue
+		}
+		loser := n.loser()
+		if losses[loser]++; losses[loser] == lives {
+			out[loser] = i + 1
+		}
+	}
+
+	ranked := make([]Standing, 0, len(b.players))
+	seed := make(map[string]int)
+	for i, p := range b.players {
+		standings[p].Eliminated = out[p] > 0
+		ranked = append(ranked, *standings[p])
+		seed[p] = i
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		oi, oj := out[ranked[i].Player], out[ranked[j].Player]
+		if (oi == 0) != (oj == 0) {
+			return oi == 0
+		}
+		if oi != oj {
+			return oi > oj
+		}
+		return seed[ranked[i].Player] < seed[ranked[j].Player]
+	})
+	return ranked
+}
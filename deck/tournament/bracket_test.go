@@ -0,0 +1,126 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeedOrder(t *testing.T) {
+	if order := seedOrder(8); !reflect.DeepEqual(order, []int{1, 8, 4, 5, 2, 7, 3, 6}) {
+		t.Errorf("Unexpected seed order: %v", order)
+	}
+	if order := seedOrder(5); len(order) != 8 {
+		t.Errorf("5 players should fill an 8 bracket: %v", order)
+	}
+}
+
+// playOut reports every ready match for the better seed until the event is
+// complete.
+func playOut(t *testing.T, e Event, seeds []string) {
+	t.Helper()
+	rank := make(map[string]int)
+	for i, p := range seeds {
+		rank[p] = i
+	}
+	for guard := 0; !e.Complete(); guard++ {
+		ready := Ready(e)
+		if len(ready) == 0 || guard > 100 {
+			t.Fatalf("Event stuck: %+v", e.Matches())
+		}
+		for _, m := range ready {
+			winner := m.Players[0]
+			if rank[m.Players[1]] < rank[winner] {
+				winner = m.Players[1]
+			}
+			if err := e.Report(m.ID, winner); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestSingleElimination(t *testing.T) {
+	players := []string{"ann", "bob", "cat", "dan", "eve"}
+	b, err := NewSingleElimination(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ann, bob and cat have byes, so bob and cat meet in round two while
+	// dan plays eve.
+	ready := Ready(b)
+	if len(ready) != 2 || ready[0].Players != [2]string{"dan", "eve"} || ready[1].Players != [2]string{"bob", "cat"} {
+		t.Fatalf("Unexpected first matches: %+v", ready)
+	}
+	if err := b.Report(ready[0].ID, "cat"); err == nil {
+		t.Errorf("Report accepted a winner not in the match")
+	}
+
+	playOut(t, b, players)
+	if b.Champion() != "ann" {
+		t.Errorf("Top seed should win, got %v", b.Champion())
+	}
+	standings := b.Standings()
+	if standings[0].Player != "ann" || standings[1].Player != "bob" || !standings[1].Eliminated {
+		t.Errorf("Unexpected standings: %+v", standings)
+	}
+	if _, err := NewSingleElimination([]string{"ann", "ann"}); err == nil {
+		t.Errorf("Accepted duplicate players")
+	}
+}
+
+func TestDoubleEliminationReset(t *testing.T) {
+	players := []string{"ann", "bob", "cat", "dan"}
+	b, err := NewDoubleElimination(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := func(winner string) {
+		t.Helper()
+		ready := Ready(b)
+		if len(ready) == 0 {
+			t.Fatalf("Nothing to play")
+		}
+		if err := b.Report(ready[0].ID, winner); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report("ann") // ann beats dan
+	report("bob") // bob beats cat
+	report("ann") // winners' final
+	report("cat") // losers' round 1: cat beats dan
+	report("cat") // cat beats bob, who dropped from the winners' final
+	final := Ready(b)[0]
+	if final.Bracket != Final || final.Players != [2]string{"ann", "cat"} {
+		t.Fatalf("Unexpected grand final: %+v", final)
+	}
+	report("cat")
+	if b.Complete() {
+		t.Fatalf("Cat's win should force a reset")
+	}
+	report("cat")
+	if b.Champion() != "cat" {
+		t.Errorf("Cat should win the reset, got %v", b.Champion())
+	}
+	if standings := b.Standings(); standings[0].Player != "cat" || standings[1].Player != "ann" || standings[3].Player != "dan" {
+		t.Errorf("Unexpected standings: %+v", standings)
+	}
+}
+
+func TestDoubleEliminationNoReset(t *testing.T) {
+	players := []string{"ann", "bob", "cat", "dan", "eve", "fay"}
+	b, err := NewDoubleElimination(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playOut(t, b, players)
+	matches := b.Matches()
+	if reset := matches[len(matches)-1]; !reset.Walkover || b.Champion() != "ann" {
+		t.Errorf("The reset should be skipped when the winners' champion wins: %+v", reset)
+	}
+	for _, s := range b.Standings()[1:] {
+		if s.Losses > 2 || !s.Eliminated {
+			t.Errorf("Unexpected record %+v", s)
+		}
+	}
+}
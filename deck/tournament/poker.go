@@ -0,0 +1,369 @@
+package tournament
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BlindLevel is one step of a blind schedule.
+type BlindLevel struct {
+	SmallBlind int `json:"small_blind"`
+	BigBlind   int `json:"big_blind"`
+	Ante       int `json:"ante,omitempty"`
+	Minutes    int `json:"minutes"`
+}
+
+// DefaultBlinds is a 20-minute schedule for starting stacks of about 10,000.
+var DefaultBlinds = []BlindLevel{
+	{SmallBlind: 25, BigBlind: 50, Minutes: 20},
+	{SmallBlind: 50, BigBlind: 100, Minutes: 20},
+	{SmallBlind: 75, BigBlind: 150, Minutes: 20},
+	{SmallBlind: 100, BigBlind: 200, Ante: 25, Minutes: 20},
+	{SmallBlind: 150, BigBlind: 300, Ante: 25, Minutes: 20},
+	{SmallBlind: 200, BigBlind: 400, Ante: 50, Minutes: 20},
+	{SmallBlind: 300, BigBlind: 600, Ante: 75, Minutes: 20},
+	{SmallBlind: 400, BigBlind: 800, Ante: 100, Minutes: 20},
+	{SmallBlind: 600, BigBlind: 1200, Ante: 200, Minutes: 20},
+	{SmallBlind: 1000, BigBlind: 2000, Ante: 300, Minutes: 20},
+}
+
+// Table is one table of a multi-table tournament. Empty seats are "".
+type Table struct {
+	Number int        `json:"number"`
+	Seats  []string   `json:"seats"`
+	DeckID *uuid.UUID `json:"deck_id,omitempty"`
+}
+
+func (t *Table) count() int {
+	count := 0
+	for _, p := range t.Seats {
+		if p != "" {
+			count++
+		}
+	}
+	return count
+}
+
+func (t *Table) players() []string {
+	var players []string
+	for _, p := range t.Seats {
+		if p != "" {
+			players = append(players, p)
+		}
+	}
+	return players
+}
+
+// SeatRef is a seat at a table, both counted from 1.
+type SeatRef struct {
+	Table int `json:"table"`
+	Seat  int `json:"seat"`
+}
+
+// Move is a player sent to a new seat by balancing, a broken table or the
+// final table redraw.
+type Move struct {
+	Player string  `json:"player"`
+	From   SeatRef `json:"from"`
+	To     SeatRef `json:"to"`
+}
+
+// Finish is a player's finishing place.
+type Finish struct {
+	Player string `json:"player"`
+	Place  int    `json:"place"`
+}
+
+// MultiTable runs a poker tournament over several tables. Players are seated
+// by random draw. After each bust out, tables are broken as soon as the
+// others have room for their players, and tables more than one player apart
+// are balanced by moving a player drawn at random from the largest to the
+// smallest. When the field fits at one table, every seat there is redrawn.
+type MultiTable struct {
+	TableSize int          `json:"table_size"`
+	Levels    []BlindLevel `json:"levels"`
+	Started   time.Time    `json:"started"`
+	rng       *rand.Rand
+	tables    []*Table
+	chips     map[string]int
+	finishes  []Finish
+	final     bool
+}
+
+func NewMultiTable(players []string, tableSize, stack int, levels []BlindLevel, seed int64, now time.Time) (*MultiTable, error) {
+	if len(players) < 2 {
+		return nil, fmt.Errorf("A tournament needs at least 2 players")
+	}
+	if err := distinct(players); err != nil {
+		return nil, err
+	}
+	if tableSize < 2 || tableSize > 10 {
+		return nil, fmt.Errorf("Tables seat 2 to 10 players")
+	}
+	if stack < 1 {
+		return nil, fmt.Errorf("Starting stack must be positive")
+	}
+	if len(levels) == 0 {
+		levels = DefaultBlinds
+	}
+	for _, level := range levels {
+		if level.Minutes < 1 || level.SmallBlind < 1 || level.BigBlind < level.SmallBlind || level.Ante < 0 {
+			return nil, fmt.Errorf("Invalid blind level %+v", level)
+		}
+	}
+
+	m := &MultiTable{
+		TableSize: tableSize,
+		Levels:    levels,
+		Started:   now,
+		rng:       rand.New(rand.NewSource(seed)),
+		chips:     make(map[string]int),
+	}
+	count := (len(players) + tableSize - 1) / tableSize
+	for i := 0; i < count; i++ {
+		m.tables = append(m.tables, &Table{Number: i + 1, Seats: make([]string, tableSize)})
+	}
+	drawn := append([]string{}, players...)
+	m.rng.Shuffle(len(drawn), func(i, j int) { drawn[i], drawn[j] = drawn[j], drawn[i] })
+	for i, p := range drawn {
+		m.seat(p, m.tables[i%count])
+		m.chips[p] = stack
+	}
+	m.final = count == 1
+	return m, nil
+}
+
+// seat puts player in a random empty seat at table.
+func (m *MultiTable) seat(player string, table *Table) SeatRef {
+	var empty []int
+	for i, p := range table.Seats {
+		if p == "" {
+			empty = append(empty, i)
+		}
+	}
+	seat := empty[m.rng.Intn(len(empty))]
+	table.Seats[seat] = player
+	return SeatRef{Table: table.Number, Seat: seat + 1}
+}
+
+// Level returns the blind level in play at now, counted from 1. The last
+// level lasts until the end.
+func (m *MultiTable) Level(now time.Time) (int, BlindLevel) {
+	elapsed := now.Sub(m.Started)
+	for i, level := range m.Levels {
+		length := time.Duration(level.Minutes) * time.Minute
+		if elapsed < length {
+			return i + 1, level
+		}
+		elapsed -= length
+	}
+	return len(m.Levels), m.Levels[len(m.Levels)-1]
+}
+
+// Tables returns a copy of the tables in play.
+func (m *MultiTable) Tables() []Table {
+	tables := make([]Table, len(m.tables))
+	for i, t := range m.tables {
+		tables[i] = *t
+		tables[i].Seats = append([]string{}, t.Seats...)
+	}
+	return tables
+}
+
+// SetDeck records the deck a table deals its next hand from.
+func (m *MultiTable) SetDeck(number int, deckID uuid.UUID) {
+	if t, ok := m.Table(number); ok {
+		t.DeckID = &deckID
+	}
+}
+
+func (m *MultiTable) Table(number int) (*Table, bool) {
+	for _, t := range m.tables {
+		if t.Number == number {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (m *MultiTable) find(player string) (*Table, int) {
+	for _, t := range m.tables {
+		for i, p := range t.Seats {
+			if p == player {
+				return t, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+func (m *MultiTable) Chips() map[string]int {
+	chips := make(map[string]int, len(m.chips))
+	for p, c := range m.chips {
+		chips[p] = c
+	}
+	return chips
+}
+
+func (m *MultiTable) Remaining() int {
+	return len(m.chips) - len(m.finishes)
+}
+
+func (m *MultiTable) Complete() bool {
+	return m.Remaining() == 1
+}
+
+// Report records the stacks at a table after a hand. The reported players
+// must hold as many chips between them as before the hand. Players left with
+// no chips are out, placed by the stacks they started the hand with when
+// several bust at once, and the tables are rebalanced.
+func (m *MultiTable) Report(table int, chips map[string]int) ([]Move, error) {
+	t, ok := m.Table(table)
+	if !ok {
+		return nil, fmt.Errorf("No table %v", table)
+	}
+	before, after := 0, 0
+	for p, c := range chips {
+		if seated, _ := m.find(p); seated != t {
+			return nil, fmt.Errorf("%v is not at table %v", p, table)
+		}
+		if c < 0 {
+			return nil, fmt.Errorf("Negative stack for %v", p)
+		}
+		before, after = before+m.chips[p], after+c
+	}
+	if after != before {
+		return nil, fmt.Errorf("Stacks add up to %v chips, not %v", after, before)
+	}
+
+	var busted []string
+	for p, c := range chips {
+		if c == 0 {
+			busted = append(busted, p)
+		}
+	}
+	if len(busted) == m.Remaining() {
+		return nil, fmt.Errorf("Somebody must be left with chips")
+	}
+	// The bigger stack going into the hand finishes higher.
+	sort.Slice(busted, func(i, j int) bool {
+		if m.chips[busted[i]] != m.chips[busted[j]] {
+			return m.chips[busted[i]] < m.chips[busted[j]]
+		}
+		return busted[i] < busted[j]
+	})
+	for p, c := range chips {
+		m.chips[p] = c
+	}
+	for _, p := range busted {
+		m.finishes = append(m.finishes, Finish{Player: p, Place: m.Remaining()})
+		_, seat := m.find(p)
+		t.Seats[seat] = ""
+	}
+	return m.balance(), nil
+}
+
+// balance breaks and balances tables after bust outs.
+func (m *MultiTable) balance() []Move {
+	var moves []Move
+	move := func(player string, to *Table) {
+		from, seat := m.find(player)
+		from.Seats[seat] = ""
+		moves = append(moves, Move{Player: player, From: SeatRef{from.Number, seat + 1}, To: m.seat(player, to)})
+	}
+	smallest := func(tables []*Table) *Table {
+		small := tables[0]
+		for _, t := range tables[1:] {
+			if t.count() < small.count() {
+				small = t
+			}
+		}
+		return small
+	}
+
+	needed := (m.Remaining() + m.TableSize - 1) / m.TableSize
+	for len(m.tables) > needed {
+		// Break the shortest table, the highest numbered on a tie.
+		broken := 0
+		for i, t := range m.tables {
+			if t.count() <= m.tables[broken].count() {
+				broken = i
+			}
+		}
+		others := append(m.tables[:broken:broken], m.tables[broken+1:]...)
+		players := m.tables[broken].players()
+		m.rng.Shuffle(len(players), func(i, j int) { players[i], players[j] = players[j], players[i] })
+		for _, p := range players {
+			move(p, smallest(others))
+		}
+		m.tables = others
+	}
+
+	if len(m.tables) == 1 && !m.final {
+		m.final = true
+		table := m.tables[0]
+		from := make(map[string]SeatRef)
+		for i, p := range table.Seats {
+			if p != "" {
+				from[p] = SeatRef{Table: table.Number, Seat: i + 1}
+			}
+		}
+		// Players who just arrived from a broken table move once, from
+		// their old seat.
+		for _, move := range moves {
+			from[move.Player] = move.From
+		}
+		players := table.players()
+		table.Seats = make([]string, m.TableSize)
+		moves = nil
+		for _, p := range players {
+			moves = append(moves, Move{Player: p, From: from[p], To: m.seat(p, table)})
+		}
+		return moves
+	}
+
+	for {
+		large := m.tables[0]
+		for _, t := range m.tables[1:] {
+			if t.count() > large.count() {
+				large = t
+			}
+		}
+		small := smallest(m.tables)
+		if large.count()-small.count() <= 1 {
+			return moves
+		}
+		players := large.players()
+		move(players[m.rng.Intn(len(players))], small)
+	}
+}
+
+// Standings list the players still in by chip count, then those out by
+// finishing place.
+func (m *MultiTable) Standings() []Finish {
+	var in []string
+	for p := range m.chips {
+		if m.chips[p] > 0 {
+			in = append(in, p)
+		}
+	}
+	sort.Slice(in, func(i, j int) bool {
+		if m.chips[in[i]] != m.chips[in[j]] {
+			return m.chips[in[i]] > m.chips[in[j]]
+		}
+		return in[i] < in[j]
+	})
+
+	standings := make([]Finish, 0, len(m.chips))
+	for i, p := range in {
+		standings = append(standings, Finish{Player: p, Place: i + 1})
+	}
+	for i := len(m.finishes) - 1; i >= 0; i-- {
+		standings = append(standings, m.finishes[i])
+	}
+	return standings
+}
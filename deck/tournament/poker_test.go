@@ -0,0 +1,98 @@
+package tournament
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMultiTableBalancing(t *testing.T) {
+	var players []string
+	for i := 0; i < 20; i++ {
+		players = append(players, fmt.Sprintf("p%02d", i))
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	m, err := NewMultiTable(players, 9, 10000, nil, 1, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Tables()) != 3 {
+		t.Fatalf("20 players need 3 tables of 9")
+	}
+	checkBalanced := func() {
+		t.Helper()
+		low, high := 10, 0
+		for _, table := range m.Tables() {
+			low, high = min(low, table.count()), max(high, table.count())
+		}
+		if high-low > 1 {
+			t.Errorf("Tables out of balance: %v to %v", low, high)
+		}
+	}
+	checkBalanced()
+
+	if level, blinds := m.Level(start.Add(45 * time.Minute)); level != 3 || blinds.BigBlind != 150 {
+		t.Errorf("Expected level 3 after 45 minutes, got %v %+v", level, blinds)
+	}
+	if level, _ := m.Level(start.Add(100 * time.Hour)); level != len(DefaultBlinds) {
+		t.Errorf("The last level should last, got %v", level)
+	}
+
+	// Bust players at the first table one by one.
+	for m.Remaining() > 9 {
+		table := m.Tables()[0]
+		victim, winner := table.players()[0], table.players()[1]
+		if _, err := m.Report(table.Number, map[string]int{victim: 0}); err == nil {
+			t.Fatalf("Report lost %v's chips", victim)
+		}
+		stacks := map[string]int{victim: 0, winner: m.chips[winner] + m.chips[victim]}
+		if _, err := m.Report(table.Number, stacks); err != nil {
+			t.Fatal(err)
+		}
+		checkBalanced()
+		if len(m.Tables()) != (m.Remaining()+8)/9 {
+			t.Fatalf("%v players at %v tables", m.Remaining(), len(m.Tables()))
+		}
+	}
+	if !m.final || len(m.Tables()) != 1 {
+		t.Fatalf("Should be at the final table")
+	}
+
+	table := m.Tables()[0]
+	if _, err := m.Report(table.Number+1, map[string]int{}); err == nil {
+		t.Errorf("Report accepted a missing table")
+	}
+	stacks := make(map[string]int)
+	for i, p := range table.players() {
+		stacks[p] = 0
+		if i == 0 {
+			stacks[p] = 200000
+		}
+	}
+	if _, err := m.Report(table.Number, stacks); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Complete() {
+		t.Fatalf("One player should be left")
+	}
+	standings := m.Standings()
+	if len(standings) != 20 || standings[0].Place != 1 || standings[19].Place != 20 || standings[1].Place != 2 {
+		t.Errorf("Unexpected standings: %+v", standings)
+	}
+}
+
+func TestMultiTableBustOrder(t *testing.T) {
+	m, _ := NewMultiTable([]string{"ann", "bob", "cat"}, 9, 100, nil, 1, time.Now())
+	m.Report(1, map[string]int{"ann": 50, "bob": 150})
+	if _, err := m.Report(1, map[string]int{"ann": 0, "bob": 0, "cat": 300}); err != nil {
+		t.Fatal(err)
+	}
+	// Ann started the hand with fewer chips than bob, so finishes lower.
+	standings := m.Standings()
+	if standings[1].Player != "bob" || standings[2].Player != "ann" || standings[2].Place != 3 {
+		t.Errorf("Unexpected finishes: %+v", standings)
+	}
+	if _, err := NewMultiTable([]string{"ann", "bob"}, 11, 100, nil, 1, time.Now()); err == nil {
+		t.Errorf("Accepted 11-handed tables")
+	}
+}
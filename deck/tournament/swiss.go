@@ -0,0 +1,247 @@
+package tournament
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Swiss plays a fixed number of rounds in which players meet others on the
+// same score without meeting anyone twice. With an odd number of players the
+// lowest ranked player who has not had a bye sits out and scores a win. The
+// next round is paired as soon as the last result of a round is in.
+type Swiss struct {
+	players []string
+	rounds  int
+	round   int
+	matches []*Match
+}
+
+// NewSwiss pairs the first round in seed order, top half against bottom
+// half. Zero rounds plays enough to leave one unbeaten player.
+func NewSwiss(players []string, rounds int) (*Swiss, error) {
+	n := len(players)
+	if n < 2 {
+		return nil, fmt.Errorf("Swiss needs at least 2 players")
+	}
+	if err := distinct(players); err != nil {
+		return nil, err
+	}
+	if rounds == 0 {
+		rounds = bits.Len(uint(n - 1))
+	}
+	most := n - 1
+	if n%2 == 1 {
+		most = n
+	}
+	if rounds < 1 || rounds > most {
+		return nil, fmt.Errorf("%v players can play 1 to %v rounds", n, most)
+	}
+
+	s := &Swiss{players: players, rounds: rounds}
+	s.pair()
+	return s, nil
+}
+
+func (s *Swiss) Round() int {
+	return s.round
+}
+
+func (s *Swiss) Rounds() int {
+	return s.rounds
+}
+
+func (s *Swiss) played(a, b string) bool {
+	for _, m := range s.matches {
+		if m.has(a) && m.has(b) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Swiss) hadBye(player string) bool {
+	for _, m := range s.matches {
+		if m.Walkover && m.Players[0] == player {
+			return true
+		}
+	}
+	return false
+}
+
+// pair starts the next round. Players are ranked by score, then seed,
+// except in the first round, where the top half of the seeds meets the
+// bottom half.
+func (s *Swiss) pair() {
+	s.round++
+	ranked := append([]string{}, s.players...)
+	if s.round > 1 {
+		for i, st := range s.Standings() {
+			ranked[i] = st.Player
+		}
+	}
+
+	bye := ""
+	if len(ranked)%2 == 1 {
+		bye = ranked[len(ranked)-1]
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if !s.hadBye(ranked[i]) {
+				bye = ranked[i]
+				break
+			}
+		}
+		for i, p := range ranked {
+			if p == bye {
+				ranked = append(ranked[:i:i], ranked[i+1:]...)
+				break
+			}
+		}
+	}
+
+	var pairs [][2]string
+	half := len(ranked) / 2
+	if s.round == 1 {
+		for i := 0; i < half; i++ {
+			pairs = append(pairs, [2]string{ranked[i], ranked[i+half]})
+		}
+	} else if pairs, _ = pairUp(ranked, s.played); pairs == nil {
+		// Every pairing repeats a match; fall back to score order.
+		for i := 0; i < len(ranked); i += 2 {
+			pairs = append(pairs, [2]string{ranked[i], ranked[i+1]})
+		}
+	}
+	for _, pair := range pairs {
+		s.matches = append(s.matches, &Match{ID: len(s.matches) + 1, Round: s.round, Players: pair})
+	}
+	if bye != "" {
+		s.matches = append(s.matches, &Match{ID: len(s.matches) + 1, Round: s.round, Players: [2]string{bye}, Winner: bye, Walkover: true, Done: true})
+	}
+}
+
+// pairUp pairs each player with the highest ranked player below them they
+// have not met, backtracking when that leaves the rest unpairable.
+func pairUp(players []string, played func(a, b string) bool) ([][2]string, bool) {
+	if len(players) == 0 {
+		return nil, true
+	}
+	first := players[0]
+	for i := 1; i < len(players); i++ {
+		if played(first, players[i]) {
+			continue
+		}
+		rest := append(append([]string{}, players[1:i]...), players[i+1:]...)
+		if pairs, ok := pairUp(rest, played); ok {
+			return append([][2]string{{first, players[i]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+func (s *Swiss) Matches() []Match {
+	matches := make([]Match, len(s.matches))
+	for i, m := range s.matches {
+		matches[i] = *m
+	}
+	return matches
+}
+
+func (s *Swiss) find(id int) (*Match, error) {
+	if id < 1 || id > len(s.matches) {
+		return nil, ErrMatchNotFound
+	}
+	return s.matches[id-1], nil
+}
+
+// Report records a result; an empty winner is a draw.
+func (s *Swiss) Report(id int, winner string) error {
+	m, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if !m.Ready() {
+		return fmt.Errorf("Match %v is not waiting for a result", id)
+	}
+	if winner != "" && !m.has(winner) {
+		return fmt.Errorf("%v is not playing match %v", winner, id)
+	}
+	m.Winner, m.Draw, m.Done = winner, winner == "", true
+
+	for _, m := range s.matches {
+		if !m.Done {
+			return nil
+		}
+	}
+	if s.round < s.rounds {
+		s.pair()
+	}
+	return nil
+}
+
+func (s *Swiss) SetGame(id int, gameID uuid.UUID) error {
+	m, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	m.GameID = &gameID
+	return nil
+}
+
+func (s *Swiss) Complete() bool {
+	if s.round < s.rounds {
+		return false
+	}
+	for _, m := range s.matches {
+		if !m.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// Standings rank players by points, then Buchholz (the sum of their
+// opponents' points), then Sonneborn-Berger (the points of the opponents
+// they beat plus half those of the opponents they drew), then wins, then
+// seed. A bye scores a win but adds nothing to the tiebreaks.
+func (s *Swiss) Standings() []Standing {
+	standings := record(s.players, s.Matches())
+	for _, m := range s.matches {
+		if m.Walkover {
+			standings[m.Players[0]].Points++
+		}
+	}
+	for _, m := range s.matches {
+		if !m.Done || m.Walkover {
+			continue
+		}
+		for i, p := range m.Players {
+			opponent := standings[m.Players[1-i]].Points
+			standings[p].Buchholz += opponent
+			switch {
+			case m.Draw:
+				standings[p].SonnebornBerger += opponent / 2
+			case m.Winner == p:
+				standings[p].SonnebornBerger += opponent
+			}
+		}
+	}
+
+	ranked := make([]Standing, len(s.players))
+	for i, p := range s.players {
+		ranked[i] = *standings[p]
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		switch {
+		case a.Points != b.Points:
+			return a.Points > b.Points
+		case a.Buchholz != b.Buchholz:
+			return a.Buchholz > b.Buchholz
+		case a.SonnebornBerger != b.SonnebornBerger:
+			return a.SonnebornBerger > b.SonnebornBerger
+		}
+		return a.Wins > b.Wins
+	})
+	return ranked
+}
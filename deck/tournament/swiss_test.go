@@ -0,0 +1,81 @@
+package tournament
+
+import "testing"
+
+func TestSwissPairsWithoutRematches(t *testing.T) {
+	players := []string{"ann", "bob", "cat", "dan", "eve"}
+	s, err := NewSwiss(players, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Rounds() != 3 {
+		t.Errorf("5 players should play 3 rounds, got %v", s.Rounds())
+	}
+	first := Ready(s)
+	if len(first) != 2 || first[0].Players != [2]string{"ann", "cat"} || first[1].Players != [2]string{"bob", "dan"} {
+		t.Fatalf("Top half should meet bottom half: %+v", first)
+	}
+
+	playOut(t, s, players)
+	met := make(map[[2]string]bool)
+	byes := make(map[string]int)
+	for _, m := range s.Matches() {
+		if m.Walkover {
+			byes[m.Players[0]]++
+			continue
+		}
+		pair := m.Players
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		if met[pair] {
+			t.Errorf("Rematch %v", pair)
+		}
+		met[pair] = true
+	}
+	for p, n := range byes {
+		if n > 1 {
+			t.Errorf("%v had %v byes", p, n)
+		}
+	}
+	if standings := s.Standings(); standings[0].Player != "ann" || standings[0].Points != 3 {
+		t.Errorf("Ann should win every round: %+v", standings)
+	}
+}
+
+func TestSwissTiebreaks(t *testing.T) {
+	s, _ := NewSwiss([]string{"ann", "bob", "cat", "dan"}, 2)
+	report := func(id int, winner string) {
+		t.Helper()
+		if err := s.Report(id, winner); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Round 1: ann-cat, bob-dan.
+	report(1, "cat")
+	report(2, "")
+	if s.Round() != 2 {
+		t.Fatalf("Round 2 should be paired")
+	}
+	// Round 2: cat (1) meets the best of bob and dan, who drew.
+	for _, m := range Ready(s) {
+		report(m.ID, m.Players[0])
+	}
+	if !s.Complete() {
+		t.Fatalf("Swiss should be complete")
+	}
+
+	standings := s.Standings()
+	if standings[0].Player != "cat" || standings[0].Points != 2 {
+		t.Errorf("Cat should lead: %+v", standings)
+	}
+	for i := 1; i < len(standings); i++ {
+		a, b := standings[i-1], standings[i]
+		if a.Points == b.Points && a.Buchholz < b.Buchholz {
+			t.Errorf("Buchholz out of order: %+v", standings)
+		}
+	}
+	if _, err := NewSwiss([]string{"ann", "bob"}, 2); err == nil {
+		t.Errorf("Two players cannot play two rounds without a rematch")
+	}
+}
@@ -0,0 +1,106 @@
+package tournament
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrMatchNotFound = errors.New("Match not found")
+
+// Match pairs two players. A player left empty is a bye; a walkover is a
+// match decided without being played.
+type Match struct {
+	ID       int        `json:"id"`
+	Round    int        `json:"round"`
+	Bracket  string     `json:"bracket,omitempty"`
+	Players  [2]string  `json:"players"`
+	Winner   string     `json:"winner,omitempty"`
+	Draw     bool       `json:"draw,omitempty"`
+	Walkover bool       `json:"walkover,omitempty"`
+	Done     bool       `json:"done"`
+	GameID   *uuid.UUID `json:"game_id,omitempty"`
+}
+
+// Ready reports whether the match has both players and waits for a result.
+func (m Match) Ready() bool {
+	return !m.Done && m.Players[0] != "" && m.Players[1] != ""
+}
+
+func (m Match) has(player string) bool {
+	return player != "" && (m.Players[0] == player || m.Players[1] == player)
+}
+
+func (m Match) loser() string {
+	if m.Draw {
+		return ""
+	}
+	if m.Players[0] == m.Winner {
+		return m.Players[1]
+	}
+	return m.Players[0]
+}
+
+// Standing is a player's record so far. Eliminated players of a bracket
+// rank below those still in it.
+type Standing struct {
+	Player          string  `json:"player"`
+	Wins            int     `json:"wins"`
+	Losses          int     `json:"losses"`
+	Draws           int     `json:"draws"`
+	Points          float64 `json:"points"`
+	Buchholz        float64 `json:"buchholz,omitempty"`
+	SonnebornBerger float64 `json:"sonneborn_berger,omitempty"`
+	Eliminated      bool    `json:"eliminated,omitempty"`
+}
+
+// Event pairs players into matches and moves on as results come in.
+type Event interface {
+	Matches() []Match
+	// Report records a match result; an empty winner is a draw, where the
+	// format allows one.
+	Report(id int, winner string) error
+	// SetGame records the hosted game a match is played in.
+	SetGame(id int, gameID uuid.UUID) error
+	Complete() bool
+	Standings() []Standing
+}
+
+// Ready lists the matches of an event waiting to be played.
+func Ready(e Event) []Match {
+	var ready []Match
+	for _, m := range e.Matches() {
+		if m.Ready() {
+			ready = append(ready, m)
+		}
+	}
+	return ready
+}
+
+// record counts wins, losses and draws from played matches, leaving out
+// walkovers.
+func record(players []string, matches []Match) map[string]*Standing {
+	standings := make(map[string]*Standing)
+	for _, p := range players {
+		standings[p] = &Standing{Player: p}
+	}
+	for _, m := range matches {
+		if !m.Done || m.Walkover {
+			continue
+		}
+		for _, p := range m.Players {
+			s := standings[p]
+			switch {
+			case m.Draw:
+				s.Draws++
+				s.Points += 0.5
+			case m.Winner == p:
+				s.Wins++
+				s.Points++
+			default:
+				s.Losses++
+			}
+		}
+	}
+	return standings
+}
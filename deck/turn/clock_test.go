@@ -0,0 +1,35 @@
+package turn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	var fired []string
+	clock.AfterFunc(3*time.Second, func() { fired = append(fired, "three") })
+	stopped := clock.AfterFunc(2*time.Second, func() { fired = append(fired, "stopped") })
+	clock.AfterFunc(time.Second, func() {
+		fired = append(fired, "one")
+		clock.AfterFunc(time.Second, func() { fired = append(fired, "chained") })
+	})
+	if !stopped.Stop() || stopped.Stop() {
+		t.Errorf("Stop should report a pending timer once")
+	}
+
+	clock.Advance(2500 * time.Millisecond)
+	if len(fired) != 2 || fired[0] != "one" || fired[1] != "chained" {
+		t.Errorf("Unexpected timers fired: %v", fired)
+	}
+	if !clock.Now().Equal(start.Add(2500 * time.Millisecond)) {
+		t.Errorf("Clock is at %v", clock.Now())
+	}
+
+	clock.Advance(time.Second)
+	if len(fired) != 3 || fired[2] != "three" {
+		t.Errorf("Unexpected timers fired: %v", fired)
+	}
+}
@@ -0,0 +1,231 @@
+package turn
+
+import (
+	"fmt"
+	"time"
+
+	"cardGame/deck/game"
+)
+
+// Default actions taken for a player whose time runs out. Any other action
+// type may be named too; when the named action isn't legal the player's
+// first legal action is taken instead.
+const (
+	DefaultPass    = "pass"
+	DefaultFold    = "fold"
+	DefaultForfeit = "forfeit"
+)
+
+// Control is a table's time control. Move limits each move; Bank is each
+// player's total thinking time, topped up by Increment after every move, as
+// on a chess clock. Either, both or neither may be set.
+type Control struct {
+	Move      time.Duration
+	Bank      time.Duration
+	Increment time.Duration
+	Default   string
+}
+
+func (c Control) Enabled() bool {
+	return c.Move > 0 || c.Bank > 0
+}
+
+func (c Control) Validate() error {
+	if c.Move < 0 || c.Bank < 0 || c.Increment < 0 {
+		return fmt.Errorf("Time control durations must not be negative")
+	}
+	if c.Increment > 0 && c.Bank == 0 {
+		return fmt.Errorf("An increment needs a time bank")
+	}
+	return nil
+}
+
+// ToAct lists the players who have a legal action now.
+func ToAct(g game.Game) []string {
+	var players []string
+	if g.Terminal() {
+		return players
+	}
+	for _, player := range g.Players() {
+		if len(g.LegalActions(player)) > 0 {
+			players = append(players, player)
+		}
+	}
+	return players
+}
+
+// Turns runs the clocks of one table. Every player to act has a clock
+// running; when it runs out expire is called from the timer with the
+// player and the generation of that clock. Turns is not safe for concurrent
+// use, so expire must take the table's lock and check Expire before acting.
+type Turns struct {
+	control    Control
+	clock      Clock
+	expire     func(player string, generation int)
+	toAct      []string
+	banks      map[string]time.Duration
+	started    map[string]time.Time
+	timers     map[string]Timer
+	generation map[string]int
+	timeouts   map[string]int
+}
+
+// Status is the turn state as clients see it. Banks are what each player
+// has left, in milliseconds.
+type Status struct {
+	ToAct     []string             `json:"to_act"`
+	Deadlines map[string]time.Time `json:"deadlines,omitempty"`
+	Banks     map[string]int64     `json:"banks_ms,omitempty"`
+	Timeouts  map[string]int       `json:"timeouts,omitempty"`
+}
+
+func NewTurns(control Control, clock Clock, players []string, expire func(player string, generation int)) *Turns {
+	t := &Turns{
+		control:    control,
+		clock:      clock,
+		expire:     expire,
+		banks:      make(map[string]time.Duration),
+		started:    make(map[string]time.Time),
+		timers:     make(map[string]Timer),
+		generation: make(map[string]int),
+		timeouts:   make(map[string]int),
+	}
+	for _, player := range players {
+		t.banks[player] = control.Bank
+	}
+	return t
+}
+
+// Acting reports whether player is one of those to act.
+func (t *Turns) Acting(player string) bool {
+	for _, p := range t.toAct {
+		if p == player {
+			return true
+		}
+	}
+	return false
+}
+
+// Update sets who is to act after mover's move, or after setup when mover
+// is empty. The mover's clock is stopped and their increment added, and
+// then every clock is running exactly for those to act.
+func (t *Turns) Update(toAct []string, mover string) {
+	now := t.clock.Now()
+	if _, running := t.started[mover]; running {
+		t.stop(mover, now)
+		t.banks[mover] += t.control.Increment
+	}
+
+	t.toAct = toAct
+	for player := range t.started {
+		if !t.Acting(player) {
+			t.stop(player, now)
+		}
+	}
+	for _, player := range toAct {
+		if _, running := t.started[player]; !running {
+			t.start(player, now)
+		}
+	}
+}
+
+// limit is how long player has for their next move.
+func (t *Turns) limit(player string) time.Duration {
+	limit := t.control.Move
+	if bank := t.banks[player]; t.control.Bank > 0 && (limit == 0 || bank < limit) {
+		limit = bank
+	}
+	return limit
+}
+
+func (t *Turns) start(player string, now time.Time) {
+	t.started[player] = now
+	if !t.control.Enabled() {
+		return
+	}
+	t.generation[player]++
+	generation := t.generation[player]
+	t.timers[player] = t.clock.AfterFunc(t.limit(player), func() { t.expire(player, generation) })
+}
+
+// stop charges the time player has used to their bank.
+func (t *Turns) stop(player string, now time.Time) {
+	if t.control.Bank > 0 {
+		t.banks[player] -= now.Sub(t.started[player])
+		if t.banks[player] < 0 {
+			t.banks[player] = 0
+		}
+	}
+	if timer, ok := t.timers[player]; ok {
+		timer.Stop()
+		delete(t.timers, player)
+	}
+	delete(t.started, player)
+}
+
+// Expire confirms that the clock a timer belongs to is still the one
+// running, so a move made just before the timer fired wins, and stops it.
+func (t *Turns) Expire(player string, generation int) bool {
+	if _, running := t.started[player]; !running || t.generation[player] != generation {
+		return false
+	}
+	t.stop(player, t.clock.Now())
+	t.timeouts[player]++
+	return true
+}
+
+// Stop halts every clock once the game is over.
+func (t *Turns) Stop() {
+	now := t.clock.Now()
+	for player := range t.started {
+		t.stop(player, now)
+	}
+	t.toAct = nil
+}
+
+// DefaultAction picks the action taken for a player who ran out of time.
+func (t *Turns) DefaultAction(legal []game.Action) (game.Action, bool) {
+	for _, action := range legal {
+		if action.Type == t.control.Default {
+			return action, true
+		}
+	}
+	if len(legal) == 0 {
+		return game.Action{}, false
+	}
+	return legal[0], true
+}
+
+// Forfeits reports whether running out of time loses the game.
+func (t *Turns) Forfeits() bool {
+	return t.control.Default == DefaultForfeit
+}
+
+func (t *Turns) Status() Status {
+	status := Status{ToAct: append([]string{}, t.toAct...)}
+	if len(t.timeouts) > 0 {
+		status.Timeouts = make(map[string]int)
+		for player, timeouts := range t.timeouts {
+			status.Timeouts[player] = timeouts
+		}
+	}
+	if !t.control.Enabled() {
+		return status
+	}
+
+	now := t.clock.Now()
+	status.Deadlines = make(map[string]time.Time)
+	for player, started := range t.started {
+		status.Deadlines[player] = started.Add(t.limit(player))
+	}
+	if t.control.Bank > 0 {
+		status.Banks = make(map[string]int64)
+		for player, bank := range t.banks {
+			if started, running := t.started[player]; running {
+				bank -= now.Sub(started)
+			}
+			status.Banks[player] = bank.Milliseconds()
+		}
+	}
+	return status
+}
@@ -0,0 +1,123 @@
+package turn
+
+import (
+	"testing"
+	"time"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+type expiry struct {
+	player     string
+	generation int
+}
+
+func newTestTurns(control Control) (*Turns, *FakeClock, *[]expiry) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	var expired []expiry
+	turns := NewTurns(control, clock, []string{"ann", "bob"}, func(player string, generation int) {
+		expired = append(expired, expiry{player, generation})
+	})
+	return turns, clock, &expired
+}
+
+func TestMoveTimeout(t *testing.T) {
+	turns, clock, expired := newTestTurns(Control{Move: 10 * time.Second, Default: DefaultPass})
+	turns.Update([]string{"ann"}, "")
+
+	clock.Advance(9 * time.Second)
+	turns.Update([]string{"bob"}, "ann")
+	if !turns.Acting("bob") || turns.Acting("ann") {
+		t.Errorf("Bob should be to act: %+v", turns.Status())
+	}
+	clock.Advance(9 * time.Second)
+	if len(*expired) != 0 {
+		t.Fatalf("Timer fired early: %v", *expired)
+	}
+
+	clock.Advance(time.Second)
+	if len(*expired) != 1 || (*expired)[0].player != "bob" {
+		t.Fatalf("Bob's move did not time out: %v", *expired)
+	}
+	if !turns.Expire("bob", (*expired)[0].generation) || turns.Expire("bob", (*expired)[0].generation) {
+		t.Errorf("Expire should confirm the running clock once")
+	}
+	if status := turns.Status(); status.Timeouts["bob"] != 1 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+}
+
+func TestStaleExpiry(t *testing.T) {
+	turns, clock, expired := newTestTurns(Control{Move: 10 * time.Second})
+	turns.Update([]string{"ann"}, "")
+	clock.Advance(10 * time.Second)
+
+	// Ann's move arrives before the expiry gets the table's lock.
+	turns.Update([]string{"ann"}, "ann")
+	if turns.Expire("ann", (*expired)[0].generation) {
+		t.Errorf("A stale timer expired Ann's new clock")
+	}
+}
+
+func TestTimeBank(t *testing.T) {
+	turns, clock, expired := newTestTurns(Control{Bank: time.Minute, Increment: 5 * time.Second, Default: DefaultForfeit})
+	turns.Update([]string{"ann"}, "")
+
+	clock.Advance(20 * time.Second)
+	turns.Update([]string{"bob"}, "ann")
+	clock.Advance(30 * time.Second)
+	status := turns.Status()
+	if status.Banks["ann"] != 45000 || status.Banks["bob"] != 30000 {
+		t.Errorf("Unexpected banks: %+v", status.Banks)
+	}
+	if deadline := status.Deadlines["bob"]; !deadline.Equal(time.Unix(80, 0)) {
+		t.Errorf("Unexpected deadline for Bob: %v", deadline)
+	}
+
+	turns.Update([]string{"ann"}, "bob")
+	clock.Advance(45 * time.Second)
+	if len(*expired) != 1 || (*expired)[0].player != "ann" || !turns.Forfeits() {
+		t.Fatalf("Ann's bank did not run out: %v", *expired)
+	}
+	turns.Expire("ann", (*expired)[0].generation)
+	if bank := turns.Status().Banks["ann"]; bank != 0 {
+		t.Errorf("Ann has %vms left", bank)
+	}
+}
+
+func TestMoveLimitWithinBank(t *testing.T) {
+	turns, clock, expired := newTestTurns(Control{Move: 30 * time.Second, Bank: 20 * time.Second})
+	turns.Update([]string{"ann", "bob"}, "")
+
+	clock.Advance(20 * time.Second)
+	if len(*expired) != 2 {
+		t.Errorf("Both banks should run out before the move limit: %v", *expired)
+	}
+}
+
+func TestToActAndDefaultAction(t *testing.T) {
+	g := game.NewHighCard()
+	if err := g.Setup(model.NewDeck(false, "3C,QH"), game.Config{Players: []string{"ann", "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+	if toAct := ToAct(g); len(toAct) != 1 || toAct[0] != "ann" {
+		t.Errorf("ToAct = %v, want [ann]", toAct)
+	}
+
+	turns, _, _ := newTestTurns(Control{Move: time.Second, Default: DefaultFold})
+	legal := []game.Action{{Type: "call"}, {Type: "fold"}}
+	if action, ok := turns.DefaultAction(legal); !ok || action.Type != "fold" {
+		t.Errorf("DefaultAction = %+v", action)
+	}
+	if action, ok := turns.DefaultAction(legal[:1]); !ok || action.Type != "call" {
+		t.Errorf("DefaultAction did not fall back to the first legal action: %+v", action)
+	}
+	if _, ok := turns.DefaultAction(nil); ok {
+		t.Errorf("DefaultAction found an action with none legal")
+	}
+
+	if err := (Control{Increment: time.Second}).Validate(); err == nil {
+		t.Errorf("Validate accepted an increment without a bank")
+	}
+}
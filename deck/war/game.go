@@ -0,0 +1,278 @@
+package war
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+// Pickup is what happens to the cards a battle is won with.
+type Pickup string
+
+const (
+	// PickupBottom puts them under the winner's stack in the order they were
+	// played.
+	PickupBottom Pickup = "bottom"
+	// PickupShuffle shuffles them before putting them under the stack.
+	PickupShuffle Pickup = "shuffle"
+	// PickupWinnings keeps them in a separate pile, which is shuffled to
+	// become the stack when it runs out.
+	PickupWinnings Pickup = "winnings"
+)
+
+const (
+	DefaultWarCards   = 3
+	DefaultMaxBattles = 1000
+)
+
+// Battle is one resolved battle: the cards each player turned face up,
+// through however many wars it took, and who took the pot.
+type Battle struct {
+	Cards  map[string][]model.Card `json:"cards"`
+	Wars   int                     `json:"wars"`
+	Pot    int                     `json:"pot"`
+	Winner string                  `json:"winner"`
+}
+
+// Game plays War for 2 to 4 players for the game registry. Every player
+// still holding cards flips, and once all have the battle is resolved. Ties
+// for the highest card go to war: the tied players lay cards face down and
+// flip again until one of them wins.
+type Game struct {
+	rng        *rand.Rand
+	pickup     Pickup
+	warCards   int
+	maxBattles int
+	players    []string
+	// stacks are the players' face-down stacks, top first, and won their
+	// winnings piles.
+	stacks  [][]model.Card
+	won     [][]model.Card
+	flipped []bool
+	battles int
+	last    *Battle
+}
+
+type View struct {
+	Cards   map[string]int `json:"cards"`
+	Flipped []string       `json:"flipped"`
+	Battles int            `json:"battles"`
+	Last    *Battle        `json:"last,omitempty"`
+}
+
+func NewGame() game.Game {
+	return &Game{}
+}
+
+// Setup deals the whole deck round the players. Options: "pickup" (bottom,
+// shuffle or winnings), "war_cards" laid face down in a war, "max_battles"
+// before the player with most cards wins, and "seed".
+func (g *Game) Setup(deck model.Deck, config game.Config) error {
+	n := len(config.Players)
+	if n < 2 || n > 4 {
+		return fmt.Errorf("War needs 2 to 4 players")
+	}
+	if len(deck.Cards) < n {
+		return fmt.Errorf("Not enough cards to deal War")
+	}
+
+	g.pickup = PickupBottom
+	switch pickup := Pickup(config.Options["pickup"]); pickup {
+	case "":
+	case PickupBottom, PickupShuffle, PickupWinnings:
+		g.pickup = pickup
+	default:
+		return fmt.Errorf("Unknown pickup rule %q", pickup)
+	}
+	g.warCards = DefaultWarCards
+	if cards, err := strconv.Atoi(config.Options["war_cards"]); err == nil && cards >= 0 {
+		g.warCards = cards
+	}
+	g.maxBattles = DefaultMaxBattles
+	if battles, err := strconv.Atoi(config.Options["max_battles"]); err == nil && battles > 0 {
+		g.maxBattles = battles
+	}
+	seed := time.Now().UnixNano()
+	if value, err := strconv.ParseInt(config.Options["seed"], 10, 64); err == nil {
+		seed = value
+	}
+	g.rng = rand.New(rand.NewSource(seed))
+
+	g.players = config.Players
+	g.stacks = make([][]model.Card, n)
+	g.won = make([][]model.Card, n)
+	g.flipped = make([]bool, n)
+	for i, c := range deck.Cards {
+		g.stacks[i%n] = append(g.stacks[i%n], c)
+	}
+	return nil
+}
+
+func (g *Game) Players() []string {
+	return g.players
+}
+
+func (g *Game) seat(player string) int {
+	for i, p := range g.players {
+		if p == player {
+			return i
+		}
+	}
+	return -1
+}
+
+func (g *Game) count(seat int) int {
+	return len(g.stacks[seat]) + len(g.won[seat])
+}
+
+func (g *Game) LegalActions(player string) []game.Action {
+	seat := g.seat(player)
+	if seat < 0 || g.Terminal() || g.flipped[seat] || g.count(seat) == 0 {
+		return nil
+	}
+	return []game.Action{{Type: "flip"}}
+}
+
+func (g *Game) Apply(player string, action game.Action) error {
+	if !game.IsLegal(g, player, action) {
+		return fmt.Errorf("Illegal action %q for %v", action.Type, player)
+	}
+
+	g.flipped[g.seat(player)] = true
+	for seat := range g.players {
+		if g.count(seat) > 0 && !g.flipped[seat] {
+			return nil
+		}
+	}
+	g.battle()
+	g.flipped = make([]bool, len(g.players))
+	return nil
+}
+
+// draw takes the top card of seat's stack, turning the winnings over first
+// if the stack is empty.
+func (g *Game) draw(seat int) (model.Card, bool) {
+	if len(g.stacks[seat]) == 0 {
+		g.stacks[seat], g.won[seat] = g.won[seat], nil
+		g.shuffle(g.stacks[seat])
+	}
+	if len(g.stacks[seat]) == 0 {
+		return model.Card{}, false
+	}
+	c := g.stacks[seat][0]
+	g.stacks[seat] = g.stacks[seat][1:]
+	return c, true
+}
+
+func (g *Game) shuffle(cards []model.Card) {
+	g.rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+}
+
+// battle has every player with cards flip one, then settles ties with wars.
+// A tied player who runs out of cards lays down all but their last and
+// flips that; one with no cards at all loses the war.
+func (g *Game) battle() {
+	battle := &Battle{Cards: make(map[string][]model.Card)}
+	var pot []model.Card
+	var contenders []int
+	for seat := range g.players {
+		if g.count(seat) > 0 {
+			contenders = append(contenders, seat)
+		}
+	}
+
+	winner := contenders[0]
+	for {
+		best, tied := 0, []int(nil)
+		for _, seat := range contenders {
+			c, ok := g.draw(seat)
+			if !ok {
+				continue
+			}
+			pot = append(pot, c)
+			battle.Cards[g.players[seat]] = append(battle.Cards[g.players[seat]], c)
+			switch rank := c.Rank(); {
+			case rank > best:
+				best, tied = rank, []int{seat}
+			case rank == best:
+				tied = append(tied, seat)
+			}
+		}
+		if len(tied) == 0 {
+			break
+		}
+		winner = tied[0]
+		if len(tied) == 1 {
+			break
+		}
+
+		battle.Wars++
+		contenders = nil
+		for _, seat := range tied {
+			down := g.warCards
+			if left := g.count(seat) - 1; left < down {
+				down = left
+			}
+			for i := 0; i < down; i++ {
+				c, _ := g.draw(seat)
+				pot = append(pot, c)
+			}
+			if g.count(seat) > 0 {
+				contenders = append(contenders, seat)
+			}
+		}
+		if len(contenders) == 0 {
+			break
+		}
+	}
+
+	switch g.pickup {
+	case PickupShuffle:
+		g.shuffle(pot)
+		g.stacks[winner] = append(g.stacks[winner], pot...)
+	case PickupWinnings:
+		g.won[winner] = append(g.won[winner], pot...)
+	default:
+		g.stacks[winner] = append(g.stacks[winner], pot...)
+	}
+	battle.Pot = len(pot)
+	battle.Winner = g.players[winner]
+	g.last = battle
+	g.battles++
+}
+
+// Terminal is true once one player holds every card or the battle limit is
+// reached.
+func (g *Game) Terminal() bool {
+	holding := 0
+	for seat := range g.players {
+		if g.count(seat) > 0 {
+			holding++
+		}
+	}
+	return holding <= 1 || g.battles >= g.maxBattles
+}
+
+// Scores are the cards each player holds.
+func (g *Game) Scores() map[string]int {
+	scores := make(map[string]int)
+	for seat, p := range g.players {
+		scores[p] = g.count(seat)
+	}
+	return scores
+}
+
+// View is the same for everyone: no player may look at their stack.
+func (g *Game) View(player string) interface{} {
+	view := View{Cards: g.Scores(), Flipped: []string{}, Battles: g.battles, Last: g.last}
+	for seat, p := range g.players {
+		if g.flipped[seat] {
+			view.Flipped = append(view.Flipped, p)
+		}
+	}
+	return view
+}
@@ -0,0 +1,105 @@
+package war
+
+import (
+	"testing"
+
+	"cardGame/deck/game"
+	"cardGame/deck/model"
+)
+
+func setup(t *testing.T, codes string, options map[string]string) game.Game {
+	t.Helper()
+	g := NewGame()
+	if err := g.Setup(model.NewDeck(false, codes), game.Config{Players: []string{"ann", "bob"}, Options: options}); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func flip(t *testing.T, g game.Game, players ...string) View {
+	t.Helper()
+	for _, player := range players {
+		if err := g.Apply(player, game.Action{Type: "flip"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g.View("").(View)
+}
+
+func TestBattle(t *testing.T) {
+	g := setup(t, "KH,2S,3H,4S", nil)
+
+	view := flip(t, g, "ann")
+	if view.Battles != 0 || len(view.Flipped) != 1 {
+		t.Fatalf("Battle resolved before Bob flipped: %+v", view)
+	}
+	if err := g.Apply("ann", game.Action{Type: "flip"}); err == nil {
+		t.Errorf("Ann flipped twice")
+	}
+
+	view = flip(t, g, "bob")
+	if view.Last.Winner != "ann" || view.Last.Pot != 2 || view.Cards["ann"] != 3 || view.Cards["bob"] != 1 {
+		t.Errorf("Unexpected battle: %+v %+v", view, view.Last)
+	}
+	if len(view.Flipped) != 0 || len(g.LegalActions("bob")) != 1 {
+		t.Errorf("Flips were not reset: %+v", view)
+	}
+}
+
+func TestWar(t *testing.T) {
+	g := setup(t, "5H,5S,2C,2D,3C,3D,4C,4D,9H,7S", nil)
+
+	view := flip(t, g, "ann", "bob")
+	if view.Last.Wars != 1 || view.Last.Winner != "ann" || view.Last.Pot != 10 {
+		t.Errorf("Unexpected war: %+v", view.Last)
+	}
+	if cards := view.Last.Cards["bob"]; len(cards) != 2 || cards[1].Code != "7S" {
+		t.Errorf("Unexpected face-up cards: %v", cards)
+	}
+	if !g.Terminal() || g.Scores()["ann"] != 10 {
+		t.Errorf("Ann should hold every card: %v", g.Scores())
+	}
+}
+
+func TestWarWithFewCards(t *testing.T) {
+	// Ann has two cards left after the tie, so she lays one down and flips
+	// the other; Bob flips his last card straight away.
+	g := setup(t, "5S,5H,2D,9H,3D", nil)
+
+	view := flip(t, g, "ann", "bob")
+	if view.Last.Winner != "bob" || view.Last.Pot != 5 || !g.Terminal() {
+		t.Errorf("Unexpected war: %+v", view.Last)
+	}
+}
+
+func TestPlayOut(t *testing.T) {
+	for _, pickup := range []string{"bottom", "shuffle", "winnings"} {
+		g := NewGame()
+		config := game.Config{Players: []string{"ann", "bob", "cat"}, Options: map[string]string{"pickup": pickup, "seed": "4", "max_battles": "300"}}
+		if err := g.Setup(model.NewSeededDeck(4, ""), config); err != nil {
+			t.Fatal(err)
+		}
+		for !g.Terminal() {
+			for _, player := range g.Players() {
+				if actions := g.LegalActions(player); len(actions) > 0 {
+					if err := g.Apply(player, actions[0]); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		}
+
+		total := 0
+		for _, cards := range g.Scores() {
+			total += cards
+		}
+		if total != 52 {
+			t.Errorf("%v: cards went missing: %v", pickup, g.Scores())
+		}
+	}
+
+	g := NewGame()
+	if err := g.Setup(model.NewDeck(false, ""), game.Config{Players: []string{"ann", "bob"}, Options: map[string]string{"pickup": "top"}}); err == nil {
+		t.Errorf("Setup accepted an unknown pickup rule")
+	}
+}
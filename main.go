@@ -1,32 +1,289 @@
 package main
 
 import (
+	"log"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"cardGame/deck/ai"
 	"cardGame/deck/api"
+	"cardGame/deck/baccarat"
+	"cardGame/deck/bot"
+	"cardGame/deck/capability"
+	"cardGame/deck/chat"
+	"cardGame/deck/climbing"
+	"cardGame/deck/cribbage"
 	"cardGame/deck/dao"
+	"cardGame/deck/game"
+	"cardGame/deck/rummy"
 	"cardGame/deck/service"
+	"cardGame/deck/shedding"
+	"cardGame/deck/spectate"
+	"cardGame/deck/speed"
+	"cardGame/deck/war"
 )
 
 func main() {
 	deckStorage := dao.NewDeckStorage()
 	deckService := service.NewDeckService(deckStorage)
 	deckHandler := api.NewDeckHandler(deckService, deckStorage)
+	solitaireService := service.NewSolitaireService(deckStorage)
+	solitaireHandler := api.NewSolitaireHandler(solitaireService)
+	bridgeService := service.NewBridgeService(deckStorage)
+	bridgeHandler := api.NewBridgeHandler(bridgeService)
+	ledgerService := service.NewLedgerService()
+	ledgerHandler := api.NewLedgerHandler(ledgerService)
+	registry := registerGames()
+	gameService := service.NewGameService(deckStorage, registry)
+	gameService.SetBank(ledgerService)
+	botService := newBotService()
+	gameService.SetBots(botService)
+	gameHandler := api.NewGameHandler(gameService)
+	ratingService := service.NewRatingService(gameService)
+	ratingService.Exclude("baccarat")
+	ratingHandler := api.NewRatingHandler(ratingService)
+	lobbyService := service.NewLobbyService(gameService)
+	lobbyService.SetSkill(ratingService.Skill)
+	lobbyService.OnStart(ratingService.Rate)
+	lobbyHandler := api.NewLobbyHandler(lobbyService)
+	tournamentHandler := api.NewTournamentHandler(service.NewTournamentService(gameService, deckService))
 
-	router := configureRoutes(deckHandler)
+	accountHandler := api.NewAccountHandler(service.NewAccountService())
+	apiKeyHandler := newAPIKeyHandler()
+	capabilityHandler := api.NewCapabilityHandler(service.NewCapabilityService(capabilityKey()), gameService)
+	chatService := service.NewChatService(lobbyService, gameService)
+	chatService.SetFilter(chatFilter())
+	chatHandler := api.NewChatHandler(chatService, gameService)
+	resumeHandler := api.NewResumeHandler(service.NewResumeService(gameService), gameService, chatService)
+	spectatorService := service.NewSpectatorService(gameService, deckService)
+	spectatorService.Delay = spectatorDelay()
+	spectatorHandler := api.NewSpectatorHandler(spectatorService, deckService)
+	botHandler := api.NewBotHandler(botService, service.NewArenaService(botService, registry))
+
+	router := configureRoutes(handlers{
+		deck:       deckHandler,
+		solitaire:  solitaireHandler,
+		bridge:     bridgeHandler,
+		game:       gameHandler,
+		lobby:      lobbyHandler,
+		tournament: tournamentHandler,
+		ledger:     ledgerHandler,
+		rating:     ratingHandler,
+		account:    accountHandler,
+		apiKey:     apiKeyHandler,
+		capability: capabilityHandler,
+		resume:     resumeHandler,
+		spectator:  spectatorHandler,
+		chat:       chatHandler,
+		bot:        botHandler,
+	})
 
 	http.Handle("/", router)
 	http.ListenAndServe(":8080", nil)
 }
 
-func configureRoutes(deckHandler *api.DeckHandler) *mux.Router {
+// newAPIKeyHandler requires API keys when ADMIN_API_KEY is set, and adds
+// that key as the admin key that issues the others.
+func newAPIKeyHandler() *api.APIKeyHandler {
+	apiKeyService := service.NewAPIKeyService()
+	raw := os.Getenv("ADMIN_API_KEY")
+	if raw == "" {
+		return api.NewAPIKeyHandler(apiKeyService, false)
+	}
+	if _, err := apiKeyService.Import("admin", raw); err != nil {
+		log.Fatalf("ADMIN_API_KEY: %v", err)
+	}
+	return api.NewAPIKeyHandler(apiKeyService, true)
+}
+
+// capabilityKey returns the key capability tokens are signed with, from
+// CAPABILITY_KEY. Without it a random key is used, and tokens stop working
+// when the server restarts.
+func capabilityKey() []byte {
+	key := os.Getenv("CAPABILITY_KEY")
+	if key == "" {
+		return capability.NewKey()
+	}
+	if len(key) < 32 {
+		log.Fatalf("CAPABILITY_KEY needs at least 32 characters")
+	}
+	return []byte(key)
+}
+
+// spectatorDelay returns the broadcast delay of spectator feeds, from
+// SPECTATOR_DELAY (such as "45s"), or the default.
+func spectatorDelay() time.Duration {
+	delay := os.Getenv("SPECTATOR_DELAY")
+	if delay == "" {
+		return service.DefaultSpectatorDelay
+	}
+	d, err := time.ParseDuration(delay)
+	if err != nil || d < 0 || d > spectate.MaxDelay {
+		log.Fatalf("SPECTATOR_DELAY must be a duration up to %v", spectate.MaxDelay)
+	}
+	return d
+}
+
+// chatFilter masks the comma-separated words in CHAT_BLOCKLIST in chat
+// messages, or with CHAT_BLOCK=true refuses messages containing them.
+func chatFilter() chat.Filter {
+	filter := chat.NewWordList(strings.Split(os.Getenv("CHAT_BLOCKLIST"), ","))
+	filter.Block = os.Getenv("CHAT_BLOCK") == "true"
+	return filter
+}
+
+// newBotService registers the built-in bots: random and first, and the AI
+// at each difficulty as ai-easy, ai-medium and ai-hard. AI_MOVE_TIME (such
+// as "5s") sets how long the AI may think about a move.
+func newBotService() *service.BotService {
+	bots := service.NewBotService()
+	bots.RegisterPlayer("random", bot.Random)
+	bots.RegisterPlayer("first", bot.First)
+
+	limit := ai.DefaultTimeLimit
+	if value := os.Getenv("AI_MOVE_TIME"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			log.Fatalf("AI_MOVE_TIME must be a positive duration")
+		}
+		limit = d
+	}
+	for _, difficulty := range ai.Difficulties {
+		player, _ := ai.New(difficulty, limit)
+		bots.RegisterPlayer("ai-"+string(difficulty), player)
+	}
+	return bots
+}
+
+// registerGames lists the games hosted under /games/{type}.
+func registerGames() *game.Registry {
+	registry := game.NewRegistry()
+	registry.Register("highcard", game.NewHighCard)
+	registry.Register("baccarat", baccarat.NewGame)
+	registry.Register("gin", rummy.NewGinGame)
+	registry.Register("cribbage", cribbage.NewGame)
+	registry.Register("president", climbing.NewPresident)
+	registry.Register("bigtwo", climbing.NewBigTwo)
+	registry.Register("crazyeights", shedding.NewGame)
+	registry.Register("war", war.NewGame)
+	registry.Register("speed", speed.NewGame)
+	return registry
+}
+
+// handlers are the API handlers the routes are served by.
+type handlers struct {
+	deck       *api.DeckHandler
+	solitaire  *api.SolitaireHandler
+	bridge     *api.BridgeHandler
+	game       *api.GameHandler
+	lobby      *api.LobbyHandler
+	tournament *api.TournamentHandler
+	ledger     *api.LedgerHandler
+	rating     *api.RatingHandler
+	account    *api.AccountHandler
+	apiKey     *api.APIKeyHandler
+	capability *api.CapabilityHandler
+	resume     *api.ResumeHandler
+	spectator  *api.SpectatorHandler
+	chat       *api.ChatHandler
+	bot        *api.BotHandler
+}
+
+func configureRoutes(h handlers) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(h.capability.Authenticate, h.apiKey.Authenticate, h.account.Authenticate)
 
-	router.HandleFunc("/health", deckHandler.HealthCheck).Methods("GET")
-	router.HandleFunc("/deck", deckHandler.CreateDeck).Methods("GET")
-	router.HandleFunc("/deck/{deckID}/draw", deckHandler.DrawCards).Methods("GET")
+	router.HandleFunc("/health", h.deck.HealthCheck).Methods("GET")
+	router.HandleFunc("/deck", h.deck.CreateDeck).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/draw", h.deck.DrawCards).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/deal", h.deck.Deal).Methods("POST")
+	router.HandleFunc("/deck/{deckID}", h.deck.DeleteDeck).Methods("DELETE")
+	router.HandleFunc("/deck/{deckID}/claim", h.deck.Claim).Methods("POST")
+	router.HandleFunc("/deck/{deckID}/grants", h.deck.Grant).Methods("POST")
+	router.HandleFunc("/deck/{deckID}/grants/{keyID}", h.deck.Ungrant).Methods("DELETE")
+	router.HandleFunc("/deck/{deckID}/spectators", h.spectator.Subscribe).Methods("POST")
+	router.HandleFunc("/deck/{deckID}/spectators/{subscriptionID}", h.spectator.Feed).Methods("GET")
+	router.HandleFunc("/deck/{deckID}/spectators/{subscriptionID}", h.spectator.Unsubscribe).Methods("DELETE")
+	router.HandleFunc("/deck/{deckID}/delay", h.spectator.SetDelay).Methods("PUT")
+	router.HandleFunc("/solitaire/{variant}/deal", h.solitaire.Deal).Methods("GET")
+	router.HandleFunc("/solitaire/{variant}/solve", h.solitaire.Solve).Methods("POST")
+	router.HandleFunc("/bridge/deals", h.bridge.DealBoards).Methods("GET")
+	router.HandleFunc("/bridge/deals/{deckID}/analysis", h.bridge.AnalyzeDeal).Methods("GET")
+	router.HandleFunc("/bridge/deals/{deckID}/results", h.bridge.BoardResults).Methods("GET")
+	router.HandleFunc("/bridge/tables", h.bridge.StartTable).Methods("POST")
+	router.HandleFunc("/bridge/tables/{tableID}", h.bridge.GetTable).Methods("GET")
+	router.HandleFunc("/bridge/tables/{tableID}/calls", h.bridge.MakeCall).Methods("POST")
+	router.HandleFunc("/bridge/tables/{tableID}/hands/{seat}", h.bridge.GetHand).Methods("GET")
+	router.HandleFunc("/bridge/tables/{tableID}/result", h.bridge.RecordResult).Methods("POST")
+	router.HandleFunc("/bridge/rubbers", h.bridge.NewRubber).Methods("POST")
+	router.HandleFunc("/bridge/rubbers/{rubberID}", h.bridge.GetRubber).Methods("GET")
+	router.HandleFunc("/games", h.game.ListTypes).Methods("GET")
+	router.HandleFunc("/games/{type}", h.game.CreateGame).Methods("POST")
+	router.HandleFunc("/games/{type}/tools/{tool}", h.game.RunTool).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}", h.game.GetGame).Methods("GET")
+	router.HandleFunc("/games/{type}/{gameID}", h.game.PostAction).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/attach", h.resume.Attach).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/resume", h.resume.Resume).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/events", h.resume.Events).Methods("GET")
+	router.HandleFunc("/games/{type}/{gameID}/spectators", h.spectator.Subscribe).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/spectators/{subscriptionID}", h.spectator.Feed).Methods("GET")
+	router.HandleFunc("/games/{type}/{gameID}/spectators/{subscriptionID}", h.spectator.Unsubscribe).Methods("DELETE")
+	router.HandleFunc("/games/{type}/{gameID}/delay", h.spectator.SetDelay).Methods("PUT")
+	router.HandleFunc("/games/{type}/{gameID}/chat", h.chat.Post).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/chat", h.chat.Messages).Methods("GET")
+	router.HandleFunc("/games/{type}/{gameID}/chat/mutes", h.chat.Mute).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/chat/mutes/{player}", h.chat.Unmute).Methods("DELETE")
+	router.HandleFunc("/games/{type}/{gameID}/chat/bans", h.chat.Ban).Methods("POST")
+	router.HandleFunc("/games/{type}/{gameID}/chat/bans/{player}", h.chat.Unban).Methods("DELETE")
+	router.HandleFunc("/rooms", h.lobby.ListRooms).Methods("GET")
+	router.HandleFunc("/rooms", h.lobby.CreateRoom).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}", h.lobby.GetRoom).Methods("GET")
+	router.HandleFunc("/rooms/{roomID}/join", h.lobby.JoinRoom).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/leave", h.lobby.LeaveRoom).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/ready", h.lobby.Ready).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/start", h.lobby.StartRoom).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/chat", h.chat.Post).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/chat", h.chat.Messages).Methods("GET")
+	router.HandleFunc("/rooms/{roomID}/chat/mutes", h.chat.Mute).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/chat/mutes/{player}", h.chat.Unmute).Methods("DELETE")
+	router.HandleFunc("/rooms/{roomID}/chat/bans", h.chat.Ban).Methods("POST")
+	router.HandleFunc("/rooms/{roomID}/chat/bans/{player}", h.chat.Unban).Methods("DELETE")
+	router.HandleFunc("/matchmaking", h.lobby.Enqueue).Methods("POST")
+	router.HandleFunc("/matchmaking", h.lobby.GetMatch).Methods("GET")
+	router.HandleFunc("/matchmaking", h.lobby.Dequeue).Methods("DELETE")
+	router.HandleFunc("/tournaments", h.tournament.CreateTournament).Methods("POST")
+	router.HandleFunc("/tournaments/{tournamentID}", h.tournament.GetTournament).Methods("GET")
+	router.HandleFunc("/tournaments/{tournamentID}/matches/{matchID}/result", h.tournament.ReportMatch).Methods("POST")
+	router.HandleFunc("/tournaments/{tournamentID}/tables/{table}/result", h.tournament.ReportTable).Methods("POST")
+	router.HandleFunc("/bots", h.bot.RegisterBot).Methods("POST")
+	router.HandleFunc("/bots", h.bot.ListBots).Methods("GET")
+	router.HandleFunc("/bots/{name}", h.bot.RemoveBot).Methods("DELETE")
+	router.HandleFunc("/arena", h.bot.StartArena).Methods("POST")
+	router.HandleFunc("/arena/{runID}", h.bot.GetArena).Methods("GET")
+	router.HandleFunc("/ledger/players/{player}", h.ledger.GetPlayer).Methods("GET")
+	router.HandleFunc("/ledger/players/{player}/buyin", h.ledger.BuyIn).Methods("POST")
+	router.HandleFunc("/ledger/players/{player}/cashout", h.ledger.CashOut).Methods("POST")
+	router.HandleFunc("/ledger/transfers", h.ledger.Transfer).Methods("POST")
+	router.HandleFunc("/ledger/pots/{potID}", h.ledger.GetPot).Methods("GET")
+	router.HandleFunc("/ledger/audit", h.ledger.Audit).Methods("GET")
+	router.HandleFunc("/ratings/{type}", h.rating.Leaderboard).Methods("GET")
+	router.HandleFunc("/ratings/{type}/players/{player}", h.rating.GetPlayer).Methods("GET")
+	router.HandleFunc("/ratings/{type}/results", h.rating.RecordResult).Methods("POST")
+	router.HandleFunc("/accounts", h.account.Register).Methods("POST")
+	router.HandleFunc("/accounts/{id}", h.account.GetAccount).Methods("GET")
+	router.HandleFunc("/accounts/{id}/profile", h.account.UpdateProfile).Methods("PUT")
+	router.HandleFunc("/accounts/{id}/password", h.account.ChangePassword).Methods("POST")
+	router.HandleFunc("/sessions", h.account.Login).Methods("POST")
+	router.HandleFunc("/sessions", h.account.Logout).Methods("DELETE")
+	router.HandleFunc("/keys", h.apiKey.CreateKey).Methods("POST")
+	router.HandleFunc("/keys", h.apiKey.ListKeys).Methods("GET")
+	router.HandleFunc("/keys/{keyID}", h.apiKey.RevokeKey).Methods("DELETE")
+	router.HandleFunc("/capabilities", h.capability.Issue).Methods("POST")
+	router.HandleFunc("/capabilities/revocations", h.capability.Revoke).Methods("POST")
 
 	return router
 }
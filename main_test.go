@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"cardGame/deck/api"
+	"cardGame/deck/bot"
 	"cardGame/deck/dao"
 	"cardGame/deck/service"
 )
@@ -67,3 +68,35 @@ func TestDrawCardsHandler(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code, "Expected BAD Request")
 }
+
+// TestRegisteredGamesPlay has bots play every registered game type in the
+// arena, at the smallest table it can be set up for.
+func TestRegisteredGamesPlay(t *testing.T) {
+	bots := service.NewBotService()
+	bots.RegisterPlayer("first", bot.First)
+	bots.RegisterPlayer("random", bot.Random)
+	registry := registerGames()
+	arena := service.NewArenaService(bots, registry)
+
+	for _, gameType := range registry.Types() {
+		var started service.ArenaRun
+		var err error
+		for seats := []string{"random"}; len(seats) <= 6; seats = append(seats, "first") {
+			config := service.ArenaConfig{GameType: gameType, Bots: seats, Games: 4, Parallel: 2, MaxMoves: 2000}
+			if started, err = arena.Start(config); err == nil {
+				break
+			}
+		}
+		if !assert.NoError(t, err, "No table could be set up for %v", gameType) {
+			continue
+		}
+
+		run, err := arena.Wait(started.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, run.Played, "Unexpected run of %v: %+v", gameType, run)
+		assert.Equal(t, 0, run.Failed, "Games of %v failed", gameType)
+		for _, entrant := range run.Entrants {
+			assert.Equal(t, 0, entrant.Errors, "%v erred at %v", entrant.Player, gameType)
+		}
+	}
+}